@@ -0,0 +1,141 @@
+package diffview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrNoBase is returned by ResolveBase when no base branch can be found.
+var ErrNoBase = errors.New("no base branch found")
+
+// Base is the branch a branch's changes are compared against, and why it
+// was picked.
+type Base struct {
+	Branch string
+	Reason string // Such as "origin/HEAD" or "upstream tracking branch"
+}
+
+// String returns the branch with its reason, like "main (origin/HEAD)".
+func (b Base) String() string {
+	if b.Reason == "" {
+		return b.Branch
+	}
+	return fmt.Sprintf("%s (%s)", b.Branch, b.Reason)
+}
+
+// ResolveBase picks the base branch of the branch checked out in repoPath.
+// Its upstream tracking branch wins, unless that is the same branch on a
+// remote, as after git push -u. Otherwise the default branch, from
+// origin/HEAD or named main, master or trunk locally or on a remote, is
+// compared with the nearest ancestor branch: the branch whose merge base
+// with HEAD is the fewest commits behind it. The nearest ancestor wins only
+// when it is strictly closer, which finds the parent of a stacked branch.
+//
+// It returns ErrNoBase if no candidate exists.
+func ResolveBase(ctx context.Context, git GitRunner, repoPath string) (Base, error) {
+	current, _ := git.CurrentBranch(ctx, repoPath)
+
+	if upstream, err := git.Upstream(ctx, repoPath); err == nil && !sameBranch(upstream, current) {
+		return Base{Branch: upstream, Reason: "upstream tracking branch"}, nil
+	}
+	var base Base
+	if branch, err := git.DefaultBranch(ctx, repoPath); err == nil {
+		base = Base{Branch: branch, Reason: "origin/HEAD"}
+	}
+	if err := ctx.Err(); err != nil {
+		return Base{}, err
+	}
+
+	branches, err := git.Branches(ctx, repoPath)
+	if err != nil {
+		return Base{}, err
+	}
+	if base.Branch == "" {
+		base = defaultBranchByName(branches)
+	}
+
+	var candidates []ancestor
+	for _, branch := range branches {
+		if sameBranch(branch, current) {
+			continue
+		}
+		n, err := git.CountCommits(ctx, repoPath, branch, "HEAD")
+		// A branch at or ahead of HEAD has nothing to compare against
+		if err == nil && n > 0 {
+			candidates = append(candidates, ancestor{branch: branch, ahead: n})
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return Base{}, err
+	}
+	slices.SortStableFunc(candidates, func(a, b ancestor) int { return a.ahead - b.ahead })
+
+	// Only a branch strictly closer than the default branch can beat it
+	limit := -1
+	if base.Branch != "" {
+		n, err := git.CountCommits(ctx, repoPath, base.Branch, "HEAD")
+		if err != nil {
+			return base, nil
+		}
+		limit = n
+	}
+	nearest, distance := "", 0
+	for _, c := range candidates {
+		if limit >= 0 && c.ahead >= limit {
+			break
+		}
+		// Unrelated history counts every commit of HEAD, so the count alone
+		// can't rule it out
+		if _, err := git.MergeBase(ctx, repoPath, c.branch, "HEAD"); err == nil {
+			nearest, distance = c.branch, c.ahead
+			break
+		}
+	}
+	if nearest == "" {
+		if base.Branch != "" {
+			return base, nil
+		}
+		return Base{}, ErrNoBase
+	}
+	reason := fmt.Sprintf("nearest ancestor branch, %d commits back", distance)
+	if distance == 1 {
+		reason = "nearest ancestor branch, 1 commit back"
+	}
+	return Base{Branch: nearest, Reason: reason}, nil
+}
+
+// defaultBranchByName returns the first of main, master and trunk found in
+// branches, locally or on a remote, or a zero Base if there is none.
+func defaultBranchByName(branches []string) Base {
+	for _, name := range []string{"main", "master", "trunk"} {
+		for _, branch := range branches {
+			if branch == name || strings.HasSuffix(branch, "/"+name) {
+				return Base{Branch: branch, Reason: "default branch name"}
+			}
+		}
+	}
+	return Base{}
+}
+
+// ancestor is a candidate base branch that HEAD is ahead commits ahead of.
+type ancestor struct {
+	branch string
+	ahead  int
+}
+
+// sameBranch reports whether branch is current or its copy on a remote,
+// such as origin/feature for feature. A longer name that merely ends in
+// /current, like origin/fix/feature, is a different branch.
+func sameBranch(branch, current string) bool {
+	if current == "" {
+		return false
+	}
+	if branch == current {
+		return true
+	}
+	remote, ok := strings.CutSuffix(branch, "/"+current)
+	return ok && remote != "" && !strings.Contains(remote, "/")
+}
@@ -0,0 +1,170 @@
+package diffview_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// baseRepo returns a mock repository on branch current with the given
+// upstream, origin/HEAD and branches, where HEAD is ahead[b] commits ahead
+// of branch b; a negative count means unrelated history. Empty upstream
+// and originHEAD are unset.
+func baseRepo(current, upstream, originHEAD string, branches []string, ahead map[string]int) *mock.GitRunner {
+	return &mock.GitRunner{
+		CurrentBranchFn: func(context.Context, string) (string, error) { return current, nil },
+		UpstreamFn: func(context.Context, string) (string, error) {
+			if upstream == "" {
+				return "", errors.New("no upstream")
+			}
+			return upstream, nil
+		},
+		DefaultBranchFn: func(context.Context, string) (string, error) {
+			if originHEAD == "" {
+				return "", errors.New("no remote configured: origin/HEAD not set")
+			}
+			return originHEAD, nil
+		},
+		BranchesFn: func(context.Context, string) ([]string, error) { return branches, nil },
+		MergeBaseFn: func(_ context.Context, _, ref1, _ string) (string, error) {
+			if ahead[ref1] < 0 {
+				return "", errors.New("no merge base")
+			}
+			return "abc123", nil
+		},
+		CountCommitsFn: func(_ context.Context, _, base, _ string) (int, error) {
+			// Unrelated history counts all of HEAD's commits
+			if ahead[base] < 0 {
+				return 1, nil
+			}
+			return ahead[base], nil
+		},
+	}
+}
+
+func TestResolveBase(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		git  *mock.GitRunner
+		want diffview.Base
+	}{
+		{
+			name: "upstream tracking branch",
+			git:  baseRepo("child", "parent", "main", []string{"child", "main", "parent"}, nil),
+			want: diffview.Base{Branch: "parent", Reason: "upstream tracking branch"},
+		},
+		{
+			name: "own remote copy is not a base",
+			git:  baseRepo("feature", "origin/feature", "main", []string{"feature", "main", "origin/feature"}, nil),
+			want: diffview.Base{Branch: "main", Reason: "origin/HEAD"},
+		},
+		{
+			name: "default branch name",
+			git:  baseRepo("feature", "", "", []string{"feature", "master", "origin/main"}, nil),
+			want: diffview.Base{Branch: "origin/main", Reason: "default branch name"},
+		},
+		{
+			name: "default branch name prefers main",
+			git:  baseRepo("feature", "", "", []string{"feature", "master", "trunk", "main"}, nil),
+			want: diffview.Base{Branch: "main", Reason: "default branch name"},
+		},
+		{
+			name: "nearest ancestor branch",
+			git: baseRepo("part-3", "", "", []string{"develop", "part-1", "part-2", "part-3", "part-4", "unrelated"},
+				map[string]int{"develop": 9, "part-1": 5, "part-2": 2, "part-4": 0, "unrelated": -1}),
+			want: diffview.Base{Branch: "part-2", Reason: "nearest ancestor branch, 2 commits back"},
+		},
+		{
+			name: "stacked branch with origin/HEAD set",
+			git: baseRepo("part-2", "", "origin/main", []string{"main", "part-1", "part-2", "origin/main"},
+				map[string]int{"main": 5, "part-1": 2, "origin/main": 5}),
+			want: diffview.Base{Branch: "part-1", Reason: "nearest ancestor branch, 2 commits back"},
+		},
+		{
+			name: "origin/HEAD wins a tie",
+			git: baseRepo("feature", "", "origin/main", []string{"develop", "feature", "origin/main"},
+				map[string]int{"develop": 3, "origin/main": 3}),
+			want: diffview.Base{Branch: "origin/main", Reason: "origin/HEAD"},
+		},
+		{
+			name: "stacked branch with a default branch name",
+			git: baseRepo("part-2", "", "", []string{"main", "part-1", "part-2"},
+				map[string]int{"main": 5, "part-1": 2}),
+			want: diffview.Base{Branch: "part-1", Reason: "nearest ancestor branch, 2 commits back"},
+		},
+		{
+			name: "branch ending in the current name is not a remote copy",
+			git: baseRepo("feature", "", "", []string{"feature", "origin/fix/feature"},
+				map[string]int{"origin/fix/feature": 1}),
+			want: diffview.Base{Branch: "origin/fix/feature", Reason: "nearest ancestor branch, 1 commit back"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			base, err := diffview.ResolveBase(context.Background(), tt.git, "/repo")
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, base)
+		})
+	}
+
+	t.Run("branch whose commits can't be listed is skipped", func(t *testing.T) {
+		t.Parallel()
+		git := baseRepo("part-3", "", "", []string{"broken", "part-2", "part-3"}, map[string]int{"broken": 1, "part-2": 2})
+		countCommits := git.CountCommitsFn
+		git.CountCommitsFn = func(ctx context.Context, repoPath, base, head string) (int, error) {
+			if base == "broken" {
+				return 0, errors.New("bad object")
+			}
+			return countCommits(ctx, repoPath, base, head)
+		}
+
+		base, err := diffview.ResolveBase(context.Background(), git, "/repo")
+
+		require.NoError(t, err)
+		assert.Equal(t, diffview.Base{Branch: "part-2", Reason: "nearest ancestor branch, 2 commits back"}, base)
+	})
+
+	t.Run("merge base is checked only for the nearest candidates", func(t *testing.T) {
+		t.Parallel()
+		git := baseRepo("part-3", "", "origin/main", []string{"origin/main", "part-1", "part-2", "part-3"},
+			map[string]int{"origin/main": 9, "part-1": 5, "part-2": 2})
+		var checked []string
+		mergeBase := git.MergeBaseFn
+		git.MergeBaseFn = func(ctx context.Context, repoPath, ref1, ref2 string) (string, error) {
+			checked = append(checked, ref1)
+			return mergeBase(ctx, repoPath, ref1, ref2)
+		}
+
+		base, err := diffview.ResolveBase(context.Background(), git, "/repo")
+
+		require.NoError(t, err)
+		assert.Equal(t, diffview.Base{Branch: "part-2", Reason: "nearest ancestor branch, 2 commits back"}, base)
+		assert.Equal(t, []string{"part-2"}, checked)
+	})
+
+	t.Run("no base", func(t *testing.T) {
+		t.Parallel()
+		git := baseRepo("feature", "", "", []string{"feature", "origin/feature"}, nil)
+
+		_, err := diffview.ResolveBase(context.Background(), git, "/repo")
+
+		require.ErrorIs(t, err, diffview.ErrNoBase)
+	})
+}
+
+func TestBase_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "main (origin/HEAD)", diffview.Base{Branch: "main", Reason: "origin/HEAD"}.String())
+	assert.Equal(t, "main", diffview.Base{Branch: "main"}.String())
+}
@@ -0,0 +1,152 @@
+package bubbletea
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fwojciec/diffstory"
+)
+
+// commentEditor manages review comments for a viewer: the comments anchored
+// to diff lines, their persistence, and the comment currently being edited.
+// Each line holds at most one comment; editing a line replaces its comment.
+type commentEditor struct {
+	comments map[lineAnchor]diffview.Comment
+	store    diffview.CommentStore
+	path     string
+
+	editing bool
+	anchor  lineAnchor    // line being commented on while editing
+	line    diffview.Line // content of that line, shown above the input
+	input   textarea.Model
+}
+
+// newCommentEditor creates a commentEditor seeded with existing comments.
+// Comments are persisted to path after every edit when store is non-nil.
+func newCommentEditor(store diffview.CommentStore, path string, existing []diffview.Comment) commentEditor {
+	comments := make(map[lineAnchor]diffview.Comment, len(existing))
+	for _, c := range existing {
+		comments[lineAnchor{file: c.File, side: c.Side, line: c.Line}] = c
+	}
+	return commentEditor{
+		comments: comments,
+		store:    store,
+		path:     path,
+	}
+}
+
+// begin starts editing the comment on anchor, prefilled with any existing text.
+func (e *commentEditor) begin(anchor lineAnchor, line diffview.Line, width, height int) tea.Cmd {
+	ta := textarea.New()
+	ta.Placeholder = "Enter review comment..."
+	ta.ShowLineNumbers = false
+	ta.SetWidth(width - 4)
+	ta.SetHeight(max(height-8, 1))
+
+	if existing, ok := e.comments[anchor]; ok {
+		ta.SetValue(existing.Body)
+	}
+
+	ta.Focus()
+	e.input = ta
+	e.anchor = anchor
+	e.line = line
+	e.editing = true
+
+	return textarea.Blink
+}
+
+// commit ends the edit and stores the input as the anchor's comment.
+// An empty input removes the comment.
+func (e *commentEditor) commit() {
+	e.editing = false
+
+	body := strings.TrimSpace(e.input.Value())
+	if body == "" {
+		delete(e.comments, e.anchor)
+	} else {
+		e.comments[e.anchor] = diffview.Comment{
+			File:      e.anchor.file,
+			Side:      e.anchor.side,
+			Line:      e.anchor.line,
+			Body:      body,
+			CreatedAt: time.Now(),
+		}
+	}
+
+	e.persist()
+}
+
+// update forwards a message to the comment input.
+func (e *commentEditor) update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	e.input, cmd = e.input.Update(msg)
+	return cmd
+}
+
+// view renders the full-screen comment editor.
+// save is the binding that saves the comment, shown in the hint.
+func (e commentEditor) view(renderer *lipgloss.Renderer, save key.Binding) string {
+	if renderer == nil {
+		renderer = lipgloss.DefaultRenderer()
+	}
+
+	var s strings.Builder
+
+	header := fmt.Sprintf("COMMENT %s:%d (%s)", e.anchor.file, e.anchor.line, e.anchor.side)
+	s.WriteString(renderer.NewStyle().Bold(true).Render(header))
+	s.WriteString("\n\n")
+	s.WriteString(renderer.NewStyle().Faint(true).Render(
+		e.line.Prefix() + strings.TrimSuffix(e.line.Content, "\n")))
+	s.WriteString("\n\n")
+	s.WriteString(e.input.View())
+	s.WriteString("\n\n")
+	s.WriteString(renderer.NewStyle().Faint(true).Render(fmt.Sprintf("[%s] save and exit (empty comment deletes)", keyLabel(save))))
+
+	return s.String()
+}
+
+// list returns all comments ordered by file, side and line.
+func (e commentEditor) list() []diffview.Comment {
+	comments := make([]diffview.Comment, 0, len(e.comments))
+	for _, c := range e.comments {
+		comments = append(comments, c)
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		a, b := comments[i], comments[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Side != b.Side {
+			return a.Side < b.Side
+		}
+		return a.Line < b.Line
+	})
+	return comments
+}
+
+func (e *commentEditor) persist() {
+	if e.store == nil || e.path == "" {
+		return
+	}
+	// Best-effort save - errors are silently ignored in UI
+	_ = e.store.Save(e.path, e.list())
+}
+
+// anchorAtRow returns the first commentable line shown at or below row.
+// Header rows are skipped so the top of the viewport can sit on a file or
+// hunk header and still target the first line beneath it.
+func anchorAtRow(rows []lineAnchor, row int) (lineAnchor, bool) {
+	for i := max(row, 0); i < len(rows); i++ {
+		if rows[i] != (lineAnchor{}) {
+			return rows[i], true
+		}
+	}
+	return lineAnchor{}, false
+}
@@ -0,0 +1,283 @@
+package bubbletea_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/exp/teatest"
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/bubbletea"
+	dv "github.com/fwojciec/diffstory/lipgloss"
+	"github.com/fwojciec/diffstory/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func commentDiff() *diffview.Diff {
+	return &diffview.Diff{
+		Files: []diffview.FileDiff{
+			{
+				OldPath:   "main.go",
+				NewPath:   "main.go",
+				Operation: diffview.FileModified,
+				Hunks: []diffview.Hunk{
+					{
+						OldStart: 1, OldCount: 2, NewStart: 1, NewCount: 2,
+						Lines: []diffview.Line{
+							{Type: diffview.LineContext, Content: "package main", OldLineNum: 1, NewLineNum: 1},
+							{Type: diffview.LineDeleted, Content: "var x = 1", OldLineNum: 2},
+							{Type: diffview.LineAdded, Content: "var x = 2", NewLineNum: 2},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// commentRecorder captures the comments saved through a mock.CommentStore.
+type commentRecorder struct {
+	mu       sync.Mutex
+	path     string
+	comments []diffview.Comment
+	saves    int
+}
+
+func (r *commentRecorder) store() *mock.CommentStore {
+	return &mock.CommentStore{
+		SaveFn: func(path string, comments []diffview.Comment) error {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.path = path
+			r.comments = comments
+			r.saves++
+			return nil
+		},
+	}
+}
+
+func (r *commentRecorder) Saves() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.saves
+}
+
+func (r *commentRecorder) Saved() (string, []diffview.Comment) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.path, r.comments
+}
+
+func typeText(tm *teatest.TestModel, text string) {
+	tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+}
+
+func TestModel_RendersExistingCommentsInline(t *testing.T) {
+	t.Parallel()
+
+	m := bubbletea.NewModel(commentDiff(),
+		bubbletea.WithExistingComments([]diffview.Comment{
+			{File: "main.go", Side: diffview.SideOld, Line: 2, Body: "why change x?"},
+		}),
+	)
+	tm := teatest.NewTestModel(t, m, teatest.WithInitialTermSize(80, 24))
+
+	teatest.WaitFor(t, tm.Output(), func(out []byte) bool {
+		return bytes.Contains(out, []byte("┃ why change x?"))
+	})
+
+	tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	tm.WaitFinished(t, teatest.WithFinalTimeout(time.Second))
+}
+
+func TestModel_AddComment(t *testing.T) {
+	t.Parallel()
+
+	recorder := &commentRecorder{}
+	m := bubbletea.NewModel(commentDiff(),
+		bubbletea.WithCommentStore(recorder.store(), "/tmp/comments.jsonl"),
+	)
+	tm := teatest.NewTestModel(t, m, teatest.WithInitialTermSize(80, 24))
+
+	teatest.WaitFor(t, tm.Output(), func(out []byte) bool {
+		return bytes.Contains(out, []byte("package main"))
+	})
+
+	// The viewport top is the file header, so the first diff line is targeted
+	tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	teatest.WaitFor(t, tm.Output(), func(out []byte) bool {
+		return bytes.Contains(out, []byte("COMMENT main.go:1 (new)"))
+	})
+
+	typeText(tm, "keep the package doc")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEsc})
+
+	teatest.WaitFor(t, tm.Output(), func(out []byte) bool {
+		return bytes.Contains(out, []byte("┃ keep the package doc"))
+	})
+	require.Equal(t, 1, recorder.Saves())
+
+	path, saved := recorder.Saved()
+	assert.Equal(t, "/tmp/comments.jsonl", path)
+	require.Len(t, saved, 1)
+	assert.Equal(t, "main.go", saved[0].File)
+	assert.Equal(t, diffview.SideNew, saved[0].Side)
+	assert.Equal(t, 1, saved[0].Line)
+	assert.Equal(t, "keep the package doc", saved[0].Body)
+
+	tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	final, ok := tm.FinalModel(t, teatest.WithFinalTimeout(time.Second)).(bubbletea.Model)
+	require.True(t, ok)
+	assert.Len(t, final.Comments(), 1)
+}
+
+func TestModel_EmptyCommentDeletesExisting(t *testing.T) {
+	t.Parallel()
+
+	recorder := &commentRecorder{}
+	m := bubbletea.NewModel(commentDiff(),
+		bubbletea.WithCommentStore(recorder.store(), "/tmp/comments.jsonl"),
+		bubbletea.WithExistingComments([]diffview.Comment{
+			{File: "main.go", Side: diffview.SideNew, Line: 1, Body: "x"},
+		}),
+	)
+	tm := teatest.NewTestModel(t, m, teatest.WithInitialTermSize(80, 24))
+
+	teatest.WaitFor(t, tm.Output(), func(out []byte) bool {
+		return bytes.Contains(out, []byte("package main"))
+	})
+
+	tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	teatest.WaitFor(t, tm.Output(), func(out []byte) bool {
+		return bytes.Contains(out, []byte("COMMENT main.go:1 (new)"))
+	})
+	tm.Send(tea.KeyMsg{Type: tea.KeyBackspace})
+	tm.Send(tea.KeyMsg{Type: tea.KeyEsc})
+
+	teatest.WaitFor(t, tm.Output(), func([]byte) bool {
+		return recorder.Saves() == 1
+	})
+	_, saved := recorder.Saved()
+	assert.Empty(t, saved)
+
+	tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	tm.WaitFinished(t, teatest.WithFinalTimeout(time.Second))
+}
+
+func TestStoryModel_AddComment(t *testing.T) {
+	t.Parallel()
+
+	story := &diffview.StoryClassification{
+		Sections: []diffview.Section{
+			{Role: "core", Title: "Bump x", Hunks: []diffview.HunkRef{{File: "main.go", HunkIndex: 0}}},
+		},
+	}
+
+	recorder := &commentRecorder{}
+	m := bubbletea.NewStoryModel(commentDiff(), story,
+		bubbletea.WithStoryCommentStore(recorder.store(), "/tmp/comments.jsonl"),
+	)
+	// A short terminal lets the viewport scroll past the headers
+	tm := teatest.NewTestModel(t, m, teatest.WithInitialTermSize(100, 3))
+
+	teatest.WaitFor(t, tm.Output(), func(out []byte) bool {
+		return bytes.Contains(out, []byte("main.go"))
+	})
+
+	// Scroll so the deleted line is at the top of the viewport
+	for range 3 {
+		tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	}
+	// The editor header is clipped at this height, so wait for the input instead
+	tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	teatest.WaitFor(t, tm.Output(), func(out []byte) bool {
+		return bytes.Contains(out, []byte("Enter review comment"))
+	})
+
+	typeText(tm, "was 1 intentional?")
+	tm.Send(tea.KeyMsg{Type: tea.KeyEsc})
+
+	teatest.WaitFor(t, tm.Output(), func([]byte) bool {
+		return recorder.Saves() == 1
+	})
+	_, saved := recorder.Saved()
+	require.Len(t, saved, 1)
+	assert.Equal(t, diffview.SideOld, saved[0].Side)
+	assert.Equal(t, 2, saved[0].Line)
+
+	tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	tm.WaitFinished(t, teatest.WithFinalTimeout(time.Second))
+}
+
+func TestStoryModel_CommentAttachesToFocusedHunk(t *testing.T) {
+	t.Parallel()
+
+	hunk := func(first, second string) diffview.Hunk {
+		return diffview.Hunk{NewStart: 1, NewCount: 2, Lines: []diffview.Line{
+			{Type: diffview.LineAdded, Content: first, NewLineNum: 1},
+			{Type: diffview.LineAdded, Content: second, NewLineNum: 2},
+		}}
+	}
+	diff := &diffview.Diff{Files: []diffview.FileDiff{
+		{NewPath: "auth.go", Operation: diffview.FileAdded, Hunks: []diffview.Hunk{hunk("func login() {}", "// login")}},
+		{NewPath: "auth_test.go", Operation: diffview.FileAdded, Hunks: []diffview.Hunk{hunk("func TestLogin() {}", "// test")}},
+	}}
+	story := &diffview.StoryClassification{Sections: []diffview.Section{
+		{Title: "Auth", Hunks: []diffview.HunkRef{{File: "auth.go", HunkIndex: 0}, {File: "auth_test.go", HunkIndex: 0}}},
+	}}
+
+	tests := []struct {
+		name   string
+		scroll string
+		file   string
+		line   int
+	}{
+		{name: "hunk header at the top", scroll: "j", file: "auth.go", line: 1},
+		{name: "line at the top", scroll: "jjj", file: "auth.go", line: 2},
+		// The next file's header is at the top, but the hunk above is still focused
+		{name: "focused hunk scrolled past", scroll: "jjjj", file: "auth.go", line: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var m tea.Model = bubbletea.NewStoryModel(diff, story)
+			m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 6})
+			m = press(m, tt.scroll+"c")
+			m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("why?")})
+			m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+			comments := m.(bubbletea.StoryModel).Comments()
+			require.Len(t, comments, 1)
+			assert.Equal(t, tt.file, comments[0].File)
+			assert.Equal(t, diffview.SideNew, comments[0].Side)
+			assert.Equal(t, tt.line, comments[0].Line)
+		})
+	}
+}
+
+func TestViewer_CommentStoreLoadError(t *testing.T) {
+	t.Parallel()
+
+	loadErr := errors.New("corrupt sidecar")
+	var in, out bytes.Buffer
+	viewer := bubbletea.NewViewer(
+		dv.TestTheme(),
+		bubbletea.WithProgramOptions(tea.WithInput(&in), tea.WithOutput(&out)),
+		bubbletea.WithViewerCommentStore(&mock.CommentStore{
+			LoadFn: func(path string) ([]diffview.Comment, error) {
+				assert.Equal(t, "review.jsonl", path)
+				return nil, loadErr
+			},
+		}, "review.jsonl"),
+	)
+
+	err := viewer.View(context.Background(), commentDiff())
+
+	require.ErrorIs(t, err, loadErr)
+}
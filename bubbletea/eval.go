@@ -133,6 +133,13 @@ func WithClipboard(c diffview.Clipboard) EvalModelOption {
 	}
 }
 
+// WithEvalKeyMap replaces the default key bindings.
+func WithEvalKeyMap(km EvalKeyMap) EvalModelOption {
+	return func(m *EvalModel) {
+		m.keymap = km
+	}
+}
+
 // NewEvalModel creates a new EvalModel with the given cases.
 func NewEvalModel(cases []diffview.EvalCase, opts ...EvalModelOption) EvalModel {
 	m := EvalModel{
@@ -805,7 +812,7 @@ func (m *EvalModel) filteredDiffWithIndices() (*diffview.Diff, map[hunkKey]int)
 	originalIndices := make(map[hunkKey]int)
 	var filteredFiles []diffview.FileDiff
 	for _, file := range diff.Files {
-		path := filePath(file)
+		path := file.Path()
 		var filteredHunks []diffview.Hunk
 		for hunkIdx, hunk := range file.Hunks {
 			if activeHunks[hunkKey{file: path, hunkIndex: hunkIdx}] {
@@ -846,12 +853,12 @@ func formatCaseForExport(c diffview.EvalCase) string {
 	}
 	sb.WriteString("\n```diff\n")
 	for _, file := range c.Input.Diff.Files {
-		sb.WriteString(fmt.Sprintf("=== %s (%s) ===\n", formatFilePath(file), formatFileOp(file.Operation)))
+		details := append([]string{formatFileOp(file.Operation)}, file.Notes()...)
+		sb.WriteString(fmt.Sprintf("=== %s (%s) ===\n", file.DisplayPath(), strings.Join(details, ", ")))
 		for _, hunk := range file.Hunks {
-			sb.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n",
-				hunk.OldStart, hunk.OldCount, hunk.NewStart, hunk.NewCount))
+			sb.WriteString(hunk.Range() + "\n")
 			for _, line := range hunk.Lines {
-				prefix := formatLinePrefix(line.Type)
+				prefix := line.Prefix()
 				sb.WriteString(prefix)
 				sb.WriteString(line.Content)
 				if !strings.HasSuffix(line.Content, "\n") {
@@ -898,13 +905,6 @@ func formatCaseForExport(c diffview.EvalCase) string {
 	return sb.String()
 }
 
-func formatFilePath(file diffview.FileDiff) string {
-	if file.NewPath != "" {
-		return file.NewPath
-	}
-	return file.OldPath
-}
-
 func formatFileOp(op diffview.FileOp) string {
 	switch op {
 	case diffview.FileAdded:
@@ -922,17 +922,6 @@ func formatFileOp(op diffview.FileOp) string {
 	}
 }
 
-func formatLinePrefix(lt diffview.LineType) string {
-	switch lt {
-	case diffview.LineAdded:
-		return "+"
-	case diffview.LineDeleted:
-		return "-"
-	default:
-		return " "
-	}
-}
-
 // View implements tea.Model.
 func (m EvalModel) View() string {
 	if !m.ready {
@@ -990,7 +979,7 @@ func (m EvalModel) renderCritiqueView() string {
 	s.WriteString("\n\n")
 	s.WriteString(m.critiqueTextarea.View())
 	s.WriteString("\n\n")
-	s.WriteString(lipgloss.NewStyle().Faint(true).Render("[Esc] save and exit"))
+	s.WriteString(lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf("[%s] save and exit", keyLabel(m.keymap.ExitCritique))))
 
 	return s.String()
 }
@@ -1002,47 +991,59 @@ func (m EvalModel) renderHelpView() string {
 	keyStyle := lipgloss.NewStyle().Bold(true)
 	descStyle := lipgloss.NewStyle().Faint(true)
 
+	km := m.keymap
+	groups := []struct {
+		title string
+		rows  [][2]string // keys, description
+	}{
+		{"Navigation", [][2]string{
+			{keyPair(km.NextCase, km.PrevCase), "next/previous case"},
+			{keyPair(km.NextUnjudged, km.PrevUnjudged), "next/previous unjudged"},
+		}},
+		{"Scrolling", [][2]string{
+			{keyPair(km.ScrollDown, km.ScrollUp), "scroll down/up"},
+			{keyPair(km.HalfPageDown, km.HalfPageUp), "half page down/up"},
+			{keyPair(km.GotoTop, km.GotoBottom), "go to top/bottom"},
+		}},
+		{"View", [][2]string{
+			{keyLabel(km.ToggleView), "toggle story/data view"},
+			{keyPair(km.IncreaseSplit, km.DecreaseSplit), "resize split"},
+			{keyName(km.ToggleMode), "toggle story/raw mode"},
+			{keyPair(km.NextSection, km.PrevSection), "next/prev section (story mode)"},
+		}},
+		{"Judgment", [][2]string{
+			{keyName(km.Pass), "mark pass"},
+			{keyName(km.Fail), "mark fail"},
+			{keyName(km.Critique), "enter critique"},
+		}},
+		{"Other", [][2]string{
+			{keyName(km.CopyCase), "copy case to clipboard"},
+			{keyName(km.Help), "toggle help"},
+			{keyName(km.Quit), "quit"},
+		}},
+	}
+
+	keyWidth := 0
+	for _, g := range groups {
+		for _, row := range g.rows {
+			keyWidth = max(keyWidth, lipgloss.Width(row[0]))
+		}
+	}
+
 	s.WriteString(headerStyle.Render("HELP"))
 	s.WriteString("\n\n")
 
-	// Navigation
-	s.WriteString(headerStyle.Render("Navigation"))
-	s.WriteString("\n")
-	s.WriteString(fmt.Sprintf("  %s  %s\n", keyStyle.Render("n/N"), descStyle.Render("next/previous case")))
-	s.WriteString(fmt.Sprintf("  %s  %s\n", keyStyle.Render("u/U"), descStyle.Render("next/previous unjudged")))
-	s.WriteString("\n")
-
-	// Scrolling
-	s.WriteString(headerStyle.Render("Scrolling"))
-	s.WriteString("\n")
-	s.WriteString(fmt.Sprintf("  %s  %s\n", keyStyle.Render("j/k"), descStyle.Render("scroll down/up")))
-	s.WriteString(fmt.Sprintf("  %s  %s\n", keyStyle.Render("ctrl+d/u"), descStyle.Render("half page down/up")))
-	s.WriteString(fmt.Sprintf("  %s  %s\n", keyStyle.Render("g/G"), descStyle.Render("go to top/bottom")))
-	s.WriteString("\n")
-
-	// View
-	s.WriteString(headerStyle.Render("View"))
-	s.WriteString("\n")
-	s.WriteString(fmt.Sprintf("  %s  %s\n", keyStyle.Render("Tab"), descStyle.Render("toggle story/data view")))
-	s.WriteString(fmt.Sprintf("  %s  %s\n", keyStyle.Render("=/+/-"), descStyle.Render("resize split")))
-	s.WriteString(fmt.Sprintf("  %s    %s\n", keyStyle.Render("m"), descStyle.Render("toggle story/raw mode")))
-	s.WriteString(fmt.Sprintf("  %s  %s\n", keyStyle.Render("]/["), descStyle.Render("next/prev section (story mode)")))
-	s.WriteString("\n")
-
-	// Judgment
-	s.WriteString(headerStyle.Render("Judgment"))
-	s.WriteString("\n")
-	s.WriteString(fmt.Sprintf("  %s    %s\n", keyStyle.Render("p"), descStyle.Render("mark pass")))
-	s.WriteString(fmt.Sprintf("  %s    %s\n", keyStyle.Render("f"), descStyle.Render("mark fail")))
-	s.WriteString(fmt.Sprintf("  %s    %s\n", keyStyle.Render("c"), descStyle.Render("enter critique")))
-	s.WriteString("\n")
-
-	// Other
-	s.WriteString(headerStyle.Render("Other"))
-	s.WriteString("\n")
-	s.WriteString(fmt.Sprintf("  %s    %s\n", keyStyle.Render("y"), descStyle.Render("copy case to clipboard")))
-	s.WriteString(fmt.Sprintf("  %s    %s\n", keyStyle.Render("?"), descStyle.Render("toggle help")))
-	s.WriteString(fmt.Sprintf("  %s    %s\n", keyStyle.Render("q"), descStyle.Render("quit")))
+	for i, g := range groups {
+		s.WriteString(headerStyle.Render(g.title))
+		s.WriteString("\n")
+		for _, row := range g.rows {
+			keys := row[0] + strings.Repeat(" ", keyWidth-lipgloss.Width(row[0]))
+			s.WriteString(fmt.Sprintf("  %s  %s\n", keyStyle.Render(keys), descStyle.Render(row[1])))
+		}
+		if i < len(groups)-1 {
+			s.WriteString("\n")
+		}
+	}
 	s.WriteString("\n\n")
 
 	s.WriteString(descStyle.Render("Press any key to close"))
@@ -1190,12 +1191,12 @@ func (m EvalModel) renderStatusBar() string {
 	parts = append(parts, judgmentState)
 
 	// Contextual key hints
-	var hints string
+	km := m.keymap
+	hints := keyPair(km.NextCase, km.PrevCase) + " case"
 	if m.viewMode == ViewStory && m.storyMode {
-		hints = "n/N case ]/[ section p/f judge"
-	} else {
-		hints = "n/N case p/f judge"
+		hints += " " + keyPair(km.NextSection, km.PrevSection) + " section"
 	}
+	hints += " " + keyPair(km.Pass, km.Fail) + " judge"
 	parts = append(parts, hints)
 
 	return strings.Join(parts, " │ ")
@@ -139,3 +139,40 @@ func DefaultEvalKeyMap() EvalKeyMap {
 		),
 	}
 }
+
+// Override returns a copy of the key map with the named bindings replaced.
+// Names are the snake_case field names (e.g. "next_case"). Returns an error
+// for unknown names or when a key ends up bound to two actions.
+func (km EvalKeyMap) Override(overrides map[string][]string) (EvalKeyMap, error) {
+	err := overrideBindings(km.bindings(), overrides)
+	return km, err
+}
+
+// bindings returns the configurable bindings of the key map.
+func (km *EvalKeyMap) bindings() []namedBinding {
+	return []namedBinding{
+		{name: "next_case", binding: &km.NextCase},
+		{name: "prev_case", binding: &km.PrevCase},
+		{name: "next_unjudged", binding: &km.NextUnjudged},
+		{name: "prev_unjudged", binding: &km.PrevUnjudged},
+		{name: "scroll_down", binding: &km.ScrollDown},
+		{name: "scroll_up", binding: &km.ScrollUp},
+		{name: "half_page_up", binding: &km.HalfPageUp},
+		{name: "half_page_down", binding: &km.HalfPageDown},
+		{name: "goto_top", binding: &km.GotoTop},
+		{name: "goto_bottom", binding: &km.GotoBottom},
+		{name: "next_section", binding: &km.NextSection},
+		{name: "prev_section", binding: &km.PrevSection},
+		{name: "toggle_mode", binding: &km.ToggleMode},
+		{name: "toggle_view", binding: &km.ToggleView},
+		{name: "increase_split", binding: &km.IncreaseSplit},
+		{name: "decrease_split", binding: &km.DecreaseSplit},
+		{name: "pass", binding: &km.Pass},
+		{name: "fail", binding: &km.Fail},
+		{name: "critique", binding: &km.Critique},
+		{name: "exit_critique", mode: "critique", binding: &km.ExitCritique},
+		{name: "copy_case", binding: &km.CopyCase},
+		{name: "quit", binding: &km.Quit},
+		{name: "help", binding: &km.Help},
+	}
+}
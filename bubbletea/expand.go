@@ -0,0 +1,72 @@
+package bubbletea
+
+import "github.com/fwojciec/diffstory"
+
+// contextStep is the number of context lines each expand action adds.
+const contextStep = 10
+
+// contextLines splits both versions of every file into lines for context
+// expansion, keyed by path. Files without content are left out.
+func contextLines(versions map[string]diffview.FileVersions) map[string]diffview.FileLines {
+	if len(versions) == 0 {
+		return nil
+	}
+	lines := make(map[string]diffview.FileLines, len(versions))
+	for path, v := range versions {
+		if l := v.Lines(); l.Old != nil || l.New != nil {
+			lines[path] = l
+		}
+	}
+	return lines
+}
+
+// expandedDiff returns diff with the context expansions applied to the files
+// whose content is known. Hunk indices are unchanged.
+func expandedDiff(diff *diffview.Diff, lines map[string]diffview.FileLines, expansions map[hunkKey]diffview.ContextExpansion) *diffview.Diff {
+	if diff == nil || len(expansions) == 0 {
+		return diff
+	}
+	byFile := make(map[string]map[int]diffview.ContextExpansion)
+	for key, exp := range expansions {
+		if byFile[key.file] == nil {
+			byFile[key.file] = make(map[int]diffview.ContextExpansion)
+		}
+		byFile[key.file][key.hunkIndex] = exp
+	}
+
+	files := make([]diffview.FileDiff, len(diff.Files))
+	for i, file := range diff.Files {
+		path := file.Path()
+		if l, ok := lines[path]; ok && byFile[path] != nil {
+			file = file.ExpandContext(l, byFile[path])
+		}
+		files[i] = file
+	}
+	return &diffview.Diff{Files: files}
+}
+
+// expandHunkAt adds contextStep lines above or below the hunk holding the
+// anchored line, recording it in expansions. Returns false when the line
+// isn't in the diff or its file content is unknown.
+func expandHunkAt(expansions map[hunkKey]diffview.ContextExpansion, diff *diffview.Diff, lines map[string]diffview.FileLines, anchor lineAnchor, above bool) bool {
+	if diff == nil {
+		return false
+	}
+	if _, ok := lines[anchor.file]; !ok {
+		return false
+	}
+	hunkIndex, _, ok := diff.FindLine(anchor.file, anchor.side, anchor.line)
+	if !ok {
+		return false
+	}
+
+	key := hunkKey{file: anchor.file, hunkIndex: hunkIndex}
+	exp := expansions[key]
+	if above {
+		exp.Above += contextStep
+	} else {
+		exp.Below += contextStep
+	}
+	expansions[key] = exp
+	return true
+}
@@ -0,0 +1,148 @@
+package bubbletea_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	diffview "github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+// expandStory changes line 20 of a 60-line file and adds a line after
+// line 30, with one line of context around each hunk.
+func expandStory() (*diffview.Diff, map[string]diffview.FileVersions) {
+	var sb strings.Builder
+	for i := 1; i <= 60; i++ {
+		switch i {
+		case 20:
+			sb.WriteString("changed 20\n")
+		case 31:
+			sb.WriteString("inserted\n")
+			fmt.Fprintf(&sb, "line %d\n", i)
+		default:
+			fmt.Fprintf(&sb, "line %d\n", i)
+		}
+	}
+	diff := &diffview.Diff{Files: []diffview.FileDiff{{
+		OldPath: "f.txt", NewPath: "f.txt",
+		Hunks: []diffview.Hunk{
+			{OldStart: 19, OldCount: 3, NewStart: 19, NewCount: 3, Lines: []diffview.Line{
+				{Type: diffview.LineContext, Content: "line 19", OldLineNum: 19, NewLineNum: 19},
+				{Type: diffview.LineDeleted, Content: "line 20", OldLineNum: 20},
+				{Type: diffview.LineAdded, Content: "changed 20", NewLineNum: 20},
+				{Type: diffview.LineContext, Content: "line 21", OldLineNum: 21, NewLineNum: 21},
+			}},
+			{OldStart: 30, OldCount: 1, NewStart: 30, NewCount: 2, Lines: []diffview.Line{
+				{Type: diffview.LineContext, Content: "line 30", OldLineNum: 30, NewLineNum: 30},
+				{Type: diffview.LineAdded, Content: "inserted", NewLineNum: 31},
+			}},
+		},
+	}}}
+	return diff, map[string]diffview.FileVersions{"f.txt": {New: sb.String()}}
+}
+
+func sizedExpandModel(opts ...bubbletea.ModelOption) tea.Model {
+	diff, _ := expandStory()
+	var m tea.Model = bubbletea.NewModel(diff, opts...)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 100, Height: 60})
+	return m
+}
+
+func TestModel_ExpandContext(t *testing.T) {
+	t.Parallel()
+
+	_, versions := expandStory()
+
+	t.Run("above and below the hunk at the top", func(t *testing.T) {
+		t.Parallel()
+
+		m := sizedExpandModel(bubbletea.WithFileVersions(versions))
+		assert.NotContains(t, m.View(), "line 18 ")
+		assert.NotContains(t, m.View(), "line 22")
+
+		m = press(m, "{")
+		view := m.View()
+		assert.Contains(t, view, "line 9 ")
+		assert.NotContains(t, view, "line 8 ")
+
+		m = press(m, "}")
+		view = m.View()
+		assert.Contains(t, view, "line 22")
+		assert.Contains(t, view, "line 29")
+	})
+
+	t.Run("gap between hunks is shown once", func(t *testing.T) {
+		t.Parallel()
+
+		m := sizedExpandModel(bubbletea.WithFileVersions(versions))
+		m = press(m, "}}}")
+
+		view := m.View()
+		for i := 22; i <= 29; i++ {
+			assert.Equal(t, 1, strings.Count(view, fmt.Sprintf("line %d ", i)), "line %d", i)
+		}
+	})
+
+	t.Run("without file content nothing changes", func(t *testing.T) {
+		t.Parallel()
+
+		m := sizedExpandModel()
+		before := m.View()
+
+		assert.Equal(t, before, press(m, "{}").View())
+	})
+}
+
+func TestStoryModel_ExpandContext(t *testing.T) {
+	t.Parallel()
+
+	diff, versions := expandStory()
+	story := &diffview.StoryClassification{Sections: []diffview.Section{
+		{Title: "Change", Hunks: []diffview.HunkRef{{File: "f.txt", HunkIndex: 0}, {File: "f.txt", HunkIndex: 1}}},
+	}}
+
+	var m tea.Model = bubbletea.NewStoryModel(diff, story, bubbletea.WithStoryFileVersions(versions))
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 100, Height: 60})
+	assert.NotContains(t, m.View(), "line 18 ")
+
+	m = press(m, "{")
+
+	assert.Contains(t, m.View(), "line 18 ")
+	assert.Contains(t, m.View(), "line 9 ")
+}
+
+func TestStoryModel_ExpandContext_FocusedHunk(t *testing.T) {
+	t.Parallel()
+
+	diff, versions := expandStory()
+	diff.Files = append(diff.Files, diffview.FileDiff{
+		OldPath: "g.txt", NewPath: "g.txt",
+		Hunks: []diffview.Hunk{{OldStart: 5, OldCount: 1, NewStart: 5, NewCount: 1, Lines: []diffview.Line{
+			{Type: diffview.LineDeleted, Content: "g old 5", OldLineNum: 5},
+			{Type: diffview.LineAdded, Content: "g new 5", NewLineNum: 5},
+		}}},
+	})
+	versions["g.txt"] = diffview.FileVersions{New: "g 1\ng 2\ng 3\ng 4\ng new 5\n"}
+	story := &diffview.StoryClassification{Sections: []diffview.Section{
+		{Title: "Change", Hunks: []diffview.HunkRef{
+			{File: "f.txt", HunkIndex: 0}, {File: "f.txt", HunkIndex: 1}, {File: "g.txt", HunkIndex: 0},
+		}},
+	}}
+
+	var m tea.Model = bubbletea.NewStoryModel(diff, story, bubbletea.WithStoryFileVersions(versions))
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 100, Height: 5})
+	// Scroll until g.txt's file header is at the top; the focused hunk is
+	// still the last hunk of f.txt, as the status bar reports.
+	m = press(m, strings.Repeat("j", 9))
+	assert.Contains(t, m.View(), "hunk 2/3")
+
+	m = press(m, "{")
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 100, Height: 60})
+
+	view := m.View()
+	assert.Contains(t, view, "line 29 ")
+	assert.NotContains(t, view, "g 4")
+}
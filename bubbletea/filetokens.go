@@ -0,0 +1,87 @@
+package bubbletea
+
+import (
+	"strings"
+
+	"github.com/fwojciec/diffstory"
+)
+
+// maxFileSizeForTokenization is the largest file version tokenized as a
+// whole. Bigger files fall back to per-hunk tokenization.
+const maxFileSizeForTokenization = 1024 * 1024 // 1MB
+
+// fileTokens holds the per-line tokens of both versions of a file, indexed
+// by line number - 1. A side is nil when it has no content or couldn't be
+// tokenized.
+type fileTokens struct {
+	old [][]diffview.Token
+	new [][]diffview.Token
+}
+
+// tokenizeFileVersions tokenizes every file version once, with the whole
+// file as context, so hunks starting inside a block comment or multi-line
+// string are highlighted correctly.
+func tokenizeFileVersions(versions map[string]diffview.FileVersions, detector diffview.LanguageDetector, tokenizer diffview.Tokenizer) map[string]fileTokens {
+	if len(versions) == 0 || detector == nil || tokenizer == nil {
+		return nil
+	}
+	result := make(map[string]fileTokens, len(versions))
+	for path, v := range versions {
+		language := detector.DetectFromPath(path)
+		if language == "" {
+			continue
+		}
+		result[path] = fileTokens{
+			old: tokenizeFile(v.Old, language, tokenizer),
+			new: tokenizeFile(v.New, language, tokenizer),
+		}
+	}
+	return result
+}
+
+// tokenizeFile tokenizes one file version. Returns nil for empty content and
+// for content that is too large or has very long lines (likely data, not
+// code).
+func tokenizeFile(content, language string, tokenizer diffview.Tokenizer) [][]diffview.Token {
+	if content == "" || len(content) > maxFileSizeForTokenization {
+		return nil
+	}
+	for line := range strings.SplitSeq(content, "\n") {
+		if len(line) > diffview.MaxTokenizeLineLength {
+			return nil
+		}
+	}
+	return tokenizer.TokenizeLines(language, strings.TrimSuffix(content, "\n"))
+}
+
+// hunkTokensFromFile maps file tokens onto hunk lines: deleted lines by
+// OldLineNum, added and context lines by NewLineNum. Returns nil when any line
+// can't be mapped or its tokens don't spell out the line, so the caller falls
+// back to tokenizing the hunk on its own.
+func hunkTokensFromFile(lines []diffview.Line, ft fileTokens) [][]diffview.Token {
+	result := make([][]diffview.Token, len(lines))
+	for i, line := range lines {
+		side, num := ft.new, line.NewLineNum
+		if line.Type == diffview.LineDeleted {
+			side, num = ft.old, line.OldLineNum
+		}
+		if num < 1 || num > len(side) {
+			return nil
+		}
+		tokens := side[num-1]
+		if tokenText(tokens) != strings.TrimSuffix(line.Content, "\n") {
+			return nil
+		}
+		result[i] = tokens
+	}
+	return result
+}
+
+// tokenText concatenates the text of tokens.
+func tokenText(tokens []diffview.Token) string {
+	var sb strings.Builder
+	for _, t := range tokens {
+		sb.WriteString(t.Text)
+	}
+	return sb.String()
+}
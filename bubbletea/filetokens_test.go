@@ -0,0 +1,102 @@
+package bubbletea_test
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	diffview "github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/bubbletea"
+	dv "github.com/fwojciec/diffstory/lipgloss"
+	"github.com/fwojciec/diffstory/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// commentAwareTokenizer colors every line red when the source starts a block
+// comment, as a full-file tokenizer would for lines inside it, and green
+// otherwise.
+func commentAwareTokenizer() *mock.Tokenizer {
+	return &mock.Tokenizer{
+		TokenizeLinesFn: func(_, source string) [][]diffview.Token {
+			color := "#00ff00"
+			if strings.HasPrefix(source, "/*") {
+				color = "#ff0000"
+			}
+			var lines [][]diffview.Token
+			for line := range strings.SplitSeq(source, "\n") {
+				lines = append(lines, []diffview.Token{{Text: line, Style: diffview.Style{Foreground: color}}})
+			}
+			return lines
+		},
+	}
+}
+
+// commentHunkStory has a hunk that starts inside a block comment.
+func commentHunkStory() (*diffview.Diff, *diffview.StoryClassification) {
+	diff := &diffview.Diff{Files: []diffview.FileDiff{{
+		OldPath: "main.go", NewPath: "main.go",
+		Hunks: []diffview.Hunk{{
+			OldStart: 2, OldCount: 1, NewStart: 2, NewCount: 2,
+			Lines: []diffview.Line{
+				{Type: diffview.LineContext, Content: "still a comment", OldLineNum: 2, NewLineNum: 2},
+				{Type: diffview.LineAdded, Content: "more comment", NewLineNum: 3},
+			},
+		}},
+	}}}
+	story := &diffview.StoryClassification{Sections: []diffview.Section{
+		{Title: "Docs", Hunks: []diffview.HunkRef{{File: "main.go", HunkIndex: 0}}},
+	}}
+	return diff, story
+}
+
+func renderStoryWithVersions(versions map[string]diffview.FileVersions) string {
+	diff, story := commentHunkStory()
+	var m tea.Model = bubbletea.NewStoryModel(diff, story,
+		bubbletea.WithStoryTheme(dv.TestTheme()),
+		bubbletea.WithStoryRenderer(storyTrueColorRenderer()),
+		bubbletea.WithStoryLanguageDetector(&mock.LanguageDetector{
+			DetectFromPathFn: func(string) string { return "Go" },
+		}),
+		bubbletea.WithStoryTokenizer(commentAwareTokenizer()),
+		bubbletea.WithStoryFileVersions(versions),
+	)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+	return m.View()
+}
+
+func TestStoryModel_FileVersionsTokenizeWholeFile(t *testing.T) {
+	t.Parallel()
+
+	view := renderStoryWithVersions(map[string]diffview.FileVersions{"main.go": {
+		Old: "/*\nstill a comment\n*/\n",
+		New: "/*\nstill a comment\nmore comment\n*/\n",
+	}})
+
+	assert.Contains(t, view, "38;2;255;0;0", "lines inside the comment use whole-file tokens")
+	assert.NotContains(t, view, "38;2;0;255;0")
+}
+
+func TestStoryModel_FileVersionsFallBackToHunkTokens(t *testing.T) {
+	t.Parallel()
+
+	t.Run("without versions", func(t *testing.T) {
+		t.Parallel()
+
+		view := renderStoryWithVersions(nil)
+
+		assert.Contains(t, view, "38;2;0;255;0")
+		assert.NotContains(t, view, "38;2;255;0;0")
+	})
+
+	t.Run("when versions don't match the hunk", func(t *testing.T) {
+		t.Parallel()
+
+		view := renderStoryWithVersions(map[string]diffview.FileVersions{"main.go": {
+			Old: "/*\nsomething else\n*/\n",
+			New: "/*\nsomething else\n*/\n",
+		}})
+
+		assert.Contains(t, view, "38;2;0;255;0")
+		assert.NotContains(t, view, "38;2;255;0;0")
+	})
+}
@@ -0,0 +1,45 @@
+package bubbletea
+
+import "github.com/fwojciec/diffstory"
+
+// fullFileIndicator is shown in the status bar while a file is shown in full.
+const fullFileIndicator = "full file"
+
+// fullFileDiff returns diff with the file at path expanded to the whole
+// file, removed and added lines in place, as by FileDiff.FullFile. The other
+// files and all hunk indices are unchanged. Reports false when the file's
+// content is unknown or doesn't match its hunks.
+func fullFileDiff(diff *diffview.Diff, lines map[string]diffview.FileLines, path string) (*diffview.Diff, bool) {
+	if diff == nil {
+		return diff, false
+	}
+	l, ok := lines[path]
+	if !ok {
+		return diff, false
+	}
+	for i, file := range diff.Files {
+		if file.Path() != path {
+			continue
+		}
+		full, ok := file.FullFile(l)
+		if !ok {
+			return diff, false
+		}
+		files := make([]diffview.FileDiff, len(diff.Files))
+		copy(files, diff.Files)
+		files[i] = full
+		return &diffview.Diff{Files: files}, true
+	}
+	return diff, false
+}
+
+// rowOfAnchor returns the first rendered row showing anchor, or 0 when no
+// row does.
+func rowOfAnchor(rows []lineAnchor, anchor lineAnchor) int {
+	for i, a := range rows {
+		if a == anchor {
+			return i
+		}
+	}
+	return 0
+}
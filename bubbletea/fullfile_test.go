@@ -0,0 +1,103 @@
+package bubbletea_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	diffview "github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModel_ToggleFullFile(t *testing.T) {
+	t.Parallel()
+
+	_, versions := expandStory()
+
+	t.Run("shows the whole file and returns to the hunk view", func(t *testing.T) {
+		t.Parallel()
+
+		m := sizedExpandModel(bubbletea.WithFileVersions(versions))
+		before := m.View()
+		assert.NotContains(t, before, "line 45")
+
+		m = press(m, "f")
+		view := m.View()
+		assert.Contains(t, view, "line 19 ", "the top line stays in place")
+		assert.Contains(t, view, "line 45")
+		assert.Contains(t, view, "changed 20")
+		assert.Contains(t, view, "full file")
+
+		m = press(m, "f")
+		assert.Equal(t, before, m.View())
+	})
+
+	t.Run("shows removed lines where they were deleted", func(t *testing.T) {
+		t.Parallel()
+
+		var old strings.Builder
+		for i := 1; i <= 60; i++ {
+			fmt.Fprintf(&old, "line %d\n", i)
+		}
+		oldOnly := map[string]diffview.FileVersions{"f.txt": {Old: old.String()}}
+		m := press(sizedExpandModel(bubbletea.WithFileVersions(oldOnly)), "fgg")
+
+		view := m.View()
+		assert.Contains(t, view, "full file")
+		assert.Regexp(t, `line 19\s*\n.*-line 20\s*\n.*\+changed 20\s*\n.*line 21`, view)
+		assert.Contains(t, view, "line 45")
+	})
+
+	t.Run("stale file content is not shown", func(t *testing.T) {
+		t.Parallel()
+
+		stale := map[string]diffview.FileVersions{"f.txt": {New: "something else\n"}}
+		m := sizedExpandModel(bubbletea.WithFileVersions(stale))
+		before := m.View()
+
+		assert.Equal(t, before, press(m, "f").View())
+	})
+}
+
+func TestStoryModel_ToggleFullFile(t *testing.T) {
+	t.Parallel()
+
+	diff, versions := expandStory()
+	story := &diffview.StoryClassification{Sections: []diffview.Section{
+		{Title: "Rename", Hunks: []diffview.HunkRef{{File: "f.txt", HunkIndex: 0}}},
+		{Title: "Insert", Hunks: []diffview.HunkRef{{File: "f.txt", HunkIndex: 1, Collapsed: true}}},
+	}}
+	sized := func() tea.Model {
+		var m tea.Model = bubbletea.NewStoryModel(diff, story, bubbletea.WithStoryFileVersions(versions))
+		m, _ = m.Update(tea.WindowSizeMsg{Width: 100, Height: 60})
+		return m
+	}
+
+	t.Run("labels the hunks of every section", func(t *testing.T) {
+		t.Parallel()
+
+		m := sized()
+		before := m.View()
+		assert.NotContains(t, before, "inserted")
+
+		m = press(m, "fgg")
+		view := m.View()
+		assert.Contains(t, view, "inserted", "hunks of other sections are shown uncollapsed")
+		assert.Contains(t, view, "section 1: Rename (current)")
+		assert.Contains(t, view, "section 2: Insert")
+
+		m = press(m, "f")
+		assert.Equal(t, before, m.View(), "the offset from before is restored")
+	})
+
+	t.Run("changing section leaves the full file", func(t *testing.T) {
+		t.Parallel()
+
+		m := press(sized(), "fsS")
+
+		assert.NotContains(t, m.View(), "full file")
+		assert.NotContains(t, m.View(), "inserted")
+	})
+}
@@ -0,0 +1,28 @@
+package bubbletea
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// helpView renders the full-screen key binding overlay, generated from the
+// help text of the key map's bindings.
+func helpView(renderer *lipgloss.Renderer, km help.KeyMap, width int) string {
+	if renderer == nil {
+		renderer = lipgloss.DefaultRenderer()
+	}
+
+	h := help.New()
+	h.Width = width
+	h.ShowAll = true
+
+	var s strings.Builder
+	s.WriteString(renderer.NewStyle().Bold(true).Render("HELP"))
+	s.WriteString("\n\n")
+	s.WriteString(h.View(km))
+	s.WriteString("\n\n")
+	s.WriteString(renderer.NewStyle().Faint(true).Render("Press any key to close"))
+	return s.String()
+}
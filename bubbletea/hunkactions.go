@@ -0,0 +1,268 @@
+package bubbletea
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fwojciec/diffstory"
+)
+
+// pendingHunkAction is an action waiting for confirmation, such as a
+// discard that can't be undone.
+type pendingHunkAction struct {
+	action diffview.HunkAction
+	refs   []diffview.HunkRef
+}
+
+// prompt asks to confirm the action.
+func (p pendingHunkAction) prompt(confirm string) string {
+	return fmt.Sprintf("discard %s? %s to confirm, any other key to cancel", hunkCount(len(p.refs)), confirm)
+}
+
+// hunkCount formats a number of hunks, as in "1 hunk" or "3 hunks".
+func hunkCount(n int) string {
+	if n == 1 {
+		return "1 hunk"
+	}
+	return fmt.Sprintf("%d hunks", n)
+}
+
+// focusedHunk returns the hunk at the top of the viewport: the last hunk
+// starting at or above it, or the first hunk below it.
+func (m StoryModel) focusedHunk() (diffview.HunkRef, bool) {
+	if m.onIntro() {
+		return diffview.HunkRef{}, false
+	}
+	positions, refs, _ := m.computePositions()
+	if len(refs) == 0 {
+		return diffview.HunkRef{}, false
+	}
+	focused := 0
+	for i, pos := range positions {
+		if pos > m.viewport.YOffset {
+			break
+		}
+		focused = i
+	}
+	return refs[focused], true
+}
+
+// sectionHunks returns the hunks of the current section, or nil on the
+// intro slide.
+func (m StoryModel) sectionHunks() []diffview.HunkRef {
+	if m.story == nil {
+		return nil
+	}
+	idx := m.codeSectionIndex()
+	if idx < 0 || idx >= len(m.story.Sections) {
+		return nil
+	}
+	return m.story.Sections[idx].Hunks
+}
+
+// requestHunkAction applies action to the focused hunk, or to every hunk of
+// the current section. Discards wait for confirmation first.
+func (m *StoryModel) requestHunkAction(action diffview.HunkAction, wholeSection bool) tea.Cmd {
+	if m.applier == nil || m.applying {
+		return nil
+	}
+	var refs []diffview.HunkRef
+	if wholeSection {
+		refs = m.sectionHunks()
+	} else if ref, ok := m.focusedHunk(); ok {
+		refs = []diffview.HunkRef{ref}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	if action == diffview.HunkDiscard {
+		m.pendingAction = &pendingHunkAction{action: action, refs: refs}
+		return nil
+	}
+	return m.applyHunks(action, refs)
+}
+
+// confirmHunkAction applies the pending action if confirmed, or cancels it.
+func (m *StoryModel) confirmHunkAction(confirmed bool) tea.Cmd {
+	pending := m.pendingAction
+	m.pendingAction = nil
+	if !confirmed {
+		m.actionStatus = "discard cancelled"
+		return nil
+	}
+	return m.applyHunks(pending.action, pending.refs)
+}
+
+// hunksAppliedMsg reports the result of applying a hunk action, with the
+// diff reloaded after it.
+type hunksAppliedMsg struct {
+	action    diffview.HunkAction
+	refs      []diffview.HunkRef
+	err       error
+	diff      *diffview.Diff
+	reloadErr error
+}
+
+// applyHunks returns a command applying action to refs and reloading the
+// diff, so that later actions build their patches from current line
+// numbers. No other action starts until it reports back.
+func (m *StoryModel) applyHunks(action diffview.HunkAction, refs []diffview.HunkRef) tea.Cmd {
+	m.applying = true
+	applier, source, reload := m.applier, m.source, m.reload
+	return func() tea.Msg {
+		ctx := context.Background()
+		if err := applier.ApplyHunks(ctx, source, refs, action); err != nil {
+			return hunksAppliedMsg{action: action, refs: refs, err: err}
+		}
+		msg := hunksAppliedMsg{action: action, refs: refs}
+		if reload != nil {
+			msg.diff, msg.reloadErr = reload(ctx)
+		}
+		return msg
+	}
+}
+
+// handleHunksApplied refreshes the view after a hunk action: hunks are
+// labelled with what was done to them, and the reloaded diff replaces the
+// source. Discarded hunks are no longer in it; without a reloaded diff they
+// are collapsed instead.
+func (m *StoryModel) handleHunksApplied(msg hunksAppliedMsg) {
+	m.applying = false
+	if msg.err != nil {
+		m.actionStatus = msg.err.Error()
+		return
+	}
+	for _, ref := range msg.refs {
+		key := hunkKey{file: ref.File, hunkIndex: ref.HunkIndex}
+		m.appliedActions[key] = msg.action
+		if msg.action == diffview.HunkDiscard {
+			m.collapsedHunks[key] = true
+		}
+	}
+	m.actionStatus = fmt.Sprintf("%s %s", msg.action, hunkCount(len(msg.refs)))
+	if msg.reloadErr != nil {
+		m.actionStatus += fmt.Sprintf(" (reloading the diff failed: %v)", msg.reloadErr)
+	}
+	if msg.diff != nil {
+		m.replaceSource(msg.diff)
+		return
+	}
+	m.refreshContent()
+}
+
+// replaceSource makes diff the source, moving the story and per-hunk state
+// to the hunks' indices in it. Hunks missing from diff are dropped.
+func (m *StoryModel) replaceSource(diff *diffview.Diff) {
+	indices := hunkIndices(m.source, diff)
+	if m.story != nil {
+		story := *m.story
+		story.Sections = make([]diffview.Section, len(m.story.Sections))
+		for i, section := range m.story.Sections {
+			hunks := make([]diffview.HunkRef, 0, len(section.Hunks))
+			for _, ref := range section.Hunks {
+				if idx, ok := indices[hunkKey{file: ref.File, hunkIndex: ref.HunkIndex}]; ok {
+					ref.HunkIndex = idx
+					hunks = append(hunks, ref)
+				}
+			}
+			section.Hunks = hunks
+			story.Sections[i] = section
+		}
+		m.story = &story
+	}
+	m.hunkToSection = remapHunkKeys(m.hunkToSection, indices)
+	m.hunkCategories = remapHunkKeys(m.hunkCategories, indices)
+	m.collapseText = remapHunkKeys(m.collapseText, indices)
+	m.collapsedHunks = remapHunkKeys(m.collapsedHunks, indices)
+	m.llmCollapsedHunks = remapHunkKeys(m.llmCollapsedHunks, indices)
+	m.appliedActions = remapHunkKeys(m.appliedActions, indices)
+	m.expansions = remapHunkKeys(m.expansions, indices)
+	m.source = diff
+	m.refreshDiff()
+}
+
+// hunkIndices maps each hunk of old to the index of the same hunk in new:
+// the first hunk of the file not matched yet with the same added and
+// deleted lines. Line numbers and context are ignored, as they shift when
+// other hunks are discarded.
+func hunkIndices(old, new *diffview.Diff) map[hunkKey]int {
+	indices := make(map[hunkKey]int)
+	if old == nil || new == nil {
+		return indices
+	}
+	newFiles := make(map[string]diffview.FileDiff, len(new.Files))
+	for _, file := range new.Files {
+		newFiles[file.Path()] = file
+	}
+	for _, file := range old.Files {
+		path := file.Path()
+		newFile, ok := newFiles[path]
+		if !ok {
+			continue
+		}
+		matched := make(map[int]bool, len(newFile.Hunks))
+		for i, hunk := range file.Hunks {
+			for j, candidate := range newFile.Hunks {
+				if !matched[j] && sameChanges(hunk, candidate) {
+					matched[j] = true
+					indices[hunkKey{file: path, hunkIndex: i}] = j
+					break
+				}
+			}
+		}
+	}
+	return indices
+}
+
+// sameChanges reports whether a and b add and delete the same lines.
+func sameChanges(a, b diffview.Hunk) bool {
+	return hunkChanges(a) == hunkChanges(b)
+}
+
+// hunkChanges returns the added and deleted lines of hunk with their
+// prefixes, without context.
+func hunkChanges(hunk diffview.Hunk) string {
+	var sb strings.Builder
+	for _, line := range hunk.Lines {
+		if line.Type == diffview.LineAdded || line.Type == diffview.LineDeleted {
+			sb.WriteString(line.Prefix())
+			sb.WriteString(line.Content)
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// remapHunkKeys returns m with its keys moved to their new hunk indices,
+// dropping the keys of hunks that are gone.
+func remapHunkKeys[V any](m map[hunkKey]V, indices map[hunkKey]int) map[hunkKey]V {
+	remapped := make(map[hunkKey]V, len(m))
+	for key, v := range m {
+		if idx, ok := indices[key]; ok {
+			remapped[hunkKey{file: key.file, hunkIndex: idx}] = v
+		}
+	}
+	return remapped
+}
+
+// hunkLabels returns the labels appended to hunk headers: the section owning
+// each hunk of a file shown in full, and the last action applied to a hunk.
+func (m StoryModel) hunkLabels() map[hunkKey]string {
+	labels := m.sectionLabels()
+	if len(m.appliedActions) == 0 {
+		return labels
+	}
+	if labels == nil {
+		labels = make(map[hunkKey]string, len(m.appliedActions))
+	}
+	for key, action := range m.appliedActions {
+		if label, ok := labels[key]; ok {
+			labels[key] = label + " · " + action.String()
+			continue
+		}
+		labels[key] = action.String()
+	}
+	return labels
+}
@@ -0,0 +1,218 @@
+package bubbletea_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/bubbletea"
+	"github.com/fwojciec/diffstory/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// appliedCall records a call to a mock hunk applier.
+type appliedCall struct {
+	diff   *diffview.Diff
+	refs   []diffview.HunkRef
+	action diffview.HunkAction
+}
+
+// recordingApplier returns a mock applier that records its calls and
+// returns err.
+func recordingApplier(calls *[]appliedCall, err error) *mock.HunkApplier {
+	return &mock.HunkApplier{
+		ApplyHunksFn: func(_ context.Context, diff *diffview.Diff, refs []diffview.HunkRef, action diffview.HunkAction) error {
+			*calls = append(*calls, appliedCall{diff: diff, refs: refs, action: action})
+			return err
+		},
+	}
+}
+
+// pressApplying is press that also delivers the result of each hunk action
+// started, as the bubbletea runtime would.
+func pressApplying(m tea.Model, keys string) tea.Model {
+	for _, r := range keys {
+		var cmd tea.Cmd
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		if cmd != nil {
+			m, _ = m.Update(cmd())
+		}
+	}
+	return m
+}
+
+func TestStoryModel_HunkActions(t *testing.T) {
+	t.Parallel()
+
+	coreHunk := diffview.HunkRef{File: "auth.go", HunkIndex: 0}
+	testHunk := diffview.HunkRef{File: "auth_test.go", HunkIndex: 0}
+
+	t.Run("stage focused hunk", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []appliedCall
+		m := pressApplying(sizedStoryModel(t, bubbletea.WithStoryHunkApplier(recordingApplier(&calls, nil), nil)), "a")
+
+		require.Len(t, calls, 1)
+		assert.Equal(t, []diffview.HunkRef{coreHunk}, calls[0].refs)
+		assert.Equal(t, diffview.HunkStage, calls[0].action)
+		view := m.View()
+		assert.Contains(t, view, "staged 1 hunk")
+		assert.Contains(t, view, "· staged")
+	})
+
+	t.Run("unstage section labels hunks", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []appliedCall
+		m := pressApplying(sizedStoryModel(t, bubbletea.WithStoryHunkApplier(recordingApplier(&calls, nil), nil)), "sAU")
+
+		require.Len(t, calls, 2)
+		assert.Equal(t, []diffview.HunkRef{testHunk}, calls[1].refs)
+		assert.Equal(t, diffview.HunkUnstage, calls[1].action)
+		view := m.View()
+		assert.Contains(t, view, "unstaged 1 hunk")
+		assert.Contains(t, view, "· unstaged")
+		assert.NotContains(t, view, "· staged")
+	})
+
+	t.Run("discard waits for confirmation", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []appliedCall
+		m := pressApplying(sizedStoryModel(t, bubbletea.WithStoryHunkApplier(recordingApplier(&calls, nil), nil)), "x")
+
+		assert.Empty(t, calls)
+		assert.Contains(t, m.View(), "discard 1 hunk? y to confirm")
+
+		m = pressApplying(m, "y")
+
+		require.Len(t, calls, 1)
+		assert.Equal(t, diffview.HunkDiscard, calls[0].action)
+		view := m.View()
+		assert.Contains(t, view, "discarded 1 hunk")
+		assert.Contains(t, view, "▸ collapsed · discarded")
+		assert.NotContains(t, view, "func login")
+	})
+
+	t.Run("other key cancels discard", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []appliedCall
+		m := pressApplying(sizedStoryModel(t, bubbletea.WithStoryHunkApplier(recordingApplier(&calls, nil), nil)), "Xs")
+
+		assert.Empty(t, calls)
+		view := m.View()
+		assert.Contains(t, view, "discard cancelled")
+		assert.Contains(t, view, "section 1/2", "cancelling key is not handled as a command")
+	})
+
+	t.Run("error is shown", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []appliedCall
+		m := pressApplying(sizedStoryModel(t, bubbletea.WithStoryHunkApplier(recordingApplier(&calls, errors.New("patch does not apply")), nil)), "a")
+
+		view := m.View()
+		assert.Contains(t, view, "patch does not apply")
+		assert.NotContains(t, view, "· staged")
+	})
+
+	t.Run("later actions use the reloaded diff", func(t *testing.T) {
+		t.Parallel()
+
+		hunk := func(start int, deleted, added string) diffview.Hunk {
+			return diffview.Hunk{
+				OldStart: start, OldCount: 1, NewStart: start, NewCount: 2,
+				Lines: []diffview.Line{
+					{Type: diffview.LineDeleted, Content: deleted, OldLineNum: start},
+					{Type: diffview.LineAdded, Content: added, NewLineNum: start},
+					{Type: diffview.LineAdded, Content: added + " again", NewLineNum: start + 1},
+				},
+			}
+		}
+		diff := &diffview.Diff{Files: []diffview.FileDiff{{
+			OldPath: "list.go", NewPath: "list.go", Operation: diffview.FileModified,
+			Hunks: []diffview.Hunk{hunk(2, "old first", "new first"), hunk(20, "old last", "new last")},
+		}}}
+		// After discarding the first hunk, the last one starts a line earlier
+		reloaded := &diffview.Diff{Files: []diffview.FileDiff{{
+			OldPath: "list.go", NewPath: "list.go", Operation: diffview.FileModified,
+			Hunks: []diffview.Hunk{hunk(19, "old last", "new last")},
+		}}}
+		story := &diffview.StoryClassification{Sections: []diffview.Section{{
+			Title: "Lists",
+			Hunks: []diffview.HunkRef{{File: "list.go", HunkIndex: 0}, {File: "list.go", HunkIndex: 1}},
+		}}}
+		var calls []appliedCall
+		reload := func(context.Context) (*diffview.Diff, error) { return reloaded, nil }
+		var m tea.Model = bubbletea.NewStoryModel(diff, story,
+			bubbletea.WithStoryHunkApplier(recordingApplier(&calls, nil), reload))
+		m, _ = m.Update(tea.WindowSizeMsg{Width: 200, Height: 20})
+
+		m = pressApplying(m, "xya")
+
+		require.Len(t, calls, 2)
+		assert.Equal(t, diffview.HunkDiscard, calls[0].action)
+		assert.Same(t, diff, calls[0].diff)
+		assert.Equal(t, diffview.HunkStage, calls[1].action)
+		assert.Same(t, reloaded, calls[1].diff)
+		assert.Equal(t, []diffview.HunkRef{{File: "list.go", HunkIndex: 0}}, calls[1].refs)
+		view := m.View()
+		assert.NotContains(t, view, "new first")
+		assert.Contains(t, view, "new last")
+		assert.Contains(t, view, "· staged")
+	})
+
+	t.Run("actions wait for the running one", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []appliedCall
+		m := sizedStoryModel(t, bubbletea.WithStoryHunkApplier(recordingApplier(&calls, nil), nil))
+
+		m, first := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+		m, second := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+
+		require.NotNil(t, first)
+		assert.Nil(t, second)
+		m, _ = m.Update(first())
+		_, third := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+		assert.NotNil(t, third)
+	})
+
+	t.Run("ignored without applier", func(t *testing.T) {
+		t.Parallel()
+
+		m := press(sizedStoryModel(t), "x")
+
+		assert.NotContains(t, m.View(), "discard 1 hunk?")
+	})
+
+	t.Run("palette", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []appliedCall
+		m := pressApplying(sizedStoryModel(t, bubbletea.WithStoryHunkApplier(recordingApplier(&calls, nil), nil)), ":stage section")
+		require.Contains(t, m.View(), "Stage section")
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		require.NotNil(t, cmd)
+		assert.Empty(t, calls, "hunks are applied by the returned command")
+		m, _ = m.Update(cmd())
+
+		require.Len(t, calls, 1)
+		assert.Equal(t, []diffview.HunkRef{coreHunk}, calls[0].refs)
+		assert.Equal(t, diffview.HunkStage, calls[0].action)
+		assert.Contains(t, m.View(), "staged 1 hunk")
+	})
+
+	t.Run("palette hides actions without applier", func(t *testing.T) {
+		t.Parallel()
+
+		m := press(sizedStoryModel(t), ":")
+
+		assert.NotContains(t, m.View(), "Stage hunk")
+	})
+}
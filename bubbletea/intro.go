@@ -29,7 +29,7 @@ func NarrativeDiagram(narrative string, sections []diffview.Section, renderer *l
 
 // linearFlowDiagram renders roles as a horizontal flow: role1 → role2 → role3
 func linearFlowDiagram(sections []diffview.Section, renderer *lipgloss.Renderer) string {
-	roles := extractRoles(sections)
+	roles := diffview.SectionRoles(sections)
 	if len(roles) == 0 {
 		return ""
 	}
@@ -68,7 +68,7 @@ func linearFlowDiagram(sections []diffview.Section, renderer *lipgloss.Renderer)
 //	             |
 //	           infra
 func hubAndSpokeDiagram(sections []diffview.Section, renderer *lipgloss.Renderer) string {
-	roles := extractRoles(sections)
+	roles := diffview.SectionRoles(sections)
 	if len(roles) == 0 {
 		return ""
 	}
@@ -191,16 +191,3 @@ func cardinalHubAndSpoke(coreNode string, peripheralRoles []string, renderer *li
 
 	return lipgloss.JoinVertical(lipgloss.Center, rows...)
 }
-
-// extractRoles returns unique roles from sections in order.
-func extractRoles(sections []diffview.Section) []string {
-	var roles []string
-	seen := make(map[string]bool)
-	for _, s := range sections {
-		if s.Role != "" && !seen[s.Role] {
-			roles = append(roles, s.Role)
-			seen[s.Role] = true
-		}
-	}
-	return roles
-}
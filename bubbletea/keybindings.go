@@ -0,0 +1,101 @@
+package bubbletea
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// ErrUnknownBinding is returned when a key binding override names no binding.
+var ErrUnknownBinding = errors.New("unknown key binding")
+
+// ErrKeyConflict is returned when one key is bound to two actions of the same mode.
+var ErrKeyConflict = errors.New("conflicting key bindings")
+
+// namedBinding exposes a key map field under its config name.
+// Bindings in different modes (e.g. normal navigation and the comment editor)
+// never see the same key press, so they may share keys.
+type namedBinding struct {
+	name    string
+	mode    string
+	binding *key.Binding
+}
+
+// overrideBindings replaces the keys of the named bindings and validates that
+// no key is bound twice within a mode. Help text is updated to the new keys.
+func overrideBindings(bindings []namedBinding, overrides map[string][]string) error {
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	slices.Sort(names) // Deterministic error for multiple problems
+
+	for _, name := range names {
+		keys := overrides[name]
+		i := slices.IndexFunc(bindings, func(b namedBinding) bool { return b.name == name })
+		if i < 0 {
+			return fmt.Errorf("%w %q (valid: %s)", ErrUnknownBinding, name, bindingNames(bindings))
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("key binding %q: no keys given", name)
+		}
+		b := bindings[i].binding
+		b.SetKeys(keys...)
+		b.SetHelp(strings.Join(keys, "/"), b.Help().Desc)
+	}
+
+	return validateBindings(bindings)
+}
+
+// validateBindings reports the first key bound to two actions of the same mode.
+func validateBindings(bindings []namedBinding) error {
+	type owner struct{ mode, key string }
+	seen := make(map[owner]string)
+	for _, b := range bindings {
+		for _, k := range b.binding.Keys() {
+			o := owner{b.mode, k}
+			if other, ok := seen[o]; ok {
+				return fmt.Errorf("%w: %q is bound to both %s and %s", ErrKeyConflict, k, other, b.name)
+			}
+			seen[o] = b.name
+		}
+	}
+	return nil
+}
+
+// bindingNames lists the names of bindings for error messages.
+func bindingNames(bindings []namedBinding) string {
+	names := make([]string, len(bindings))
+	for i, b := range bindings {
+		names[i] = b.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// keyName returns the primary key of a binding for status bar hints.
+func keyName(b key.Binding) string {
+	keys := b.Keys()
+	if len(keys) == 0 {
+		return ""
+	}
+	return keys[0]
+}
+
+// keyPair formats two bindings as "a/b" for status bar hints.
+func keyPair(a, b key.Binding) string {
+	return keyName(a) + "/" + keyName(b)
+}
+
+// keyLabel formats a binding for bracketed hints such as "[Esc] save".
+func keyLabel(b key.Binding) string {
+	name := keyName(b)
+	switch name {
+	case "esc", "tab", "enter", "space":
+		return strings.ToUpper(name[:1]) + name[1:]
+	default:
+		return name
+	}
+}
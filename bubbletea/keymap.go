@@ -15,6 +15,19 @@ type KeyMap struct {
 	NextFile     key.Binding
 	PrevFile     key.Binding
 	Quit         key.Binding
+
+	// Display
+	ToggleWhitespace key.Binding
+	ExpandAbove      key.Binding
+	ExpandBelow      key.Binding
+	ToggleFullFile   key.Binding
+
+	// Review comments
+	Comment     key.Binding
+	SaveComment key.Binding
+
+	// Discoverability
+	Help key.Binding
 }
 
 // DefaultKeyMap returns the default vim-style key bindings.
@@ -64,5 +77,79 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("q", "ctrl+c"),
 			key.WithHelp("q", "quit"),
 		),
+		ToggleWhitespace: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "toggle ignore whitespace"),
+		),
+		ExpandAbove: key.NewBinding(
+			key.WithKeys("{"),
+			key.WithHelp("{", "more context above"),
+		),
+		ExpandBelow: key.NewBinding(
+			key.WithKeys("}"),
+			key.WithHelp("}", "more context below"),
+		),
+		ToggleFullFile: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "toggle full file"),
+		),
+		Comment: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "comment on top line"),
+		),
+		SaveComment: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "save comment"),
+		),
+		Help: key.NewBinding(
+			key.WithKeys("?"),
+			key.WithHelp("?", "toggle help"),
+		),
+	}
+}
+
+// ShortHelp implements help.KeyMap.
+func (km KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{km.Down, km.NextHunk, km.NextFile, km.Comment, km.Help, km.Quit}
+}
+
+// FullHelp implements help.KeyMap, grouping bindings into help columns.
+func (km KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{km.Up, km.Down, km.HalfPageUp, km.HalfPageDown, km.GotoTop, km.GotoBottom},
+		{km.NextHunk, km.PrevHunk, km.NextFile, km.PrevFile, km.ToggleWhitespace, km.ExpandAbove, km.ExpandBelow, km.ToggleFullFile},
+		{km.Comment, km.SaveComment, km.Help, km.Quit},
+	}
+}
+
+// Override returns a copy of the key map with the named bindings replaced.
+// Names are the snake_case field names (e.g. "next_hunk"). Returns an error
+// for unknown names or when a key ends up bound to two actions.
+func (km KeyMap) Override(overrides map[string][]string) (KeyMap, error) {
+	err := overrideBindings(km.bindings(), overrides)
+	return km, err
+}
+
+// bindings returns the configurable bindings of the key map.
+func (km *KeyMap) bindings() []namedBinding {
+	return []namedBinding{
+		{name: "up", binding: &km.Up},
+		{name: "down", binding: &km.Down},
+		{name: "half_page_up", binding: &km.HalfPageUp},
+		{name: "half_page_down", binding: &km.HalfPageDown},
+		{name: "goto_top", binding: &km.GotoTop},
+		{name: "goto_bottom", binding: &km.GotoBottom},
+		{name: "next_hunk", binding: &km.NextHunk},
+		{name: "prev_hunk", binding: &km.PrevHunk},
+		{name: "next_file", binding: &km.NextFile},
+		{name: "prev_file", binding: &km.PrevFile},
+		{name: "quit", binding: &km.Quit},
+		{name: "toggle_whitespace", binding: &km.ToggleWhitespace},
+		{name: "expand_above", binding: &km.ExpandAbove},
+		{name: "expand_below", binding: &km.ExpandBelow},
+		{name: "toggle_full_file", binding: &km.ToggleFullFile},
+		{name: "comment", binding: &km.Comment},
+		{name: "save_comment", mode: "comment", binding: &km.SaveComment},
+		{name: "help", binding: &km.Help},
 	}
 }
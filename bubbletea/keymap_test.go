@@ -5,8 +5,10 @@ import (
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	diffview "github.com/fwojciec/diffstory"
 	"github.com/fwojciec/diffstory/bubbletea"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDefaultKeyMap_HasExpectedBindings(t *testing.T) {
@@ -111,3 +113,112 @@ func TestKeyMap_HelpText(t *testing.T) {
 		assert.NotEmpty(t, km.Quit.Help().Desc, "Quit should have help description")
 	})
 }
+
+func TestKeyMap_Override(t *testing.T) {
+	t.Parallel()
+
+	t.Run("replaces keys and help text", func(t *testing.T) {
+		t.Parallel()
+
+		km, err := bubbletea.DefaultKeyMap().Override(map[string][]string{
+			"next_hunk": {"J", "ctrl+n"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"J", "ctrl+n"}, km.NextHunk.Keys())
+		assert.Equal(t, "J/ctrl+n", km.NextHunk.Help().Key)
+		assert.Equal(t, "next hunk", km.NextHunk.Help().Desc)
+		// The default key map is not modified
+		assert.Equal(t, []string{"n"}, bubbletea.DefaultKeyMap().NextHunk.Keys())
+	})
+
+	t.Run("unknown binding name", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := bubbletea.DefaultKeyMap().Override(map[string][]string{"next_chunk": {"x"}})
+		require.ErrorIs(t, err, bubbletea.ErrUnknownBinding)
+		assert.Contains(t, err.Error(), "next_hunk")
+	})
+
+	t.Run("conflicting keys", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := bubbletea.DefaultKeyMap().Override(map[string][]string{"next_file": {"n"}})
+		require.ErrorIs(t, err, bubbletea.ErrKeyConflict)
+		assert.Contains(t, err.Error(), `"n" is bound to both next_hunk and next_file`)
+	})
+
+	t.Run("editor keys may reuse navigation keys", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := bubbletea.DefaultKeyMap().Override(map[string][]string{"save_comment": {"q"}})
+		assert.NoError(t, err)
+	})
+
+	t.Run("empty key list", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := bubbletea.DefaultKeyMap().Override(map[string][]string{"quit": {}})
+		assert.Error(t, err)
+	})
+}
+
+func TestStoryKeyMap_Override_SwapsKeys(t *testing.T) {
+	t.Parallel()
+
+	// Swapping keys between two bindings is valid once both are applied
+	km, err := bubbletea.DefaultStoryKeyMap().Override(map[string][]string{
+		"next_section": {"]"},
+		"prev_section": {"["},
+		"next_file":    {"s"},
+		"prev_file":    {"S"},
+	})
+	require.NoError(t, err)
+
+	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{']'}}
+	assert.True(t, key.Matches(msg, km.NextSection))
+	assert.False(t, key.Matches(msg, km.NextFile))
+}
+
+func TestEvalKeyMap_Override(t *testing.T) {
+	t.Parallel()
+
+	km, err := bubbletea.DefaultEvalKeyMap().Override(map[string][]string{"next_case": {"J"}, "prev_case": {"K"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"J"}, km.NextCase.Keys())
+
+	_, err = bubbletea.DefaultEvalKeyMap().Override(map[string][]string{"pass": {"f"}})
+	require.ErrorIs(t, err, bubbletea.ErrKeyConflict)
+}
+
+func TestStoryModel_StatusBarShowsCustomKeys(t *testing.T) {
+	t.Parallel()
+
+	km, err := bubbletea.DefaultStoryKeyMap().Override(map[string][]string{
+		"next_section": {"]"},
+		"prev_section": {"["},
+		"next_file":    {"s"},
+		"prev_file":    {"S"},
+	})
+	require.NoError(t, err)
+
+	diff := &diffview.Diff{Files: []diffview.FileDiff{{
+		NewPath: "a.go",
+		Hunks: []diffview.Hunk{{
+			NewStart: 1, NewCount: 1,
+			Lines: []diffview.Line{{Type: diffview.LineAdded, Content: "x", NewLineNum: 1}},
+		}},
+	}}}
+	story := &diffview.StoryClassification{Sections: []diffview.Section{{
+		Title: "Only",
+		Hunks: []diffview.HunkRef{{File: "a.go", HunkIndex: 0}},
+	}}}
+
+	var m tea.Model = bubbletea.NewStoryModel(diff, story,
+		bubbletea.WithStoryKeyMap(km), bubbletea.WithIntroSlide())
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 160, Height: 20})
+	view := m.View()
+
+	assert.Contains(t, view, "]/[:section")
+	assert.Contains(t, view, "[]] next section")
+}
@@ -143,7 +143,7 @@ func TestLargeDiff_SkipsTokenizationForLongLines(t *testing.T) {
 
 	// Create a diff with very long lines (like data files)
 	// These should NOT be tokenized - too long to be real code
-	diff := generateLargeDiff(10, 2000) // 10 lines, 2000 chars each (over maxLineLength)
+	diff := generateLargeDiff(10, 2000) // 10 lines, 2000 chars each (over diffview.MaxTokenizeLineLength)
 
 	// Create a tokenizer that panics if called - proving tokenization is skipped
 	panicTokenizer := &panicOnCallTokenizer{}
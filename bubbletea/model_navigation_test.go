@@ -486,8 +486,8 @@ func TestModel_TracksHunkPositions(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath: "a/file1.go",
-				NewPath: "b/file1.go",
+				OldPath: "file1.go",
+				NewPath: "file1.go",
 				Hunks: []diffview.Hunk{
 					{
 						Lines: []diffview.Line{
@@ -503,8 +503,8 @@ func TestModel_TracksHunkPositions(t *testing.T) {
 				},
 			},
 			{
-				OldPath: "a/file2.go",
-				NewPath: "b/file2.go",
+				OldPath: "file2.go",
+				NewPath: "file2.go",
 				Hunks: []diffview.Hunk{
 					{
 						Lines: []diffview.Line{
@@ -543,8 +543,8 @@ func TestModel_SkipsFilesWithNoHunks(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath: "a/file1.go",
-				NewPath: "b/file1.go",
+				OldPath: "file1.go",
+				NewPath: "file1.go",
 				Hunks: []diffview.Hunk{
 					{
 						Lines: []diffview.Line{
@@ -555,14 +555,14 @@ func TestModel_SkipsFilesWithNoHunks(t *testing.T) {
 			},
 			{
 				// Binary file with no hunks
-				OldPath:  "a/image.png",
-				NewPath:  "b/image.png",
+				OldPath:  "image.png",
+				NewPath:  "image.png",
 				IsBinary: true,
 				Hunks:    nil,
 			},
 			{
-				OldPath: "a/file2.go",
-				NewPath: "b/file2.go",
+				OldPath: "file2.go",
+				NewPath: "file2.go",
 				Hunks: []diffview.Hunk{
 					{
 						Lines: []diffview.Line{
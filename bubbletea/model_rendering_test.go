@@ -19,8 +19,8 @@ func TestModel_RendersFileHeaders(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/test.go",
-				NewPath:   "b/test.go",
+				OldPath:   "test.go",
+				NewPath:   "test.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -58,8 +58,8 @@ func TestModel_RendersHunkHeaders(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/test.go",
-				NewPath:   "b/test.go",
+				OldPath:   "test.go",
+				NewPath:   "test.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -97,8 +97,8 @@ func TestModel_RendersLinePrefixes(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/test.go",
-				NewPath:   "b/test.go",
+				OldPath:   "test.go",
+				NewPath:   "test.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -141,8 +141,8 @@ func TestModel_AppliesColors(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/test.go",
-				NewPath:   "b/test.go",
+				OldPath:   "test.go",
+				NewPath:   "test.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -186,8 +186,8 @@ func TestModel_BackgroundExtendsFullWidth(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/test.go",
-				NewPath:   "b/test.go",
+				OldPath:   "test.go",
+				NewPath:   "test.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -232,8 +232,8 @@ func TestModel_BackgroundExtendsFullWidthWithUnicode(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/test.go",
-				NewPath:   "b/test.go",
+				OldPath:   "test.go",
+				NewPath:   "test.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -276,22 +276,22 @@ func TestModel_StatusBarShowsFilePosition(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath: "a/first.go",
-				NewPath: "b/first.go",
+				OldPath: "first.go",
+				NewPath: "first.go",
 				Hunks: []diffview.Hunk{
 					{Lines: []diffview.Line{{Type: diffview.LineContext, Content: "first file"}}},
 				},
 			},
 			{
-				OldPath: "a/second.go",
-				NewPath: "b/second.go",
+				OldPath: "second.go",
+				NewPath: "second.go",
 				Hunks: []diffview.Hunk{
 					{Lines: []diffview.Line{{Type: diffview.LineContext, Content: "second file"}}},
 				},
 			},
 			{
-				OldPath: "a/third.go",
-				NewPath: "b/third.go",
+				OldPath: "third.go",
+				NewPath: "third.go",
 				Hunks: []diffview.Hunk{
 					{Lines: []diffview.Line{{Type: diffview.LineContext, Content: "third file"}}},
 				},
@@ -320,8 +320,8 @@ func TestModel_StatusBarShowsHunkPosition(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath: "a/file.go",
-				NewPath: "b/file.go",
+				OldPath: "file.go",
+				NewPath: "file.go",
 				Hunks: []diffview.Hunk{
 					{Lines: []diffview.Line{{Type: diffview.LineContext, Content: "hunk1"}}},
 					{Lines: []diffview.Line{{Type: diffview.LineContext, Content: "hunk2"}}},
@@ -357,8 +357,8 @@ func TestModel_StatusBarShowsScrollPosition(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath: "a/file.go",
-				NewPath: "b/file.go",
+				OldPath: "file.go",
+				NewPath: "file.go",
 				Hunks: []diffview.Hunk{
 					{Lines: lines},
 				},
@@ -402,8 +402,8 @@ func TestModel_StatusBarShowsKeyHints(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath: "a/file.go",
-				NewPath: "b/file.go",
+				OldPath: "file.go",
+				NewPath: "file.go",
 				Hunks: []diffview.Hunk{
 					{Lines: []diffview.Line{{Type: diffview.LineContext, Content: "content"}}},
 				},
@@ -439,8 +439,8 @@ func TestModel_RendersLineNumbersInGutter(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/test.go",
-				NewPath:   "b/test.go",
+				OldPath:   "test.go",
+				NewPath:   "test.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -488,8 +488,8 @@ func TestModel_GutterUsesEmptySpaceForMissingLineNumbers(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/test.go",
-				NewPath:   "b/test.go",
+				OldPath:   "test.go",
+				NewPath:   "test.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -535,8 +535,8 @@ func TestModel_GutterHasColoredBackgroundForAddedLines(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/test.go",
-				NewPath:   "b/test.go",
+				OldPath:   "test.go",
+				NewPath:   "test.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -583,8 +583,8 @@ func TestModel_GutterHasColoredBackgroundForDeletedLines(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/test.go",
-				NewPath:   "b/test.go",
+				OldPath:   "test.go",
+				NewPath:   "test.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -631,8 +631,8 @@ func TestModel_RendersFileHeaderWithStats(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/handler.go",
-				NewPath:   "b/handler.go",
+				OldPath:   "handler.go",
+				NewPath:   "handler.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -679,8 +679,8 @@ func TestModel_WithTheme(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/test.go",
-				NewPath:   "b/test.go",
+				OldPath:   "test.go",
+				NewPath:   "test.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -727,8 +727,8 @@ func TestModel_StatusBarUsesThemeUIColors(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath: "a/file.go",
-				NewPath: "b/file.go",
+				OldPath: "file.go",
+				NewPath: "file.go",
 				Hunks: []diffview.Hunk{
 					{Lines: []diffview.Line{{Type: diffview.LineContext, Content: "content"}}},
 				},
@@ -778,8 +778,8 @@ func TestModel_AppliesSyntaxHighlighting(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/main.go",
-				NewPath:   "b/main.go",
+				OldPath:   "main.go",
+				NewPath:   "main.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -868,8 +868,8 @@ func TestModel_PaddingBetweenGutterAndCodePrefix(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/test.go",
-				NewPath:   "b/test.go",
+				OldPath:   "test.go",
+				NewPath:   "test.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -919,8 +919,8 @@ func TestModel_PaddingUsesCodeLineBackgroundColor(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/test.go",
-				NewPath:   "b/test.go",
+				OldPath:   "test.go",
+				NewPath:   "test.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -1025,3 +1025,53 @@ func TestModel_ShowsEmptyFileDeletion(t *testing.T) {
 	tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
 	tm.WaitFinished(t, teatest.WithFinalTimeout(0))
 }
+
+func TestModel_RendersCombinedDiff(t *testing.T) {
+	t.Parallel()
+
+	both := []diffview.LineType{diffview.LineAdded, diffview.LineAdded}
+	diff := &diffview.Diff{Files: []diffview.FileDiff{{
+		OldPath: "f.txt", NewPath: "f.txt",
+		Hunks: []diffview.Hunk{{
+			OldStart: 1, OldCount: 2, NewStart: 1, NewCount: 2,
+			Parents: []diffview.ParentRange{{Start: 1, Count: 2}, {Start: 1, Count: 2}},
+			Lines: []diffview.Line{
+				{Type: diffview.LineContext, Content: "a", OldLineNum: 1, NewLineNum: 1, Origins: []diffview.LineType{diffview.LineContext, diffview.LineContext}},
+				{Type: diffview.LineDeleted, Content: "ours", OldLineNum: 2, Origins: []diffview.LineType{diffview.LineDeleted, diffview.LineContext}},
+				{Type: diffview.LineDeleted, Content: "theirs", Origins: []diffview.LineType{diffview.LineContext, diffview.LineDeleted}},
+				{Type: diffview.LineAdded, Content: "resolved", NewLineNum: 2, Origins: both},
+			},
+		}},
+	}}}
+
+	var m tea.Model = bubbletea.NewModel(diff)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	view := m.View()
+
+	assert.Contains(t, view, "@@@ -1,2 -1,2 +1,2 @@@")
+	assert.Contains(t, view, "- ours")
+	assert.Contains(t, view, " -theirs")
+	assert.Contains(t, view, "++resolved")
+}
+
+func TestModel_RendersFileMetadataInHeaders(t *testing.T) {
+	t.Parallel()
+
+	diff := &diffview.Diff{Files: []diffview.FileDiff{
+		{
+			OldPath: "old.go", NewPath: "new.go", Operation: diffview.FileRenamed, Similarity: 90,
+			Hunks: []diffview.Hunk{{OldStart: 1, OldCount: 1, NewStart: 1, NewCount: 1, Lines: []diffview.Line{
+				{Type: diffview.LineDeleted, Content: "package old", OldLineNum: 1},
+				{Type: diffview.LineAdded, Content: "package new", NewLineNum: 1},
+			}}},
+		},
+		{OldPath: "run.sh", NewPath: "run.sh", OldMode: 0o100644, NewMode: 0o100755},
+	}}
+
+	var m tea.Model = bubbletea.NewModel(diff)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+	view := m.View()
+
+	assert.Contains(t, view, "old.go → new.go (90% similar)")
+	assert.Contains(t, view, "run.sh (mode 100644 → 100755)", "mode-only changes are shown")
+}
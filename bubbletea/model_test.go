@@ -21,8 +21,8 @@ func TestModel_Init(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/file.go",
-				NewPath:   "b/file.go",
+				OldPath:   "file.go",
+				NewPath:   "file.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -65,8 +65,8 @@ func TestModel_ViewAfterReady(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/test.go",
-				NewPath:   "b/test.go",
+				OldPath:   "test.go",
+				NewPath:   "test.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -131,8 +131,8 @@ func TestModel_WindowResize(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/resize.go",
-				NewPath:   "b/resize.go",
+				OldPath:   "resize.go",
+				NewPath:   "resize.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
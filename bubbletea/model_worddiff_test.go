@@ -24,8 +24,8 @@ func TestModel_WordDiffHighlighting(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/test.go",
-				NewPath:   "b/test.go",
+				OldPath:   "test.go",
+				NewPath:   "test.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -101,8 +101,8 @@ func TestModel_WordDiffHighlighting_NonPairedLinesNoHighlight(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/test.go",
-				NewPath:   "b/test.go",
+				OldPath:   "test.go",
+				NewPath:   "test.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -158,8 +158,8 @@ func TestModel_WordDiffHighlighting_MultiplePairs(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/test.go",
-				NewPath:   "b/test.go",
+				OldPath:   "test.go",
+				NewPath:   "test.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -230,8 +230,8 @@ func TestModel_WordDiffHighlighting_ConsecutiveDeletesAndAdds(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/test.go",
-				NewPath:   "b/test.go",
+				OldPath:   "test.go",
+				NewPath:   "test.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -324,8 +324,8 @@ func TestModel_WordDiffHighlighting_SkipsWhenLinesTooDifferent(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/test.go",
-				NewPath:   "b/test.go",
+				OldPath:   "test.go",
+				NewPath:   "test.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -395,8 +395,8 @@ func TestModel_WordDiffHighlighting_NoWordDiffer(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/test.go",
-				NewPath:   "b/test.go",
+				OldPath:   "test.go",
+				NewPath:   "test.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
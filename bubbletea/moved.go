@@ -0,0 +1,43 @@
+package bubbletea
+
+import (
+	"fmt"
+
+	"github.com/fwojciec/diffstory"
+)
+
+// movedKey identifies a line within a hunk.
+type movedKey struct {
+	hunk hunkKey
+	line int // Index into Hunk.Lines
+}
+
+// movedLineMarks maps every line of the moved blocks to its annotation.
+// The first line of each block is annotated with where the code moved
+// from (added side) or to (deleted side); the other lines map to "".
+func movedLineMarks(blocks []diffview.MovedBlock) map[movedKey]string {
+	if len(blocks) == 0 {
+		return nil
+	}
+	marks := make(map[movedKey]string)
+	for _, b := range blocks {
+		markMovedSide(marks, b.From, b.Lines, fmt.Sprintf("moved to %s:%d", b.To.File, b.To.Line))
+		markMovedSide(marks, b.To, b.Lines, fmt.Sprintf("moved from %s:%d", b.From.File, b.From.Line))
+	}
+	return marks
+}
+
+// markMovedSide records the lines of one side of a moved block.
+func markMovedSide(marks map[movedKey]string, loc diffview.MovedLocation, lines int, annotation string) {
+	hunk := hunkKey{file: loc.File, hunkIndex: loc.HunkIndex}
+	for i := range lines {
+		marks[movedKey{hunk: hunk, line: loc.LineIndex + i}] = ""
+	}
+	marks[movedKey{hunk: hunk, line: loc.LineIndex}] = annotation
+}
+
+// detectMovedLines finds the moved blocks in diff and marks their lines
+// for rendering.
+func detectMovedLines(diff *diffview.Diff) map[movedKey]string {
+	return movedLineMarks(diffview.DetectMovedBlocks(diff, diffview.DefaultMinMovedLines))
+}
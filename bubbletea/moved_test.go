@@ -0,0 +1,57 @@
+package bubbletea_test
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	diffview "github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/bubbletea"
+	dv "github.com/fwojciec/diffstory/lipgloss"
+	"github.com/stretchr/testify/assert"
+)
+
+// movedDiff moves a three-line function from old.go to new.go.
+func movedDiff() *diffview.Diff {
+	body := []string{"func helper() int {", "\treturn 42", "}"}
+	var deleted, added []diffview.Line
+	for i, content := range body {
+		deleted = append(deleted, diffview.Line{Type: diffview.LineDeleted, Content: content, OldLineNum: 20 + i})
+		added = append(added, diffview.Line{Type: diffview.LineAdded, Content: content, NewLineNum: 5 + i})
+	}
+	return &diffview.Diff{Files: []diffview.FileDiff{
+		{OldPath: "old.go", NewPath: "old.go", Hunks: []diffview.Hunk{{OldStart: 20, OldCount: 3, Lines: deleted}}},
+		{OldPath: "new.go", NewPath: "new.go", Hunks: []diffview.Hunk{{NewStart: 5, NewCount: 3, Lines: added}}},
+	}}
+}
+
+func TestModel_MovedLines(t *testing.T) {
+	t.Parallel()
+
+	var m tea.Model = bubbletea.NewModel(movedDiff(),
+		bubbletea.WithTheme(dv.TestTheme()),
+		bubbletea.WithRenderer(trueColorRenderer()),
+	)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+	view := m.View()
+
+	assert.Contains(t, view, "↳ moved to new.go:5")
+	assert.Contains(t, view, "↳ moved from old.go:20")
+	assert.Equal(t, 2, strings.Count(view, "↳"), "only the first line of each block is annotated")
+
+	// TestTheme moved background: 15% blend of #ffff00 into #000000
+	assert.Contains(t, view, "48;2;38;38;0")
+}
+
+func TestStoryModel_MovedLines(t *testing.T) {
+	t.Parallel()
+
+	story := &diffview.StoryClassification{Sections: []diffview.Section{
+		{Title: "Move helper", Hunks: []diffview.HunkRef{{File: "new.go", HunkIndex: 0}}},
+		{Title: "Cleanup", Hunks: []diffview.HunkRef{{File: "old.go", HunkIndex: 0}}},
+	}}
+	var m tea.Model = bubbletea.NewStoryModel(movedDiff(), story)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+
+	assert.Contains(t, m.View(), "↳ moved from old.go:20")
+}
@@ -0,0 +1,129 @@
+package bubbletea_test
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	diffview "github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/bubbletea"
+	dv "github.com/fwojciec/diffstory/lipgloss"
+	"github.com/fwojciec/diffstory/worddiff"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingDiffer records the line pairs the viewer word-diffs.
+type recordingDiffer struct {
+	*worddiff.Differ
+	pairs [][2]string
+}
+
+func (d *recordingDiffer) Diff(old, new string) (oldSegs, newSegs []diffview.Segment) {
+	d.pairs = append(d.pairs, [2]string{old, new})
+	return d.Differ.Diff(old, new)
+}
+
+// changeRunDiff is a single hunk deleting deleted and adding added.
+func changeRunDiff(deleted, added []string) *diffview.Diff {
+	lines := make([]diffview.Line, 0, len(deleted)+len(added))
+	for i, content := range deleted {
+		lines = append(lines, diffview.Line{Type: diffview.LineDeleted, Content: content, OldLineNum: i + 1})
+	}
+	for i, content := range added {
+		lines = append(lines, diffview.Line{Type: diffview.LineAdded, Content: content, NewLineNum: i + 1})
+	}
+	return &diffview.Diff{Files: []diffview.FileDiff{
+		{OldPath: "main.go", NewPath: "main.go", Hunks: []diffview.Hunk{{OldStart: 1, OldCount: len(deleted), NewStart: 1, NewCount: len(added), Lines: lines}}},
+	}}
+}
+
+func TestModel_WordDiffPairsLinesBySimilarity(t *testing.T) {
+	t.Parallel()
+
+	// Two lines are inserted in the middle of the block; positional pairing
+	// would compare each later line with the wrong one.
+	diff := changeRunDiff(
+		[]string{
+			"total := sum(values)",
+			"log.Printf(\"total: %d\", total)",
+			"return total, nil",
+		},
+		[]string{
+			"total := sum(values, weights)",
+			"if total < 0 {",
+			"\treturn 0, errNegative",
+			"log.Printf(\"weighted total: %d\", total)",
+			"return total, err",
+		},
+	)
+	differ := &recordingDiffer{Differ: worddiff.NewDiffer()}
+
+	var m tea.Model = bubbletea.NewModel(diff,
+		bubbletea.WithTheme(dv.TestTheme()),
+		bubbletea.WithWordDiffer(differ),
+	)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+	m.View()
+
+	assert.Equal(t, [][2]string{
+		{"total := sum(values)", "total := sum(values, weights)"},
+		{"log.Printf(\"total: %d\", total)", "log.Printf(\"weighted total: %d\", total)"},
+		{"return total, nil", "return total, err"},
+	}, differ.pairs)
+}
+
+func TestModel_WordDiffLeavesDissimilarLinesUnpaired(t *testing.T) {
+	t.Parallel()
+
+	diff := changeRunDiff(
+		[]string{"x := compute(a, b)", "// TODO: remove"},
+		[]string{"x := compute(a, b, c)", "defer cleanup()"},
+	)
+	differ := &recordingDiffer{Differ: worddiff.NewDiffer()}
+
+	var m tea.Model = bubbletea.NewModel(diff,
+		bubbletea.WithTheme(dv.TestTheme()),
+		bubbletea.WithWordDiffer(differ),
+	)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+	m.View()
+
+	assert.Equal(t, [][2]string{{"x := compute(a, b)", "x := compute(a, b, c)"}}, differ.pairs)
+}
+
+// largeChangeRun rewrites n lines, inserting a new line after every tenth.
+func largeChangeRun(n int) *diffview.Diff {
+	deleted := make([]string, n)
+	added := make([]string, 0, n+n/10)
+	for i := range n {
+		deleted[i] = fmt.Sprintf("\tresult%d, err := s.store.Load(ctx, key%d, opts)", i, i)
+		added = append(added, fmt.Sprintf("\tresult%d, err := s.store.Load(ctx, key%d, options)", i, i))
+		if i%10 == 9 {
+			added = append(added, fmt.Sprintf("\tmetrics.Inc(\"load_%d\")", i))
+		}
+	}
+	return changeRunDiff(deleted, added)
+}
+
+func BenchmarkWordDiffPairing(b *testing.B) {
+	// 120 deleted and 132 added lines is the largest run below the alignment
+	// cap; 500 lines exceed it and measure the positional fallback.
+	for _, n := range []int{10, 100, 120, 500} {
+		b.Run(fmt.Sprintf("lines_%d", n), func(b *testing.B) {
+			diff := largeChangeRun(n)
+			msg := tea.WindowSizeMsg{Width: 120, Height: 40}
+			opts := []bubbletea.ModelOption{
+				bubbletea.WithTheme(dv.TestTheme()),
+				bubbletea.WithWordDiffer(worddiff.NewDiffer()),
+			}
+
+			b.ReportAllocs()
+			var result string
+			for b.Loop() {
+				m, _ := bubbletea.NewModel(diff, opts...).Update(msg)
+				result = m.View()
+			}
+			benchResult = result
+		})
+	}
+}
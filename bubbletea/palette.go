@@ -0,0 +1,194 @@
+package bubbletea
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fwojciec/diffstory"
+)
+
+// paletteAction identifies what a palette command does when run.
+type paletteAction int
+
+const (
+	actionGotoSection paletteAction = iota
+	actionGotoFile
+	actionToggleCollapse
+	actionToggleWhitespace
+	actionExpandAbove
+	actionExpandBelow
+	actionToggleFullFile
+	actionSaveCase
+	actionExport
+	actionApplyHunks
+)
+
+// paletteCommand is an entry in the command palette.
+type paletteCommand struct {
+	title   string
+	action  paletteAction
+	section int    // Navigable section index for actionGotoSection
+	file    string // File path for actionGotoFile
+
+	// Hunk action for actionApplyHunks, to the focused hunk or the section
+	hunkAction   diffview.HunkAction
+	wholeSection bool
+}
+
+// commandPalette is a ":" prompt that fuzzy-filters a list of commands.
+type commandPalette struct {
+	open     bool
+	input    textinput.Model
+	commands []paletteCommand
+	matches  []paletteCommand
+	selected int
+}
+
+// begin opens the palette with the given commands.
+func (p *commandPalette) begin(commands []paletteCommand, width int) tea.Cmd {
+	p.input = textinput.New()
+	p.input.Prompt = ":"
+	p.input.Placeholder = "type to filter commands"
+	p.input.Width = max(width-2, 1)
+	p.commands = commands
+	p.open = true
+	p.filter()
+	return p.input.Focus()
+}
+
+// close hides the palette.
+func (p *commandPalette) close() {
+	p.open = false
+	p.input.Blur()
+}
+
+// update forwards a key to the prompt and refilters the commands.
+func (p *commandPalette) update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	p.filter()
+	return cmd
+}
+
+// move changes the selection by delta, wrapping around the matches.
+func (p *commandPalette) move(delta int) {
+	if len(p.matches) == 0 {
+		return
+	}
+	p.selected = (p.selected + delta + len(p.matches)) % len(p.matches)
+}
+
+// current returns the selected command.
+func (p commandPalette) current() (paletteCommand, bool) {
+	if p.selected >= len(p.matches) {
+		return paletteCommand{}, false
+	}
+	return p.matches[p.selected], true
+}
+
+// filter keeps the commands matching the prompt, best matches first.
+func (p *commandPalette) filter() {
+	type scored struct {
+		cmd   paletteCommand
+		score int
+	}
+	query := p.input.Value()
+	var results []scored
+	for _, c := range p.commands {
+		if score, ok := fuzzyScore(query, c.title); ok {
+			results = append(results, scored{c, score})
+		}
+	}
+	slices.SortStableFunc(results, func(a, b scored) int { return b.score - a.score })
+
+	p.matches = make([]paletteCommand, len(results))
+	for i, r := range results {
+		p.matches[i] = r.cmd
+	}
+	p.selected = 0
+}
+
+// view renders the palette full-screen: the prompt followed by the matches.
+func (p commandPalette) view(renderer *lipgloss.Renderer, width, height int) string {
+	if renderer == nil {
+		renderer = lipgloss.DefaultRenderer()
+	}
+
+	var s strings.Builder
+	s.WriteString(renderer.NewStyle().Bold(true).Render("COMMANDS"))
+	s.WriteString("\n\n")
+	s.WriteString(p.input.View())
+	s.WriteString("\n\n")
+
+	if len(p.matches) == 0 {
+		s.WriteString(renderer.NewStyle().Faint(true).Render("No matching commands"))
+		return s.String()
+	}
+
+	// Keep the selection visible when there are more matches than rows
+	rows := max(height-5, 1)
+	start := max(p.selected-rows+1, 0)
+	end := min(start+rows, len(p.matches))
+
+	selectedStyle := renderer.NewStyle().Reverse(true)
+	for i := start; i < end; i++ {
+		line := truncateRunes(p.matches[i].title, max(width-2, 1))
+		if i == p.selected {
+			fmt.Fprintf(&s, "%s\n", selectedStyle.Render("> "+line))
+		} else {
+			fmt.Fprintf(&s, "  %s\n", line)
+		}
+	}
+	return strings.TrimSuffix(s.String(), "\n")
+}
+
+// fuzzyScore reports whether query's characters appear in target in order,
+// ignoring case. Matches at word starts and runs of consecutive characters
+// score higher, so "gts" prefers "Go to section" over "toggle settings".
+func fuzzyScore(query, target string) (int, bool) {
+	q := []rune(strings.ToLower(strings.TrimSpace(query)))
+	if len(q) == 0 {
+		return 0, true
+	}
+
+	t := []rune(strings.ToLower(target))
+	score, qi, prev := 0, 0, -2
+	for ti, r := range t {
+		if qi == len(q) {
+			break
+		}
+		if r != q[qi] {
+			continue
+		}
+		score++
+		if ti == prev+1 {
+			score += 2 // Consecutive characters
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score += 3 // Start of a word or path segment
+		}
+		prev = ti
+		qi++
+	}
+	if qi < len(q) {
+		return 0, false
+	}
+	return score, true
+}
+
+// truncateRunes shortens s to at most n runes, marking the cut with "…".
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n <= 1 {
+		return string(r[:n])
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,177 @@
+package bubbletea_test
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/bubbletea"
+	"github.com/fwojciec/diffstory/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func paletteStory() (*diffview.Diff, *diffview.StoryClassification) {
+	hunk := func(content string) diffview.Hunk {
+		return diffview.Hunk{
+			NewStart: 1, NewCount: 1,
+			Lines: []diffview.Line{{Type: diffview.LineAdded, Content: content, NewLineNum: 1}},
+		}
+	}
+	diff := &diffview.Diff{Files: []diffview.FileDiff{
+		{NewPath: "auth.go", Operation: diffview.FileAdded, Hunks: []diffview.Hunk{hunk("func login() {}")}},
+		{NewPath: "auth_test.go", Operation: diffview.FileAdded, Hunks: []diffview.Hunk{hunk("func TestLogin() {}")}},
+	}}
+	story := &diffview.StoryClassification{Sections: []diffview.Section{
+		{Title: "Core change", Hunks: []diffview.HunkRef{{File: "auth.go", HunkIndex: 0}}},
+		{Title: "Tests", Hunks: []diffview.HunkRef{{File: "auth_test.go", HunkIndex: 0}}},
+	}}
+	return diff, story
+}
+
+// press sends each rune of keys to the model as a separate key press.
+func press(m tea.Model, keys string) tea.Model {
+	for _, r := range keys {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return m
+}
+
+func sizedStoryModel(t *testing.T, opts ...bubbletea.StoryModelOption) tea.Model {
+	t.Helper()
+
+	diff, story := paletteStory()
+	var m tea.Model = bubbletea.NewStoryModel(diff, story, opts...)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 200, Height: 20})
+	return m
+}
+
+func TestStoryModel_CommandPalette(t *testing.T) {
+	t.Parallel()
+
+	t.Run("lists commands", func(t *testing.T) {
+		t.Parallel()
+
+		m := press(sizedStoryModel(t, bubbletea.WithIntroSlide()), ":")
+		view := m.View()
+
+		assert.Contains(t, view, "Go to intro")
+		assert.Contains(t, view, "Go to section 2: Tests")
+		assert.Contains(t, view, "Go to file: auth_test.go")
+		assert.Contains(t, view, "Toggle collapsed hunks")
+		assert.NotContains(t, view, "Export story")
+	})
+
+	t.Run("fuzzy filter and run go to section", func(t *testing.T) {
+		t.Parallel()
+
+		m := press(sizedStoryModel(t), ":tests")
+		assert.Contains(t, m.View(), "Go to section 2: Tests")
+		assert.NotContains(t, m.View(), "Core change")
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.Contains(t, m.View(), "section 2/2: Tests")
+	})
+
+	t.Run("go to file switches to its section", func(t *testing.T) {
+		t.Parallel()
+
+		m := press(sizedStoryModel(t), ":file auth_test")
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		view := m.View()
+		assert.Contains(t, view, "section 2/2: Tests")
+		assert.Contains(t, view, "TestLogin")
+	})
+
+	t.Run("arrow keys move the selection", func(t *testing.T) {
+		t.Parallel()
+
+		m := press(sizedStoryModel(t), ":go to section")
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.Contains(t, m.View(), "section 2/2: Tests")
+	})
+
+	t.Run("esc closes without running", func(t *testing.T) {
+		t.Parallel()
+
+		m := press(sizedStoryModel(t), ":tests")
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+		view := m.View()
+		assert.NotContains(t, view, "COMMANDS")
+		assert.Contains(t, view, "section 1/2: Core change")
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		t.Parallel()
+
+		m := press(sizedStoryModel(t), ":zzzz")
+
+		assert.Contains(t, m.View(), "No matching commands")
+	})
+
+	t.Run("export", func(t *testing.T) {
+		t.Parallel()
+
+		var exportedPath string
+		exporter := &mock.StoryExporter{
+			ExportFn: func(path string, _ *diffview.Diff, story *diffview.StoryClassification) error {
+				exportedPath = path
+				assert.Len(t, story.Sections, 2)
+				return nil
+			},
+		}
+
+		m := press(sizedStoryModel(t, bubbletea.WithStoryExporter(exporter, "/tmp/story.md")), ":export")
+		require.Contains(t, m.View(), "Export story to story.md")
+		_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.Equal(t, "/tmp/story.md", exportedPath)
+	})
+}
+
+func TestStoryModel_HelpOverlay(t *testing.T) {
+	t.Parallel()
+
+	m := press(sizedStoryModel(t), "?")
+	view := m.View()
+
+	assert.Contains(t, view, "HELP")
+	assert.Contains(t, view, "next section")
+	assert.Contains(t, view, "command palette")
+
+	// Any key closes the overlay without acting on it
+	m = press(m, "s")
+	view = m.View()
+	assert.NotContains(t, view, "HELP")
+	assert.Contains(t, view, "section 1/2")
+}
+
+func TestStoryModel_HelpOverlayShowsCustomKeys(t *testing.T) {
+	t.Parallel()
+
+	km, err := bubbletea.DefaultStoryKeyMap().Override(map[string][]string{"toggle_collapse_all": {"Z"}})
+	require.NoError(t, err)
+
+	m := press(sizedStoryModel(t, bubbletea.WithStoryKeyMap(km)), "?")
+
+	assert.Contains(t, m.View(), "Z toggle LLM-collapsed")
+}
+
+func TestModel_HelpOverlay(t *testing.T) {
+	t.Parallel()
+
+	diff, _ := paletteStory()
+	var m tea.Model = bubbletea.NewModel(diff)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 200, Height: 20})
+
+	m = press(m, "?")
+	assert.Contains(t, m.View(), "next hunk")
+
+	m = press(m, "q")
+	assert.NotContains(t, m.View(), "HELP")
+}
@@ -0,0 +1,164 @@
+package bubbletea
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fwojciec/diffstory"
+	"github.com/muesli/termenv"
+)
+
+// DefaultPrintWidth is the print width when neither a width flag nor
+// $COLUMNS is set.
+const DefaultPrintWidth = 80
+
+// PrintWidth returns the output width for printing: flagWidth if set,
+// then $COLUMNS, then DefaultPrintWidth.
+func PrintWidth(flagWidth int) int {
+	if flagWidth > 0 {
+		return flagWidth
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return DefaultPrintWidth
+}
+
+// NewPrintRenderer returns a renderer for non-interactive output to w.
+// Colors are emitted even when w is not a terminal, so output survives
+// pipes into "less -R" and CI logs; plain disables all escape sequences.
+func NewPrintRenderer(w io.Writer, plain bool) *lipgloss.Renderer {
+	r := lipgloss.NewRenderer(w)
+	if plain {
+		r.SetColorProfile(termenv.Ascii)
+	} else {
+		r.SetColorProfile(termenv.TrueColor)
+	}
+	return r
+}
+
+// Compile-time interface verification.
+var _ diffview.Viewer = (*Printer)(nil)
+
+// Printer implements diffview.Viewer by printing the diff instead of
+// opening a TUI.
+type Printer struct {
+	w     io.Writer
+	width int
+	opts  []ModelOption
+}
+
+// NewPrinter creates a Printer that writes to w at the given width, with
+// the same meaning as in PrintDiff.
+func NewPrinter(w io.Writer, width int, opts ...ModelOption) *Printer {
+	return &Printer{w: w, width: width, opts: opts}
+}
+
+// View prints the diff.
+func (p *Printer) View(_ context.Context, diff *diffview.Diff) error {
+	return PrintDiff(p.w, diff, p.width, p.opts...)
+}
+
+// PrintDiff renders the whole diff to w, without a TUI. A positive width,
+// typically an explicit --width flag, lays out and clips lines to it as the
+// TUI does. Otherwise the layout width comes from PrintWidth and long lines
+// are printed whole, so pipes and CI logs don't silently lose code.
+func PrintDiff(w io.Writer, diff *diffview.Diff, width int, opts ...ModelOption) error {
+	m := NewModel(diff, opts...)
+	m.width = PrintWidth(width)
+	content, _ := m.renderContent()
+	_, err := io.WriteString(w, trimPlainPadding(m.renderer, clipLines(m.renderer, content, width)))
+	return err
+}
+
+// PrintStory renders the whole story to w, without a TUI: the intro summary
+// followed by each section's header, explanation and hunks. Collapsed hunks
+// are shown as their summary line. width is handled as in PrintDiff.
+func PrintStory(w io.Writer, diff *diffview.Diff, story *diffview.StoryClassification, width int, opts ...StoryModelOption) error {
+	m := NewStoryModel(diff, story, opts...)
+	m.width = PrintWidth(width)
+
+	var b strings.Builder
+	b.WriteString(strings.TrimPrefix(m.introBody(), "\n"))
+
+	if story == nil || len(story.Sections) == 0 {
+		content, _ := m.renderContent()
+		b.WriteString("\n")
+		b.WriteString(clipLines(m.renderer, content, width))
+	} else {
+		// Sections are printed in order by stepping through them as the TUI would
+		m.showIntro = false
+		for i, section := range story.Sections {
+			m.activeSection = i
+			b.WriteString("\n")
+			b.WriteString(m.sectionHeader(i, section))
+			b.WriteString("\n")
+			if section.Explanation != "" {
+				b.WriteString(m.newStyle().Width(m.width).Render(section.Explanation))
+				b.WriteString("\n\n")
+			}
+			content, _ := m.renderContent()
+			b.WriteString(clipLines(m.renderer, content, width))
+		}
+	}
+
+	_, err := io.WriteString(w, trimPlainPadding(m.renderer, b.String()))
+	return err
+}
+
+// sectionHeader renders a rule introducing a section: ━━ 1/3 [role] Title ━━━
+func (m StoryModel) sectionHeader(i int, section diffview.Section) string {
+	title := fmt.Sprintf("%d/%d ", i+1, len(m.story.Sections))
+	if section.Role != "" {
+		title += fmt.Sprintf("[%s] ", section.Role)
+	}
+	title += section.Title
+
+	header := "━━ " + title + " "
+	if fill := m.width - lipgloss.Width(header); fill > 0 {
+		header += strings.Repeat("━", fill)
+	} else {
+		header += "━━"
+	}
+
+	return m.newStyle().
+		Bold(true).
+		Foreground(lipgloss.Color(m.styles.HunkHeader.Foreground)).
+		Render(header)
+}
+
+// clipLines truncates each line to a positive width, as the viewport does in
+// the TUI, and leaves content unchanged otherwise.
+func clipLines(renderer *lipgloss.Renderer, content string, width int) string {
+	if width <= 0 {
+		return content
+	}
+	if renderer == nil {
+		renderer = lipgloss.DefaultRenderer()
+	}
+	trailing := strings.HasSuffix(content, "\n")
+	clipped := renderer.NewStyle().MaxWidth(width).Render(strings.TrimSuffix(content, "\n"))
+	if trailing {
+		clipped += "\n"
+	}
+	return clipped
+}
+
+// trimPlainPadding removes the trailing spaces lines are padded with to the
+// full width when renderer emits no escape sequences. Padding only matters
+// for backgrounds, and plain output is meant for piping and diffing.
+func trimPlainPadding(renderer *lipgloss.Renderer, content string) string {
+	if renderer == nil || renderer.ColorProfile() != termenv.Ascii {
+		return content
+	}
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
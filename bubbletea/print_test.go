@@ -0,0 +1,238 @@
+package bubbletea_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/bubbletea"
+	dv "github.com/fwojciec/diffstory/lipgloss"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func printDiff() *diffview.Diff {
+	return &diffview.Diff{
+		Files: []diffview.FileDiff{
+			{
+				OldPath:   "main.go",
+				NewPath:   "main.go",
+				Operation: diffview.FileModified,
+				Hunks: []diffview.Hunk{
+					{
+						OldStart: 1, OldCount: 1, NewStart: 1, NewCount: 1,
+						Lines: []diffview.Line{
+							{Type: diffview.LineDeleted, Content: "return nil", OldLineNum: 1},
+							{Type: diffview.LineAdded, Content: "return err", NewLineNum: 1},
+						},
+					},
+					{
+						OldStart: 30, OldCount: 1, NewStart: 30, NewCount: 1,
+						Lines: []diffview.Line{
+							{Type: diffview.LineAdded, Content: "// regenerated", NewLineNum: 30},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func printStory() *diffview.StoryClassification {
+	return &diffview.StoryClassification{
+		ChangeType: "bugfix",
+		Summary:    "Propagate errors",
+		Sections: []diffview.Section{
+			{
+				Role:        "fix",
+				Title:       "Return the error",
+				Explanation: "Callers were seeing nil.",
+				Hunks:       []diffview.HunkRef{{File: "main.go", HunkIndex: 0, Category: "core"}},
+			},
+			{
+				Role:  "supporting",
+				Title: "Generated",
+				Hunks: []diffview.HunkRef{
+					{File: "main.go", HunkIndex: 1, Category: "noise", CollapseText: "Regenerated code"},
+				},
+			},
+		},
+	}
+}
+
+func TestPrintStory(t *testing.T) {
+	t.Parallel()
+
+	t.Run("prints intro followed by every section", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		err := bubbletea.PrintStory(&buf, printDiff(), printStory(), 60,
+			bubbletea.WithStoryRenderer(bubbletea.NewPrintRenderer(&buf, true)),
+			bubbletea.WithStoryTheme(dv.TestTheme()),
+		)
+		require.NoError(t, err)
+		out := buf.String()
+
+		assert.True(t, strings.HasPrefix(out, "[bugfix] Propagate errors\n"))
+		assert.Contains(t, out, "━━ 1/2 [fix] Return the error ━━")
+		assert.Contains(t, out, "Callers were seeing nil.")
+		assert.Contains(t, out, "return err")
+		assert.Contains(t, out, "━━ 2/2 [supporting] Generated ━━")
+		assert.Contains(t, out, "▸ [noise] Regenerated code")
+		assert.NotContains(t, out, "// regenerated")
+		assert.NotContains(t, out, "[s] next section")
+		assert.Less(t, strings.Index(out, "Return the error ━"), strings.Index(out, "Generated ━"))
+	})
+
+	t.Run("plain output has no escape sequences", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		err := bubbletea.PrintStory(&buf, printDiff(), printStory(), 60,
+			bubbletea.WithStoryRenderer(bubbletea.NewPrintRenderer(&buf, true)),
+			bubbletea.WithStoryTheme(dv.TestTheme()),
+		)
+		require.NoError(t, err)
+
+		assert.NotContains(t, buf.String(), "\x1b[")
+	})
+
+	t.Run("plain output has no trailing whitespace", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		err := bubbletea.PrintStory(&buf, printDiff(), printStory(), 60,
+			bubbletea.WithStoryRenderer(bubbletea.NewPrintRenderer(&buf, true)),
+			bubbletea.WithStoryTheme(dv.TestTheme()),
+		)
+		require.NoError(t, err)
+
+		for _, line := range strings.Split(buf.String(), "\n") {
+			assert.Equal(t, strings.TrimRight(line, " \t"), line, "trailing whitespace: %q", line)
+		}
+	})
+
+	t.Run("colored output has escape sequences", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		err := bubbletea.PrintStory(&buf, printDiff(), printStory(), 60,
+			bubbletea.WithStoryRenderer(bubbletea.NewPrintRenderer(&buf, false)),
+			bubbletea.WithStoryTheme(dv.TestTheme()),
+		)
+		require.NoError(t, err)
+
+		assert.Contains(t, buf.String(), "\x1b[")
+	})
+
+	t.Run("prints all hunks without a story", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		err := bubbletea.PrintStory(&buf, printDiff(), nil, 60,
+			bubbletea.WithStoryRenderer(bubbletea.NewPrintRenderer(&buf, true)),
+		)
+		require.NoError(t, err)
+
+		assert.Contains(t, buf.String(), "(No classification available)")
+		assert.Contains(t, buf.String(), "// regenerated")
+	})
+}
+
+func TestPrintDiff(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := bubbletea.PrintDiff(&buf, printDiff(), 40,
+		bubbletea.WithRenderer(bubbletea.NewPrintRenderer(&buf, true)),
+		bubbletea.WithTheme(dv.TestTheme()),
+	)
+	require.NoError(t, err)
+	out := buf.String()
+
+	assert.Contains(t, out, "main.go")
+	assert.Contains(t, out, "return nil")
+	assert.Contains(t, out, "// regenerated")
+	assert.NotContains(t, out, "\x1b[")
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		assert.LessOrEqual(t, len([]rune(line)), 40, "line exceeds width: %q", line)
+		assert.Equal(t, strings.TrimRight(line, " \t"), line, "trailing whitespace: %q", line)
+	}
+}
+
+func TestPrintDiff_LongLines(t *testing.T) {
+	// Can't use t.Parallel with t.Setenv
+	t.Setenv("COLUMNS", "")
+
+	long := "x := callSomething(alpha, beta, gamma, delta, epsilon, zeta, eta, theta, iota, kappa)"
+	diff := &diffview.Diff{Files: []diffview.FileDiff{{
+		OldPath: "main.go", NewPath: "main.go", Operation: diffview.FileModified,
+		Hunks: []diffview.Hunk{{
+			OldStart: 1, OldCount: 1, NewStart: 1, NewCount: 1,
+			Lines: []diffview.Line{
+				{Type: diffview.LineDeleted, Content: long, OldLineNum: 1},
+				{Type: diffview.LineAdded, Content: strings.Replace(long, "kappa", "lambda", 1), NewLineNum: 1},
+			},
+		}},
+	}}}
+	render := func(width int) string {
+		var buf bytes.Buffer
+		err := bubbletea.PrintDiff(&buf, diff, width,
+			bubbletea.WithRenderer(bubbletea.NewPrintRenderer(&buf, true)),
+			bubbletea.WithTheme(dv.TestTheme()),
+		)
+		require.NoError(t, err)
+		return buf.String()
+	}
+
+	t.Run("without a width lines are printed whole", func(t *testing.T) {
+		out := render(0)
+
+		assert.Contains(t, out, long)
+		assert.Contains(t, out, "iota, lambda)")
+	})
+
+	t.Run("an explicit width clips lines", func(t *testing.T) {
+		out := render(60)
+
+		assert.NotContains(t, out, "kappa")
+		for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+			assert.LessOrEqual(t, len([]rune(line)), 60, "line exceeds width: %q", line)
+		}
+	})
+}
+
+func TestPrinter_View(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	printer := bubbletea.NewPrinter(&buf, 60,
+		bubbletea.WithRenderer(bubbletea.NewPrintRenderer(&buf, true)),
+	)
+
+	require.NoError(t, printer.View(context.Background(), printDiff()))
+
+	assert.Contains(t, buf.String(), "return err")
+}
+
+func TestPrintWidth(t *testing.T) {
+	// Can't use t.Parallel with t.Setenv
+
+	t.Run("flag wins over COLUMNS", func(t *testing.T) {
+		t.Setenv("COLUMNS", "120")
+		assert.Equal(t, 60, bubbletea.PrintWidth(60))
+	})
+
+	t.Run("COLUMNS", func(t *testing.T) {
+		t.Setenv("COLUMNS", "120")
+		assert.Equal(t, 120, bubbletea.PrintWidth(0))
+	})
+
+	t.Run("default", func(t *testing.T) {
+		t.Setenv("COLUMNS", "wide")
+		assert.Equal(t, bubbletea.DefaultPrintWidth, bubbletea.PrintWidth(0))
+	})
+}
@@ -14,6 +14,14 @@ type hunkKey struct {
 	hunkIndex int
 }
 
+// lineAnchor identifies a diff line that review comments attach to.
+// The zero value marks rendered rows that don't show a diff line (headers).
+type lineAnchor struct {
+	file string
+	side diffview.CommentSide
+	line int
+}
+
 // renderConfig holds all rendering parameters for renderDiff.
 type renderConfig struct {
 	diff             *diffview.Diff
@@ -29,6 +37,18 @@ type renderConfig struct {
 	hunkCategories  map[hunkKey]string // Category for each hunk (for styling)
 	collapseText    map[hunkKey]string // Summary text for collapsed hunks
 	originalIndices map[hunkKey]int    // Maps (file, filtered position) -> original hunk index
+	hunkLabels      map[hunkKey]string // Text appended to the hunk header, e.g. the owning section
+
+	// Review comments rendered below the line they are anchored to (optional)
+	comments map[lineAnchor]diffview.Comment
+
+	// Lines of moved blocks, keyed by original hunk index (optional).
+	// Values annotate the first line of each block.
+	movedLines map[movedKey]string
+
+	// Tokens of whole file versions, keyed by file path (optional).
+	// Hunks of other files are tokenized on their own.
+	fileTokens map[string]fileTokens
 }
 
 // minGutterWidth is the minimum width of each line number column in the gutter.
@@ -38,12 +58,21 @@ const minGutterWidth = 4
 // If renderer is nil, the default lipgloss renderer is used.
 // Width is the terminal width for full-width backgrounds.
 func renderDiff(cfg renderConfig) string {
+	content, _ := renderDiffRows(cfg)
+	return content
+}
+
+// renderDiffRows renders a Diff like renderDiff and also returns the line
+// anchor shown on each rendered row, so viewport offsets can be mapped back
+// to diff lines. Header rows have a zero anchor; comment rows carry the
+// anchor of the line they annotate.
+func renderDiffRows(cfg renderConfig) (string, []lineAnchor) {
 	diff := cfg.diff
 	styles := cfg.styles
 	renderer := cfg.renderer
 	width := cfg.width
 	if diff == nil {
-		return ""
+		return "", nil
 	}
 
 	// Calculate dynamic gutter width based on max line number in the diff
@@ -60,11 +89,16 @@ func renderDiff(cfg renderConfig) string {
 	deletedGutterStyle := styleFromColorPair(styles.DeletedGutter, renderer)
 	addedHighlightStyle := styleFromColorPair(styles.AddedHighlight, renderer)
 	deletedHighlightStyle := styleFromColorPair(styles.DeletedHighlight, renderer)
+	movedStyle := styleFromColorPair(styles.Moved, renderer)
 
 	// Create dimmed style for non-core categories
 	dimmedStyle := createDimmedStyle(styles, renderer)
 
+	// Review comments reuse the file header colors to stand apart from code
+	commentStyle := styleFromColorPair(styles.FileHeader, renderer)
+
 	var sb strings.Builder
+	var rows []lineAnchor
 	for _, file := range diff.Files {
 		// Skip files that shouldn't be rendered (binary files, mode-only changes)
 		if !shouldRenderFile(file) {
@@ -72,7 +106,7 @@ func renderDiff(cfg renderConfig) string {
 		}
 
 		// Detect language for syntax highlighting
-		path := filePath(file)
+		path := file.Path()
 		var language string
 		if cfg.languageDetector != nil {
 			language = cfg.languageDetector.DetectFromPath(path)
@@ -86,7 +120,7 @@ func renderDiff(cfg renderConfig) string {
 		// Build header: "── " + path + " " + fill + " " + stats + " ──"
 		prefix := "── "
 		suffix := " ──"
-		middle := prefix + path + " "
+		middle := prefix + fileHeaderText(file) + " "
 		end := " " + stats + suffix
 
 		// Calculate fill width
@@ -99,12 +133,14 @@ func renderDiff(cfg renderConfig) string {
 		header := middle + fill + end
 		sb.WriteString(fileHeaderStyle.Render(header))
 		sb.WriteString("\n")
+		rows = append(rows, lineAnchor{})
 
 		// Handle empty files (no hunks)
 		if len(file.Hunks) == 0 {
 			emptyLine := contextStyle.Render("(empty)")
 			sb.WriteString(emptyLine)
 			sb.WriteString("\n")
+			rows = append(rows, lineAnchor{})
 			continue
 		}
 
@@ -132,6 +168,7 @@ func renderDiff(cfg renderConfig) string {
 				}
 				sb.WriteString(renderCollapsedHunk(hunk, key, cfg, collapseStyle))
 				sb.WriteString("\n")
+				rows = append(rows, lineAnchor{})
 				continue
 			}
 
@@ -145,16 +182,27 @@ func renderDiff(cfg renderConfig) string {
 			currentLineNumStyle := lineNumStyle
 
 			// Render hunk header with styling
-			header := formatHunkHeader(hunk)
+			header := hunk.Header()
+			if label, ok := cfg.hunkLabels[key]; ok {
+				header += " · " + label
+			}
 			sb.WriteString(currentHunkHeaderStyle.Render(header))
 			sb.WriteString("\n")
+			rows = append(rows, lineAnchor{})
 
 			// Compute word diff segments for paired lines (delete followed by add)
-			lineSegments := computeLinePairSegments(hunk.Lines, cfg.wordDiffer)
-
-			// Pre-tokenize all lines in the hunk together for proper multi-line construct handling
-			// (e.g., /* */ comments, JSDoc). This gives each line correct context-aware tokens.
-			hunkTokens := tokenizeHunkLines(hunk.Lines, language, cfg.tokenizer)
+			lineSegments := diffview.PairSegments(hunk.Lines, cfg.wordDiffer)
+
+			// Take tokens from the whole file when available; otherwise pre-tokenize all lines
+			// in the hunk together for proper multi-line construct handling (e.g., /* */
+			// comments, JSDoc). This gives each line correct context-aware tokens.
+			var hunkTokens [][]diffview.Token
+			if ft, ok := cfg.fileTokens[path]; ok {
+				hunkTokens = hunkTokensFromFile(hunk.Lines, ft)
+			}
+			if hunkTokens == nil {
+				hunkTokens = diffview.TokenizeHunkLines(hunk.Lines, language, cfg.tokenizer)
+			}
 
 			// Render lines with gutter and prefixes
 			for i, line := range hunk.Lines {
@@ -177,21 +225,39 @@ func renderDiff(cfg renderConfig) string {
 				}
 				sb.WriteString(formatGutter(line.OldLineNum, line.NewLineNum, gutterWidth, gutterStyle))
 
+				// Moved lines keep the added/deleted gutter but get the moved
+				// background, and the first line of a block says where it moved.
+				annotation, moved := cfg.movedLines[movedKey{hunk: key, line: i}]
+				if moved {
+					lineStyle = movedStyle
+				}
+				// Lines are padded to width past the gutter and clipped by the
+				// viewport, so an annotation must fit in the visible part.
+				lineWidth := width
+				if annotation != "" {
+					annotation = "  ↳ " + annotation + " "
+					lineWidth = width - (2*gutterWidth + 3) - DisplayWidth(annotation)
+				}
+
 				// Add padding space between gutter and code prefix, styled with code line's background
 				sb.WriteString(lineStyle.Render(" "))
 
 				// Get prefix and content
-				prefix := linePrefixFor(line.Type)
+				prefix := line.Prefix()
 				lineContent := strings.TrimSuffix(line.Content, "\n")
 				fullLine := prefix + lineContent
 
-				// Check if this line has word-level diff segments
+				// Check if this line has word-level diff segments.
+				// Moved lines are unchanged, so they skip word-level highlighting.
 				segments := lineSegments[i]
+				if moved {
+					segments = nil
+				}
 
 				var styledLine string
 				if segments != nil {
 					// Render with word-level highlighting
-					styledLine = renderLineWithSegments(prefix, segments, lineStyle, highlightStyle, width)
+					styledLine = renderLineWithSegments(prefix, segments, lineStyle, highlightStyle, lineWidth)
 				} else {
 					// Use pre-computed tokens from hunk-level tokenization
 					var tokens []diffview.Token
@@ -202,33 +268,80 @@ func renderDiff(cfg renderConfig) string {
 					if tokens != nil {
 						// Render with syntax highlighting (prefix + tokens)
 						var colors diffview.ColorPair
-						switch line.Type {
-						case diffview.LineAdded:
+						switch {
+						case moved:
+							colors = styles.Moved
+						case line.Type == diffview.LineAdded:
 							colors = styles.Added
-						case diffview.LineDeleted:
+						case line.Type == diffview.LineDeleted:
 							colors = styles.Deleted
 						default:
 							colors = styles.Context
 						}
-						styledLine = renderLineWithTokens(prefix, tokens, colors, renderer, width)
+						styledLine = renderLineWithTokens(prefix, tokens, colors, renderer, lineWidth)
 					} else {
 						// Plain rendering - entire line including prefix
-						switch line.Type {
-						case diffview.LineAdded:
-							styledLine = currentAddedStyle.Render(padLine(fullLine, width))
-						case diffview.LineDeleted:
-							styledLine = currentDeletedStyle.Render(padLine(fullLine, width))
+						switch {
+						case moved:
+							styledLine = movedStyle.Render(padLine(fullLine, lineWidth))
+						case line.Type == diffview.LineAdded:
+							styledLine = currentAddedStyle.Render(padLine(fullLine, lineWidth))
+						case line.Type == diffview.LineDeleted:
+							styledLine = currentDeletedStyle.Render(padLine(fullLine, lineWidth))
 						default:
 							styledLine = currentContextStyle.Render(fullLine)
 						}
 					}
 				}
 				sb.WriteString(styledLine)
+				if annotation != "" {
+					sb.WriteString(movedStyle.Render(annotation))
+				}
 				sb.WriteString("\n")
+
+				side, num := diffview.CommentAnchor(line)
+				anchor := lineAnchor{file: path, side: side, line: num}
+				rows = append(rows, anchor)
+
+				// Review comment rows below the line they annotate
+				if comment, ok := cfg.comments[anchor]; ok {
+					for _, row := range renderComment(comment, gutterWidth, width, commentStyle) {
+						sb.WriteString(row)
+						sb.WriteString("\n")
+						rows = append(rows, anchor)
+					}
+				}
 			}
 		}
 	}
-	return sb.String()
+	return sb.String(), rows
+}
+
+// renderComment renders a review comment as rows indented past the gutter.
+// Each line of the comment body becomes one row.
+func renderComment(comment diffview.Comment, gutterWidth, width int, style lipgloss.Style) []string {
+	// Gutter is "old new " - two number columns plus two spaces
+	indent := strings.Repeat(" ", 2*gutterWidth+2)
+	bodyLines := commentLines(comment)
+	rendered := make([]string, 0, len(bodyLines))
+	for _, text := range bodyLines {
+		rendered = append(rendered, style.Render(padLine(indent+" ┃ "+ExpandTabs(text, 0), width)))
+	}
+	return rendered
+}
+
+// commentLines splits a comment body into the lines rendered below its anchor.
+func commentLines(comment diffview.Comment) []string {
+	return strings.Split(strings.TrimRight(comment.Body, "\n"), "\n")
+}
+
+// commentRowCount returns the number of rows comments add below a line.
+func commentRowCount(comments map[lineAnchor]diffview.Comment, anchor lineAnchor) int {
+	comment, ok := comments[anchor]
+	if !ok {
+		return 0
+	}
+	return len(commentLines(comment))
 }
 
 // createDimmedStyle creates a dimmed style for non-core hunks.
@@ -247,10 +360,11 @@ func createDimmedStyle(styles diffview.Styles, renderer *lipgloss.Renderer) lipg
 }
 
 // renderCollapsedHunk renders a collapsed hunk as a single summary line.
-// Format: @@ -50,8 +52,10 @@ ▸ [category] collapse text
+// Format: @@ -50,8 +52,10 @@ ▸ [category] collapse text, followed by the
+// hunk's label, if any.
 func renderCollapsedHunk(hunk diffview.Hunk, key hunkKey, cfg renderConfig, headerStyle lipgloss.Style) string {
 	// Build the hunk range portion
-	rangeStr := fmt.Sprintf("@@ -%d,%d +%d,%d @@", hunk.OldStart, hunk.OldCount, hunk.NewStart, hunk.NewCount)
+	rangeStr := hunk.Range()
 
 	// Get collapse text, defaulting to a generic message
 	collapseText := "collapsed"
@@ -273,100 +387,11 @@ func renderCollapsedHunk(hunk diffview.Hunk, key hunkKey, cfg renderConfig, head
 	} else {
 		summary = fmt.Sprintf("▸ %s", collapseText)
 	}
-
-	return headerStyle.Render(rangeStr + " " + summary)
-}
-
-// computeLinePairSegments identifies paired delete/add lines and computes word-level diff segments.
-// Returns a map from line index to segments. Lines without word-level diffs have nil segments.
-// Only applies word-level highlighting when there's meaningful shared content (>30% unchanged).
-//
-// Handles both simple pairs (one delete followed by one add) and runs of consecutive
-// deletes followed by consecutive adds (pairs them 1:1 in order).
-func computeLinePairSegments(lines []diffview.Line, wordDiffer diffview.WordDiffer) map[int][]diffview.Segment {
-	if wordDiffer == nil {
-		return nil
+	if label, ok := cfg.hunkLabels[key]; ok {
+		summary += " · " + label
 	}
 
-	result := make(map[int][]diffview.Segment)
-
-	// Find runs of consecutive deleted lines followed by runs of added lines
-	for i := 0; i < len(lines); i++ {
-		if lines[i].Type != diffview.LineDeleted {
-			continue
-		}
-
-		// Found start of a delete run - count consecutive deletes
-		deleteStart := i
-		deleteEnd := i
-		for deleteEnd < len(lines) && lines[deleteEnd].Type == diffview.LineDeleted {
-			deleteEnd++
-		}
-
-		// Check if immediately followed by added lines
-		if deleteEnd >= len(lines) || lines[deleteEnd].Type != diffview.LineAdded {
-			i = deleteEnd - 1 // Skip to end of delete run
-			continue
-		}
-
-		// Count consecutive adds
-		addStart := deleteEnd
-		addEnd := addStart
-		for addEnd < len(lines) && lines[addEnd].Type == diffview.LineAdded {
-			addEnd++
-		}
-
-		// Pair up deletes and adds 1:1
-		deleteCount := deleteEnd - deleteStart
-		addCount := addEnd - addStart
-		pairCount := deleteCount
-		if addCount < pairCount {
-			pairCount = addCount
-		}
-
-		for j := 0; j < pairCount; j++ {
-			delIdx := deleteStart + j
-			addIdx := addStart + j
-
-			oldContent := strings.TrimSuffix(lines[delIdx].Content, "\n")
-			newContent := strings.TrimSuffix(lines[addIdx].Content, "\n")
-			oldSegs, newSegs := wordDiffer.Diff(oldContent, newContent)
-
-			// Only use word-level highlighting if there's meaningful shared content.
-			if hasSignificantUnchangedContent(oldSegs) && hasSignificantUnchangedContent(newSegs) {
-				result[delIdx] = oldSegs
-				result[addIdx] = newSegs
-			}
-		}
-
-		i = addEnd - 1 // Skip to end of add run
-	}
-
-	return result
-}
-
-// hasSignificantUnchangedContent checks if segments have enough unchanged content
-// to make word-level highlighting useful (at least 30% unchanged).
-func hasSignificantUnchangedContent(segments []diffview.Segment) bool {
-	if len(segments) == 0 {
-		return false
-	}
-
-	var unchangedLen, totalLen int
-	for _, seg := range segments {
-		segLen := len(seg.Text)
-		totalLen += segLen
-		if !seg.Changed {
-			unchangedLen += segLen
-		}
-	}
-
-	if totalLen == 0 {
-		return false
-	}
-
-	// Require at least 30% unchanged content for word-level diff to be useful
-	return float64(unchangedLen)/float64(totalLen) >= 0.30
+	return headerStyle.Render(rangeStr + " " + summary)
 }
 
 // renderLineWithSegments renders a line with word-level diff highlighting.
@@ -530,27 +555,6 @@ func styleFromColorPair(cp diffview.ColorPair, renderer *lipgloss.Renderer) lipg
 	return style
 }
 
-// formatHunkHeader formats a hunk header in standard diff format.
-func formatHunkHeader(hunk diffview.Hunk) string {
-	header := fmt.Sprintf("@@ -%d,%d +%d,%d @@", hunk.OldStart, hunk.OldCount, hunk.NewStart, hunk.NewCount)
-	if hunk.Section != "" {
-		header += " " + hunk.Section
-	}
-	return header
-}
-
-// linePrefixFor returns the appropriate prefix for a line type.
-func linePrefixFor(lineType diffview.LineType) string {
-	switch lineType {
-	case diffview.LineAdded:
-		return "+"
-	case diffview.LineDeleted:
-		return "-"
-	default:
-		return " "
-	}
-}
-
 // padLine pads a line with spaces to the specified display width.
 // Uses DisplayWidth() to correctly handle tabs and multi-byte Unicode characters.
 // If the line is already wider, it is returned unchanged.
@@ -581,23 +585,18 @@ func shouldRenderFile(file diffview.FileDiff) bool {
 	if file.Operation == diffview.FileRenamed || file.Operation == diffview.FileCopied {
 		return true
 	}
-	// Skip mode-only changes without hunks (or add logic to show them later)
-	return false
+	// Render mode-only changes
+	return file.ModeChanged()
 }
 
-// filePath returns the display path for a file in the diff.
-// Uses NewPath for most operations, OldPath for deleted files.
-func filePath(file diffview.FileDiff) string {
-	var path string
-	if file.Operation == diffview.FileDeleted {
-		path = file.OldPath
-	} else {
-		path = file.NewPath
+// fileHeaderText returns the path shown in a file header, "old → new" for
+// renames and copies, followed by the file's notes in parentheses.
+func fileHeaderText(file diffview.FileDiff) string {
+	text := file.DisplayPath()
+	if notes := file.Notes(); len(notes) > 0 {
+		text += " (" + strings.Join(notes, ", ") + ")"
 	}
-	// Strip "a/" or "b/" prefix if present
-	path = strings.TrimPrefix(path, "a/")
-	path = strings.TrimPrefix(path, "b/")
-	return path
+	return text
 }
 
 // digitWidth returns the number of digits needed to display n.
@@ -615,7 +614,8 @@ func digitWidth(n int) int {
 
 // computePositions calculates the line numbers where each hunk and file starts.
 // This is independent of terminal width and can be computed eagerly.
-func computePositions(diff *diffview.Diff) (hunkPositions, filePositions []int) {
+// Rows added by review comments are included so positions match renderDiff.
+func computePositions(diff *diffview.Diff, comments map[lineAnchor]diffview.Comment) (hunkPositions, filePositions []int) {
 	if diff == nil {
 		return nil, nil
 	}
@@ -646,55 +646,17 @@ func computePositions(diff *diffview.Diff) (hunkPositions, filePositions []int)
 
 				// Content lines
 				lineNum += len(hunk.Lines)
+
+				// Comment rows below their lines
+				if len(comments) > 0 {
+					path := file.Path()
+					for _, line := range hunk.Lines {
+						side, num := diffview.CommentAnchor(line)
+						lineNum += commentRowCount(comments, lineAnchor{file: path, side: side, line: num})
+					}
+				}
 			}
 		}
 	}
 	return hunkPositions, filePositions
 }
-
-// maxHunkSizeForTokenization is the maximum total size (in bytes) of hunk content
-// that we'll attempt to tokenize. Real code with multi-line comments is small;
-// larger hunks are likely data files or minified code where syntax highlighting
-// provides little value.
-const maxHunkSizeForTokenization = 16 * 1024 // 16KB
-
-// maxLineLength is the maximum length of a single line we'll include in
-// hunk-level tokenization. Lines longer than this are likely data, not code.
-const maxLineLength = 1000
-
-// tokenizeHunkLines tokenizes all lines in a hunk together with full context,
-// returning per-line tokens. This correctly handles multi-line constructs like
-// /* */ comments and JSDoc that span multiple lines.
-// Returns nil if tokenizer is nil, language is empty, language is unsupported,
-// or hunk content exceeds size limits (likely not normal code).
-func tokenizeHunkLines(lines []diffview.Line, language string, tokenizer diffview.Tokenizer) [][]diffview.Token {
-	if tokenizer == nil || language == "" || len(lines) == 0 {
-		return nil
-	}
-
-	// Pre-check total size to avoid allocations for large hunks
-	totalSize := 0
-	for _, line := range lines {
-		lineLen := len(line.Content)
-		if lineLen > maxLineLength {
-			return nil // Long line suggests data file, not code
-		}
-		totalSize += lineLen
-		if totalSize > maxHunkSizeForTokenization {
-			return nil
-		}
-	}
-
-	// Build the full hunk content by joining all line contents
-	var sb strings.Builder
-	sb.Grow(totalSize) // Pre-allocate to avoid reallocations
-	for i, line := range lines {
-		content := strings.TrimSuffix(line.Content, "\n")
-		sb.WriteString(content)
-		if i < len(lines)-1 {
-			sb.WriteString("\n")
-		}
-	}
-
-	return tokenizer.TokenizeLines(language, sb.String())
-}
@@ -1,7 +1,10 @@
 package bubbletea
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -17,15 +20,18 @@ type StoryModel struct {
 	story *diffview.StoryClassification
 
 	// Pre-computed mappings (built on construction)
-	hunkToSection     map[hunkKey]int    // hunk → section index
-	hunkCategories    map[hunkKey]string // hunk → category for styling
-	collapseText      map[hunkKey]string // hunk → collapse text
-	collapsedHunks    map[hunkKey]bool   // tracks runtime collapse state
-	llmCollapsedHunks map[hunkKey]bool   // tracks which hunks were originally collapsed by LLM
+	hunkToSection     map[hunkKey]int       // hunk → section index
+	hunkCategories    map[hunkKey]string    // hunk → category for styling
+	collapseText      map[hunkKey]string    // hunk → collapse text
+	collapsedHunks    map[hunkKey]bool      // tracks runtime collapse state
+	llmCollapsedHunks map[hunkKey]bool      // tracks which hunks were originally collapsed by LLM
+	movedLines        map[movedKey]string   // lines of blocks moved within the diff
+	fileTokens        map[string]fileTokens // whole-file tokens for syntax highlighting
 
 	// Section filtering
-	activeSection int  // 0 = intro (if showIntro) or first code section
-	showIntro     bool // whether intro slide is enabled
+	activeSection int           // 0 = intro (if showIntro) or first code section
+	showIntro     bool          // whether intro slide is enabled
+	base          diffview.Base // branch the changes are compared against, shown on the intro
 
 	// Syntax highlighting
 	languageDetector diffview.LanguageDetector
@@ -37,6 +43,42 @@ type StoryModel struct {
 	caseSaver     diffview.EvalCaseSaver
 	caseSaverPath string
 
+	// Review comments
+	comments commentEditor
+	rows     []lineAnchor // diff line shown on each rendered row
+
+	// Export from the command palette
+	exporter   diffview.StoryExporter
+	exportPath string
+
+	// Hunk actions: the applier and the diff reloader, the last action
+	// applied to each hunk, a discard waiting for confirmation, whether an
+	// action is running and the result of the last action
+	applier        diffview.HunkApplier
+	reload         func(context.Context) (*diffview.Diff, error)
+	appliedActions map[hunkKey]diffview.HunkAction
+	pendingAction  *pendingHunkAction
+	applying       bool
+	actionStatus   string
+
+	// Overlays
+	showHelp bool
+	commands commandPalette
+
+	// Whitespace mode: diff is source, or source without whitespace changes
+	source           *diffview.Diff
+	ignoreWhitespace bool
+
+	// Context expansion: file lines to read context from, and the extra
+	// context shown around each hunk
+	fileLines  map[string]diffview.FileLines
+	expansions map[hunkKey]diffview.ContextExpansion
+
+	// Full-file mode: the file shown in full with the hunks of every
+	// section, and the viewport offset to return to when leaving it
+	fullFile       string
+	fullFileOffset int
+
 	// UI state
 	viewport   viewport.Model
 	keymap     StoryKeyMap
@@ -58,9 +100,20 @@ type storyModelConfig struct {
 	tokenizer        diffview.Tokenizer
 	wordDiffer       diffview.WordDiffer
 	showIntro        bool
+	base             diffview.Base
 	input            *diffview.ClassificationInput
 	caseSaver        diffview.EvalCaseSaver
 	caseSaverPath    string
+	commentStore     diffview.CommentStore
+	commentPath      string
+	comments         []diffview.Comment
+	keymap           *StoryKeyMap
+	exporter         diffview.StoryExporter
+	exportPath       string
+	ignoreWhitespace bool
+	fileVersions     map[string]diffview.FileVersions
+	applier          diffview.HunkApplier
+	reload           func(context.Context) (*diffview.Diff, error)
 }
 
 // WithStoryRenderer sets a custom lipgloss renderer for the model.
@@ -91,6 +144,15 @@ func WithStoryTokenizer(t diffview.Tokenizer) StoryModelOption {
 	}
 }
 
+// WithStoryFileVersions sets the full old and new content of the diff's
+// files, keyed by path. Those files are tokenized as a whole, so hunks that
+// start inside a multi-line comment or string are highlighted correctly.
+func WithStoryFileVersions(versions map[string]diffview.FileVersions) StoryModelOption {
+	return func(cfg *storyModelConfig) {
+		cfg.fileVersions = versions
+	}
+}
+
 // WithStoryWordDiffer sets the word differ for word-level highlighting.
 func WithStoryWordDiffer(d diffview.WordDiffer) StoryModelOption {
 	return func(cfg *storyModelConfig) {
@@ -106,6 +168,14 @@ func WithIntroSlide() StoryModelOption {
 	}
 }
 
+// WithStoryBase sets the branch the changes are compared against, shown on
+// the intro slide with the reason it was picked.
+func WithStoryBase(base diffview.Base) StoryModelOption {
+	return func(cfg *storyModelConfig) {
+		cfg.base = base
+	}
+}
+
 // WithStoryInput sets the classification input for constructing EvalCase when saving.
 func WithStoryInput(input diffview.ClassificationInput) StoryModelOption {
 	return func(cfg *storyModelConfig) {
@@ -121,6 +191,55 @@ func WithStoryCaseSaver(s diffview.EvalCaseSaver, path string) StoryModelOption
 	}
 }
 
+// WithStoryCommentStore sets the store and sidecar path for persisting review comments.
+func WithStoryCommentStore(store diffview.CommentStore, path string) StoryModelOption {
+	return func(cfg *storyModelConfig) {
+		cfg.commentStore = store
+		cfg.commentPath = path
+	}
+}
+
+// WithStoryExporter adds an "export" command to the command palette that
+// writes the story to path.
+func WithStoryExporter(e diffview.StoryExporter, path string) StoryModelOption {
+	return func(cfg *storyModelConfig) {
+		cfg.exporter = e
+		cfg.exportPath = path
+	}
+}
+
+// WithStoryKeyMap replaces the default key bindings.
+func WithStoryKeyMap(km StoryKeyMap) StoryModelOption {
+	return func(cfg *storyModelConfig) {
+		cfg.keymap = &km
+	}
+}
+
+// WithStoryComments loads previously written review comments.
+func WithStoryComments(comments []diffview.Comment) StoryModelOption {
+	return func(cfg *storyModelConfig) {
+		cfg.comments = comments
+	}
+}
+
+// WithStoryIgnoreWhitespace starts the model with whitespace-only changes hidden.
+func WithStoryIgnoreWhitespace(ignore bool) StoryModelOption {
+	return func(cfg *storyModelConfig) {
+		cfg.ignoreWhitespace = ignore
+	}
+}
+
+// WithStoryHunkApplier enables staging, unstaging and discarding hunks with
+// a. The diff must be of uncommitted changes of the applier's repository.
+// reload returns the diff as it is after an action, so later actions apply
+// to current line numbers; it may be nil if the diff never changes.
+func WithStoryHunkApplier(a diffview.HunkApplier, reload func(context.Context) (*diffview.Diff, error)) StoryModelOption {
+	return func(cfg *storyModelConfig) {
+		cfg.applier = a
+		cfg.reload = reload
+	}
+}
+
 // NewStoryModel creates a new StoryModel with the given diff and classification.
 func NewStoryModel(diff *diffview.Diff, story *diffview.StoryClassification, opts ...StoryModelOption) StoryModel {
 	cfg := &storyModelConfig{}
@@ -163,8 +282,18 @@ func NewStoryModel(diff *diffview.Diff, story *diffview.StoryClassification, opt
 		}
 	}
 
+	keymap := DefaultStoryKeyMap()
+	if cfg.keymap != nil {
+		keymap = *cfg.keymap
+	}
+
+	source := diff
+	diff = displayedDiff(source, cfg.ignoreWhitespace)
+
 	return StoryModel{
 		diff:              diff,
+		source:            source,
+		ignoreWhitespace:  cfg.ignoreWhitespace,
 		story:             story,
 		hunkToSection:     hunkToSection,
 		hunkCategories:    hunkCategories,
@@ -172,13 +301,24 @@ func NewStoryModel(diff *diffview.Diff, story *diffview.StoryClassification, opt
 		collapsedHunks:    collapsedHunks,
 		llmCollapsedHunks: llmCollapsedHunks,
 		showIntro:         cfg.showIntro,
+		base:              cfg.base,
 		languageDetector:  cfg.languageDetector,
 		tokenizer:         cfg.tokenizer,
 		wordDiffer:        cfg.wordDiffer,
 		input:             cfg.input,
 		caseSaver:         cfg.caseSaver,
 		caseSaverPath:     cfg.caseSaverPath,
-		keymap:            DefaultStoryKeyMap(),
+		comments:          newCommentEditor(cfg.commentStore, cfg.commentPath, cfg.comments),
+		exporter:          cfg.exporter,
+		exportPath:        cfg.exportPath,
+		movedLines:        detectMovedLines(diff),
+		fileTokens:        tokenizeFileVersions(cfg.fileVersions, cfg.languageDetector, cfg.tokenizer),
+		fileLines:         contextLines(cfg.fileVersions),
+		expansions:        make(map[hunkKey]diffview.ContextExpansion),
+		applier:           cfg.applier,
+		reload:            cfg.reload,
+		appliedActions:    make(map[hunkKey]diffview.HunkAction),
+		keymap:            keymap,
 		styles:            styles,
 		palette:           palette,
 		renderer:          cfg.renderer,
@@ -194,6 +334,22 @@ func (m StoryModel) Init() tea.Cmd {
 func (m StoryModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.comments.editing {
+			return m.handleCommentKeys(msg)
+		}
+		if m.commands.open {
+			return m.handlePaletteKeys(msg)
+		}
+		if m.showHelp {
+			// Any key closes the help overlay
+			m.showHelp = false
+			return m, nil
+		}
+		m.actionStatus = ""
+		if m.pendingAction != nil {
+			return m, m.confirmHunkAction(key.Matches(msg, m.keymap.ConfirmDiscard))
+		}
+
 		// Handle multi-key sequences (gg for go to top)
 		if m.pendingKey == "g" && key.Matches(msg, m.keymap.GotoTop) {
 			m.viewport.GotoTop()
@@ -240,7 +396,41 @@ func (m StoryModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, m.keymap.SaveCase):
 			m.saveCurrentCase()
 			return m, nil
+		case key.Matches(msg, m.keymap.Comment):
+			return m, m.beginComment()
+		case key.Matches(msg, m.keymap.ToggleWhitespace):
+			m.toggleWhitespace()
+			return m, nil
+		case key.Matches(msg, m.keymap.ExpandAbove):
+			m.expandContext(true)
+			return m, nil
+		case key.Matches(msg, m.keymap.ExpandBelow):
+			m.expandContext(false)
+			return m, nil
+		case key.Matches(msg, m.keymap.ToggleFullFile):
+			m.toggleFullFile()
+			return m, nil
+		case key.Matches(msg, m.keymap.StageHunk):
+			return m, m.requestHunkAction(diffview.HunkStage, false)
+		case key.Matches(msg, m.keymap.StageSection):
+			return m, m.requestHunkAction(diffview.HunkStage, true)
+		case key.Matches(msg, m.keymap.UnstageHunk):
+			return m, m.requestHunkAction(diffview.HunkUnstage, false)
+		case key.Matches(msg, m.keymap.UnstageSection):
+			return m, m.requestHunkAction(diffview.HunkUnstage, true)
+		case key.Matches(msg, m.keymap.DiscardHunk):
+			return m, m.requestHunkAction(diffview.HunkDiscard, false)
+		case key.Matches(msg, m.keymap.DiscardSection):
+			return m, m.requestHunkAction(diffview.HunkDiscard, true)
+		case key.Matches(msg, m.keymap.Help):
+			m.showHelp = true
+			return m, nil
+		case key.Matches(msg, m.keymap.CommandPalette):
+			return m, m.commands.begin(m.paletteCommands(), m.width)
 		}
+	case hunksAppliedMsg:
+		m.handleHunksApplied(msg)
+		return m, nil
 	case tea.WindowSizeMsg:
 		statusBarHeight := 1
 		widthChanged := m.width != msg.Width
@@ -248,12 +438,12 @@ func (m StoryModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		if !m.ready {
 			m.viewport = viewport.New(msg.Width, msg.Height-statusBarHeight)
-			m.viewport.SetContent(m.renderContent())
+			m.refreshContent()
 			m.ready = true
 		} else if widthChanged {
 			m.viewport.Width = msg.Width
 			m.viewport.Height = msg.Height - statusBarHeight
-			m.viewport.SetContent(m.renderContent())
+			m.refreshContent()
 		} else {
 			m.viewport.Height = msg.Height - statusBarHeight
 		}
@@ -269,9 +459,186 @@ func (m StoryModel) View() string {
 	if !m.ready {
 		return "Loading..."
 	}
+	if m.comments.editing {
+		return m.comments.view(m.renderer, m.keymap.SaveComment)
+	}
+	if m.commands.open {
+		return m.commands.view(m.renderer, m.width, m.viewport.Height+1)
+	}
+	if m.showHelp {
+		return helpView(m.renderer, m.keymap, m.width)
+	}
 	return lipgloss.JoinVertical(lipgloss.Left, m.viewport.View(), m.statusBarView())
 }
 
+// handlePaletteKeys routes keys to the command palette while it is open.
+func (m StoryModel) handlePaletteKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keymap.PaletteClose):
+		m.commands.close()
+		return m, nil
+	case key.Matches(msg, m.keymap.PaletteRun):
+		cmd, ok := m.commands.current()
+		m.commands.close()
+		if ok {
+			return m, m.runCommand(cmd)
+		}
+		return m, nil
+	case key.Matches(msg, m.keymap.PaletteNext):
+		m.commands.move(1)
+		return m, nil
+	case key.Matches(msg, m.keymap.PalettePrev):
+		m.commands.move(-1)
+		return m, nil
+	}
+	return m, m.commands.update(msg)
+}
+
+// paletteCommands lists the commands available in the command palette.
+func (m StoryModel) paletteCommands() []paletteCommand {
+	var cmds []paletteCommand
+	offset := 0
+	if m.showIntro {
+		cmds = append(cmds, paletteCommand{title: "Go to intro", action: actionGotoSection})
+		offset = 1
+	}
+	if m.story != nil {
+		for i, section := range m.story.Sections {
+			cmds = append(cmds, paletteCommand{
+				title:   fmt.Sprintf("Go to section %d: %s", i+1, section.Title),
+				action:  actionGotoSection,
+				section: i + offset,
+			})
+		}
+	}
+	if m.diff != nil {
+		for _, file := range m.diff.Files {
+			if !shouldRenderFile(file) {
+				continue
+			}
+			path := file.Path()
+			cmds = append(cmds, paletteCommand{title: "Go to file: " + path, action: actionGotoFile, file: path})
+		}
+	}
+	cmds = append(cmds,
+		paletteCommand{title: "Toggle collapsed hunks", action: actionToggleCollapse},
+		paletteCommand{title: "Toggle ignore whitespace", action: actionToggleWhitespace},
+	)
+	if len(m.fileLines) > 0 {
+		cmds = append(cmds,
+			paletteCommand{title: "Show more context above", action: actionExpandAbove},
+			paletteCommand{title: "Show more context below", action: actionExpandBelow},
+			paletteCommand{title: "Toggle full file view", action: actionToggleFullFile},
+		)
+	}
+	if m.caseSaver != nil && m.input != nil {
+		cmds = append(cmds, paletteCommand{title: "Save case to eval dataset", action: actionSaveCase})
+	}
+	if m.exporter != nil {
+		cmds = append(cmds, paletteCommand{title: "Export story to " + filepath.Base(m.exportPath), action: actionExport})
+	}
+	if m.applier != nil {
+		cmds = append(cmds,
+			paletteCommand{title: "Stage hunk", action: actionApplyHunks, hunkAction: diffview.HunkStage},
+			paletteCommand{title: "Stage section", action: actionApplyHunks, hunkAction: diffview.HunkStage, wholeSection: true},
+			paletteCommand{title: "Unstage hunk", action: actionApplyHunks, hunkAction: diffview.HunkUnstage},
+			paletteCommand{title: "Unstage section", action: actionApplyHunks, hunkAction: diffview.HunkUnstage, wholeSection: true},
+			paletteCommand{title: "Discard hunk", action: actionApplyHunks, hunkAction: diffview.HunkDiscard},
+			paletteCommand{title: "Discard section", action: actionApplyHunks, hunkAction: diffview.HunkDiscard, wholeSection: true},
+		)
+	}
+	return cmds
+}
+
+// runCommand performs a command chosen in the palette, returning the
+// command of actions that run in the background.
+func (m *StoryModel) runCommand(c paletteCommand) tea.Cmd {
+	switch c.action {
+	case actionGotoSection:
+		m.gotoSection(c.section)
+	case actionGotoFile:
+		m.gotoFile(c.file)
+	case actionToggleCollapse:
+		m.toggleAllCollapse()
+	case actionToggleWhitespace:
+		m.toggleWhitespace()
+	case actionExpandAbove:
+		m.expandContext(true)
+	case actionExpandBelow:
+		m.expandContext(false)
+	case actionToggleFullFile:
+		m.toggleFullFile()
+	case actionSaveCase:
+		m.saveCurrentCase()
+	case actionExport:
+		m.exportStory()
+	case actionApplyHunks:
+		return m.requestHunkAction(c.hunkAction, c.wholeSection)
+	}
+	return nil
+}
+
+// handleCommentKeys routes keys to the comment editor while a comment is being written.
+func (m StoryModel) handleCommentKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key.Matches(msg, m.keymap.SaveComment) {
+		m.comments.commit()
+		m.refreshContent()
+		return m, nil
+	}
+	return m, m.comments.update(msg)
+}
+
+// beginComment opens the comment editor for the focused line.
+func (m *StoryModel) beginComment() tea.Cmd {
+	if m.diff == nil || m.onIntro() {
+		return nil
+	}
+	anchor, ok := m.focusedLine()
+	if !ok {
+		return nil
+	}
+	_, line, ok := m.diff.FindLine(anchor.file, anchor.side, anchor.line)
+	if !ok {
+		return nil
+	}
+	return m.comments.begin(anchor, line, m.width, m.viewport.Height+1)
+}
+
+// focusedLine returns the line comments attach to: the first line of the
+// focused hunk at or below the top of the viewport, or its last line above
+// the top once the hunk has scrolled past. Comments, context expansion and
+// hunk actions thus act on the same hunk.
+func (m StoryModel) focusedLine() (lineAnchor, bool) {
+	ref, ok := m.focusedHunk()
+	if !ok {
+		return lineAnchor{}, false
+	}
+	inHunk := func(a lineAnchor) bool {
+		if a == (lineAnchor{}) || a.file != ref.File {
+			return false
+		}
+		hunkIndex, _, ok := m.diff.FindLine(a.file, a.side, a.line)
+		return ok && hunkIndex == ref.HunkIndex
+	}
+	top := min(max(m.viewport.YOffset, 0), len(m.rows))
+	for _, a := range m.rows[top:] {
+		if inHunk(a) {
+			return a, true
+		}
+	}
+	for i := top - 1; i >= 0; i-- {
+		if inHunk(m.rows[i]) {
+			return m.rows[i], true
+		}
+	}
+	return lineAnchor{}, false
+}
+
+// Comments returns the review comments ordered by file, side and line.
+func (m StoryModel) Comments() []diffview.Comment {
+	return m.comments.list()
+}
+
 // onIntro returns true if the viewer is on the intro slide.
 func (m StoryModel) onIntro() bool {
 	return m.showIntro && m.activeSection == 0
@@ -298,30 +665,54 @@ func (m StoryModel) totalSections() int {
 	return total
 }
 
+// refreshContent re-renders the current section into the viewport and
+// records which diff line each rendered row shows.
+func (m *StoryModel) refreshContent() {
+	content, rows := m.renderContent()
+	m.viewport.SetContent(content)
+	m.rows = rows
+}
+
 // renderContent renders the diff content with story-aware configuration.
-func (m StoryModel) renderContent() string {
+// The intro slide has no diff lines, so it returns no rows.
+func (m StoryModel) renderContent() (string, []lineAnchor) {
 	if m.onIntro() {
-		return m.renderIntro()
+		return m.renderIntro(), nil
 	}
 	diff, originalIndices := m.filteredDiffWithIndices()
-	return renderDiff(renderConfig{
+	return renderDiffRows(renderConfig{
 		diff:             diff,
 		styles:           m.styles,
 		renderer:         m.renderer,
 		width:            m.width,
 		languageDetector: m.languageDetector,
 		tokenizer:        m.tokenizer,
-		wordDiffer:       m.wordDiffer,
-		collapsedHunks:   m.collapsedHunks,
+		wordDiffer:       displayedWordDiffer(m.wordDiffer, m.ignoreWhitespace),
+		collapsedHunks:   m.displayedCollapsedHunks(),
 		hunkCategories:   m.hunkCategories,
 		collapseText:     m.collapseText,
 		originalIndices:  originalIndices,
+		hunkLabels:       m.hunkLabels(),
+		comments:         m.comments.comments,
+		movedLines:       m.movedLines,
+		fileTokens:       m.fileTokens,
 	})
 }
 
 // renderIntro renders the intro slide content.
 func (m StoryModel) renderIntro() string {
 	var b strings.Builder
+	b.WriteString(m.introBody())
+
+	// Navigation hint
+	fmt.Fprintf(&b, "\n\n[%s] next section\n", keyLabel(m.keymap.NextSection))
+
+	return b.String()
+}
+
+// introBody renders the intro summary, narrative diagram and section list.
+func (m StoryModel) introBody() string {
+	var b strings.Builder
 
 	hasSummary := m.story != nil && m.story.Summary != ""
 	hasSections := m.story != nil && len(m.story.Sections) > 0
@@ -336,6 +727,11 @@ func (m StoryModel) renderIntro() string {
 		b.WriteString("\n")
 	}
 
+	// Base branch and why it was picked
+	if m.base.Branch != "" {
+		fmt.Fprintf(&b, "\nBase: %s\n", m.base)
+	}
+
 	// Narrative diagram
 	if m.story != nil && m.story.Narrative != "" && hasSections {
 		if diagram := NarrativeDiagram(m.story.Narrative, m.story.Sections, m.renderer); diagram != "" {
@@ -365,14 +761,12 @@ func (m StoryModel) renderIntro() string {
 		b.WriteString("\n(No classification available)\n")
 	}
 
-	// Navigation hint
-	b.WriteString("\n\n[s] next section\n")
-
 	return b.String()
 }
 
 // filteredDiffWithIndices returns a diff containing only hunks from the active section,
 // along with a mapping from (file, filtered position) to original hunk index.
+// A file shown in full keeps the hunks of every section.
 // If there are no sections or the active section is invalid, returns the full diff with nil indices.
 func (m StoryModel) filteredDiffWithIndices() (*diffview.Diff, map[hunkKey]int) {
 	if m.diff == nil || m.story == nil || len(m.story.Sections) == 0 {
@@ -395,10 +789,10 @@ func (m StoryModel) filteredDiffWithIndices() (*diffview.Diff, map[hunkKey]int)
 	originalIndices := make(map[hunkKey]int)
 	var filteredFiles []diffview.FileDiff
 	for _, file := range m.diff.Files {
-		path := filePath(file)
+		path := file.Path()
 		var filteredHunks []diffview.Hunk
 		for hunkIdx, hunk := range file.Hunks {
-			if activeHunks[hunkKey{file: path, hunkIndex: hunkIdx}] {
+			if activeHunks[hunkKey{file: path, hunkIndex: hunkIdx}] || path == m.fullFile {
 				// Map filtered position -> original index
 				filteredPos := len(filteredHunks)
 				originalIndices[hunkKey{file: path, hunkIndex: filteredPos}] = hunkIdx
@@ -426,7 +820,7 @@ func (m StoryModel) filteredDiff() *diffview.Diff {
 // computePositions calculates line positions for the current section's filtered diff.
 // Returns hunk positions (in display order) and HunkRefs (for looking up original indices).
 func (m StoryModel) computePositions() (hunkPositions []int, hunkRefs []diffview.HunkRef, filePositions []int) {
-	filtered := m.filteredDiff()
+	filtered, originalIndices := m.filteredDiffWithIndices()
 	if filtered == nil {
 		return nil, nil, nil
 	}
@@ -440,6 +834,7 @@ func (m StoryModel) computePositions() (hunkPositions []int, hunkRefs []diffview
 			refMap[hunkKey{file: ref.File, hunkIndex: ref.HunkIndex}] = ref
 		}
 	}
+	collapsed := m.displayedCollapsedHunks()
 
 	lineNum := 0
 	for _, file := range filtered.Files {
@@ -447,54 +842,80 @@ func (m StoryModel) computePositions() (hunkPositions []int, hunkRefs []diffview
 			continue
 		}
 
-		path := filePath(file)
+		path := file.Path()
 		filePositions = append(filePositions, lineNum)
 		lineNum++ // file header
 
 		if len(file.Hunks) == 0 {
 			lineNum++ // "(empty)" line
-		} else {
-			// Track which original hunk index we're at for this file
-			// filteredDiff() preserves order, so we scan through original indices
-			origIdx := 0
-			for _, hunk := range file.Hunks {
-				hunkPositions = append(hunkPositions, lineNum)
-
-				// When no sections exist, refMap is empty - create synthetic ref
-				if len(refMap) == 0 {
-					hunkRefs = append(hunkRefs, diffview.HunkRef{
-						File:      path,
-						HunkIndex: origIdx,
-					})
-					origIdx++
-				} else {
-					// Find original index by scanning until we hit one in the section
-					for {
-						key := hunkKey{file: path, hunkIndex: origIdx}
-						if ref, ok := refMap[key]; ok {
-							hunkRefs = append(hunkRefs, ref)
-							origIdx++
-							break
-						}
-						origIdx++
-					}
-				}
+			continue
+		}
+		for pos, hunk := range file.Hunks {
+			hunkPositions = append(hunkPositions, lineNum)
+
+			// Hunks outside the section (no sections, or a file shown in
+			// full) get a synthetic ref
+			origIdx := pos
+			if i, ok := originalIndices[hunkKey{file: path, hunkIndex: pos}]; ok {
+				origIdx = i
+			}
+			key := hunkKey{file: path, hunkIndex: origIdx}
+			ref, ok := refMap[key]
+			if !ok {
+				ref = diffview.HunkRef{File: path, HunkIndex: origIdx}
+			}
+			hunkRefs = append(hunkRefs, ref)
 
-				// Use the just-appended ref for collapse check
-				lastRef := hunkRefs[len(hunkRefs)-1]
-				key := hunkKey{file: lastRef.File, hunkIndex: lastRef.HunkIndex}
-				if m.collapsedHunks[key] {
-					lineNum++ // collapsed: single line
-				} else {
-					lineNum++                  // header
-					lineNum += len(hunk.Lines) // content
-				}
+			if collapsed[key] {
+				lineNum++ // collapsed: single line
+				continue
+			}
+			lineNum++                  // header
+			lineNum += len(hunk.Lines) // content
+			for _, line := range hunk.Lines {
+				side, num := diffview.CommentAnchor(line)
+				lineNum += commentRowCount(m.comments.comments, lineAnchor{file: path, side: side, line: num})
 			}
 		}
 	}
 	return hunkPositions, hunkRefs, filePositions
 }
 
+// displayedCollapsedHunks returns the collapse state used for rendering:
+// hunks of a file shown in full are never collapsed.
+func (m StoryModel) displayedCollapsedHunks() map[hunkKey]bool {
+	if m.fullFile == "" {
+		return m.collapsedHunks
+	}
+	collapsed := make(map[hunkKey]bool, len(m.collapsedHunks))
+	for key, c := range m.collapsedHunks {
+		if key.file != m.fullFile {
+			collapsed[key] = c
+		}
+	}
+	return collapsed
+}
+
+// sectionLabels names the section owning each hunk of a file shown in full,
+// so changes from other sections can be told apart. Returns nil otherwise.
+func (m StoryModel) sectionLabels() map[hunkKey]string {
+	if m.fullFile == "" || m.story == nil {
+		return nil
+	}
+	labels := make(map[hunkKey]string)
+	for key, idx := range m.hunkToSection {
+		if key.file != m.fullFile || idx < 0 || idx >= len(m.story.Sections) {
+			continue
+		}
+		label := fmt.Sprintf("section %d: %s", idx+1, m.story.Sections[idx].Title)
+		if idx == m.codeSectionIndex() {
+			label += " (current)"
+		}
+		labels[key] = label
+	}
+	return labels
+}
+
 // gotoNextSection switches to the next section.
 func (m *StoryModel) gotoNextSection() {
 	total := m.totalSections()
@@ -503,12 +924,57 @@ func (m *StoryModel) gotoNextSection() {
 	}
 	// Move to next section if possible
 	if m.activeSection < total-1 {
+		m.clearFullFile()
 		m.activeSection++
-		m.viewport.SetContent(m.renderContent())
+		m.refreshContent()
 		m.viewport.GotoTop()
 	}
 }
 
+// gotoSection switches to the navigable section at idx (0 is the intro, if shown).
+func (m *StoryModel) gotoSection(idx int) {
+	if idx < 0 || idx >= m.totalSections() {
+		return
+	}
+	m.clearFullFile()
+	m.activeSection = idx
+	m.refreshContent()
+	m.viewport.GotoTop()
+}
+
+// gotoFile switches to the first section covering path and scrolls to the file.
+// Without sections, the whole diff is shown and only the scroll position changes.
+func (m *StoryModel) gotoFile(path string) {
+	if m.story != nil {
+		for i, section := range m.story.Sections {
+			if slices.ContainsFunc(section.Hunks, func(ref diffview.HunkRef) bool { return ref.File == path }) {
+				if m.showIntro {
+					i++
+				}
+				m.gotoSection(i)
+				break
+			}
+		}
+	}
+
+	filtered := m.filteredDiff()
+	if filtered == nil {
+		return
+	}
+	_, _, filePositions := m.computePositions()
+	idx := 0
+	for _, file := range filtered.Files {
+		if !shouldRenderFile(file) {
+			continue
+		}
+		if file.Path() == path && idx < len(filePositions) {
+			m.viewport.SetYOffset(filePositions[idx])
+			return
+		}
+		idx++
+	}
+}
+
 // toggleAllCollapse toggles only LLM-collapsed hunks in the current section.
 // Hunks that were never collapsed by the LLM are not affected.
 func (m *StoryModel) toggleAllCollapse() {
@@ -554,7 +1020,77 @@ func (m *StoryModel) toggleAllCollapse() {
 	}
 
 	// Re-render content
-	m.viewport.SetContent(m.renderContent())
+	m.refreshContent()
+}
+
+// toggleWhitespace switches between the full diff and the diff without
+// whitespace-only changes. Hunks keep their indices, so sections, collapse
+// state and comments carry over.
+func (m *StoryModel) toggleWhitespace() {
+	m.ignoreWhitespace = !m.ignoreWhitespace
+	m.refreshDiff()
+}
+
+// expandContext shows more context above or below the focused hunk, the one
+// comments and hunk actions apply to, when the file's content is known.
+func (m *StoryModel) expandContext(above bool) {
+	if m.diff == nil || m.onIntro() {
+		return
+	}
+	anchor, ok := m.focusedLine()
+	if ok && expandHunkAt(m.expansions, m.diff, m.fileLines, anchor, above) {
+		m.refreshDiff()
+	}
+}
+
+// toggleFullFile shows the file at the top of the viewport in full, with
+// the hunks of every section labelled by their owner, or returns to the
+// section's hunk view at the offset it was left from.
+func (m *StoryModel) toggleFullFile() {
+	if m.fullFile != "" {
+		m.fullFile = ""
+		m.refreshDiff()
+		m.viewport.SetYOffset(m.fullFileOffset)
+		return
+	}
+	if m.onIntro() {
+		return
+	}
+	anchor, ok := anchorAtRow(m.rows, m.viewport.YOffset)
+	if !ok {
+		return
+	}
+	if _, ok := fullFileDiff(m.source, m.fileLines, anchor.file); !ok {
+		return
+	}
+	m.fullFileOffset = m.viewport.YOffset
+	m.fullFile = anchor.file
+	m.refreshDiff()
+	m.viewport.SetYOffset(rowOfAnchor(m.rows, anchor))
+}
+
+// clearFullFile leaves full-file mode without restoring the offset, for
+// navigation that moves away from the current view.
+func (m *StoryModel) clearFullFile() {
+	if m.fullFile == "" {
+		return
+	}
+	m.fullFile = ""
+	m.refreshDiff()
+}
+
+// refreshDiff rebuilds the displayed diff from the source after the
+// whitespace mode, context expansion or full-file mode changed.
+func (m *StoryModel) refreshDiff() {
+	diff := expandedDiff(m.source, m.fileLines, m.expansions)
+	if m.fullFile != "" {
+		diff, _ = fullFileDiff(diff, m.fileLines, m.fullFile)
+	}
+	m.diff = displayedDiff(diff, m.ignoreWhitespace)
+	m.movedLines = detectMovedLines(m.diff)
+	if m.ready {
+		m.refreshContent()
+	}
 }
 
 // gotoPrevSection switches to the previous section.
@@ -565,8 +1101,9 @@ func (m *StoryModel) gotoPrevSection() {
 	}
 	// Move to previous section if possible
 	if m.activeSection > 0 {
+		m.clearFullFile()
 		m.activeSection--
-		m.viewport.SetContent(m.renderContent())
+		m.refreshContent()
 		m.viewport.GotoTop()
 	}
 }
@@ -584,6 +1121,15 @@ func (m *StoryModel) saveCurrentCase() {
 	_ = m.caseSaver.Save(m.caseSaverPath, evalCase)
 }
 
+// exportStory writes the story with the configured exporter.
+func (m *StoryModel) exportStory() {
+	if m.exporter == nil || m.exportPath == "" {
+		return
+	}
+	// Best-effort export - errors are silently ignored in UI, as for saved cases
+	_ = m.exporter.Export(m.exportPath, m.source, m.story)
+}
+
 // newStyle creates a new lipgloss style using the model's renderer.
 func (m StoryModel) newStyle() lipgloss.Style {
 	if m.renderer != nil {
@@ -592,6 +1138,15 @@ func (m StoryModel) newStyle() lipgloss.Style {
 	return lipgloss.NewStyle()
 }
 
+// keyHints returns the status bar summary of the main key bindings.
+func (m StoryModel) keyHints() string {
+	km := m.keymap
+	return fmt.Sprintf("%s:scroll  %s:section  %s:toggle noise  %s:comment  %s:save  %s:commands  %s:help  %s:quit",
+		keyPair(km.Down, km.Up), keyPair(km.NextSection, km.PrevSection),
+		keyName(km.ToggleCollapseAll), keyName(km.Comment), keyName(km.SaveCase),
+		keyName(km.CommandPalette), keyName(km.Help), keyName(km.Quit))
+}
+
 // statusBarView renders the status bar with position info.
 func (m StoryModel) statusBarView() string {
 	barStyle := m.newStyle().
@@ -631,8 +1186,21 @@ func (m StoryModel) statusBarView() string {
 		content += barStyle.Render(sectionPos) + sep
 	}
 
+	if m.ignoreWhitespace {
+		content += barStyle.Render(whitespaceIndicator) + sep
+	}
+	if m.fullFile != "" {
+		content += barStyle.Render(fullFileIndicator) + sep
+	}
+	hints := m.keyHints()
+	switch {
+	case m.pendingAction != nil:
+		hints = m.pendingAction.prompt(keyName(m.keymap.ConfirmDiscard))
+	case m.actionStatus != "":
+		hints = m.actionStatus
+	}
 	content += barStyle.Render(scrollPos) + sep +
-		dimStyle.Render("j/k:scroll  s/S:section  z:toggle noise  e:save  q:quit") +
+		dimStyle.Render(hints) +
 		barStyle.Render("  ")
 
 	// Right-align by padding left side with background
@@ -28,6 +28,35 @@ type StoryKeyMap struct {
 
 	// Export
 	SaveCase key.Binding
+
+	// Display
+	ToggleWhitespace key.Binding
+	ExpandAbove      key.Binding
+	ExpandBelow      key.Binding
+	ToggleFullFile   key.Binding
+
+	// Review comments
+	Comment     key.Binding
+	SaveComment key.Binding
+
+	// Hunk actions (with a hunk applier, for uncommitted changes)
+	StageHunk      key.Binding
+	StageSection   key.Binding
+	UnstageHunk    key.Binding
+	UnstageSection key.Binding
+	DiscardHunk    key.Binding
+	DiscardSection key.Binding
+	ConfirmDiscard key.Binding
+
+	// Discoverability
+	Help           key.Binding
+	CommandPalette key.Binding
+
+	// Command palette (active while the palette is open)
+	PaletteNext  key.Binding
+	PalettePrev  key.Binding
+	PaletteRun   key.Binding
+	PaletteClose key.Binding
 }
 
 // DefaultStoryKeyMap returns the default key bindings for story mode.
@@ -93,5 +122,144 @@ func DefaultStoryKeyMap() StoryKeyMap {
 			key.WithKeys("e"),
 			key.WithHelp("e", "save case to eval dataset"),
 		),
+		ToggleWhitespace: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "toggle ignore whitespace"),
+		),
+		ExpandAbove: key.NewBinding(
+			key.WithKeys("{"),
+			key.WithHelp("{", "more context above"),
+		),
+		ExpandBelow: key.NewBinding(
+			key.WithKeys("}"),
+			key.WithHelp("}", "more context below"),
+		),
+		ToggleFullFile: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "toggle full file"),
+		),
+		Comment: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "comment on top line"),
+		),
+		SaveComment: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "save comment"),
+		),
+		StageHunk: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "stage hunk"),
+		),
+		StageSection: key.NewBinding(
+			key.WithKeys("A"),
+			key.WithHelp("A", "stage section"),
+		),
+		UnstageHunk: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "unstage hunk"),
+		),
+		UnstageSection: key.NewBinding(
+			key.WithKeys("U"),
+			key.WithHelp("U", "unstage section"),
+		),
+		DiscardHunk: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "discard hunk"),
+		),
+		DiscardSection: key.NewBinding(
+			key.WithKeys("X"),
+			key.WithHelp("X", "discard section"),
+		),
+		ConfirmDiscard: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "confirm discard"),
+		),
+		Help: key.NewBinding(
+			key.WithKeys("?"),
+			key.WithHelp("?", "toggle help"),
+		),
+		CommandPalette: key.NewBinding(
+			key.WithKeys(":"),
+			key.WithHelp(":", "command palette"),
+		),
+		PaletteNext: key.NewBinding(
+			key.WithKeys("down", "ctrl+n"),
+			key.WithHelp("↓/ctrl+n", "next command"),
+		),
+		PalettePrev: key.NewBinding(
+			key.WithKeys("up", "ctrl+p"),
+			key.WithHelp("↑/ctrl+p", "previous command"),
+		),
+		PaletteRun: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "run command"),
+		),
+		PaletteClose: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "close palette"),
+		),
+	}
+}
+
+// ShortHelp implements help.KeyMap.
+func (km StoryKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{km.Down, km.NextSection, km.ToggleCollapseAll, km.CommandPalette, km.Help, km.Quit}
+}
+
+// FullHelp implements help.KeyMap, grouping bindings into help columns.
+func (km StoryKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{km.Up, km.Down, km.HalfPageUp, km.HalfPageDown, km.GotoTop, km.GotoBottom},
+		{km.NextSection, km.PrevSection, km.ToggleCollapseAll, km.ToggleWhitespace, km.ExpandAbove, km.ExpandBelow, km.ToggleFullFile, km.SaveCase},
+		{km.StageHunk, km.StageSection, km.UnstageHunk, km.UnstageSection, km.DiscardHunk, km.DiscardSection, km.ConfirmDiscard},
+		{km.Comment, km.SaveComment, km.CommandPalette, km.Help, km.Quit},
+	}
+}
+
+// Override returns a copy of the key map with the named bindings replaced.
+// Names are the snake_case field names (e.g. "next_section"). Returns an
+// error for unknown names or when a key ends up bound to two actions.
+func (km StoryKeyMap) Override(overrides map[string][]string) (StoryKeyMap, error) {
+	err := overrideBindings(km.bindings(), overrides)
+	return km, err
+}
+
+// bindings returns the configurable bindings of the key map.
+func (km *StoryKeyMap) bindings() []namedBinding {
+	return []namedBinding{
+		{name: "up", binding: &km.Up},
+		{name: "down", binding: &km.Down},
+		{name: "half_page_up", binding: &km.HalfPageUp},
+		{name: "half_page_down", binding: &km.HalfPageDown},
+		{name: "goto_top", binding: &km.GotoTop},
+		{name: "goto_bottom", binding: &km.GotoBottom},
+		{name: "next_hunk", binding: &km.NextHunk},
+		{name: "prev_hunk", binding: &km.PrevHunk},
+		{name: "next_file", binding: &km.NextFile},
+		{name: "prev_file", binding: &km.PrevFile},
+		{name: "quit", binding: &km.Quit},
+		{name: "next_section", binding: &km.NextSection},
+		{name: "prev_section", binding: &km.PrevSection},
+		{name: "toggle_collapse_all", binding: &km.ToggleCollapseAll},
+		{name: "save_case", binding: &km.SaveCase},
+		{name: "toggle_whitespace", binding: &km.ToggleWhitespace},
+		{name: "expand_above", binding: &km.ExpandAbove},
+		{name: "expand_below", binding: &km.ExpandBelow},
+		{name: "toggle_full_file", binding: &km.ToggleFullFile},
+		{name: "comment", binding: &km.Comment},
+		{name: "save_comment", mode: "comment", binding: &km.SaveComment},
+		{name: "stage_hunk", binding: &km.StageHunk},
+		{name: "stage_section", binding: &km.StageSection},
+		{name: "unstage_hunk", binding: &km.UnstageHunk},
+		{name: "unstage_section", binding: &km.UnstageSection},
+		{name: "discard_hunk", binding: &km.DiscardHunk},
+		{name: "discard_section", binding: &km.DiscardSection},
+		{name: "confirm_discard", mode: "confirm", binding: &km.ConfirmDiscard},
+		{name: "help", binding: &km.Help},
+		{name: "command_palette", binding: &km.CommandPalette},
+		{name: "palette_next", mode: "palette", binding: &km.PaletteNext},
+		{name: "palette_prev", mode: "palette", binding: &km.PalettePrev},
+		{name: "palette_run", mode: "palette", binding: &km.PaletteRun},
+		{name: "palette_close", mode: "palette", binding: &km.PaletteClose},
 	}
 }
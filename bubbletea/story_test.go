@@ -21,8 +21,8 @@ func TestStoryModel_BasicRendering(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/main.go",
-				NewPath:   "b/main.go",
+				OldPath:   "main.go",
+				NewPath:   "main.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -104,7 +104,7 @@ func TestStoryModel_SectionNavigation(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				NewPath:   "b/first.go",
+				NewPath:   "first.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -114,7 +114,7 @@ func TestStoryModel_SectionNavigation(t *testing.T) {
 				},
 			},
 			{
-				NewPath:   "b/second.go",
+				NewPath:   "second.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -194,7 +194,7 @@ func TestStoryModel_NoiseHunksCollapsedByDefault(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				NewPath:   "b/file.go",
+				NewPath:   "file.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -252,7 +252,7 @@ func TestStoryModel_ZKeyOnlyTogglesLLMCollapsedHunks(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				NewPath:   "b/file.go",
+				NewPath:   "file.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -350,7 +350,7 @@ func TestStoryModel_CategoryStyling(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				NewPath:   "b/file.go",
+				NewPath:   "file.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -401,7 +401,7 @@ func TestStoryModel_SectionIndicator_NoSections(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				NewPath:   "b/file.go",
+				NewPath:   "file.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -460,21 +460,21 @@ func TestStoryModel_SectionIndicator(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				NewPath:   "b/first.go",
+				NewPath:   "first.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{OldStart: 1, OldCount: 20, NewStart: 1, NewCount: 20, Lines: firstFileLines},
 				},
 			},
 			{
-				NewPath:   "b/second.go",
+				NewPath:   "second.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{OldStart: 1, OldCount: 20, NewStart: 1, NewCount: 20, Lines: secondFileLines},
 				},
 			},
 			{
-				NewPath:   "b/third.go",
+				NewPath:   "third.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{OldStart: 1, OldCount: 20, NewStart: 1, NewCount: 20, Lines: thirdFileLines},
@@ -545,7 +545,7 @@ func TestStoryModel_IntroSlide_StartsAtIntro(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				NewPath:   "b/file.go",
+				NewPath:   "file.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -596,7 +596,7 @@ func TestStoryModel_WithoutIntroSlide_StartsAtCodeSection(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				NewPath:   "b/file.go",
+				NewPath:   "file.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -647,7 +647,7 @@ func TestStoryModel_IntroSlide_NavigationToCodeAndBack(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				NewPath:   "b/file.go",
+				NewPath:   "file.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -715,7 +715,7 @@ func TestStoryModel_SectionFiltering(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				NewPath:   "b/first.go",
+				NewPath:   "first.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -727,7 +727,7 @@ func TestStoryModel_SectionFiltering(t *testing.T) {
 				},
 			},
 			{
-				NewPath:   "b/second.go",
+				NewPath:   "second.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -839,8 +839,8 @@ func TestStoryModel_ExpandedHunksGetFullStyling(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				OldPath:   "a/main.go",
-				NewPath:   "b/main.go",
+				OldPath:   "main.go",
+				NewPath:   "main.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -1091,7 +1091,7 @@ func TestStoryModel_FilteredDiffUsesOriginalHunkIndices(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				NewPath:   "b/file.go",
+				NewPath:   "file.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -1178,7 +1178,7 @@ func TestStoryModel_IntroSlide_ShowsChangeTypePrefix(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				NewPath:   "b/file.go",
+				NewPath:   "file.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -1221,6 +1221,44 @@ func TestStoryModel_IntroSlide_ShowsChangeTypePrefix(t *testing.T) {
 	tm.WaitFinished(t, teatest.WithFinalTimeout(0))
 }
 
+func TestStoryModel_IntroSlide_ShowsBase(t *testing.T) {
+	t.Parallel()
+
+	diff := &diffview.Diff{
+		Files: []diffview.FileDiff{
+			{
+				NewPath:   "file.go",
+				Operation: diffview.FileModified,
+				Hunks: []diffview.Hunk{
+					{
+						OldStart: 1, OldCount: 1, NewStart: 1, NewCount: 1,
+						Lines: []diffview.Line{
+							{Type: diffview.LineContext, Content: "content"},
+						},
+					},
+				},
+			},
+		},
+	}
+	story := &diffview.StoryClassification{
+		ChangeType: "feature",
+		Summary:    "Add handler",
+	}
+
+	m := bubbletea.NewStoryModel(diff, story, bubbletea.WithIntroSlide(),
+		bubbletea.WithStoryBase(diffview.Base{Branch: "part-1", Reason: "upstream tracking branch"}))
+	tm := teatest.NewTestModel(t, m,
+		teatest.WithInitialTermSize(80, 24),
+	)
+
+	teatest.WaitFor(t, tm.Output(), func(out []byte) bool {
+		return bytes.Contains(out, []byte("Base: part-1 (upstream tracking branch)"))
+	})
+
+	tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	tm.WaitFinished(t, teatest.WithFinalTimeout(0))
+}
+
 func TestStoryModel_IntroSlide_ShowsNarrativeDiagram(t *testing.T) {
 	t.Parallel()
 
@@ -1286,7 +1324,7 @@ func TestStoryModel_IntroSlide_ShowsNarrativeDiagram(t *testing.T) {
 			diff := &diffview.Diff{
 				Files: []diffview.FileDiff{
 					{
-						NewPath:   "b/file.go",
+						NewPath:   "file.go",
 						Operation: diffview.FileModified,
 						Hunks: []diffview.Hunk{
 							{
@@ -1334,7 +1372,7 @@ func TestStoryModel_IntroSlide_ShowsSectionRolePrefixes(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				NewPath:   "b/file.go",
+				NewPath:   "file.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -1415,7 +1453,7 @@ func TestStoryModel_IntroSlide_UnknownNarrativeFallback(t *testing.T) {
 	diff := &diffview.Diff{
 		Files: []diffview.FileDiff{
 			{
-				NewPath:   "b/file.go",
+				NewPath:   "file.go",
 				Operation: diffview.FileModified,
 				Hunks: []diffview.Hunk{
 					{
@@ -29,9 +29,27 @@ type Model struct {
 	ready            bool
 	keymap           KeyMap
 	pendingKey       string
-	hunkPositions    []int // line numbers where each hunk starts
-	filePositions    []int // line numbers where each file starts
-	width            int   // terminal width for rendering
+	hunkPositions    []int        // line numbers where each hunk starts
+	filePositions    []int        // line numbers where each file starts
+	rows             []lineAnchor // diff line shown on each rendered row
+	width            int          // terminal width for rendering
+	comments         commentEditor
+	movedLines       map[movedKey]string // lines of blocks moved within the diff
+	showHelp         bool
+
+	// Whitespace mode: diff is source, or source without whitespace changes
+	source           *diffview.Diff
+	ignoreWhitespace bool
+
+	// Context expansion: file lines to read context from, and the extra
+	// context shown around each hunk
+	fileLines  map[string]diffview.FileLines
+	expansions map[hunkKey]diffview.ContextExpansion
+
+	// Full-file mode: the file shown in full, and the viewport offset to
+	// return to when leaving it
+	fullFile       string
+	fullFileOffset int
 }
 
 // ModelOption configures a Model.
@@ -43,6 +61,12 @@ type modelConfig struct {
 	languageDetector diffview.LanguageDetector
 	tokenizer        diffview.Tokenizer
 	wordDiffer       diffview.WordDiffer
+	commentStore     diffview.CommentStore
+	commentPath      string
+	comments         []diffview.Comment
+	keymap           *KeyMap
+	ignoreWhitespace bool
+	fileVersions     map[string]diffview.FileVersions
 }
 
 // WithRenderer sets a custom lipgloss renderer for the model.
@@ -82,6 +106,43 @@ func WithWordDiffer(d diffview.WordDiffer) ModelOption {
 	}
 }
 
+// WithCommentStore sets the store and sidecar path for persisting review comments.
+func WithCommentStore(store diffview.CommentStore, path string) ModelOption {
+	return func(cfg *modelConfig) {
+		cfg.commentStore = store
+		cfg.commentPath = path
+	}
+}
+
+// WithExistingComments loads previously written review comments.
+func WithExistingComments(comments []diffview.Comment) ModelOption {
+	return func(cfg *modelConfig) {
+		cfg.comments = comments
+	}
+}
+
+// WithKeyMap replaces the default key bindings.
+func WithKeyMap(km KeyMap) ModelOption {
+	return func(cfg *modelConfig) {
+		cfg.keymap = &km
+	}
+}
+
+// WithIgnoreWhitespace starts the model with whitespace-only changes hidden.
+func WithIgnoreWhitespace(ignore bool) ModelOption {
+	return func(cfg *modelConfig) {
+		cfg.ignoreWhitespace = ignore
+	}
+}
+
+// WithFileVersions sets the full content of the diff's files, keyed by
+// path. Context around hunks of those files can be expanded.
+func WithFileVersions(versions map[string]diffview.FileVersions) ModelOption {
+	return func(cfg *modelConfig) {
+		cfg.fileVersions = versions
+	}
+}
+
 // NewModel creates a new Model with the given diff.
 // Use WithTheme to set a custom theme, otherwise uses hardcoded defaults.
 func NewModel(diff *diffview.Diff, opts ...ModelOption) Model {
@@ -100,20 +161,36 @@ func NewModel(diff *diffview.Diff, opts ...ModelOption) Model {
 		palette = defaultPalette()
 	}
 
+	keymap := DefaultKeyMap()
+	if cfg.keymap != nil {
+		keymap = *cfg.keymap
+	}
+
+	comments := newCommentEditor(cfg.commentStore, cfg.commentPath, cfg.comments)
+
+	source := diff
+	diff = displayedDiff(source, cfg.ignoreWhitespace)
+
 	// Compute positions eagerly - they don't depend on terminal width
-	hunkPositions, filePositions := computePositions(diff)
+	hunkPositions, filePositions := computePositions(diff, comments.comments)
 
 	return Model{
 		diff:             diff,
+		source:           source,
+		ignoreWhitespace: cfg.ignoreWhitespace,
 		styles:           styles,
 		palette:          palette,
 		renderer:         cfg.renderer,
 		languageDetector: cfg.languageDetector,
 		tokenizer:        cfg.tokenizer,
 		wordDiffer:       cfg.wordDiffer,
-		keymap:           DefaultKeyMap(),
+		keymap:           keymap,
 		hunkPositions:    hunkPositions,
 		filePositions:    filePositions,
+		comments:         comments,
+		movedLines:       detectMovedLines(diff),
+		fileLines:        contextLines(cfg.fileVersions),
+		expansions:       make(map[hunkKey]diffview.ContextExpansion),
 	}
 }
 
@@ -161,6 +238,10 @@ func defaultStyles() diffview.Styles {
 			Foreground: "#e6edf3", // Same as code line foreground (neutral)
 			Background: "#5f2728", // Same as gutter (35% blend)
 		},
+		Moved: diffview.ColorPair{
+			Foreground: "#e6edf3", // Normal text (neutral)
+			Background: "#2a2518", // Subtle yellow background (15% blend of #d29922 with #0d1117)
+		},
 	}
 }
 
@@ -213,6 +294,15 @@ func (m Model) newStyle() lipgloss.Style {
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.comments.editing {
+			return m.handleCommentKeys(msg)
+		}
+		if m.showHelp {
+			// Any key closes the help overlay
+			m.showHelp = false
+			return m, nil
+		}
+
 		// Handle multi-key sequences (gg for go to top)
 		if m.pendingKey == "g" && key.Matches(msg, m.keymap.GotoTop) {
 			m.viewport.GotoTop()
@@ -259,6 +349,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, m.keymap.PrevFile):
 			m.gotoPrevPosition(m.filePositions)
 			return m, nil
+		case key.Matches(msg, m.keymap.Comment):
+			return m, m.beginComment()
+		case key.Matches(msg, m.keymap.ToggleWhitespace):
+			m.toggleWhitespace()
+			return m, nil
+		case key.Matches(msg, m.keymap.ExpandAbove):
+			m.expandContext(true)
+			return m, nil
+		case key.Matches(msg, m.keymap.ExpandBelow):
+			m.expandContext(false)
+			return m, nil
+		case key.Matches(msg, m.keymap.ToggleFullFile):
+			m.toggleFullFile()
+			return m, nil
+		case key.Matches(msg, m.keymap.Help):
+			m.showHelp = true
+			return m, nil
 		}
 	case tea.WindowSizeMsg:
 		statusBarHeight := 1
@@ -268,13 +375,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if !m.ready {
 			// First render - create viewport and render content
 			m.viewport = viewport.New(msg.Width, msg.Height-statusBarHeight)
-			m.viewport.SetContent(m.renderContent())
+			m.refreshContent()
 			m.ready = true
 		} else if widthChanged {
 			// Width changed - re-render content
 			m.viewport.Width = msg.Width
 			m.viewport.Height = msg.Height - statusBarHeight
-			m.viewport.SetContent(m.renderContent())
+			m.refreshContent()
 		} else {
 			// Only height changed
 			m.viewport.Height = msg.Height - statusBarHeight
@@ -286,27 +393,136 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// handleCommentKeys routes keys to the comment editor while a comment is being written.
+func (m Model) handleCommentKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key.Matches(msg, m.keymap.SaveComment) {
+		m.comments.commit()
+		m.hunkPositions, m.filePositions = computePositions(m.diff, m.comments.comments)
+		m.refreshContent()
+		return m, nil
+	}
+	return m, m.comments.update(msg)
+}
+
+// toggleWhitespace switches between the full diff and the diff without
+// whitespace-only changes, keeping the viewport offset where possible.
+func (m *Model) toggleWhitespace() {
+	m.ignoreWhitespace = !m.ignoreWhitespace
+	m.refreshDiff()
+}
+
+// expandContext shows more context above or below the hunk at the top of
+// the viewport, when the file's content is known.
+func (m *Model) expandContext(above bool) {
+	anchor, ok := anchorAtRow(m.rows, m.viewport.YOffset)
+	if ok && expandHunkAt(m.expansions, m.diff, m.fileLines, anchor, above) {
+		m.refreshDiff()
+	}
+}
+
+// toggleFullFile shows the file at the top of the viewport in full, or
+// returns to the hunk view at the offset it was left from.
+func (m *Model) toggleFullFile() {
+	if m.fullFile != "" {
+		m.fullFile = ""
+		m.refreshDiff()
+		m.viewport.SetYOffset(m.fullFileOffset)
+		return
+	}
+	anchor, ok := anchorAtRow(m.rows, m.viewport.YOffset)
+	if !ok {
+		return
+	}
+	if _, ok := fullFileDiff(m.source, m.fileLines, anchor.file); !ok {
+		return
+	}
+	m.fullFileOffset = m.viewport.YOffset
+	m.fullFile = anchor.file
+	m.refreshDiff()
+	m.viewport.SetYOffset(rowOfAnchor(m.rows, anchor))
+}
+
+// refreshDiff rebuilds the displayed diff from the source after the
+// whitespace mode, context expansion or full-file mode changed.
+func (m *Model) refreshDiff() {
+	diff := expandedDiff(m.source, m.fileLines, m.expansions)
+	if m.fullFile != "" {
+		diff, _ = fullFileDiff(diff, m.fileLines, m.fullFile)
+	}
+	m.diff = displayedDiff(diff, m.ignoreWhitespace)
+	m.movedLines = detectMovedLines(m.diff)
+	m.hunkPositions, m.filePositions = computePositions(m.diff, m.comments.comments)
+	if m.ready {
+		m.refreshContent()
+	}
+}
+
+// beginComment opens the comment editor for the first diff line at the top of the viewport.
+func (m *Model) beginComment() tea.Cmd {
+	if m.diff == nil {
+		return nil
+	}
+	anchor, ok := anchorAtRow(m.rows, m.viewport.YOffset)
+	if !ok {
+		return nil
+	}
+	_, line, ok := m.diff.FindLine(anchor.file, anchor.side, anchor.line)
+	if !ok {
+		return nil
+	}
+	return m.comments.begin(anchor, line, m.width, m.viewport.Height+1)
+}
+
+// Comments returns the review comments ordered by file, side and line.
+func (m Model) Comments() []diffview.Comment {
+	return m.comments.list()
+}
+
 // View implements tea.Model.
 func (m Model) View() string {
 	if !m.ready {
 		return "Loading..."
 	}
+	if m.comments.editing {
+		return m.comments.view(m.renderer, m.keymap.SaveComment)
+	}
+	if m.showHelp {
+		return helpView(m.renderer, m.keymap, m.width)
+	}
 	return lipgloss.JoinVertical(lipgloss.Left, m.viewport.View(), m.statusBarView())
 }
 
-// renderContent renders the diff content with current model configuration.
-func (m Model) renderContent() string {
-	return renderDiff(renderConfig{
+// refreshContent re-renders the diff into the viewport and records
+// which diff line each rendered row shows.
+func (m *Model) refreshContent() {
+	content, rows := m.renderContent()
+	m.viewport.SetContent(content)
+	m.rows = rows
+}
+
+// renderContent renders the diff with the current model configuration.
+func (m Model) renderContent() (string, []lineAnchor) {
+	return renderDiffRows(renderConfig{
 		diff:             m.diff,
 		styles:           m.styles,
 		renderer:         m.renderer,
 		width:            m.width,
 		languageDetector: m.languageDetector,
 		tokenizer:        m.tokenizer,
-		wordDiffer:       m.wordDiffer,
+		wordDiffer:       displayedWordDiffer(m.wordDiffer, m.ignoreWhitespace),
+		comments:         m.comments.comments,
+		movedLines:       m.movedLines,
 	})
 }
 
+// keyHints returns the status bar summary of the main key bindings.
+func (m Model) keyHints() string {
+	km := m.keymap
+	return fmt.Sprintf("%s:scroll  %s:hunk  %s:file  %s:comment  %s:help  %s:quit",
+		keyPair(km.Down, km.Up), keyPair(km.NextHunk, km.PrevHunk),
+		keyPair(km.NextFile, km.PrevFile), keyName(km.Comment), keyName(km.Help), keyName(km.Quit))
+}
+
 // statusBarView renders the status bar with position info.
 func (m Model) statusBarView() string {
 	// Create styles using palette colors and renderer
@@ -337,9 +553,15 @@ func (m Model) statusBarView() string {
 	// Build status bar with separators
 	sep := sepStyle.Render(" │ ")
 	content := barStyle.Render(filePos) + sep +
-		barStyle.Render(hunkPos) + sep +
-		barStyle.Render(scrollPos) + sep +
-		dimStyle.Render("j/k:scroll  n/N:hunk  ]/[:file  q:quit") +
+		barStyle.Render(hunkPos) + sep
+	if m.ignoreWhitespace {
+		content += barStyle.Render(whitespaceIndicator) + sep
+	}
+	if m.fullFile != "" {
+		content += barStyle.Render(fullFileIndicator) + sep
+	}
+	content += barStyle.Render(scrollPos) + sep +
+		dimStyle.Render(m.keyHints()) +
 		barStyle.Render("  ") // Right padding
 
 	// Right-align by padding left side with background
@@ -478,6 +700,11 @@ type Viewer struct {
 	languageDetector diffview.LanguageDetector
 	tokenizer        diffview.Tokenizer
 	wordDiffer       diffview.WordDiffer
+	commentStore     diffview.CommentStore
+	commentPath      string
+	keymap           *KeyMap
+	ignoreWhitespace bool
+	fileSource       diffview.FileVersionSource
 	programOpts      []tea.ProgramOption
 }
 
@@ -513,6 +740,37 @@ func WithViewerWordDiffer(d diffview.WordDiffer) ViewerOption {
 	}
 }
 
+// WithViewerCommentStore enables review comments, loading and saving them
+// in the sidecar file at path.
+func WithViewerCommentStore(store diffview.CommentStore, path string) ViewerOption {
+	return func(v *Viewer) {
+		v.commentStore = store
+		v.commentPath = path
+	}
+}
+
+// WithViewerKeyMap replaces the default key bindings.
+func WithViewerKeyMap(km KeyMap) ViewerOption {
+	return func(v *Viewer) {
+		v.keymap = &km
+	}
+}
+
+// WithViewerIgnoreWhitespace starts the viewer with whitespace-only changes hidden.
+func WithViewerIgnoreWhitespace(ignore bool) ViewerOption {
+	return func(v *Viewer) {
+		v.ignoreWhitespace = ignore
+	}
+}
+
+// WithViewerFileSource reads the full content of the diff's files from src
+// when the viewer opens, so context around hunks can be expanded.
+func WithViewerFileSource(src diffview.FileVersionSource) ViewerOption {
+	return func(v *Viewer) {
+		v.fileSource = src
+	}
+}
+
 // NewViewer creates a new Viewer with the given theme.
 func NewViewer(theme diffview.Theme, opts ...ViewerOption) *Viewer {
 	v := &Viewer{theme: theme}
@@ -524,12 +782,34 @@ func NewViewer(theme diffview.Theme, opts ...ViewerOption) *Viewer {
 
 // View displays the diff and blocks until the user exits.
 func (v *Viewer) View(ctx context.Context, diff *diffview.Diff) error {
-	m := NewModel(diff,
+	modelOpts := []ModelOption{
 		WithTheme(v.theme),
 		WithLanguageDetector(v.languageDetector),
 		WithTokenizer(v.tokenizer),
 		WithWordDiffer(v.wordDiffer),
-	)
+		WithIgnoreWhitespace(v.ignoreWhitespace),
+	}
+	if v.keymap != nil {
+		modelOpts = append(modelOpts, WithKeyMap(*v.keymap))
+	}
+	if v.fileSource != nil {
+		versions, err := v.fileSource.FileVersions(ctx, diff)
+		if err != nil {
+			return fmt.Errorf("reading files: %w", err)
+		}
+		modelOpts = append(modelOpts, WithFileVersions(versions))
+	}
+	if v.commentStore != nil {
+		existing, err := v.commentStore.Load(v.commentPath)
+		if err != nil {
+			return fmt.Errorf("loading comments: %w", err)
+		}
+		modelOpts = append(modelOpts,
+			WithCommentStore(v.commentStore, v.commentPath),
+			WithExistingComments(existing),
+		)
+	}
+	m := NewModel(diff, modelOpts...)
 	opts := []tea.ProgramOption{
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
@@ -0,0 +1,53 @@
+package bubbletea
+
+import (
+	"strings"
+
+	"github.com/fwojciec/diffstory"
+)
+
+// whitespaceIndicator is shown in the status bar while whitespace is ignored.
+const whitespaceIndicator = "ignoring whitespace"
+
+// displayedDiff returns the diff the viewer shows: source itself, or source
+// without whitespace-only changes when ignoreWhitespace is set.
+func displayedDiff(source *diffview.Diff, ignoreWhitespace bool) *diffview.Diff {
+	if !ignoreWhitespace {
+		return source
+	}
+	return source.IgnoringWhitespace()
+}
+
+// displayedWordDiffer returns the word differ for the current whitespace
+// mode. Differs that can't ignore whitespace are used unchanged.
+func displayedWordDiffer(d diffview.WordDiffer, ignoreWhitespace bool) diffview.WordDiffer {
+	if !ignoreWhitespace {
+		return d
+	}
+	if wd, ok := d.(diffview.WhitespaceWordDiffer); ok {
+		return whitespaceIgnoringDiffer{wd}
+	}
+	return d
+}
+
+// whitespaceIgnoringDiffer adapts a WhitespaceWordDiffer so its
+// whitespace-insensitive diff is used as the WordDiffer.
+type whitespaceIgnoringDiffer struct {
+	differ diffview.WhitespaceWordDiffer
+}
+
+// Diff implements diffview.WordDiffer.
+func (d whitespaceIgnoringDiffer) Diff(old, new string) (oldSegs, newSegs []diffview.Segment) {
+	return d.differ.DiffIgnoringWhitespace(old, new)
+}
+
+// Similarity implements diffview.SimilarityScorer, scoring lines with
+// whitespace runs collapsed.
+func (d whitespaceIgnoringDiffer) Similarity(old, new string) float64 {
+	return diffview.LineSimilarity(d.differ, collapseWhitespace(old), collapseWhitespace(new))
+}
+
+// collapseWhitespace trims s and collapses inner whitespace runs.
+func collapseWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,90 @@
+package bubbletea_test
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	diffview "github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/bubbletea"
+	"github.com/fwojciec/diffstory/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// whitespaceDiff re-indents one line and changes another.
+func whitespaceDiff() *diffview.Diff {
+	return &diffview.Diff{Files: []diffview.FileDiff{{
+		OldPath: "main.go", NewPath: "main.go",
+		Hunks: []diffview.Hunk{{
+			OldStart: 1, OldCount: 2, NewStart: 1, NewCount: 2,
+			Lines: []diffview.Line{
+				{Type: diffview.LineDeleted, Content: "  indented()", OldLineNum: 1},
+				{Type: diffview.LineDeleted, Content: "old()", OldLineNum: 2},
+				{Type: diffview.LineAdded, Content: "\tindented()", NewLineNum: 1},
+				{Type: diffview.LineAdded, Content: "renamed()", NewLineNum: 2},
+			},
+		}},
+	}}}
+}
+
+func TestModel_ToggleWhitespace(t *testing.T) {
+	t.Parallel()
+
+	var m tea.Model = bubbletea.NewModel(whitespaceDiff())
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 20})
+
+	assert.Contains(t, m.View(), "-  indented()")
+	assert.NotContains(t, m.View(), "ignoring whitespace")
+
+	m = press(m, "w")
+	view := m.View()
+	assert.NotContains(t, view, "-  indented()")
+	assert.Contains(t, view, "     indented()", "re-indented line is shown as context")
+	assert.Contains(t, view, "-old()")
+	assert.Contains(t, view, "+renamed()")
+	assert.Contains(t, view, "ignoring whitespace")
+
+	m = press(m, "w")
+	assert.Contains(t, m.View(), "-  indented()")
+}
+
+func TestModel_IgnoreWhitespaceUsesWhitespaceWordDiff(t *testing.T) {
+	t.Parallel()
+
+	var ignored bool
+	differ := &mock.WordDiffer{
+		DiffFn: func(old, new string) (oldSegs, newSegs []diffview.Segment) {
+			return nil, nil
+		},
+		DiffIgnoringWhitespaceFn: func(old, new string) (oldSegs, newSegs []diffview.Segment) {
+			ignored = true
+			return nil, nil
+		},
+	}
+
+	var m tea.Model = bubbletea.NewModel(whitespaceDiff(),
+		bubbletea.WithWordDiffer(differ),
+		bubbletea.WithIgnoreWhitespace(true),
+	)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 20})
+
+	assert.True(t, ignored)
+	assert.Contains(t, m.View(), "ignoring whitespace")
+}
+
+func TestStoryModel_ToggleWhitespace(t *testing.T) {
+	t.Parallel()
+
+	story := &diffview.StoryClassification{Sections: []diffview.Section{
+		{Title: "Rename", Hunks: []diffview.HunkRef{{File: "main.go", HunkIndex: 0}}},
+	}}
+	var m tea.Model = bubbletea.NewStoryModel(whitespaceDiff(), story)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 200, Height: 20})
+
+	assert.Contains(t, m.View(), "-  indented()")
+
+	m = press(m, "w")
+	view := m.View()
+	assert.NotContains(t, view, "-  indented()")
+	assert.Contains(t, view, "+renamed()")
+	assert.Contains(t, view, "ignoring whitespace")
+}
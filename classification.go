@@ -5,6 +5,7 @@ import "context"
 // CommitBrief captures essential commit metadata for PR context.
 type CommitBrief struct {
 	Hash    string `json:"hash"`
+	Author  string `json:"author,omitempty"` // "Name <email>", when known
 	Message string `json:"message"`
 	Diff    *Diff  `json:"diff,omitempty"`
 }
@@ -61,6 +62,19 @@ type Section struct {
 	Explanation string    `json:"explanation"` // Why this section matters
 }
 
+// SectionRoles returns the unique non-empty roles of sections in order.
+func SectionRoles(sections []Section) []string {
+	var roles []string
+	seen := make(map[string]bool)
+	for _, s := range sections {
+		if s.Role != "" && !seen[s.Role] {
+			roles = append(roles, s.Role)
+			seen[s.Role] = true
+		}
+	}
+	return roles
+}
+
 // HunkRef references a specific hunk with classification metadata.
 type HunkRef struct {
 	File         string `json:"file"`
@@ -74,3 +88,8 @@ type HunkRef struct {
 type StoryClassifier interface {
 	Classify(ctx context.Context, input ClassificationInput) (*StoryClassification, error)
 }
+
+// StoryExporter writes a classified diff to a file in a shareable format.
+type StoryExporter interface {
+	Export(path string, diff *Diff, story *StoryClassification) error
+}
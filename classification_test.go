@@ -82,3 +82,11 @@ func TestStoryClassification_JSONOmitsEmptyEvolution(t *testing.T) {
 		assert.Contains(t, string(data), "Changes evolved")
 	})
 }
+
+func TestSectionRoles(t *testing.T) {
+	t.Parallel()
+
+	sections := []diffview.Section{{Role: "problem"}, {Role: "fix"}, {Role: ""}, {Role: "problem"}, {Role: "test"}}
+
+	assert.Equal(t, []string{"problem", "fix", "test"}, diffview.SectionRoles(sections))
+}
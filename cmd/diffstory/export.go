@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/html"
+	"github.com/fwojciec/diffstory/markdown"
+)
+
+// ErrNotCached is returned by --cached exports when no cached classification exists.
+var ErrNotCached = errors.New("no cached classification for this diff (run without --cached)")
+
+// ErrNoAPIKey is returned when a diff without a cached classification is
+// classified without GEMINI_API_KEY set.
+var ErrNoAPIKey = errors.New("GEMINI_API_KEY environment variable required")
+
+// ExportApp writes a classified story as a pull request description
+// (Markdown) or a self-contained walkthrough (HTML).
+type ExportApp struct {
+	Format   string         // FormatMarkdown or FormatHTML
+	Snippets bool           // Include fenced diff snippets for expanded hunks (Markdown only)
+	HTML     *html.Renderer // Renderer for FormatHTML
+	Output   io.Writer      // Destination for the exported story
+}
+
+// Run writes the story for diff to the output.
+func (a *ExportApp) Run(diff *diffview.Diff, story *diffview.StoryClassification) error {
+	if err := validateFormat(a.Format, FormatMarkdown, FormatHTML); err != nil {
+		return err
+	}
+
+	if a.Format == FormatHTML {
+		return a.HTML.Render(a.Output, diff, story)
+	}
+
+	var opts []markdown.StoryOption
+	if a.Snippets {
+		opts = append(opts, markdown.WithDiffSnippets())
+	}
+
+	_, err := io.WriteString(a.Output, markdown.FormatStory(diff, story, opts...))
+	return err
+}
+
+// cacheOnlyClassifier is the inner classifier for --cached exports.
+// It is only reached on a cache miss, so it always fails.
+type cacheOnlyClassifier struct{}
+
+func (cacheOnlyClassifier) Classify(context.Context, diffview.ClassificationInput) (*diffview.StoryClassification, error) {
+	return nil, ErrNotCached
+}
+
+// noAPIKeyClassifier is the inner classifier when GEMINI_API_KEY isn't set,
+// so cached classifications still work offline. It is only reached on a
+// cache miss, so it always fails.
+type noAPIKeyClassifier struct{}
+
+func (noAPIKeyClassifier) Classify(context.Context, diffview.ClassificationInput) (*diffview.StoryClassification, error) {
+	return nil, ErrNoAPIKey
+}
@@ -0,0 +1,78 @@
+package main_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	main "github.com/fwojciec/diffstory/cmd/diffstory"
+	"github.com/fwojciec/diffstory/html"
+	"github.com/fwojciec/diffstory/lipgloss"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportApp_Run_Markdown(t *testing.T) {
+	t.Parallel()
+
+	diff, story, _ := reviewFixture()
+	var out bytes.Buffer
+	app := &main.ExportApp{
+		Format: main.FormatMarkdown,
+		Output: &out,
+	}
+
+	require.NoError(t, app.Run(diff, story))
+
+	assert.Contains(t, out.String(), "# Add run\n")
+	assert.Contains(t, out.String(), "## 1. Entry point")
+	assert.Contains(t, out.String(), "- `main.go`")
+	assert.NotContains(t, out.String(), "```diff")
+}
+
+func TestExportApp_Run_Snippets(t *testing.T) {
+	t.Parallel()
+
+	diff, story, _ := reviewFixture()
+	var out bytes.Buffer
+	app := &main.ExportApp{
+		Format:   main.FormatMarkdown,
+		Snippets: true,
+		Output:   &out,
+	}
+
+	require.NoError(t, app.Run(diff, story))
+
+	assert.Contains(t, out.String(), "  +func run() {}\n")
+}
+
+func TestExportApp_Run_UnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	diff, story, _ := reviewFixture()
+	app := &main.ExportApp{
+		Format: main.FormatGitHub,
+		Output: &bytes.Buffer{},
+	}
+
+	err := app.Run(diff, story)
+
+	require.ErrorIs(t, err, main.ErrUnknownFormat)
+}
+
+func TestExportApp_Run_HTML(t *testing.T) {
+	t.Parallel()
+
+	diff, story, _ := reviewFixture()
+	var out bytes.Buffer
+	app := &main.ExportApp{
+		Format: main.FormatHTML,
+		HTML:   html.NewRenderer(lipgloss.TestTheme()),
+		Output: &out,
+	}
+
+	require.NoError(t, app.Run(diff, story))
+
+	assert.True(t, strings.HasPrefix(out.String(), "<!DOCTYPE html>"))
+	assert.Contains(t, out.String(), "<h1>Entry point</h1>")
+}
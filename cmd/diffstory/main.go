@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
@@ -21,8 +23,11 @@ import (
 	"github.com/fwojciec/diffstory/gemini"
 	"github.com/fwojciec/diffstory/git"
 	"github.com/fwojciec/diffstory/gitdiff"
+	"github.com/fwojciec/diffstory/html"
 	"github.com/fwojciec/diffstory/jsonl"
 	"github.com/fwojciec/diffstory/lipgloss"
+	"github.com/fwojciec/diffstory/markdown"
+	"github.com/fwojciec/diffstory/unidiff"
 	"github.com/fwojciec/diffstory/worddiff"
 )
 
@@ -35,6 +40,34 @@ var ErrInvalidRange = errors.New("invalid commit range: expected format like 'ma
 // ErrOnBaseBranch is returned when running in branch mode while on the base branch.
 var ErrOnBaseBranch = errors.New("already on base branch, no changes to show")
 
+// ErrClassificationFailed wraps errors returned by the classifier.
+var ErrClassificationFailed = errors.New("classification failed")
+
+// Exit codes returned by diffstory so scripts can branch on the outcome.
+const (
+	ExitOK                   = 0 // Story classified (and shown or written)
+	ExitError                = 1 // Any other failure: bad arguments, git errors, I/O
+	ExitNoChanges            = 2 // Nothing to classify
+	ExitClassificationFailed = 3 // The diff was collected but could not be classified
+)
+
+// ExitCode maps an error returned by run to the process exit code.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrNoChanges), errors.Is(err, ErrOnBaseBranch):
+		return ExitNoChanges
+	case errors.Is(err, ErrNoAPIKey):
+		// A missing key is a setup error even though the classifier reports it
+		return ExitError
+	case errors.Is(err, ErrClassificationFailed):
+		return ExitClassificationFailed
+	default:
+		return ExitError
+	}
+}
+
 // ParseRange parses a git commit range specification into its components.
 // Supports both two-dot (A..B) and three-dot (A...B) notation.
 // Returns base ref, head ref, and any error.
@@ -57,32 +90,29 @@ func ParseRange(rangeSpec string) (base, head string, err error) {
 	return base, head, nil
 }
 
+// Modes select which changes are classified. Modes other than ModeCommitted
+// include uncommitted changes, such as those an agent leaves behind.
+const (
+	ModeCommitted = ""         // BaseBranch...HEAD, or Range
+	ModeWorktree  = "worktree" // HEAD to the working tree, including untracked files
+	ModeStaged    = "staged"   // HEAD to the index
+	ModeAll       = "all"      // Merge base with BaseBranch to the working tree, including untracked files
+)
+
 // App encapsulates the application logic for testing.
 type App struct {
 	GitRunner  diffview.GitRunner       // Git runner for git operations
 	RepoPath   string                   // Repository path
 	BaseBranch string                   // Base branch (auto-detected if empty)
 	Range      string                   // Raw commit range (e.g., "main...feature"), overrides BaseBranch
+	Mode       string                   // Which changes to classify (default: committed)
 	Classifier diffview.StoryClassifier // Classifier for story generation
 }
 
 // Run parses the diff input and classifies it.
 // Returns the parsed diff and classification for TUI display.
 func (a *App) Run(ctx context.Context) (*diffview.Diff, *diffview.StoryClassification, error) {
-	// Get diff from git - use raw Range if provided, otherwise use BaseBranch...HEAD
-	var diffStr string
-	var err error
-	if a.Range != "" {
-		diffStr, err = a.GitRunner.Diff(ctx, a.RepoPath, a.Range)
-	} else {
-		diffStr, err = a.GitRunner.DiffRange(ctx, a.RepoPath, a.BaseBranch, "HEAD")
-	}
-	if err != nil {
-		return nil, nil, err
-	}
-
-	parser := gitdiff.NewParser()
-	diff, err := parser.Parse(strings.NewReader(diffStr))
+	diff, err := a.LoadDiff(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -98,12 +128,77 @@ func (a *App) Run(ctx context.Context) (*diffview.Diff, *diffview.StoryClassific
 
 	classification, err := a.Classifier.Classify(ctx, classInput)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, fmt.Errorf("%w: %w", ErrClassificationFailed, err)
 	}
 
 	return diff, classification, nil
 }
 
+// LoadDiff returns the parsed diff for the app's mode from git.
+func (a *App) LoadDiff(ctx context.Context) (*diffview.Diff, error) {
+	diffStr, err := a.diff(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return gitdiff.NewParser().Parse(strings.NewReader(diffStr))
+}
+
+// diff returns the diff for the app's mode from git.
+func (a *App) diff(ctx context.Context) (string, error) {
+	switch a.Mode {
+	case ModeWorktree:
+		return a.GitRunner.WorktreeDiff(ctx, a.RepoPath, "HEAD")
+	case ModeStaged:
+		return a.GitRunner.StagedDiff(ctx, a.RepoPath)
+	case ModeAll:
+		mergeBase, err := a.GitRunner.MergeBase(ctx, a.RepoPath, a.BaseBranch, "HEAD")
+		if err != nil {
+			return "", err
+		}
+		return a.GitRunner.WorktreeDiff(ctx, a.RepoPath, mergeBase)
+	}
+	// Use raw Range if provided, otherwise use BaseBranch...HEAD
+	if a.Range != "" {
+		return a.GitRunner.Diff(ctx, a.RepoPath, a.Range)
+	}
+	return a.GitRunner.DiffRange(ctx, a.RepoPath, a.BaseBranch, "HEAD")
+}
+
+// Revisions returns the old and new revisions the diff compares: the merge
+// base and head for three-dot ranges and branch mode, or both ends of a
+// two-dot range. In the uncommitted modes newRev is empty, as the new side
+// is the index (ModeStaged) or the working tree.
+func (a *App) Revisions(ctx context.Context) (oldRev, newRev string, err error) {
+	switch a.Mode {
+	case ModeWorktree, ModeStaged:
+		return "HEAD", "", nil
+	case ModeAll:
+		mergeBase, err := a.GitRunner.MergeBase(ctx, a.RepoPath, a.BaseBranch, "HEAD")
+		if err != nil {
+			return "", "", err
+		}
+		return mergeBase, "", nil
+	}
+
+	base, head := a.BaseBranch, "HEAD"
+	threeDot := true
+	if a.Range != "" {
+		base, head, err = ParseRange(a.Range)
+		if err != nil {
+			return "", "", err
+		}
+		threeDot = strings.Contains(a.Range, "...")
+	}
+	if !threeDot {
+		return base, head, nil
+	}
+	mergeBase, err := a.GitRunner.MergeBase(ctx, a.RepoPath, base, head)
+	if err != nil {
+		return "", "", err
+	}
+	return mergeBase, head, nil
+}
+
 // spinner displays a progress indicator on stderr while a long-running operation executes.
 type spinner struct {
 	frames   []string
@@ -171,17 +266,75 @@ func isTerminal(f *os.File) bool {
 func main() {
 	if err := run(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(ExitCode(err))
 	}
 }
 
 func usage() {
-	fmt.Fprintf(os.Stderr, `Usage: diffstory [range | command]
+	fmt.Fprintf(os.Stderr, `Usage: diffstory [flags] [range | -] | diffstory <command>
 
 Modes:
   (default)              Analyze current branch diff vs auto-detected base
   <range>                Analyze diff for specific commit range
+  -                      Analyze a diff or format-patch series read from stdin
   replay <file> [index]  Replay a saved eval case from JSONL file
+  review [flags] [range] Export review comments (markdown or github)
+  export [flags] [range] Export the story (markdown PR description or html)
+
+Flags:
+  --base BRANCH          Compare against BRANCH instead of the detected base:
+                         the upstream tracking branch, else the closer of
+                         the default branch (origin/HEAD, main, master or
+                         trunk) and the nearest ancestor branch. The intro
+                         slide shows the base and why it was picked. Also
+                         accepted by review and export
+  --worktree             Analyze uncommitted changes (HEAD to working tree),
+                         including untracked files. Hunks can be staged (a/A),
+                         unstaged (u/U) and discarded (x/X) from the viewer
+  --staged               Analyze staged changes (HEAD to index)
+  --all                  Analyze the branch and its uncommitted changes (base
+                         branch to working tree), including untracked files
+  --patch FILE           Analyze the diff or format-patch series in FILE
+                         instead of git (plain diff -u, hg and svn diffs too)
+  --title TEXT           Title of the change, for - or --patch
+  --message TEXT         Description of the change, for - or --patch
+  --print                Print the whole story to stdout instead of opening the TUI
+  --width N              Output width for --print (default: $COLUMNS or 80)
+  --plain                With --print, write plain text without escape sequences
+                         (also enabled by setting NO_COLOR)
+  --json                 Write the classification as JSON to stdout instead of
+                         opening the TUI (schema: docs/json-output.md)
+  --json-out FILE        Also write the JSON classification to FILE
+  -w, --ignore-whitespace
+                         Start with whitespace-only changes hidden (toggle
+                         with w in the viewer)
+  --theme NAME           Color theme: auto, a preset (dark, light, high-contrast,
+                         solarized-dark, solarized-light, dracula) or a .toml
+                         or .json theme file. Defaults to $DIFFSTORY_THEME, then
+                         "theme" in ~/.config/diffstory/config.toml, then auto
+                         (light or dark to match the terminal background)
+  --git BACKEND          How git is read: exec (run the git command, the
+                         default) or go-git (in-process, no git needed).
+                         Defaults to $DIFFSTORY_GIT, then "git" in the config
+                         file. Also accepted by review and export
+
+Diff options (also accepted by review and export; they override the [diff]
+section of the config file, and don't apply to - or --patch):
+  --diff-algorithm NAME  myers, minimal, patience or histogram (exec backend
+                         only, except myers)
+  -U, --unified N        Lines of context around changes (default 3)
+  --find-renames N       Similarity in percent for a rename (default 50)
+  --no-renames           Turn off rename detection
+  --find-copies N        Similarity in percent for a copy (exec backend only)
+  --include PATHSPEC     Only diff matching paths; repeat for more
+  --exclude PATHSPEC     Leave out matching paths, such as '*_test.go' or
+                         vendor; repeat for more
+
+Exit codes:
+  0  Success
+  1  Error (bad arguments, git or I/O failure)
+  2  No changes to classify (empty diff or on the base branch)
+  3  Classification failed
 
 Range examples:
   main...feature         Three-dot: changes on feature since diverging from main
@@ -190,9 +343,28 @@ Range examples:
 Examples:
   diffstory                      # Analyze current branch vs base
   diffstory main...feature       # Analyze specific branch comparison
+  diffstory --base part-1        # Analyze a stacked branch against its parent
   diffstory HEAD~3..HEAD         # Analyze last 3 commits
+  diffstory --worktree           # Review an agent's uncommitted changes
+  diffstory --exclude vendor --exclude '*.pb.go'
+  git diff | diffstory - --title "Fix login redirect"
+  diffstory --patch fix.patch    # Analyze a patch file or mailed series
+  diffstory --print | less -R    # Read the story in a pager
+  diffstory --json | jq .summary # Script against the classification
   diffstory replay cases.jsonl   # Replay first case
   diffstory replay cases.jsonl 2 # Replay third case (0-indexed)
+  diffstory review               # Print comments as a Markdown review
+  diffstory review --format github > review.json
+  diffstory export --snippets    # PR description with diff snippets
+  diffstory export --format html > story.html
+  diffstory export --cached      # Use cached classification only (no API key)
+  diffstory export --replay cases.jsonl --index 2
+
+Press ? in the viewer for key bindings and : for the command palette.
+Press { or } in the viewer for more context above or below the focused hunk.
+Press f to show the whole file at the top of the screen, and again to go back.
+Press c in the viewer to comment on the focused hunk.
+Comments are saved to diffstory-comments.jsonl in the current directory.
 `)
 }
 
@@ -200,90 +372,280 @@ func run() error {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	// Check for subcommand or range argument
-	var rangeArg string
+	// Check for subcommand
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
 		case "replay":
 			return runReplay(ctx)
-		case "-h", "--help", "help":
+		case "review":
+			return runReview(ctx, os.Args[2:])
+		case "export":
+			return runExport(ctx, os.Args[2:])
+		case "help":
 			usage()
 			return nil
-		default:
-			// Validate as commit range - provides helpful error for malformed ranges
-			if _, _, err := ParseRange(os.Args[1]); err != nil {
-				return fmt.Errorf("unknown argument %q (use --help for usage)", os.Args[1])
-			}
-			rangeArg = os.Args[1]
 		}
 	}
 
-	// Check for API key
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	if apiKey == "" {
-		return fmt.Errorf("GEMINI_API_KEY environment variable required")
+	flags := flag.NewFlagSet("diffstory", flag.ContinueOnError)
+	flags.Usage = usage
+	printMode := flags.Bool("print", false, "Print the story to stdout instead of opening the TUI")
+	width := flags.Int("width", 0, "Clip --print output to this width (default: lay out at $COLUMNS or 80 without clipping)")
+	plain := flags.Bool("plain", false, "Plain text output for --print")
+	jsonMode := flags.Bool("json", false, "Write the classification as JSON to stdout instead of opening the TUI")
+	jsonOut := flags.String("json-out", "", "Also write the JSON classification to this file")
+	themeName := flags.String("theme", "", "Color theme: preset name or theme file")
+	gitBackend := flags.String("git", "", "Git backend: exec or go-git")
+	var diffFlags diffview.DiffOptions
+	diffFlags.RegisterFlags(flags)
+	var ignoreWhitespace bool
+	flags.BoolVar(&ignoreWhitespace, "ignore-whitespace", false, "Hide whitespace-only changes")
+	flags.BoolVar(&ignoreWhitespace, "w", false, "Hide whitespace-only changes (shorthand)")
+	patchPath := flags.String("patch", "", "Classify the diff or format-patch series in this file instead of git")
+	title := flags.String("title", "", "Title of the change, for --patch or -")
+	message := flags.String("message", "", "Description of the change, for --patch or -")
+	worktree := flags.Bool("worktree", false, "Classify uncommitted changes, including untracked files")
+	staged := flags.Bool("staged", false, "Classify staged changes")
+	all := flags.Bool("all", false, "Classify the branch and its uncommitted changes, including untracked files")
+	baseFlag := flags.String("base", "", "Compare against this branch instead of detecting the base")
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	// A "-" argument reads the diff from stdin, like --patch -. Flags may
+	// follow it, which the flag package would otherwise leave as arguments.
+	args := flags.Args()
+	if len(args) > 0 && args[0] == "-" {
+		if *patchPath != "" {
+			return fmt.Errorf("use either - or --patch, not both")
+		}
+		*patchPath = "-"
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *patchPath != "-" {
+			return fmt.Errorf("use either - or --patch, not both")
+		}
+		args = flags.Args()
+	}
+
+	// Validate range argument - provides helpful error for malformed ranges
+	var rangeArg string
+	if len(args) > 0 {
+		if _, _, err := ParseRange(args[0]); err != nil || *patchPath != "" {
+			return fmt.Errorf("unknown argument %q (use --help for usage)", args[0])
+		}
+		rangeArg = args[0]
+	}
+
+	mode, err := selectMode(*worktree, *staged, *all)
+	if err != nil {
+		return err
+	}
+	if mode != ModeCommitted && (rangeArg != "" || *patchPath != "") {
+		return fmt.Errorf("--%s can't be combined with a range or patch", mode)
+	}
+	if *baseFlag != "" && *patchPath != "" {
+		return fmt.Errorf("--base can't be combined with a patch")
+	}
+
+	// Load the config first so mistakes are reported before classifying
+	cfg, err := fs.LoadConfig(fs.DefaultConfigPath())
+	if err != nil {
+		return err
+	}
+	keymap, err := storyKeyMap(cfg)
+	if err != nil {
+		return err
+	}
+	diffOpts, err := cfg.DiffOptions(diffFlags)
+	if err != nil {
+		return err
+	}
+	gitRunner, err := git.SelectRunner(*gitBackend, cfg, diffOpts)
+	if err != nil {
+		return err
+	}
+
+	var diff *diffview.Diff
+	var classification *diffview.StoryClassification
+	var classInput diffview.ClassificationInput
+	var base diffview.Base
+	if *patchPath != "" {
+		diff, classification, classInput, err = classifyPatch(ctx, *patchPath, *title, *message)
+	} else {
+		if base, err = resolveBase(ctx, gitRunner, *baseFlag, rangeArg, mode); err != nil {
+			return err
+		}
+		diff, classification, classInput, err = classify(ctx, gitRunner, diffOpts, base.Branch, rangeArg, mode, false)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *jsonOut != "" {
+		if err := writeReportFile(*jsonOut, diffview.NewReport(classInput, classification)); err != nil {
+			return err
+		}
+	}
+	if *jsonMode {
+		return writeReport(os.Stdout, diffview.NewReport(classInput, classification))
+	}
+
+	// Whole files give syntax highlighting full context; a patch has no
+	// revisions to load them from
+	var versions map[string]diffview.FileVersions
+	if *patchPath == "" {
+		versions = fileVersions(ctx, gitRunner, base.Branch, rangeArg, mode, diff)
+	}
+
+	if *printMode {
+		return printStory(diff, classification, *themeName, cfg, *width, *plain,
+			bubbletea.WithStoryIgnoreWhitespace(ignoreWhitespace),
+			bubbletea.WithStoryFileVersions(versions),
+			bubbletea.WithStoryBase(base))
 	}
 
-	// Set up git runner and detect repo
-	gitRunner := git.NewRunner()
 	cwd, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("failed to get current directory: %w", err)
 	}
 
-	var baseBranch, currentBranch string
-	if rangeArg == "" {
-		// Branch mode: auto-detect base branch from origin/HEAD
-		baseBranch, err = gitRunner.DefaultBranch(ctx, cwd)
-		if err != nil {
-			return fmt.Errorf("failed to detect base branch: %w", err)
-		}
+	// Set up syntax highlighting
+	theme, err := lipgloss.SelectTheme(*themeName, cfg)
+	if err != nil {
+		return err
+	}
+	detector := chroma.NewDetector()
+	tokenizer, err := chroma.NewTokenizer(chroma.StyleFromPalette(theme.Palette()))
+	if err != nil {
+		return fmt.Errorf("failed to set up syntax highlighting: %w", err)
+	}
+
+	// Curated cases, review comments and exports go to fixed locations in cwd
+	curatedPath := filepath.Join(cwd, "eval-curated.jsonl")
+	commentsPath := filepath.Join(cwd, commentsFile)
+	exportPath := filepath.Join(cwd, "diffstory-story.md")
 
-		// Check if we're on the base branch
+	commentStore := jsonl.NewCommentStore()
+	comments, err := commentStore.Load(commentsPath)
+	if err != nil {
+		return fmt.Errorf("failed to load comments: %w", err)
+	}
+
+	// Launch StoryModel TUI
+	opts := []bubbletea.StoryModelOption{
+		bubbletea.WithStoryTheme(theme),
+		bubbletea.WithStoryLanguageDetector(detector),
+		bubbletea.WithStoryTokenizer(tokenizer),
+		bubbletea.WithStoryWordDiffer(worddiff.NewDiffer()),
+		bubbletea.WithIntroSlide(),
+		bubbletea.WithStoryBase(base),
+		bubbletea.WithStoryInput(classInput),
+		bubbletea.WithStoryCaseSaver(jsonl.NewSaver(), curatedPath),
+		bubbletea.WithStoryCommentStore(commentStore, commentsPath),
+		bubbletea.WithStoryComments(comments),
+		bubbletea.WithStoryExporter(markdown.NewExporter(), exportPath),
+		bubbletea.WithStoryKeyMap(keymap),
+		bubbletea.WithStoryIgnoreWhitespace(ignoreWhitespace),
+		bubbletea.WithStoryFileVersions(versions),
+	}
+	if mode == ModeWorktree {
+		// Hunks of the working tree diff can be staged and discarded; the
+		// diff is reloaded after each action
+		app := &App{GitRunner: gitRunner, RepoPath: cwd, Mode: mode}
+		opts = append(opts, bubbletea.WithStoryHunkApplier(diffview.NewGitHunkApplier(gitRunner, cwd), app.LoadDiff))
+	}
+	m := bubbletea.NewStoryModel(diff, classification, opts...)
+	programOpts := []tea.ProgramOption{
+		tea.WithAltScreen(),
+		tea.WithMouseCellMotion(),
+		tea.WithContext(ctx),
+	}
+	if *patchPath == "-" {
+		// Stdin is the diff, so keys come from the terminal
+		programOpts = append(programOpts, tea.WithInputTTY())
+	}
+	p := tea.NewProgram(m, programOpts...)
+
+	_, err = p.Run()
+	return err
+}
+
+// selectMode returns the mode chosen by the --worktree, --staged and --all
+// flags, at most one of which may be set.
+func selectMode(worktree, staged, all bool) (string, error) {
+	var modes []string
+	if worktree {
+		modes = append(modes, ModeWorktree)
+	}
+	if staged {
+		modes = append(modes, ModeStaged)
+	}
+	if all {
+		modes = append(modes, ModeAll)
+	}
+	switch len(modes) {
+	case 0:
+		return ModeCommitted, nil
+	case 1:
+		return modes[0], nil
+	default:
+		return "", fmt.Errorf("use only one of --worktree, --staged and --all")
+	}
+}
+
+// classify collects the diff for the current branch against baseBranch (or
+// rangeArg, if set, or the uncommitted changes of mode) and classifies it,
+// returning the input used for case saving.
+// The diff options gitRunner was created with are part of the cache key.
+// With cachedOnly, only a previously cached classification is used. An API
+// key is only needed when the classification isn't cached.
+func classify(ctx context.Context, gitRunner diffview.GitRunner, diffOpts diffview.DiffOptions, baseBranch, rangeArg, mode string, cachedOnly bool) (*diffview.Diff, *diffview.StoryClassification, diffview.ClassificationInput, error) {
+	var classInput diffview.ClassificationInput
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, nil, classInput, fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	var currentBranch string
+	if rangeArg == "" {
+		// Check if we're on the base branch; uncommitted changes on it are
+		// still worth classifying
 		currentBranch, err = gitRunner.CurrentBranch(ctx, cwd)
 		if err != nil {
-			return fmt.Errorf("failed to get current branch: %w", err)
+			return nil, nil, classInput, fmt.Errorf("failed to get current branch: %w", err)
 		}
-		if currentBranch == baseBranch {
-			return ErrOnBaseBranch
+		if mode == ModeCommitted && currentBranch == baseBranch {
+			return nil, nil, classInput, ErrOnBaseBranch
 		}
 	}
 
-	// Set up Gemini client and classifier
-	client, err := gemini.NewClient(ctx, apiKey)
+	classifier, closeClassifier, err := newClassifier(ctx, os.Getenv("GEMINI_API_KEY"), cachedOnly, diffOpts)
 	if err != nil {
-		return fmt.Errorf("failed to create Gemini client: %w", err)
+		return nil, nil, classInput, err
 	}
-	defer client.Close()
-
-	geminiClassifier := gemini.NewClassifier(client, gemini.DefaultModel,
-		gemini.WithValidationRetry(2)) // Retry once if LLM returns invalid hunk references
-	classifier := fs.NewClassifier(geminiClassifier, fs.DefaultCacheDir())
+	defer closeClassifier()
 
 	app := &App{
 		GitRunner:  gitRunner,
 		RepoPath:   cwd,
 		BaseBranch: baseBranch,
 		Range:      rangeArg,
+		Mode:       mode,
 		Classifier: classifier,
 	}
 
-	// Show spinner while processing (only if stderr is a terminal)
-	var spin *spinner
-	if isTerminal(os.Stderr) {
-		spin = newSpinner(os.Stderr, "Classifying diff...")
-		spin.Start()
-	}
-
+	stop := startSpinner()
 	diff, classification, err := app.Run(ctx)
-
-	// Stop spinner before TUI or error output
-	if spin != nil {
-		spin.Stop()
-	}
+	stop()
 
 	if err != nil {
-		return err
+		return nil, nil, classInput, err
 	}
 
 	// Get commits for ClassificationInput
@@ -297,48 +659,370 @@ func run() error {
 		}
 		branchName = rangeArg // Use range as "branch" name for context
 	} else {
-		// Branch mode: use baseBranch...HEAD
-		commits, _ = gitRunner.CommitsInRange(ctx, cwd, baseBranch, "HEAD")
+		// Branch mode: use baseBranch...HEAD, plus uncommitted changes in
+		// ModeAll. Worktree and staged changes have no commits.
+		if mode == ModeCommitted || mode == ModeAll {
+			commits, _ = gitRunner.CommitsInRange(ctx, cwd, baseBranch, "HEAD")
+		}
 		branchName = currentBranch
+		if mode != ModeCommitted {
+			branchName = fmt.Sprintf("%s (%s)", currentBranch, mode)
+		}
 	}
 
 	// Build ClassificationInput for case saving
-	classInput := diffview.ClassificationInput{
+	classInput = diffview.ClassificationInput{
 		Repo:    filepath.Base(cwd),
 		Branch:  branchName,
 		Commits: commits,
 		Diff:    *diff,
 	}
 
-	// Set up syntax highlighting
-	theme := lipgloss.DefaultTheme()
-	detector := chroma.NewDetector()
+	return diff, classification, classInput, nil
+}
+
+// classifyPatch classifies the diff or format-patch series at path, or on
+// stdin for "-", with an optional title and message as PR context.
+func classifyPatch(ctx context.Context, path, title, message string) (*diffview.Diff, *diffview.StoryClassification, diffview.ClassificationInput, error) {
+	var classInput diffview.ClassificationInput
+
+	input, name := io.Reader(os.Stdin), "stdin"
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, nil, classInput, fmt.Errorf("failed to open patch: %w", err)
+		}
+		defer f.Close()
+		input, name = f, filepath.Base(path)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, nil, classInput, fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	classifier, closeClassifier, err := newClassifier(ctx, os.Getenv("GEMINI_API_KEY"), false, diffview.DiffOptions{})
+	if err != nil {
+		return nil, nil, classInput, err
+	}
+	defer closeClassifier()
+
+	parser := gitdiff.NewParser()
+	app := &PatchApp{
+		Input:      input,
+		Parser:     diffview.NewAutoParser(parser, unidiff.NewParser(), parser),
+		Repo:       filepath.Base(cwd),
+		Name:       name,
+		Title:      title,
+		Message:    message,
+		Classifier: classifier,
+	}
+
+	stop := startSpinner()
+	diff, classification, classInput, err := app.Run(ctx)
+	stop()
+	return diff, classification, classInput, err
+}
+
+// resolveBase returns the branch the current branch is compared against:
+// flagBase if set, otherwise the detected one. Ranges and the modes that
+// compare against HEAD have no base.
+func resolveBase(ctx context.Context, gitRunner diffview.GitRunner, flagBase, rangeArg, mode string) (diffview.Base, error) {
+	if rangeArg != "" || (mode != ModeCommitted && mode != ModeAll) {
+		if flagBase != "" {
+			return diffview.Base{}, fmt.Errorf("--base can't be combined with a range, --worktree or --staged")
+		}
+		return diffview.Base{}, nil
+	}
+	if flagBase != "" {
+		return diffview.Base{Branch: flagBase, Reason: "--base"}, nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return diffview.Base{}, fmt.Errorf("failed to get current directory: %w", err)
+	}
+	base, err := diffview.ResolveBase(ctx, gitRunner, cwd)
+	if err != nil {
+		return base, fmt.Errorf("failed to detect base branch: %w (set one with --base)", err)
+	}
+	return base, nil
+}
+
+// newClassifier returns the cached Gemini classifier for diffs generated
+// with diffOpts and a function that releases its client. With cachedOnly,
+// only cached classifications are returned and no client is created, and
+// without apiKey, a classification that isn't cached fails with ErrNoAPIKey.
+func newClassifier(ctx context.Context, apiKey string, cachedOnly bool, diffOpts diffview.DiffOptions) (diffview.StoryClassifier, func(), error) {
+	var inner diffview.StoryClassifier = cacheOnlyClassifier{}
+	closeClient := func() {}
+	if !cachedOnly && apiKey == "" {
+		inner = noAPIKeyClassifier{}
+	}
+	if !cachedOnly && apiKey != "" {
+		client, err := gemini.NewClient(ctx, apiKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create Gemini client: %w", err)
+		}
+		closeClient = func() { client.Close() }
+
+		inner = gemini.NewClassifier(client, gemini.DefaultModel,
+			gemini.WithValidationRetry(2)) // Retry once if LLM returns invalid hunk references
+
+		// Whitespace-only hunks are classified as noise without the LLM
+		inner = diffview.NewWhitespaceClassifier(inner)
+	}
+	return fs.NewClassifier(inner, fs.DefaultCacheDir(), fs.WithDiffOptions(diffOpts)), closeClient, nil
+}
+
+// startSpinner shows a spinner while classifying, if stderr is a terminal,
+// and returns a function that stops it before TUI or error output.
+func startSpinner() func() {
+	if !isTerminal(os.Stderr) {
+		return func() {}
+	}
+	spin := newSpinner(os.Stderr, "Classifying diff...")
+	spin.Start()
+	return spin.Stop
+}
+
+// fileVersions loads the full old and new content of the diff's files from
+// git, and the new content from the working tree in the modes that diff
+// against it. It only improves syntax highlighting, so failures return nil
+// and the viewer tokenizes hunks on their own.
+func fileVersions(ctx context.Context, gitRunner diffview.GitRunner, baseBranch, rangeArg, mode string, diff *diffview.Diff) map[string]diffview.FileVersions {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	app := &App{GitRunner: gitRunner, RepoPath: cwd, BaseBranch: baseBranch, Range: rangeArg, Mode: mode}
+	oldRev, newRev, err := app.Revisions(ctx)
+	if err != nil {
+		return nil
+	}
+	if mode != ModeWorktree && mode != ModeAll {
+		versions, _ := diffview.LoadFileVersions(ctx, gitRunner, cwd, oldRev, newRev, diff)
+		return versions
+	}
+
+	// The new side is the working tree, so only the old side comes from git
+	versions, _ := diffview.LoadOldFileVersions(ctx, gitRunner, cwd, oldRev, diff)
+	worktree, err := fs.NewWorkingTree(cwd).FileVersions(ctx, diff)
+	if err != nil {
+		return nil
+	}
+	if versions == nil {
+		versions = make(map[string]diffview.FileVersions)
+	}
+	for path, v := range worktree {
+		current := versions[path]
+		current.New = v.New
+		versions[path] = current
+	}
+	return versions
+}
+
+// printStory writes the story to stdout for pipelines and pagers.
+func printStory(diff *diffview.Diff, story *diffview.StoryClassification, themeName string, cfg diffview.Config, width int, plain bool, opts ...bubbletea.StoryModelOption) error {
+	plain = plain || os.Getenv("NO_COLOR") != ""
+	theme, err := lipgloss.SelectPrintTheme(themeName, cfg)
+	if err != nil {
+		return err
+	}
 	tokenizer, err := chroma.NewTokenizer(chroma.StyleFromPalette(theme.Palette()))
 	if err != nil {
 		return fmt.Errorf("failed to set up syntax highlighting: %w", err)
 	}
 
-	// Curated cases go to a fixed location in cwd
-	curatedPath := filepath.Join(cwd, "eval-curated.jsonl")
-
-	// Launch StoryModel TUI
-	m := bubbletea.NewStoryModel(diff, classification,
+	opts = append([]bubbletea.StoryModelOption{
+		bubbletea.WithStoryRenderer(bubbletea.NewPrintRenderer(os.Stdout, plain)),
 		bubbletea.WithStoryTheme(theme),
-		bubbletea.WithStoryLanguageDetector(detector),
+		bubbletea.WithStoryLanguageDetector(chroma.NewDetector()),
 		bubbletea.WithStoryTokenizer(tokenizer),
 		bubbletea.WithStoryWordDiffer(worddiff.NewDiffer()),
-		bubbletea.WithIntroSlide(),
-		bubbletea.WithStoryInput(classInput),
-		bubbletea.WithStoryCaseSaver(jsonl.NewSaver(), curatedPath),
-	)
-	p := tea.NewProgram(m,
-		tea.WithAltScreen(),
-		tea.WithMouseCellMotion(),
-		tea.WithContext(ctx),
-	)
+	}, opts...)
+	return bubbletea.PrintStory(os.Stdout, diff, story, width, opts...)
+}
 
-	_, err = p.Run()
-	return err
+// writeReport encodes report as indented JSON.
+func writeReport(w io.Writer, report diffview.Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
+
+// writeReportFile writes report as JSON to path, replacing any existing file.
+func writeReportFile(path string, report diffview.Report) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := writeReport(f, report); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// storyKeyMap returns the story viewer key bindings with config overrides.
+func storyKeyMap(cfg diffview.Config) (bubbletea.StoryKeyMap, error) {
+	km, err := bubbletea.DefaultStoryKeyMap().Override(cfg.Keys.Story)
+	if err != nil {
+		return km, fmt.Errorf("config [keys.story]: %w", err)
+	}
+	return km, nil
+}
+
+func runReview(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("review", flag.ExitOnError)
+	format := flags.String("format", FormatMarkdown, "Output format: markdown or github")
+	gitBackend := flags.String("git", "", "Git backend: exec or go-git")
+	baseFlag := flags.String("base", "", "Compare against this branch instead of detecting the base")
+	var diffFlags diffview.DiffOptions
+	diffFlags.RegisterFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	// Fail before classifying, which may call the API
+	if err := validateFormat(*format, FormatMarkdown, FormatGitHub); err != nil {
+		return err
+	}
+	cfg, err := fs.LoadConfig(fs.DefaultConfigPath())
+	if err != nil {
+		return err
+	}
+	diffOpts, err := cfg.DiffOptions(diffFlags)
+	if err != nil {
+		return err
+	}
+	gitRunner, err := git.SelectRunner(*gitBackend, cfg, diffOpts)
+	if err != nil {
+		return err
+	}
+
+	var rangeArg string
+	if flags.NArg() > 0 {
+		if _, _, err := ParseRange(flags.Arg(0)); err != nil {
+			return fmt.Errorf("unknown argument %q (use --help for usage)", flags.Arg(0))
+		}
+		rangeArg = flags.Arg(0)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	app := &ReviewApp{
+		Store:  jsonl.NewCommentStore(),
+		Path:   filepath.Join(cwd, commentsFile),
+		Format: *format,
+		Output: os.Stdout,
+	}
+
+	base, err := resolveBase(ctx, gitRunner, *baseFlag, rangeArg, ModeCommitted)
+	if err != nil {
+		return err
+	}
+
+	// Re-classify so comments can be grouped by section (cached after the first run)
+	diff, classification, _, err := classify(ctx, gitRunner, diffOpts, base.Branch, rangeArg, ModeCommitted, false)
+	if err != nil {
+		return err
+	}
+
+	return app.Run(diff, classification)
+}
+
+func runExport(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", FormatMarkdown, "Output format: markdown or html")
+	snippets := flags.Bool("snippets", false, "Include fenced diff snippets for expanded hunks")
+	cached := flags.Bool("cached", false, "Only use a cached classification (no API call)")
+	replay := flags.String("replay", "", "Export a saved eval case from this JSONL file instead")
+	index := flags.Int("index", 0, "Case index (0-based) when using --replay")
+	themeName := flags.String("theme", "", "Color theme for --format html: preset name or theme file")
+	gitBackend := flags.String("git", "", "Git backend: exec or go-git")
+	baseFlag := flags.String("base", "", "Compare against this branch instead of detecting the base")
+	var diffFlags diffview.DiffOptions
+	diffFlags.RegisterFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := validateFormat(*format, FormatMarkdown, FormatHTML); err != nil {
+		return err
+	}
+
+	cfg, err := fs.LoadConfig(fs.DefaultConfigPath())
+	if err != nil {
+		return err
+	}
+	diffOpts, err := cfg.DiffOptions(diffFlags)
+	if err != nil {
+		return err
+	}
+	gitRunner, err := git.SelectRunner(*gitBackend, cfg, diffOpts)
+	if err != nil {
+		return err
+	}
+
+	app := &ExportApp{
+		Format:   *format,
+		Snippets: *snippets,
+		Output:   os.Stdout,
+	}
+	// Only the HTML export is themed
+	if *format == FormatHTML {
+		theme, err := lipgloss.SelectPrintTheme(*themeName, cfg)
+		if err != nil {
+			return err
+		}
+		tokenizer, err := chroma.NewTokenizer(chroma.StyleFromPalette(theme.Palette()))
+		if err != nil {
+			return fmt.Errorf("failed to set up syntax highlighting: %w", err)
+		}
+		app.HTML = html.NewRenderer(theme,
+			html.WithLanguageDetector(chroma.NewDetector()),
+			html.WithTokenizer(tokenizer),
+			html.WithWordDiffer(worddiff.NewDiffer()),
+		)
+	}
+
+	if *replay != "" {
+		replayApp := &ReplayApp{
+			Loader:   jsonl.NewLoader(),
+			FilePath: *replay,
+			Index:    *index,
+		}
+		diff, story, err := replayApp.Run()
+		if err != nil {
+			return err
+		}
+		return app.Run(diff, story)
+	}
+
+	var rangeArg string
+	if flags.NArg() > 0 {
+		if _, _, err := ParseRange(flags.Arg(0)); err != nil {
+			return fmt.Errorf("unknown argument %q (use --help for usage)", flags.Arg(0))
+		}
+		rangeArg = flags.Arg(0)
+	}
+
+	base, err := resolveBase(ctx, gitRunner, *baseFlag, rangeArg, ModeCommitted)
+	if err != nil {
+		return err
+	}
+	diff, classification, _, err := classify(ctx, gitRunner, diffOpts, base.Branch, rangeArg, ModeCommitted, *cached)
+	if err != nil {
+		return err
+	}
+
+	return app.Run(diff, classification)
 }
 
 func runReplay(ctx context.Context) error {
@@ -361,13 +1045,25 @@ func runReplay(ctx context.Context) error {
 		Index:    index,
 	}
 
+	cfg, err := fs.LoadConfig(fs.DefaultConfigPath())
+	if err != nil {
+		return err
+	}
+	keymap, err := storyKeyMap(cfg)
+	if err != nil {
+		return err
+	}
+
 	diff, story, err := app.Run()
 	if err != nil {
 		return err
 	}
 
 	// Set up syntax highlighting
-	theme := lipgloss.DefaultTheme()
+	theme, err := lipgloss.SelectTheme("", cfg)
+	if err != nil {
+		return err
+	}
 	detector := chroma.NewDetector()
 	tokenizer, err := chroma.NewTokenizer(chroma.StyleFromPalette(theme.Palette()))
 	if err != nil {
@@ -381,6 +1077,7 @@ func runReplay(ctx context.Context) error {
 		bubbletea.WithStoryTokenizer(tokenizer),
 		bubbletea.WithStoryWordDiffer(worddiff.NewDiffer()),
 		bubbletea.WithIntroSlide(),
+		bubbletea.WithStoryKeyMap(keymap),
 	)
 	p := tea.NewProgram(m,
 		tea.WithAltScreen(),
@@ -3,6 +3,7 @@ package main_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/fwojciec/diffstory"
@@ -131,6 +132,32 @@ new file mode 100644
 	_, _, err := app.Run(context.Background())
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "API error")
+	assert.ErrorIs(t, err, main.ErrClassificationFailed)
+}
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", err: nil, want: main.ExitOK},
+		{name: "no changes", err: main.ErrNoChanges, want: main.ExitNoChanges},
+		{name: "on base branch", err: main.ErrOnBaseBranch, want: main.ExitNoChanges},
+		{name: "classification failed", err: fmt.Errorf("%w: %w", main.ErrClassificationFailed, errors.New("API error")), want: main.ExitClassificationFailed},
+		{name: "missing API key", err: fmt.Errorf("%w: %w", main.ErrClassificationFailed, main.ErrNoAPIKey), want: main.ExitError},
+		{name: "other error", err: errors.New("git diff failed"), want: main.ExitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, main.ExitCode(tt.err))
+		})
+	}
 }
 
 func TestApp_Run_PassesDiffToClassifier(t *testing.T) {
@@ -286,3 +313,153 @@ func TestParseRange(t *testing.T) {
 		})
 	}
 }
+
+func TestApp_Revisions(t *testing.T) {
+	t.Parallel()
+
+	mergeBase := func(_ context.Context, repoPath, ref1, ref2 string) (string, error) {
+		assert.Equal(t, "/repo", repoPath)
+		return "base(" + ref1 + "," + ref2 + ")", nil
+	}
+
+	tests := []struct {
+		name       string
+		baseBranch string
+		rangeSpec  string
+		mode       string
+		wantOld    string
+		wantNew    string
+	}{
+		{name: "branch mode uses merge base with HEAD", baseBranch: "main", wantOld: "base(main,HEAD)", wantNew: "HEAD"},
+		{name: "three-dot range uses merge base", rangeSpec: "main...feature", wantOld: "base(main,feature)", wantNew: "feature"},
+		{name: "two-dot range uses both ends", rangeSpec: "HEAD~3..HEAD", wantOld: "HEAD~3", wantNew: "HEAD"},
+		{name: "worktree mode compares HEAD to the working tree", mode: main.ModeWorktree, wantOld: "HEAD"},
+		{name: "staged mode compares HEAD to the index", mode: main.ModeStaged, wantOld: "HEAD"},
+		{name: "all mode uses merge base with the working tree", baseBranch: "main", mode: main.ModeAll, wantOld: "base(main,HEAD)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := &main.App{
+				GitRunner:  &mock.GitRunner{MergeBaseFn: mergeBase},
+				RepoPath:   "/repo",
+				BaseBranch: tt.baseBranch,
+				Range:      tt.rangeSpec,
+				Mode:       tt.mode,
+			}
+
+			oldRev, newRev, err := app.Revisions(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOld, oldRev)
+			assert.Equal(t, tt.wantNew, newRev)
+		})
+	}
+}
+
+func TestApp_Run_UncommittedModes(t *testing.T) {
+	t.Parallel()
+
+	diffText := `diff --git a/agent.go b/agent.go
+new file mode 100644
+--- /dev/null
++++ b/agent.go
+@@ -0,0 +1 @@
++package main
+`
+	classifier := &mock.StoryClassifier{
+		ClassifyFn: func(context.Context, diffview.ClassificationInput) (*diffview.StoryClassification, error) {
+			return &diffview.StoryClassification{ChangeType: "feature"}, nil
+		},
+	}
+
+	t.Run("worktree diffs HEAD against the working tree", func(t *testing.T) {
+		t.Parallel()
+
+		app := &main.App{
+			GitRunner: &mock.GitRunner{
+				WorktreeDiffFn: func(_ context.Context, repoPath, rev string) (string, error) {
+					assert.Equal(t, "/repo", repoPath)
+					assert.Equal(t, "HEAD", rev)
+					return diffText, nil
+				},
+			},
+			RepoPath:   "/repo",
+			Mode:       main.ModeWorktree,
+			Classifier: classifier,
+		}
+
+		diff, _, err := app.Run(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, "agent.go", diff.Files[0].NewPath)
+	})
+
+	t.Run("staged diffs the index", func(t *testing.T) {
+		t.Parallel()
+
+		app := &main.App{
+			GitRunner: &mock.GitRunner{
+				StagedDiffFn: func(context.Context, string) (string, error) {
+					return diffText, nil
+				},
+			},
+			RepoPath:   "/repo",
+			Mode:       main.ModeStaged,
+			Classifier: classifier,
+		}
+
+		diff, _, err := app.Run(context.Background())
+
+		require.NoError(t, err)
+		assert.Len(t, diff.Files, 1)
+	})
+
+	t.Run("all diffs the merge base against the working tree", func(t *testing.T) {
+		t.Parallel()
+
+		app := &main.App{
+			GitRunner: &mock.GitRunner{
+				MergeBaseFn: func(_ context.Context, _, ref1, ref2 string) (string, error) {
+					assert.Equal(t, "main", ref1)
+					assert.Equal(t, "HEAD", ref2)
+					return "abc123", nil
+				},
+				WorktreeDiffFn: func(_ context.Context, _, rev string) (string, error) {
+					assert.Equal(t, "abc123", rev)
+					return diffText, nil
+				},
+			},
+			RepoPath:   "/repo",
+			BaseBranch: "main",
+			Mode:       main.ModeAll,
+			Classifier: classifier,
+		}
+
+		diff, _, err := app.Run(context.Background())
+
+		require.NoError(t, err)
+		assert.Len(t, diff.Files, 1)
+	})
+
+	t.Run("no uncommitted changes", func(t *testing.T) {
+		t.Parallel()
+
+		app := &main.App{
+			GitRunner: &mock.GitRunner{
+				WorktreeDiffFn: func(context.Context, string, string) (string, error) {
+					return "", nil
+				},
+			},
+			RepoPath:   "/repo",
+			Mode:       main.ModeWorktree,
+			Classifier: classifier,
+		}
+
+		_, _, err := app.Run(context.Background())
+
+		require.ErrorIs(t, err, main.ErrNoChanges)
+	})
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/fwojciec/diffstory"
+)
+
+// PatchApp classifies a diff read from stdin or a file instead of git, such
+// as an ad hoc patch from an agent or a patch series from a mailing list.
+type PatchApp struct {
+	Input      io.Reader                // Diff or format-patch mbox content
+	Parser     diffview.SeriesParser    // Parser for the input, in any supported format
+	Repo       string                   // Repository name for context
+	Name       string                   // Where the patch came from, used as the branch name
+	Title      string                   // Optional title, sent as the PR title
+	Message    string                   // Optional message, sent as the PR description
+	Classifier diffview.StoryClassifier // Classifier for story generation
+}
+
+// Run parses the patch and classifies it. Returns the parsed diff, the
+// classification and the input used, for case saving.
+func (a *PatchApp) Run(ctx context.Context) (*diffview.Diff, *diffview.StoryClassification, diffview.ClassificationInput, error) {
+	var classInput diffview.ClassificationInput
+
+	commits, err := a.Parser.ParseSeries(a.Input)
+	if err != nil {
+		return nil, nil, classInput, fmt.Errorf("failed to parse patch: %w", err)
+	}
+	diff := diffview.SeriesDiff(commits)
+	if len(diff.Files) == 0 {
+		return nil, nil, classInput, ErrNoChanges
+	}
+
+	classInput = diffview.ClassificationInput{
+		Repo:          a.Repo,
+		Branch:        a.Name,
+		PRTitle:       a.Title,
+		PRDescription: a.Message,
+		Commits:       describedCommits(commits),
+		Diff:          *diff,
+	}
+
+	classification, err := a.Classifier.Classify(ctx, classInput)
+	if err != nil {
+		return nil, nil, classInput, fmt.Errorf("%w: %w", ErrClassificationFailed, err)
+	}
+
+	return diff, classification, classInput, nil
+}
+
+// describedCommits returns the commits that carry a hash or message. A
+// plain diff parses as a single commit without either, which would only add
+// an empty entry to the prompt.
+func describedCommits(commits []diffview.CommitBrief) []diffview.CommitBrief {
+	var described []diffview.CommitBrief
+	for _, c := range commits {
+		if c.Hash != "" || c.Message != "" {
+			described = append(described, c)
+		}
+	}
+	return described
+}
@@ -0,0 +1,148 @@
+package main_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	main "github.com/fwojciec/diffstory/cmd/diffstory"
+	"github.com/fwojciec/diffstory/gitdiff"
+	"github.com/fwojciec/diffstory/mock"
+	"github.com/fwojciec/diffstory/unidiff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPatchParser() diffview.SeriesParser {
+	return diffview.NewAutoParser(gitdiff.NewParser(), unidiff.NewParser(), gitdiff.NewParser())
+}
+
+func TestPatchApp_Run_PlainDiff(t *testing.T) {
+	t.Parallel()
+
+	input := `--- a/login.go
++++ b/login.go
+@@ -1 +1 @@
+-redirect("/")
++redirect(next)
+`
+
+	var got diffview.ClassificationInput
+	app := &main.PatchApp{
+		Input:   strings.NewReader(input),
+		Parser:  newPatchParser(),
+		Repo:    "app",
+		Name:    "stdin",
+		Title:   "Fix login redirect",
+		Message: "Return to the page that asked for the login.",
+		Classifier: &mock.StoryClassifier{
+			ClassifyFn: func(_ context.Context, input diffview.ClassificationInput) (*diffview.StoryClassification, error) {
+				got = input
+				return &diffview.StoryClassification{ChangeType: "bugfix"}, nil
+			},
+		},
+	}
+
+	diff, classification, classInput, err := app.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "bugfix", classification.ChangeType)
+	require.Len(t, diff.Files, 1)
+	assert.Equal(t, "login.go", diff.Files[0].NewPath)
+	assert.Equal(t, got, classInput)
+	assert.Equal(t, "app", got.Repo)
+	assert.Equal(t, "stdin", got.Branch)
+	assert.Equal(t, "Fix login redirect", got.PRTitle)
+	assert.Equal(t, "Return to the page that asked for the login.", got.PRDescription)
+	assert.Empty(t, got.Commits, "a plain diff has no commits")
+}
+
+func TestPatchApp_Run_Series(t *testing.T) {
+	t.Parallel()
+
+	input := `From 1111111111111111111111111111111111111111 Mon Sep 17 00:00:00 2001
+From: Ada Lovelace <ada@example.com>
+Subject: [PATCH 1/2] Add greeting
+
+diff --git a/g.txt b/g.txt
+new file mode 100644
+--- /dev/null
++++ b/g.txt
+@@ -0,0 +1 @@
++hello
+-- 
+2.39.5
+
+From 2222222222222222222222222222222222222222 Mon Sep 17 00:00:00 2001
+From: Ada Lovelace <ada@example.com>
+Subject: [PATCH 2/2] Say goodbye
+
+diff --git a/g.txt b/g.txt
+--- a/g.txt
++++ b/g.txt
+@@ -1 +1,2 @@
+ hello
++goodbye
+-- 
+2.39.5
+`
+
+	app := &main.PatchApp{
+		Input:  strings.NewReader(input),
+		Parser: newPatchParser(),
+		Classifier: &mock.StoryClassifier{
+			ClassifyFn: func(context.Context, diffview.ClassificationInput) (*diffview.StoryClassification, error) {
+				return &diffview.StoryClassification{}, nil
+			},
+		},
+	}
+
+	diff, _, classInput, err := app.Run(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, diff.Files, 1)
+	assert.Equal(t, diffview.FileAdded, diff.Files[0].Operation)
+	require.Len(t, diff.Files[0].Hunks, 1, "the patches are composed")
+	hunk := diff.Files[0].Hunks[0]
+	assert.Equal(t, "@@ -0,0 +1,2 @@", hunk.Range())
+	require.Len(t, hunk.Lines, 2)
+	assert.Equal(t, "goodbye\n", hunk.Lines[1].Content)
+	require.Len(t, classInput.Commits, 2)
+	assert.Equal(t, "Add greeting", classInput.Commits[0].Message)
+	assert.Equal(t, "Ada Lovelace <ada@example.com>", classInput.Commits[0].Author)
+	assert.Equal(t, "Say goodbye", classInput.Commits[1].Message)
+}
+
+func TestPatchApp_Run_EmptyInput(t *testing.T) {
+	t.Parallel()
+
+	app := &main.PatchApp{
+		Input:      strings.NewReader(""),
+		Parser:     newPatchParser(),
+		Classifier: &mock.StoryClassifier{},
+	}
+
+	_, _, _, err := app.Run(context.Background())
+
+	require.ErrorIs(t, err, main.ErrNoChanges)
+}
+
+func TestPatchApp_Run_ClassifierError(t *testing.T) {
+	t.Parallel()
+
+	app := &main.PatchApp{
+		Input:  strings.NewReader("--- a/x\n+++ b/x\n@@ -1 +1 @@\n-a\n+b\n"),
+		Parser: newPatchParser(),
+		Classifier: &mock.StoryClassifier{
+			ClassifyFn: func(context.Context, diffview.ClassificationInput) (*diffview.StoryClassification, error) {
+				return nil, errors.New("quota exceeded")
+			},
+		},
+	}
+
+	_, _, _, err := app.Run(context.Background())
+
+	require.ErrorIs(t, err, main.ErrClassificationFailed)
+}
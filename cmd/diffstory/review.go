@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/github"
+	"github.com/fwojciec/diffstory/markdown"
+)
+
+// commentsFile is the sidecar file review comments are stored in, relative to cwd.
+const commentsFile = "diffstory-comments.jsonl"
+
+// Export formats.
+const (
+	FormatMarkdown = "markdown"
+	FormatGitHub   = "github"
+	FormatHTML     = "html"
+)
+
+// ErrUnknownFormat is returned when the requested export format is not supported.
+var ErrUnknownFormat = errors.New("unknown format")
+
+// ReviewApp exports review comments written in the viewer.
+type ReviewApp struct {
+	Store  diffview.CommentStore // Store for the comments sidecar file
+	Path   string                // Path to the comments sidecar file
+	Format string                // FormatMarkdown or FormatGitHub
+	Output io.Writer             // Destination for the exported review
+}
+
+// Run loads the comments and writes them as a review of diff and story.
+func (a *ReviewApp) Run(diff *diffview.Diff, story *diffview.StoryClassification) error {
+	if err := validateFormat(a.Format, FormatMarkdown, FormatGitHub); err != nil {
+		return err
+	}
+
+	comments, err := a.Store.Load(a.Path)
+	if err != nil {
+		return fmt.Errorf("failed to load comments: %w", err)
+	}
+
+	if a.Format == FormatGitHub {
+		var summary string
+		if story != nil {
+			summary = story.Summary
+		}
+		enc := json.NewEncoder(a.Output)
+		enc.SetIndent("", "  ")
+		return enc.Encode(github.NewReview(summary, comments))
+	}
+
+	_, err = io.WriteString(a.Output, markdown.FormatReview(diff, story, comments))
+	return err
+}
+
+// validateFormat returns ErrUnknownFormat if format is not one of supported.
+func validateFormat(format string, supported ...string) error {
+	if slices.Contains(supported, format) {
+		return nil
+	}
+	return fmt.Errorf("%w %q (use %s)", ErrUnknownFormat, format, strings.Join(supported, " or "))
+}
@@ -0,0 +1,123 @@
+package main_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	main "github.com/fwojciec/diffstory/cmd/diffstory"
+	"github.com/fwojciec/diffstory/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reviewFixture() (*diffview.Diff, *diffview.StoryClassification, []diffview.Comment) {
+	diff := &diffview.Diff{
+		Files: []diffview.FileDiff{
+			{
+				NewPath:   "main.go",
+				Operation: diffview.FileModified,
+				Hunks: []diffview.Hunk{
+					{Lines: []diffview.Line{
+						{Type: diffview.LineAdded, Content: "func run() {}", NewLineNum: 5},
+					}},
+				},
+			},
+		},
+	}
+	story := &diffview.StoryClassification{
+		Summary: "Add run",
+		Sections: []diffview.Section{
+			{Title: "Entry point", Hunks: []diffview.HunkRef{{File: "main.go", HunkIndex: 0}}},
+		},
+	}
+	comments := []diffview.Comment{
+		{File: "main.go", Side: diffview.SideNew, Line: 5, Body: "Return an error?"},
+	}
+	return diff, story, comments
+}
+
+func commentStore(t *testing.T, comments []diffview.Comment) *mock.CommentStore {
+	t.Helper()
+	return &mock.CommentStore{
+		LoadFn: func(path string) ([]diffview.Comment, error) {
+			assert.Equal(t, "comments.jsonl", path)
+			return comments, nil
+		},
+	}
+}
+
+func TestReviewApp_Run_Markdown(t *testing.T) {
+	t.Parallel()
+
+	diff, story, comments := reviewFixture()
+	var out bytes.Buffer
+	app := &main.ReviewApp{
+		Store:  commentStore(t, comments),
+		Path:   "comments.jsonl",
+		Format: main.FormatMarkdown,
+		Output: &out,
+	}
+
+	require.NoError(t, app.Run(diff, story))
+
+	assert.Contains(t, out.String(), "## 1. Entry point")
+	assert.Contains(t, out.String(), "Return an error?")
+}
+
+func TestReviewApp_Run_GitHub(t *testing.T) {
+	t.Parallel()
+
+	diff, story, comments := reviewFixture()
+	var out bytes.Buffer
+	app := &main.ReviewApp{
+		Store:  commentStore(t, comments),
+		Path:   "comments.jsonl",
+		Format: main.FormatGitHub,
+		Output: &out,
+	}
+
+	require.NoError(t, app.Run(diff, story))
+
+	assert.JSONEq(t, `{
+		"body": "Add run",
+		"event": "COMMENT",
+		"comments": [{"path": "main.go", "line": 5, "side": "RIGHT", "body": "Return an error?"}]
+	}`, out.String())
+}
+
+func TestReviewApp_Run_UnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	diff, story, _ := reviewFixture()
+	app := &main.ReviewApp{
+		Store:  &mock.CommentStore{},
+		Format: "pdf",
+		Output: &bytes.Buffer{},
+	}
+
+	err := app.Run(diff, story)
+
+	require.ErrorIs(t, err, main.ErrUnknownFormat)
+}
+
+func TestReviewApp_Run_LoadError(t *testing.T) {
+	t.Parallel()
+
+	diff, story, _ := reviewFixture()
+	loadErr := errors.New("disk full")
+	app := &main.ReviewApp{
+		Store: &mock.CommentStore{
+			LoadFn: func(string) ([]diffview.Comment, error) {
+				return nil, loadErr
+			},
+		},
+		Format: main.FormatMarkdown,
+		Output: &bytes.Buffer{},
+	}
+
+	err := app.Run(diff, story)
+
+	require.ErrorIs(t, err, loadErr)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
@@ -12,8 +13,11 @@ import (
 	"github.com/fwojciec/diffstory"
 	"github.com/fwojciec/diffstory/bubbletea"
 	"github.com/fwojciec/diffstory/chroma"
+	"github.com/fwojciec/diffstory/fs"
 	"github.com/fwojciec/diffstory/gitdiff"
+	"github.com/fwojciec/diffstory/jsonl"
 	"github.com/fwojciec/diffstory/lipgloss"
+	"github.com/fwojciec/diffstory/unidiff"
 	"github.com/fwojciec/diffstory/worddiff"
 )
 
@@ -25,6 +29,9 @@ type App struct {
 	Stdin  io.Reader
 	Parser diffview.Parser
 	Viewer diffview.Viewer
+
+	// Paths leaves out the files its include and exclude pathspecs reject.
+	Paths diffview.DiffOptions
 }
 
 // Run parses stdin and displays the diff.
@@ -33,6 +40,7 @@ func (a *App) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	diff = a.Paths.FilterFiles(diff)
 	if len(diff.Files) == 0 {
 		return ErrNoChanges
 	}
@@ -40,6 +48,26 @@ func (a *App) Run(ctx context.Context) error {
 }
 
 func main() {
+	comments := flag.String("comments", "", "JSONL file to load and save review comments (press c to comment)")
+	printMode := flag.Bool("print", false, "Print the diff to stdout instead of opening the TUI")
+	width := flag.Int("width", 0, "Clip --print output to this width (default: lay out at $COLUMNS or 80 without clipping)")
+	plain := flag.Bool("plain", false, "With --print, write plain text without escape sequences (also set by NO_COLOR)")
+	var ignoreWhitespace bool
+	flag.BoolVar(&ignoreWhitespace, "ignore-whitespace", false, "Hide whitespace-only changes (toggle with w in the viewer)")
+	flag.BoolVar(&ignoreWhitespace, "w", false, "Shorthand for --ignore-whitespace")
+	themeName := flag.String("theme", "", "Color theme: auto, preset name or .toml/.json theme file (default: $DIFFSTORY_THEME, config, then auto)")
+	// The diff is already generated, so of the diff options only the
+	// pathspecs apply
+	var paths diffview.DiffOptions
+	paths.RegisterPathspecFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: git diff | diffview [flags]")
+		fmt.Fprintln(os.Stderr, "Also reads diff -u, hg diff and svn diff output, and git format-patch mbox series.")
+		fmt.Fprintln(os.Stderr, "--include and --exclude default to the [diff] section of the config file.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
 	// Check if stdin is a pipe (not a terminal)
 	stat, err := os.Stdin.Stat()
 	if err != nil {
@@ -47,7 +75,7 @@ func main() {
 		os.Exit(1)
 	}
 	if (stat.Mode() & os.ModeCharDevice) != 0 {
-		fmt.Fprintln(os.Stderr, "Usage: git diff | diffview")
+		flag.Usage()
 		os.Exit(1)
 	}
 
@@ -56,7 +84,33 @@ func main() {
 	defer cancel()
 
 	// Set up syntax highlighting
-	theme := lipgloss.DefaultTheme()
+	cfg, err := fs.LoadConfig(fs.DefaultConfigPath())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error loading config:", err)
+		os.Exit(1)
+	}
+	keymap, err := bubbletea.DefaultKeyMap().Override(cfg.Keys.Viewer)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error in config [keys.viewer]:", err)
+		os.Exit(1)
+	}
+	// Only the TUI queries the terminal background; printed output may be
+	// read by a pager on the same terminal.
+	var theme *lipgloss.Theme
+	if *printMode {
+		theme, err = lipgloss.SelectPrintTheme(*themeName, cfg)
+	} else {
+		theme, err = lipgloss.SelectTheme(*themeName, cfg)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error loading theme:", err)
+		os.Exit(1)
+	}
+	pathCfg := diffview.Config{Diff: diffview.DiffOptions{Include: cfg.Diff.Include, Exclude: cfg.Diff.Exclude}}
+	if paths, err = pathCfg.DiffOptions(paths); err != nil {
+		fmt.Fprintln(os.Stderr, "Error in config:", err)
+		os.Exit(1)
+	}
 	detector := chroma.NewDetector()
 	tokenizer, err := chroma.NewTokenizer(chroma.StyleFromPalette(theme.Palette()))
 	if err != nil {
@@ -64,14 +118,38 @@ func main() {
 		os.Exit(1)
 	}
 
-	app := &App{
-		Stdin:  os.Stdin,
-		Parser: gitdiff.NewParser(),
-		Viewer: bubbletea.NewViewer(theme,
+	var viewer diffview.Viewer
+	if *printMode {
+		noColor := *plain || os.Getenv("NO_COLOR") != ""
+		viewer = bubbletea.NewPrinter(os.Stdout, *width,
+			bubbletea.WithRenderer(bubbletea.NewPrintRenderer(os.Stdout, noColor)),
+			bubbletea.WithTheme(theme),
+			bubbletea.WithLanguageDetector(detector),
+			bubbletea.WithTokenizer(tokenizer),
+			bubbletea.WithWordDiffer(worddiff.NewDiffer()),
+			bubbletea.WithIgnoreWhitespace(ignoreWhitespace),
+		)
+	} else {
+		viewerOpts := []bubbletea.ViewerOption{
 			bubbletea.WithViewerLanguageDetector(detector),
 			bubbletea.WithViewerTokenizer(tokenizer),
 			bubbletea.WithViewerWordDiffer(worddiff.NewDiffer()),
-		),
+			bubbletea.WithViewerKeyMap(keymap),
+			bubbletea.WithViewerIgnoreWhitespace(ignoreWhitespace),
+			// Context is expanded from the working tree when the paths resolve
+			bubbletea.WithViewerFileSource(fs.NewWorkingTree(".")),
+		}
+		if *comments != "" {
+			viewerOpts = append(viewerOpts, bubbletea.WithViewerCommentStore(jsonl.NewCommentStore(), *comments))
+		}
+		viewer = bubbletea.NewViewer(theme, viewerOpts...)
+	}
+
+	app := &App{
+		Stdin:  os.Stdin,
+		Parser: diffview.NewAutoParser(gitdiff.NewParser(), unidiff.NewParser(), gitdiff.NewParser()),
+		Viewer: viewer,
+		Paths:  paths,
 	}
 
 	if err := app.Run(ctx); err != nil {
@@ -19,7 +19,7 @@ func TestApp_Run_Success(t *testing.T) {
 
 	input := "diff --git a/file.txt b/file.txt\n"
 	expectedDiff := &diffview.Diff{
-		Files: []diffview.FileDiff{{OldPath: "a/file.txt"}},
+		Files: []diffview.FileDiff{{OldPath: "file.txt"}},
 	}
 
 	var parsedInput string
@@ -117,3 +117,52 @@ func TestApp_Run_EmptyDiff(t *testing.T) {
 	require.ErrorIs(t, err, main.ErrNoChanges)
 	assert.False(t, viewerCalled, "viewer should not be called for empty diff")
 }
+
+func TestApp_Run_FiltersPaths(t *testing.T) {
+	t.Parallel()
+
+	var viewedDiff *diffview.Diff
+	app := &main.App{
+		Stdin: strings.NewReader(""),
+		Parser: &mock.Parser{
+			ParseFn: func(r io.Reader) (*diffview.Diff, error) {
+				return &diffview.Diff{Files: []diffview.FileDiff{
+					{OldPath: "main.go", NewPath: "main.go"},
+					{OldPath: "main_test.go", NewPath: "main_test.go"},
+				}}, nil
+			},
+		},
+		Viewer: &mock.Viewer{
+			ViewFn: func(ctx context.Context, diff *diffview.Diff) error {
+				viewedDiff = diff
+				return nil
+			},
+		},
+		Paths: diffview.DiffOptions{Exclude: []string{"*_test.go"}},
+	}
+
+	err := app.Run(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, viewedDiff.Files, 1)
+	assert.Equal(t, "main.go", viewedDiff.Files[0].NewPath)
+}
+
+func TestApp_Run_AllPathsFiltered(t *testing.T) {
+	t.Parallel()
+
+	app := &main.App{
+		Stdin: strings.NewReader(""),
+		Parser: &mock.Parser{
+			ParseFn: func(r io.Reader) (*diffview.Diff, error) {
+				return &diffview.Diff{Files: []diffview.FileDiff{{OldPath: "main.go", NewPath: "main.go"}}}, nil
+			},
+		},
+		Viewer: &mock.Viewer{},
+		Paths:  diffview.DiffOptions{Include: []string{"docs"}},
+	}
+
+	err := app.Run(context.Background())
+
+	require.ErrorIs(t, err, main.ErrNoChanges)
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/fwojciec/diffstory/bubbletea"
 	"github.com/fwojciec/diffstory/chroma"
 	"github.com/fwojciec/diffstory/clipboard"
+	"github.com/fwojciec/diffstory/fs"
 	"github.com/fwojciec/diffstory/gemini"
 	"github.com/fwojciec/diffstory/git"
 	"github.com/fwojciec/diffstory/gitdiff"
@@ -94,7 +95,18 @@ func runReview(ctx context.Context, inputPath string) error {
 	}
 
 	// Set up syntax highlighting
-	theme := lipgloss.DefaultTheme()
+	cfg, err := fs.LoadConfig(fs.DefaultConfigPath())
+	if err != nil {
+		return err
+	}
+	keymap, err := bubbletea.DefaultEvalKeyMap().Override(cfg.Keys.Eval)
+	if err != nil {
+		return fmt.Errorf("config [keys.eval]: %w", err)
+	}
+	theme, err := lipgloss.SelectTheme("", cfg)
+	if err != nil {
+		return err
+	}
 	detector := chroma.NewDetector()
 	tokenizer, err := chroma.NewTokenizer(chroma.StyleFromPalette(theme.Palette()))
 	if err != nil {
@@ -109,6 +121,7 @@ func runReview(ctx context.Context, inputPath string) error {
 		bubbletea.WithEvalTokenizer(tokenizer),
 		bubbletea.WithEvalWordDiffer(worddiff.NewDiffer()),
 		bubbletea.WithClipboard(clipboard.NewPBCopy()),
+		bubbletea.WithEvalKeyMap(keymap),
 	}
 	if len(existingJudgments) > 0 {
 		opts = append(opts, bubbletea.WithExistingJudgments(existingJudgments))
@@ -139,6 +152,10 @@ type Collector struct {
 	MaxLines int
 	MaxBytes int // Maximum serialized case size in bytes (0 = no limit)
 	Git      diffview.GitRunner
+
+	// MergeResolutions also writes the conflict resolutions of each merge
+	// commit (its combined diff) as a case of their own.
+	MergeResolutions bool
 }
 
 // Run extracts diffs from git history and writes JSONL output.
@@ -173,6 +190,12 @@ func (c *Collector) runPRLevel(ctx context.Context, mergeHashes []string) error
 
 		branch := ParseBranchFromMergeMessage(mergeMessage)
 
+		if c.MergeResolutions {
+			if err := c.writeMergeResolution(ctx, encoder, mergeHash, mergeMessage, branch); err != nil {
+				return err
+			}
+		}
+
 		// Get commits in the PR (merge^1..merge^2)
 		base := mergeHash + "^1"
 		head := mergeHash + "^2"
@@ -258,6 +281,55 @@ func (c *Collector) runPRLevel(ctx context.Context, mergeHashes []string) error
 	return nil
 }
 
+// writeMergeResolution writes a case holding the changes a merge commit
+// made on top of its parents: conflict resolutions and evil merges. Merges
+// without such changes are skipped. Resolutions are small, so MinLines
+// doesn't apply.
+func (c *Collector) writeMergeResolution(ctx context.Context, encoder *json.Encoder, mergeHash, mergeMessage, branch string) error {
+	// git show prints the combined diff of a merge commit
+	diffText, err := c.Git.Show(ctx, c.RepoPath, mergeHash)
+	if err != nil {
+		return err
+	}
+	diff, err := gitdiff.NewParser().Parse(strings.NewReader(diffText))
+	if err != nil {
+		return err
+	}
+
+	var resolved []diffview.FileDiff
+	for _, file := range diff.Files {
+		if len(file.Hunks) > 0 {
+			resolved = append(resolved, file)
+		}
+	}
+	if len(resolved) == 0 {
+		return nil
+	}
+	diff.Files = resolved
+	if c.MaxLines > 0 && countLinesChanged(diff) > c.MaxLines {
+		return nil
+	}
+
+	evalCase := diffview.EvalCase{
+		Input: diffview.ClassificationInput{
+			Repo:    c.RepoName,
+			Branch:  branch,
+			Commits: []diffview.CommitBrief{{Hash: mergeHash, Message: mergeMessage}},
+			Diff:    *diff,
+		},
+	}
+	if c.MaxBytes > 0 {
+		data, err := json.Marshal(evalCase)
+		if err != nil {
+			return err
+		}
+		if len(data) > c.MaxBytes {
+			return nil
+		}
+	}
+	return encoder.Encode(evalCase)
+}
+
 // runCommitLevel extracts individual commit cases (fallback mode).
 func (c *Collector) runCommitLevel(ctx context.Context) error {
 	hashes, err := c.Git.Log(ctx, c.RepoPath, c.Limit)
@@ -369,18 +441,34 @@ func countLinesChanged(diff *diffview.Diff) int {
 }
 
 func runCollect(ctx context.Context) error {
-	fs := flag.NewFlagSet("collect", flag.ExitOnError)
-	limit := fs.Int("limit", 50, "Maximum number of commits to extract")
-	repo := fs.String("repo", "", "Repository name (defaults to directory name)")
-	minLines := fs.Int("min-lines", 5, "Minimum lines changed (skip smaller commits)")
-	maxLines := fs.Int("max-lines", 2000, "Maximum lines changed (skip larger PRs/commits)")
-	maxBytes := fs.Int("max-bytes", 500000, "Maximum serialized case size in bytes (skip larger cases)")
-
-	if err := fs.Parse(os.Args[2:]); err != nil {
+	flags := flag.NewFlagSet("collect", flag.ExitOnError)
+	limit := flags.Int("limit", 50, "Maximum number of commits to extract")
+	repo := flags.String("repo", "", "Repository name (defaults to directory name)")
+	minLines := flags.Int("min-lines", 5, "Minimum lines changed (skip smaller commits)")
+	maxLines := flags.Int("max-lines", 2000, "Maximum lines changed (skip larger PRs/commits)")
+	maxBytes := flags.Int("max-bytes", 500000, "Maximum serialized case size in bytes (skip larger cases)")
+	mergeResolutions := flags.Bool("merge-resolutions", false, "Also extract the conflict resolutions of each merge as a case")
+	gitBackend := flags.String("git", "", "Git backend: exec or go-git")
+	var diffFlags diffview.DiffOptions
+	diffFlags.RegisterFlags(flags)
+
+	if err := flags.Parse(os.Args[2:]); err != nil {
+		return err
+	}
+	cfg, err := fs.LoadConfig(fs.DefaultConfigPath())
+	if err != nil {
+		return err
+	}
+	diffOpts, err := cfg.DiffOptions(diffFlags)
+	if err != nil {
+		return err
+	}
+	gitRunner, err := git.SelectRunner(*gitBackend, cfg, diffOpts)
+	if err != nil {
 		return err
 	}
 
-	args := fs.Args()
+	args := flags.Args()
 	repoPath := "."
 	if len(args) > 0 {
 		repoPath = args[0]
@@ -404,7 +492,9 @@ func runCollect(ctx context.Context) error {
 		MinLines: *minLines,
 		MaxLines: *maxLines,
 		MaxBytes: *maxBytes,
-		Git:      git.NewRunner(),
+		Git:      gitRunner,
+
+		MergeResolutions: *mergeResolutions,
 	}
 
 	return collector.Run(ctx)
@@ -914,3 +914,80 @@ new file mode 100644
 	require.NotNil(t, commit2.Diff, "commit2 should have Diff populated")
 	require.Len(t, commit2.Diff.Files, 1, "commit2 diff should have 1 file")
 }
+
+func TestCollector_Run_MergeResolutions(t *testing.T) {
+	t.Parallel()
+
+	resolution := `diff --cc f.txt
+index 7b37c73,94235ab..d3c8fbd
+--- a/f.txt
++++ b/f.txt
+@@@ -1,2 -1,2 +1,2 @@@
+  a
+- ours
+ -theirs
+++resolved
+`
+	prDiff := `diff --git a/f.txt b/f.txt
+--- a/f.txt
++++ b/f.txt
+@@ -1,2 +1,2 @@
+ a
+-ours
++resolved
+`
+	run := func(t *testing.T, mergeResolutions bool) []string {
+		t.Helper()
+		var stdout bytes.Buffer
+		collector := &main.Collector{
+			Output:   &stdout,
+			RepoName: "testrepo",
+			Git: &mock.GitRunner{
+				MergeCommitsFn: func(_ context.Context, _ string, _ int) ([]string, error) {
+					return []string{"merge123"}, nil
+				},
+				CommitsInRangeFn: func(_ context.Context, _ string, _, _ string) ([]diffview.CommitBrief, error) {
+					return []diffview.CommitBrief{{Hash: "feat1", Message: "Change f"}}, nil
+				},
+				DiffRangeFn: func(_ context.Context, _ string, _, _ string) (string, error) {
+					return prDiff, nil
+				},
+				MessageFn: func(_ context.Context, _ string, _ string) (string, error) {
+					return "Merge pull request #42 from user/feature-branch", nil
+				},
+				ShowFn: func(_ context.Context, _ string, hash string) (string, error) {
+					if hash == "merge123" {
+						return resolution, nil
+					}
+					return "", nil
+				},
+			},
+			MergeResolutions: mergeResolutions,
+		}
+		require.NoError(t, collector.Run(context.Background()))
+		return strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	}
+
+	t.Run("writes the resolution as its own case", func(t *testing.T) {
+		t.Parallel()
+
+		lines := run(t, true)
+
+		require.Len(t, lines, 2)
+		var resolutionCase diffview.EvalCase
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &resolutionCase))
+		assert.Equal(t, "feature-branch", resolutionCase.Input.Branch)
+		require.Len(t, resolutionCase.Input.Commits, 1)
+		assert.Equal(t, "merge123", resolutionCase.Input.Commits[0].Hash)
+		require.Len(t, resolutionCase.Input.Diff.Files, 1)
+		hunk := resolutionCase.Input.Diff.Files[0].Hunks[0]
+		assert.True(t, hunk.IsCombined())
+		assert.Equal(t, "++", hunk.Lines[3].Prefix())
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Len(t, run(t, false), 1)
+	})
+}
@@ -0,0 +1,56 @@
+package diffview
+
+import "time"
+
+// CommentSide identifies which version of a file a comment is anchored to.
+type CommentSide string
+
+// Comment sides.
+const (
+	SideOld CommentSide = "old" // Deleted lines, numbered by Line.OldLineNum
+	SideNew CommentSide = "new" // Added and context lines, numbered by Line.NewLineNum
+)
+
+// Comment is a review comment anchored to a single line of a diff.
+type Comment struct {
+	File      string      `json:"file"`       // File path as used in HunkRef.File
+	Side      CommentSide `json:"side"`       // Which version of the file Line refers to
+	Line      int         `json:"line"`       // Line number on that side
+	Body      string      `json:"body"`       // Comment text (may span multiple lines)
+	CreatedAt time.Time   `json:"created_at"` // When the comment was last edited
+}
+
+// CommentStore persists and retrieves review comments.
+type CommentStore interface {
+	Load(path string) ([]Comment, error)
+	Save(path string, comments []Comment) error
+}
+
+// CommentAnchor returns the side and line number a comment on l is anchored to.
+// Deleted lines only exist in the old file, so they anchor to the old side;
+// added and context lines anchor to the new side.
+func CommentAnchor(l Line) (CommentSide, int) {
+	if l.Type == LineDeleted {
+		return SideOld, l.OldLineNum
+	}
+	return SideNew, l.NewLineNum
+}
+
+// FindLine locates the line a comment is anchored to.
+// Returns the hunk index within the file and the line itself, or ok=false
+// if the file or line is not part of the diff.
+func (d *Diff) FindLine(path string, side CommentSide, lineNum int) (hunkIndex int, line Line, ok bool) {
+	for _, file := range d.Files {
+		if file.Path() != path {
+			continue
+		}
+		for i, hunk := range file.Hunks {
+			for _, l := range hunk.Lines {
+				if s, n := CommentAnchor(l); s == side && n == lineNum {
+					return i, l, true
+				}
+			}
+		}
+	}
+	return 0, Line{}, false
+}
@@ -0,0 +1,110 @@
+package diffview_test
+
+import (
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommentAnchor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("deleted lines anchor to the old side", func(t *testing.T) {
+		t.Parallel()
+
+		side, line := diffview.CommentAnchor(diffview.Line{Type: diffview.LineDeleted, OldLineNum: 7})
+
+		assert.Equal(t, diffview.SideOld, side)
+		assert.Equal(t, 7, line)
+	})
+
+	t.Run("added lines anchor to the new side", func(t *testing.T) {
+		t.Parallel()
+
+		side, line := diffview.CommentAnchor(diffview.Line{Type: diffview.LineAdded, NewLineNum: 9})
+
+		assert.Equal(t, diffview.SideNew, side)
+		assert.Equal(t, 9, line)
+	})
+
+	t.Run("context lines anchor to the new side", func(t *testing.T) {
+		t.Parallel()
+
+		side, line := diffview.CommentAnchor(diffview.Line{Type: diffview.LineContext, OldLineNum: 3, NewLineNum: 4})
+
+		assert.Equal(t, diffview.SideNew, side)
+		assert.Equal(t, 4, line)
+	})
+}
+
+func TestDiff_FindLine(t *testing.T) {
+	t.Parallel()
+
+	diff := &diffview.Diff{
+		Files: []diffview.FileDiff{
+			{
+				OldPath:   "main.go",
+				NewPath:   "main.go",
+				Operation: diffview.FileModified,
+				Hunks: []diffview.Hunk{
+					{Lines: []diffview.Line{
+						{Type: diffview.LineContext, Content: "package main", OldLineNum: 1, NewLineNum: 1},
+					}},
+					{Lines: []diffview.Line{
+						{Type: diffview.LineDeleted, Content: "old", OldLineNum: 10},
+						{Type: diffview.LineAdded, Content: "new", NewLineNum: 10},
+					}},
+				},
+			},
+			{
+				OldPath:   "gone.go",
+				NewPath:   "",
+				Operation: diffview.FileDeleted,
+				Hunks: []diffview.Hunk{
+					{Lines: []diffview.Line{
+						{Type: diffview.LineDeleted, Content: "package gone", OldLineNum: 1},
+					}},
+				},
+			},
+		},
+	}
+
+	t.Run("finds line on the new side", func(t *testing.T) {
+		t.Parallel()
+
+		hunkIdx, line, ok := diff.FindLine("main.go", diffview.SideNew, 10)
+
+		require.True(t, ok)
+		assert.Equal(t, 1, hunkIdx)
+		assert.Equal(t, "new", line.Content)
+	})
+
+	t.Run("finds line on the old side", func(t *testing.T) {
+		t.Parallel()
+
+		hunkIdx, line, ok := diff.FindLine("main.go", diffview.SideOld, 10)
+
+		require.True(t, ok)
+		assert.Equal(t, 1, hunkIdx)
+		assert.Equal(t, "old", line.Content)
+	})
+
+	t.Run("uses old path for deleted files", func(t *testing.T) {
+		t.Parallel()
+
+		_, line, ok := diff.FindLine("gone.go", diffview.SideOld, 1)
+
+		require.True(t, ok)
+		assert.Equal(t, "package gone", line.Content)
+	})
+
+	t.Run("reports missing lines", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, ok := diff.FindLine("main.go", diffview.SideNew, 99)
+
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,31 @@
+package diffview
+
+import "fmt"
+
+// Config holds user preferences shared by all commands.
+// Command-line flags and environment variables take precedence over it.
+type Config struct {
+	Theme string      `toml:"theme"` // Preset name or path to a theme file
+	Git   string      `toml:"git"`   // Git backend: "exec" or "go-git"
+	Diff  DiffOptions `toml:"diff"`  // How diffs are generated from git
+	Keys  KeyBindings `toml:"keys"`
+}
+
+// DiffOptions returns the [diff] options overridden by flagOpts, the
+// options set by DiffOptions.RegisterFlags, and validated.
+func (c Config) DiffOptions(flagOpts DiffOptions) (DiffOptions, error) {
+	opts := c.Diff.Override(flagOpts)
+	if err := opts.Validate(); err != nil {
+		return opts, fmt.Errorf("diff options: %w", err)
+	}
+	return opts, nil
+}
+
+// KeyBindings overrides key bindings by name, separately for each TUI.
+// Each entry maps a binding name (e.g. "next_hunk") to the keys that
+// trigger it, replacing the default keys.
+type KeyBindings struct {
+	Viewer map[string][]string `toml:"viewer"` // diffview
+	Story  map[string][]string `toml:"story"`  // diffstory
+	Eval   map[string][]string `toml:"eval"`   // evalreview
+}
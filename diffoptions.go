@@ -0,0 +1,220 @@
+package diffview
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DiffAlgorithm names a git diff algorithm (git diff --diff-algorithm).
+type DiffAlgorithm string
+
+// Diff algorithms. The empty algorithm leaves the choice to git.
+const (
+	DiffAlgorithmMyers     DiffAlgorithm = "myers"
+	DiffAlgorithmMinimal   DiffAlgorithm = "minimal"
+	DiffAlgorithmPatience  DiffAlgorithm = "patience"
+	DiffAlgorithmHistogram DiffAlgorithm = "histogram"
+)
+
+// DiffOptions controls how a GitRunner generates diffs. The zero value
+// gives git diff's defaults.
+type DiffOptions struct {
+	Algorithm DiffAlgorithm `toml:"algorithm" json:"algorithm,omitempty"`
+
+	// Context is the number of context lines around changes (-U), or 0 for
+	// git's default of 3. Hunks without context can't be staged, so it
+	// can't be set to none.
+	Context int `toml:"context" json:"context,omitempty"`
+
+	// RenameThreshold is how similar in percent a deleted and an added
+	// file must be to be shown as a rename (-M), or 0 for git's default
+	// of 50. NoRenames turns rename detection off.
+	RenameThreshold int  `toml:"rename_threshold" json:"rename_threshold,omitempty"`
+	NoRenames       bool `toml:"no_renames" json:"no_renames,omitempty"`
+
+	// CopyThreshold is how similar in percent an added file must be to a
+	// changed one to be shown as a copy (-C), or 0 to not detect copies.
+	CopyThreshold int `toml:"copy_threshold" json:"copy_threshold,omitempty"`
+
+	// Include limits diffs to paths matching these pathspecs, and Exclude
+	// leaves out paths matching those. See MatchPath.
+	Include []string `toml:"include" json:"include,omitempty"`
+	Exclude []string `toml:"exclude" json:"exclude,omitempty"`
+}
+
+// Override returns o with the options set in override, such as command
+// line flags over the config file. Turning renames off drops o's
+// thresholds, and setting a threshold turns them back on.
+func (o DiffOptions) Override(override DiffOptions) DiffOptions {
+	if override.Algorithm != "" {
+		o.Algorithm = override.Algorithm
+	}
+	if override.Context != 0 {
+		o.Context = override.Context
+	}
+	if override.NoRenames {
+		o.NoRenames, o.RenameThreshold, o.CopyThreshold = true, 0, 0
+	}
+	if override.RenameThreshold != 0 {
+		o.NoRenames, o.RenameThreshold = false, override.RenameThreshold
+	}
+	if override.CopyThreshold != 0 {
+		o.NoRenames, o.CopyThreshold = false, override.CopyThreshold
+	}
+	if override.Include != nil {
+		o.Include = override.Include
+	}
+	if override.Exclude != nil {
+		o.Exclude = override.Exclude
+	}
+	return o
+}
+
+// RegisterFlags registers the command-line flags that set diff options on
+// flags, like the git diff flags of the same names. What they set is stored
+// in o, to override the config file with Config.DiffOptions.
+func (o *DiffOptions) RegisterFlags(flags *flag.FlagSet) {
+	flags.Func("diff-algorithm", "Diff algorithm: myers, minimal, patience or histogram", func(s string) error {
+		o.Algorithm = DiffAlgorithm(s)
+		return nil
+	})
+	flags.Func("unified", "Lines of context around changes (default 3)", positiveInt(&o.Context))
+	flags.Func("U", "Lines of context around changes (shorthand)", positiveInt(&o.Context))
+	flags.Func("find-renames", "Similarity in percent for a rename (default 50)", positiveInt(&o.RenameThreshold))
+	flags.BoolVar(&o.NoRenames, "no-renames", false, "Turn off rename detection")
+	flags.Func("find-copies", "Similarity in percent for a copy (default: copies not detected)", positiveInt(&o.CopyThreshold))
+	o.RegisterPathspecFlags(flags)
+}
+
+// RegisterPathspecFlags registers only the --include and --exclude flags,
+// for commands that filter diffs they don't generate.
+func (o *DiffOptions) RegisterPathspecFlags(flags *flag.FlagSet) {
+	flags.Func("include", "Only show paths matching this pathspec (repeatable)", func(s string) error {
+		o.Include = append(o.Include, s)
+		return nil
+	})
+	flags.Func("exclude", "Leave out paths matching this pathspec (repeatable)", func(s string) error {
+		o.Exclude = append(o.Exclude, s)
+		return nil
+	})
+}
+
+// positiveInt returns a flag.Func setter parsing a positive integer into n.
+func positiveInt(n *int) func(string) error {
+	return func(s string) error {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 {
+			return fmt.Errorf("must be a positive number, got %q", s)
+		}
+		*n = v
+		return nil
+	}
+}
+
+// Validate reports the first invalid option.
+func (o DiffOptions) Validate() error {
+	switch o.Algorithm {
+	case "", DiffAlgorithmMyers, DiffAlgorithmMinimal, DiffAlgorithmPatience, DiffAlgorithmHistogram:
+	default:
+		return fmt.Errorf("unknown diff algorithm %q (use myers, minimal, patience or histogram)", o.Algorithm)
+	}
+	if o.Context < 0 {
+		return fmt.Errorf("context lines must be positive, got %d", o.Context)
+	}
+	if o.RenameThreshold < 0 || o.RenameThreshold > 100 {
+		return fmt.Errorf("rename threshold must be a percentage, got %d", o.RenameThreshold)
+	}
+	if o.CopyThreshold < 0 || o.CopyThreshold > 100 {
+		return fmt.Errorf("copy threshold must be a percentage, got %d", o.CopyThreshold)
+	}
+	if o.NoRenames && (o.RenameThreshold != 0 || o.CopyThreshold != 0) {
+		return fmt.Errorf("rename and copy thresholds can't be set with renames turned off")
+	}
+	for _, spec := range append(append([]string(nil), o.Include...), o.Exclude...) {
+		if _, err := compilePathspec(spec); err != nil {
+			return fmt.Errorf("invalid pathspec %q: %w", spec, err)
+		}
+	}
+	return nil
+}
+
+// MatchPath reports whether Include and Exclude let path into a diff. Like
+// git's pathspecs, a pattern without wildcards matches the path itself and
+// everything below it if it's a directory; a pattern with wildcards (*, ?
+// or [...]) is matched against the whole path, and * matches / too, so
+// "*.go" matches Go files at any depth.
+func (o DiffOptions) MatchPath(path string) bool {
+	if len(o.Include) > 0 && !matchAny(o.Include, path) {
+		return false
+	}
+	return !matchAny(o.Exclude, path)
+}
+
+// FilterFiles returns diff without the files whose paths MatchPath rejects.
+// A renamed or copied file is kept if either of its paths is let in.
+func (o DiffOptions) FilterFiles(diff *Diff) *Diff {
+	if len(o.Include) == 0 && len(o.Exclude) == 0 {
+		return diff
+	}
+	filtered := &Diff{}
+	for _, f := range diff.Files {
+		if (f.OldPath != "" && o.MatchPath(f.OldPath)) || (f.NewPath != "" && o.MatchPath(f.NewPath)) {
+			filtered.Files = append(filtered.Files, f)
+		}
+	}
+	return filtered
+}
+
+// matchAny reports whether any of specs matches path.
+func matchAny(specs []string, path string) bool {
+	for _, spec := range specs {
+		re, err := compilePathspec(spec)
+		if err == nil && re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// compilePathspec returns a regular expression matching the paths spec
+// matches.
+func compilePathspec(spec string) (*regexp.Regexp, error) {
+	if !strings.ContainsAny(spec, `*?[\`) {
+		dir := strings.TrimSuffix(spec, "/")
+		return regexp.Compile("^" + regexp.QuoteMeta(dir) + "(/|$)")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(spec); i++ {
+		switch c := spec[i]; c {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		case '\\':
+			if i+1 < len(spec) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(spec[i : i+1]))
+			}
+		case '[':
+			end := strings.IndexByte(spec[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated [")
+			}
+			class := spec[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
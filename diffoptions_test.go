@@ -0,0 +1,156 @@
+package diffview_test
+
+import (
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffOptions_MatchPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts diffview.DiffOptions
+		path string
+		want bool
+	}{
+		{"no pathspecs", diffview.DiffOptions{}, "src/a.go", true},
+		{"directory", diffview.DiffOptions{Include: []string{"src"}}, "src/sub/b.go", true},
+		{"directory with slash", diffview.DiffOptions{Include: []string{"src/"}}, "src/a.go", true},
+		{"directory prefix only", diffview.DiffOptions{Include: []string{"sr"}}, "src/a.go", false},
+		{"exact file", diffview.DiffOptions{Include: []string{"c.go"}}, "c.go", true},
+		{"star crosses directories", diffview.DiffOptions{Include: []string{"*.go"}}, "src/sub/b.go", true},
+		{"wildcard matches whole path", diffview.DiffOptions{Include: []string{"sr?"}}, "src/a.go", false},
+		{"class", diffview.DiffOptions{Include: []string{"[a-c].go"}}, "b.go", true},
+		{"negated class", diffview.DiffOptions{Include: []string{"[!a-c].go"}}, "b.go", false},
+		{"escaped wildcard", diffview.DiffOptions{Include: []string{`\*.go`}}, "*.go", true},
+		{"excluded", diffview.DiffOptions{Exclude: []string{"*_test.go"}}, "src/a_test.go", false},
+		{"exclude wins", diffview.DiffOptions{Include: []string{"src"}, Exclude: []string{"src/gen"}}, "src/gen/x.go", false},
+		{"not included", diffview.DiffOptions{Include: []string{"src"}}, "docs/a.md", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, tt.opts.MatchPath(tt.path))
+		})
+	}
+}
+
+func TestDiffOptions_FilterFiles(t *testing.T) {
+	t.Parallel()
+
+	diff := &diffview.Diff{Files: []diffview.FileDiff{
+		{OldPath: "a.go", NewPath: "a.go", Operation: diffview.FileModified},
+		{OldPath: "a_test.go", NewPath: "a_test.go", Operation: diffview.FileModified},
+		{OldPath: "old/b.go", NewPath: "new/b.go", Operation: diffview.FileRenamed},
+		{NewPath: "docs/c.md", Operation: diffview.FileAdded},
+	}}
+
+	filtered := diffview.DiffOptions{Include: []string{"*.go"}, Exclude: []string{"*_test.go", "old"}}.FilterFiles(diff)
+
+	var paths []string
+	for _, f := range filtered.Files {
+		paths = append(paths, f.NewPath)
+	}
+	assert.Equal(t, []string{"a.go", "new/b.go"}, paths)
+	assert.Same(t, diff, diffview.DiffOptions{Context: 5}.FilterFiles(diff))
+}
+
+func TestDiffOptions_Override(t *testing.T) {
+	t.Parallel()
+
+	cfg := diffview.DiffOptions{
+		Algorithm: diffview.DiffAlgorithmPatience, Context: 5, RenameThreshold: 70,
+		Include: []string{"src"}, Exclude: []string{"*.pb.go"},
+	}
+
+	assert.Equal(t, cfg, cfg.Override(diffview.DiffOptions{}))
+	assert.Equal(t, diffview.DiffOptions{
+		Algorithm: diffview.DiffAlgorithmHistogram, Context: 5, RenameThreshold: 70,
+		Include: []string{"src"}, Exclude: []string{"vendor"},
+	}, cfg.Override(diffview.DiffOptions{Algorithm: diffview.DiffAlgorithmHistogram, Exclude: []string{"vendor"}}))
+
+	noRenames := cfg.Override(diffview.DiffOptions{NoRenames: true})
+	require.NoError(t, noRenames.Validate())
+	assert.True(t, noRenames.NoRenames)
+	assert.Zero(t, noRenames.RenameThreshold)
+
+	copies := noRenames.Override(diffview.DiffOptions{CopyThreshold: 80})
+	assert.False(t, copies.NoRenames)
+	assert.Equal(t, 80, copies.CopyThreshold)
+}
+
+func TestDiffOptions_Validate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, diffview.DiffOptions{}.Validate())
+	require.NoError(t, diffview.DiffOptions{
+		Algorithm: diffview.DiffAlgorithmHistogram, Context: 10, RenameThreshold: 80,
+		CopyThreshold: 90, Include: []string{"src"}, Exclude: []string{"*.pb.go"},
+	}.Validate())
+
+	tests := []struct {
+		name string
+		opts diffview.DiffOptions
+		want string
+	}{
+		{"algorithm", diffview.DiffOptions{Algorithm: "fast"}, "unknown diff algorithm"},
+		{"context", diffview.DiffOptions{Context: -1}, "context lines"},
+		{"rename threshold", diffview.DiffOptions{RenameThreshold: 101}, "rename threshold"},
+		{"copy threshold", diffview.DiffOptions{CopyThreshold: -5}, "copy threshold"},
+		{"thresholds without renames", diffview.DiffOptions{NoRenames: true, RenameThreshold: 60}, "renames turned off"},
+		{"pathspec", diffview.DiffOptions{Exclude: []string{"[a-"}}, "invalid pathspec"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.opts.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestDiffOptions_RegisterFlags(t *testing.T) {
+	t.Parallel()
+
+	var opts diffview.DiffOptions
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	opts.RegisterFlags(flags)
+
+	err := flags.Parse([]string{
+		"--diff-algorithm", "patience", "-U", "8", "--find-renames", "70", "--find-copies", "90",
+		"--include", "src", "--include", "cmd", "--exclude", "*_test.go",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, diffview.DiffOptions{
+		Algorithm: diffview.DiffAlgorithmPatience, Context: 8, RenameThreshold: 70, CopyThreshold: 90,
+		Include: []string{"src", "cmd"}, Exclude: []string{"*_test.go"},
+	}, opts)
+
+	flags = flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	opts.RegisterFlags(flags)
+	require.Error(t, flags.Parse([]string{"--unified", "0"}))
+}
+
+func TestConfig_DiffOptions(t *testing.T) {
+	t.Parallel()
+
+	cfg := diffview.Config{Diff: diffview.DiffOptions{Context: 5, RenameThreshold: 70, Exclude: []string{"vendor"}}}
+
+	opts, err := cfg.DiffOptions(diffview.DiffOptions{NoRenames: true, Exclude: []string{"*.pb.go"}})
+	require.NoError(t, err)
+	assert.Equal(t, diffview.DiffOptions{Context: 5, NoRenames: true, Exclude: []string{"*.pb.go"}}, opts)
+
+	_, err = cfg.DiffOptions(diffview.DiffOptions{Algorithm: "fast"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "diff options")
+}
@@ -3,7 +3,9 @@ package diffview
 
 import (
 	"context"
+	"fmt"
 	"io/fs"
+	"strings"
 )
 
 // Diff represents a complete diff containing one or more file changes.
@@ -21,6 +23,38 @@ type FileDiff struct {
 	NewMode   fs.FileMode // For permission changes
 	Hunks     []Hunk
 	Extended  []string // Raw extended headers for passthrough
+
+	// Similarity is the similarity index (0-100) of a rename or copy.
+	Similarity int `json:",omitempty"`
+}
+
+// ModeChanged reports whether the file's mode changed, e.g. it became
+// executable.
+func (f FileDiff) ModeChanged() bool {
+	return f.OldMode != 0 && f.NewMode != 0 && f.OldMode != f.NewMode
+}
+
+// Notes returns short descriptions of the file's metadata for file headers:
+// the similarity of a rename or copy and a mode change, e.g.
+// "95% similar" and "mode 100644 → 100755".
+func (f FileDiff) Notes() []string {
+	var notes []string
+	if (f.Operation == FileRenamed || f.Operation == FileCopied) && f.Similarity > 0 {
+		notes = append(notes, fmt.Sprintf("%d%% similar", f.Similarity))
+	}
+	if f.ModeChanged() {
+		notes = append(notes, fmt.Sprintf("mode %o → %o", f.OldMode, f.NewMode))
+	}
+	return notes
+}
+
+// DisplayPath returns the path shown in file headers: "old → new" for
+// renames and copies, Path() otherwise.
+func (f FileDiff) DisplayPath() string {
+	if f.Operation == FileRenamed || f.Operation == FileCopied {
+		return f.OldPath + " → " + f.Path()
+	}
+	return f.Path()
 }
 
 // Stats returns the number of added and deleted lines in the file.
@@ -38,6 +72,30 @@ func (f FileDiff) Stats() (added, deleted int) {
 	return added, deleted
 }
 
+// Path returns the path that identifies the file in HunkRef and Comment:
+// NewPath, or OldPath for deletions. Parsers strip the "a/" and "b/"
+// prefixes of diff headers, so paths are relative to the repository root.
+func (f FileDiff) Path() string {
+	if f.Operation == FileDeleted || f.NewPath == "" {
+		return f.OldPath
+	}
+	return f.NewPath
+}
+
+// FindHunk returns the hunk at index within the file identified by path.
+func (d *Diff) FindHunk(path string, index int) (Hunk, bool) {
+	for _, file := range d.Files {
+		if file.Path() != path {
+			continue
+		}
+		if index >= 0 && index < len(file.Hunks) {
+			return file.Hunks[index], true
+		}
+		return Hunk{}, false
+	}
+	return Hunk{}, false
+}
+
 // FileOp represents the type of operation performed on a file.
 type FileOp int
 
@@ -58,6 +116,47 @@ type Hunk struct {
 	NewCount int    // From @@ ...,+X,Y
 	Section  string // Optional function name after @@ ... @@
 	Lines    []Line
+
+	// Parents holds the range of a combined diff hunk (diff --cc) in each
+	// parent of a merge; OldStart and OldCount mirror the first parent.
+	// Nil for ordinary two-way hunks.
+	Parents []ParentRange `json:",omitempty"`
+}
+
+// ParentRange is the line range of a combined diff hunk in one parent.
+type ParentRange struct {
+	Start int
+	Count int
+}
+
+// IsCombined reports whether the hunk comes from a combined diff of a merge.
+func (h Hunk) IsCombined() bool {
+	return len(h.Parents) > 1
+}
+
+// Range returns the hunk's range line without the section, e.g.
+// "@@ -1,5 +1,6 @@", or "@@@ -1,5 -1,5 +1,6 @@@" for combined hunks.
+func (h Hunk) Range() string {
+	if !h.IsCombined() {
+		return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldCount, h.NewStart, h.NewCount)
+	}
+	marker := strings.Repeat("@", len(h.Parents)+1)
+	var sb strings.Builder
+	sb.WriteString(marker)
+	for _, p := range h.Parents {
+		fmt.Fprintf(&sb, " -%d,%d", p.Start, p.Count)
+	}
+	fmt.Fprintf(&sb, " +%d,%d %s", h.NewStart, h.NewCount, marker)
+	return sb.String()
+}
+
+// Header returns the hunk's header line: its range followed by the section
+// text, when there is one.
+func (h Hunk) Header() string {
+	if h.Section == "" {
+		return h.Range()
+	}
+	return h.Range() + " " + h.Section
 }
 
 // Line represents a single line within a hunk.
@@ -67,6 +166,26 @@ type Line struct {
 	OldLineNum int  // 0 if line is Added
 	NewLineNum int  // 0 if line is Deleted
 	NoNewline  bool // "\ No newline at end of file" marker
+
+	// Origins holds the change against each parent in a combined diff:
+	// LineAdded when the parent lacks the line, LineDeleted when only the
+	// parent has it. Type is LineDeleted when the line isn't in the result
+	// and LineAdded when any parent lacks it. OldLineNum is the line in the
+	// first parent, 0 if it lacks the line. Nil for two-way diffs.
+	Origins []LineType `json:",omitempty"`
+}
+
+// Prefix returns the diff prefix of the line: "+", "-" or " ", or one
+// column per parent for combined diff lines.
+func (l Line) Prefix() string {
+	if len(l.Origins) == 0 {
+		return l.Type.prefix()
+	}
+	var sb strings.Builder
+	for _, o := range l.Origins {
+		sb.WriteString(o.prefix())
+	}
+	return sb.String()
 }
 
 // LineType represents the type of a diff line.
@@ -79,6 +198,18 @@ const (
 	LineDeleted
 )
 
+// prefix returns the unified diff prefix for the line type.
+func (t LineType) prefix() string {
+	switch t {
+	case LineAdded:
+		return "+"
+	case LineDeleted:
+		return "-"
+	default:
+		return " "
+	}
+}
+
 // Segment represents a portion of text within a line for word-level diffing.
 // Used to highlight specific changed words/characters within modified lines.
 type Segment struct {
@@ -93,6 +224,22 @@ type WordDiffer interface {
 	Diff(old, new string) (oldSegs, newSegs []Segment)
 }
 
+// SimilarityScorer is implemented by WordDiffers that can cheaply score how
+// alike two lines are, from 0 (nothing in common) to 1 (identical). Viewers
+// use it to pair deleted lines with the added lines they became.
+type SimilarityScorer interface {
+	Similarity(old, new string) float64
+}
+
+// WhitespaceWordDiffer is a WordDiffer that can also ignore whitespace, for
+// viewers in ignore-whitespace mode.
+type WhitespaceWordDiffer interface {
+	WordDiffer
+	// DiffIgnoringWhitespace is like Diff but reports segments that only
+	// add, remove or resize whitespace as unchanged.
+	DiffIgnoringWhitespace(old, new string) (oldSegs, newSegs []Segment)
+}
+
 // GitRunner provides access to git operations for extracting commit history.
 type GitRunner interface {
 	// Log returns commit hashes from the repository at repoPath, limited to n commits.
@@ -111,6 +258,9 @@ type GitRunner interface {
 	// CommitsInRange returns commits between base and head (base exclusive, head inclusive).
 	// For a merge commit, use merge^1..merge^2 to get all PR commits.
 	CommitsInRange(ctx context.Context, repoPath, base, head string) ([]CommitBrief, error)
+	// CountCommits returns the number of commits CommitsInRange would
+	// return, without reading them (git rev-list --count base..head).
+	CountCommits(ctx context.Context, repoPath, base, head string) (int, error)
 	// DiffRange returns the combined diff between base and head.
 	// Uses three-dot notation (base...head) to show changes introduced by head since common ancestor.
 	DiffRange(ctx context.Context, repoPath, base, head string) (string, error)
@@ -124,4 +274,24 @@ type GitRunner interface {
 	// DefaultBranch returns the default branch name from origin/HEAD.
 	// Returns an error if no remote is configured.
 	DefaultBranch(ctx context.Context, repoPath string) (string, error)
+	// Upstream returns the upstream tracking branch of the current branch,
+	// like "origin/main", or a local branch name for a local upstream.
+	// Returns an error if the current branch has no upstream.
+	Upstream(ctx context.Context, repoPath string) (string, error)
+	// Branches returns the local branches sorted by name, followed by the
+	// remote-tracking branches like "origin/main" sorted by name. Symbolic
+	// refs such as origin/HEAD are left out.
+	Branches(ctx context.Context, repoPath string) ([]string, error)
+	// ShowFile returns the full content of path at rev (git show rev:path).
+	// An empty rev reads the index.
+	ShowFile(ctx context.Context, repoPath, rev, path string) (string, error)
+
+	// StagedDiff returns the diff of the changes staged in the index against HEAD.
+	StagedDiff(ctx context.Context, repoPath string) (string, error)
+	// WorktreeDiff returns the diff between rev and the working tree.
+	// Untracked files that aren't ignored are shown as added, as if they had
+	// been added with git add --intent-to-add; the index is left unchanged.
+	WorktreeDiff(ctx context.Context, repoPath, rev string) (string, error)
+	// ApplyPatch applies patch to the working tree or index (git apply).
+	ApplyPatch(ctx context.Context, repoPath, patch string, opts ApplyOptions) error
 }
@@ -128,3 +128,129 @@ func TestFileDiff_Stats(t *testing.T) {
 		assert.Equal(t, 0, deleted)
 	})
 }
+
+func TestFileDiff_Path(t *testing.T) {
+	t.Parallel()
+
+	t.Run("uses new path", func(t *testing.T) {
+		t.Parallel()
+
+		file := diffview.FileDiff{OldPath: "old.go", NewPath: "new.go", Operation: diffview.FileRenamed}
+
+		assert.Equal(t, "new.go", file.Path())
+	})
+
+	t.Run("uses old path for deleted files", func(t *testing.T) {
+		t.Parallel()
+
+		file := diffview.FileDiff{OldPath: "gone.go", Operation: diffview.FileDeleted}
+
+		assert.Equal(t, "gone.go", file.Path())
+	})
+
+	t.Run("keeps top-level a and b directories", func(t *testing.T) {
+		t.Parallel()
+
+		file := diffview.FileDiff{OldPath: "a/x.go", NewPath: "b/x.go", Operation: diffview.FileRenamed}
+
+		assert.Equal(t, "b/x.go", file.Path())
+		assert.Equal(t, "a/x.go → b/x.go", file.DisplayPath())
+	})
+}
+
+func TestDiff_FindHunk(t *testing.T) {
+	t.Parallel()
+
+	diff := &diffview.Diff{
+		Files: []diffview.FileDiff{
+			{NewPath: "main.go", Hunks: []diffview.Hunk{{NewStart: 1}, {NewStart: 40}}},
+		},
+	}
+
+	hunk, ok := diff.FindHunk("main.go", 1)
+	assert.True(t, ok)
+	assert.Equal(t, 40, hunk.NewStart)
+
+	_, ok = diff.FindHunk("main.go", 2)
+	assert.False(t, ok)
+
+	_, ok = diff.FindHunk("other.go", 0)
+	assert.False(t, ok)
+}
+
+func TestHunk_Range(t *testing.T) {
+	t.Parallel()
+
+	t.Run("two-way hunk", func(t *testing.T) {
+		t.Parallel()
+
+		h := diffview.Hunk{OldStart: 3, OldCount: 4, NewStart: 5, NewCount: 6, Section: "func main()"}
+
+		assert.Equal(t, "@@ -3,4 +5,6 @@", h.Range())
+		assert.False(t, h.IsCombined())
+	})
+
+	t.Run("combined hunk has a range per parent", func(t *testing.T) {
+		t.Parallel()
+
+		h := diffview.Hunk{
+			OldStart: 1, OldCount: 5, NewStart: 1, NewCount: 6,
+			Parents: []diffview.ParentRange{{Start: 1, Count: 5}, {Start: 2, Count: 4}},
+		}
+
+		assert.Equal(t, "@@@ -1,5 -2,4 +1,6 @@@", h.Range())
+		assert.True(t, h.IsCombined())
+	})
+}
+
+func TestHunk_Header(t *testing.T) {
+	t.Parallel()
+
+	h := diffview.Hunk{OldStart: 3, OldCount: 4, NewStart: 5, NewCount: 6}
+	assert.Equal(t, "@@ -3,4 +5,6 @@", h.Header())
+
+	h.Section = "func main()"
+	assert.Equal(t, "@@ -3,4 +5,6 @@ func main()", h.Header())
+}
+
+func TestLine_Prefix(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "+", diffview.Line{Type: diffview.LineAdded}.Prefix())
+	assert.Equal(t, "-", diffview.Line{Type: diffview.LineDeleted}.Prefix())
+	assert.Equal(t, " ", diffview.Line{Type: diffview.LineContext}.Prefix())
+	assert.Equal(t, " -", diffview.Line{
+		Type:    diffview.LineDeleted,
+		Origins: []diffview.LineType{diffview.LineContext, diffview.LineDeleted},
+	}.Prefix())
+}
+
+func TestFileDiff_Notes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rename with similarity", func(t *testing.T) {
+		t.Parallel()
+
+		f := diffview.FileDiff{OldPath: "old.go", NewPath: "new.go", Operation: diffview.FileRenamed, Similarity: 95}
+
+		assert.Equal(t, []string{"95% similar"}, f.Notes())
+		assert.Equal(t, "old.go → new.go", f.DisplayPath())
+	})
+
+	t.Run("mode change", func(t *testing.T) {
+		t.Parallel()
+
+		f := diffview.FileDiff{NewPath: "run.sh", OldMode: 0o100644, NewMode: 0o100755}
+
+		assert.Equal(t, []string{"mode 100644 → 100755"}, f.Notes())
+		assert.Equal(t, "run.sh", f.DisplayPath())
+	})
+
+	t.Run("new file mode is not a change", func(t *testing.T) {
+		t.Parallel()
+
+		f := diffview.FileDiff{NewPath: "run.sh", Operation: diffview.FileAdded, NewMode: 0o100755}
+
+		assert.Empty(t, f.Notes())
+	})
+}
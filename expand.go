@@ -0,0 +1,172 @@
+package diffview
+
+import "strings"
+
+// ContextExpansion is the number of extra context lines shown above and
+// below a hunk.
+type ContextExpansion struct {
+	Above int
+	Below int
+}
+
+// ExpandContext returns a copy of the file with extra context lines spliced
+// into its hunks. lines is the content of the file, and expansions is keyed
+// by hunk index. Context is read from the new side, or from the old side
+// when the file's paths differ or only its old content is known. Expansions are
+// clamped so hunks never overlap: the gap between two hunks can be filled
+// completely but no further. Hunks whose lines don't match the side read
+// are left unchanged, so a stale file never shows the wrong context, and so
+// are combined hunks.
+func (f FileDiff) ExpandContext(lines FileLines, expansions map[int]ContextExpansion) FileDiff {
+	side := f.contextSide(lines)
+	if len(expansions) == 0 || len(f.Hunks) == 0 || side.lines == nil {
+		return f
+	}
+
+	hunks := make([]Hunk, len(f.Hunks))
+	prevLast := 0 // Last line shown by the previous hunk
+	for i, hunk := range f.Hunks {
+		first, last := side.span(hunk)
+		nextFirst := len(side.lines) + 1
+		if i+1 < len(f.Hunks) {
+			nextFirst, _ = side.span(f.Hunks[i+1])
+		}
+
+		exp := expansions[i]
+		above := max(min(exp.Above, first-1-prevLast), 0)
+		below := max(min(exp.Below, nextFirst-1-last), 0)
+		if above+below == 0 || hunk.IsCombined() || !side.matches(hunk) {
+			hunks[i] = hunk
+			prevLast = last
+			continue
+		}
+
+		hunks[i] = hunk.withContext(side, above, below)
+		prevLast = last + below
+	}
+	f.Hunks = hunks
+	return f
+}
+
+// fileSide is the content of one side of a file diff.
+type fileSide struct {
+	lines []string
+	old   bool
+}
+
+// contextSide returns the side of the file context lines are read from: the
+// new side, or the old side when the paths differ or only the old content is
+// known.
+func (f FileDiff) contextSide(lines FileLines) fileSide {
+	if lines.Old != nil && (lines.New == nil || f.OldPath != f.NewPath) {
+		return fileSide{lines: lines.Old, old: true}
+	}
+	return fileSide{lines: lines.New}
+}
+
+// span returns the first and last line numbers of the hunk on this side.
+func (s fileSide) span(h Hunk) (first, last int) {
+	if s.old {
+		return oldRange(h)
+	}
+	return newRange(h)
+}
+
+// lineNum returns the line number of line on this side, or 0 when the line
+// isn't on it.
+func (s fileSide) lineNum(line Line) int {
+	if s.old {
+		return line.OldLineNum
+	}
+	return line.NewLineNum
+}
+
+// matches reports whether every line of the hunk on this side is found at
+// its line number in the side's content.
+func (s fileSide) matches(h Hunk) bool {
+	for _, line := range h.Lines {
+		if (s.old && line.Type == LineAdded) || (!s.old && line.Type == LineDeleted) {
+			continue
+		}
+		n := s.lineNum(line)
+		if n < 1 || n > len(s.lines) || s.lines[n-1] != strings.TrimSuffix(line.Content, "\n") {
+			return false
+		}
+	}
+	return true
+}
+
+// newRange returns the first and last new line numbers of the hunk. A hunk
+// without new lines has last = first - 1, positioned after NewStart.
+func newRange(h Hunk) (first, last int) {
+	if h.NewCount == 0 {
+		return h.NewStart + 1, h.NewStart
+	}
+	return h.NewStart, h.NewStart + h.NewCount - 1
+}
+
+// oldRange is newRange for the old side of the hunk.
+func oldRange(h Hunk) (first, last int) {
+	if h.OldCount == 0 {
+		return h.OldStart + 1, h.OldStart
+	}
+	return h.OldStart, h.OldStart + h.OldCount - 1
+}
+
+// withContext returns the hunk with above context lines before it and below
+// after it, read from side. Like parsed lines, each ends in a newline.
+func (h Hunk) withContext(side fileSide, above, below int) Hunk {
+	newFirst, newLast := newRange(h)
+	oldFirst, oldLast := oldRange(h)
+	first, last := side.span(h)
+
+	contextLine := func(n, oldNum, newNum int) Line {
+		return Line{Type: LineContext, Content: side.lines[n-1] + "\n", OldLineNum: oldNum, NewLineNum: newNum}
+	}
+	expanded := make([]Line, 0, above+len(h.Lines)+below)
+	for n := first - above; n < first; n++ {
+		expanded = append(expanded, contextLine(n, n-first+oldFirst, n-first+newFirst))
+	}
+	expanded = append(expanded, h.Lines...)
+	for n := last + 1; n <= last+below; n++ {
+		expanded = append(expanded, contextLine(n, n-last+oldLast, n-last+newLast))
+	}
+
+	h.Lines = expanded
+	h.OldStart = oldFirst - above
+	h.NewStart = newFirst - above
+	h.OldCount += above + below
+	h.NewCount += above + below
+	return h
+}
+
+// FullFile returns a copy of the file whose hunks are expanded to cover the
+// whole file, so every line of it is shown with the changes in place:
+// removed lines where they were deleted and added lines where they were
+// added. The side covered is the one ExpandContext reads. Reports false when
+// that isn't possible: the file has no hunks, or a hunk doesn't match lines.
+func (f FileDiff) FullFile(lines FileLines) (FileDiff, bool) {
+	side := f.contextSide(lines)
+	if len(f.Hunks) == 0 || len(side.lines) == 0 {
+		return f, false
+	}
+	expansions := make(map[int]ContextExpansion, len(f.Hunks))
+	for i := range f.Hunks {
+		expansions[i] = ContextExpansion{Above: len(side.lines), Below: len(side.lines)}
+	}
+	full := f.ExpandContext(lines, expansions)
+
+	// Every gap is filled only when all hunks matched
+	next := 1
+	for _, hunk := range full.Hunks {
+		first, last := side.span(hunk)
+		if first != next {
+			return f, false
+		}
+		next = last + 1
+	}
+	if next != len(side.lines)+1 {
+		return f, false
+	}
+	return full, true
+}
@@ -0,0 +1,214 @@
+package diffview_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/stretchr/testify/assert"
+)
+
+// numberedLines returns n lines "line 1" … "line n".
+func numberedLines(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+	return lines
+}
+
+// expandFixture has two hunks in a 30-line file: one replacing line 10
+// and one adding a line after line 20 (new line 21).
+func expandFixture() (diffview.FileDiff, []string) {
+	lines := numberedLines(30)
+	lines = append(lines[:20:20], append([]string{"inserted"}, lines[20:]...)...)
+	lines[9] = "changed 10"
+	file := diffview.FileDiff{OldPath: "f.go", NewPath: "f.go", Hunks: []diffview.Hunk{
+		{OldStart: 9, OldCount: 3, NewStart: 9, NewCount: 3, Lines: []diffview.Line{
+			ctxLine("line 9\n", 9, 9),
+			delLine("line 10\n", 10),
+			addLine("changed 10\n", 10),
+			ctxLine("line 11\n", 11, 11),
+		}},
+		{OldStart: 20, OldCount: 1, NewStart: 20, NewCount: 2, Lines: []diffview.Line{
+			ctxLine("line 20\n", 20, 20),
+			addLine("inserted\n", 21),
+		}},
+	}}
+	return file, lines
+}
+
+func TestFileDiff_ExpandContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("adds context above and below with both line numbers", func(t *testing.T) {
+		t.Parallel()
+
+		file, lines := expandFixture()
+
+		got := file.ExpandContext(diffview.FileLines{New: lines}, map[int]diffview.ContextExpansion{0: {Above: 2, Below: 1}})
+
+		hunk := got.Hunks[0]
+		assert.Equal(t, 7, hunk.OldStart)
+		assert.Equal(t, 6, hunk.OldCount)
+		assert.Equal(t, 7, hunk.NewStart)
+		assert.Equal(t, 6, hunk.NewCount)
+		assert.Equal(t, ctxLine("line 7\n", 7, 7), hunk.Lines[0])
+		assert.Equal(t, ctxLine("line 8\n", 8, 8), hunk.Lines[1])
+		assert.Equal(t, ctxLine("line 12\n", 12, 12), hunk.Lines[len(hunk.Lines)-1])
+		assert.Equal(t, file.Hunks[1], got.Hunks[1], "other hunks are unchanged")
+		assert.Len(t, file.Hunks[0].Lines, 4, "the original file is not modified")
+	})
+
+	t.Run("old line numbers follow the hunk offset", func(t *testing.T) {
+		t.Parallel()
+
+		file, lines := expandFixture()
+
+		got := file.ExpandContext(diffview.FileLines{New: lines}, map[int]diffview.ContextExpansion{1: {Below: 2}})
+
+		hunk := got.Hunks[1]
+		assert.Equal(t, ctxLine("line 21\n", 21, 22), hunk.Lines[2])
+		assert.Equal(t, ctxLine("line 22\n", 22, 23), hunk.Lines[3])
+		assert.Equal(t, 3, hunk.OldCount)
+		assert.Equal(t, 4, hunk.NewCount)
+	})
+
+	t.Run("expansion stops at the start and end of the file", func(t *testing.T) {
+		t.Parallel()
+
+		file, lines := expandFixture()
+
+		got := file.ExpandContext(diffview.FileLines{New: lines}, map[int]diffview.ContextExpansion{0: {Above: 100}, 1: {Below: 100}})
+
+		assert.Equal(t, 1, got.Hunks[0].NewStart)
+		assert.Equal(t, 1, got.Hunks[0].OldStart)
+		last := got.Hunks[1].Lines[len(got.Hunks[1].Lines)-1]
+		assert.Equal(t, ctxLine("line 30\n", 30, 31), last)
+	})
+
+	t.Run("gap between hunks fills without overlap", func(t *testing.T) {
+		t.Parallel()
+
+		file, lines := expandFixture()
+
+		got := file.ExpandContext(diffview.FileLines{New: lines}, map[int]diffview.ContextExpansion{0: {Below: 5}, 1: {Above: 100}})
+
+		first, second := got.Hunks[0], got.Hunks[1]
+		assert.Equal(t, 16, first.NewStart+first.NewCount-1)
+		assert.Equal(t, 17, second.NewStart)
+		assert.Equal(t, ctxLine("line 17\n", 17, 17), second.Lines[0])
+	})
+
+	t.Run("renamed file reads context from the old side", func(t *testing.T) {
+		t.Parallel()
+
+		file, _ := expandFixture()
+		file.OldPath = "old.go"
+
+		got := file.ExpandContext(diffview.FileLines{Old: numberedLines(30)}, map[int]diffview.ContextExpansion{0: {Above: 1}, 1: {Below: 2}})
+
+		assert.Equal(t, ctxLine("line 8\n", 8, 8), got.Hunks[0].Lines[0])
+		hunk := got.Hunks[1]
+		assert.Equal(t, ctxLine("line 21\n", 21, 22), hunk.Lines[2])
+		assert.Equal(t, ctxLine("line 22\n", 22, 23), hunk.Lines[3])
+	})
+
+	t.Run("file with the same paths reads context from the new side", func(t *testing.T) {
+		t.Parallel()
+
+		file, lines := expandFixture()
+		old := numberedLines(30)
+		old[7] = "old 8"
+
+		got := file.ExpandContext(diffview.FileLines{Old: old, New: lines}, map[int]diffview.ContextExpansion{0: {Above: 1}})
+
+		assert.Equal(t, ctxLine("line 8\n", 8, 8), got.Hunks[0].Lines[0])
+	})
+
+	t.Run("stale file content leaves hunks unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		file, _ := expandFixture()
+
+		got := file.ExpandContext(diffview.FileLines{New: numberedLines(30)}, map[int]diffview.ContextExpansion{0: {Above: 2}})
+
+		assert.Equal(t, file.Hunks[0], got.Hunks[0])
+	})
+}
+
+func TestFileDiff_FullFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("covers every line of the new version", func(t *testing.T) {
+		t.Parallel()
+
+		file, lines := expandFixture()
+
+		full, ok := file.FullFile(diffview.FileLines{New: lines})
+
+		assert.True(t, ok)
+		var shown []string
+		for _, hunk := range full.Hunks {
+			for _, line := range hunk.Lines {
+				if line.Type != diffview.LineDeleted {
+					shown = append(shown, strings.TrimSuffix(line.Content, "\n"))
+				}
+			}
+		}
+		assert.Equal(t, lines, shown)
+		assert.Len(t, full.Hunks, 2, "hunks keep their indices")
+	})
+
+	t.Run("renamed file covers every line of the old version", func(t *testing.T) {
+		t.Parallel()
+
+		file, _ := expandFixture()
+		file.OldPath = "old.go"
+
+		full, ok := file.FullFile(diffview.FileLines{Old: numberedLines(30)})
+
+		assert.True(t, ok)
+		var shown []string
+		for _, hunk := range full.Hunks {
+			for _, line := range hunk.Lines {
+				if line.Type != diffview.LineAdded {
+					shown = append(shown, strings.TrimSuffix(line.Content, "\n"))
+				}
+			}
+		}
+		assert.Equal(t, numberedLines(30), shown)
+	})
+
+	t.Run("old version only covers it with the added lines in place", func(t *testing.T) {
+		t.Parallel()
+
+		file, _ := expandFixture()
+
+		full, ok := file.FullFile(diffview.FileLines{Old: numberedLines(30)})
+
+		assert.True(t, ok)
+		var shown []string
+		for _, hunk := range full.Hunks {
+			for _, line := range hunk.Lines {
+				shown = append(shown, strings.TrimSuffix(line.Content, "\n"))
+			}
+		}
+		want := append(numberedLines(30)[:10:10], "changed 10")
+		want = append(want, numberedLines(30)[10:20]...)
+		want = append(want, "inserted")
+		want = append(want, numberedLines(30)[20:]...)
+		assert.Equal(t, want, shown)
+	})
+
+	t.Run("stale content is not a full file", func(t *testing.T) {
+		t.Parallel()
+
+		file, _ := expandFixture()
+
+		_, ok := file.FullFile(diffview.FileLines{New: numberedLines(30)})
+
+		assert.False(t, ok)
+	})
+}
@@ -57,14 +57,13 @@ func (f *DefaultFormatter) Format(input ClassificationInput) string {
 	for _, file := range input.Diff.Files {
 		// File header
 		sb.WriteString(fmt.Sprintf("=== FILE: %s (%s) ===\n\n",
-			filePath(file), operationName(file.Operation)))
+			file.Path(), fileDetails(file)))
 
 		// Hunks
 		for _, hunk := range file.Hunks {
-			sb.WriteString(fmt.Sprintf("--- HUNK H%d (@@ -%d,%d +%d,%d @@) ---\n",
-				hunkNum, hunk.OldStart, hunk.OldCount, hunk.NewStart, hunk.NewCount))
+			sb.WriteString(fmt.Sprintf("--- HUNK H%d (%s) ---\n", hunkNum, hunk.Range()))
 			for _, line := range hunk.Lines {
-				prefix := linePrefix(line.Type)
+				prefix := line.Prefix()
 				sb.WriteString(prefix)
 				sb.WriteString(line.Content)
 				if !strings.HasSuffix(line.Content, "\n") {
@@ -77,14 +76,51 @@ func (f *DefaultFormatter) Format(input ClassificationInput) string {
 	}
 
 	sb.WriteString("</diff>")
+
+	// Moved code section (when blocks were detected)
+	formatMovedBlocks(&sb, &input.Diff)
 	return sb.String()
 }
 
-func filePath(file FileDiff) string {
-	if file.NewPath != "" {
-		return file.NewPath
+// formatMovedBlocks writes the blocks of code the diff moves unchanged,
+// referring to hunks by the IDs used in the diff section.
+func formatMovedBlocks(sb *strings.Builder, diff *Diff) {
+	blocks := DetectMovedBlocks(diff, DefaultMinMovedLines)
+	if len(blocks) == 0 {
+		return
+	}
+
+	hunkIDs := make(map[MovedLocation]int)
+	hunkNum := 1
+	for _, file := range diff.Files {
+		for i := range file.Hunks {
+			hunkIDs[MovedLocation{File: file.Path(), HunkIndex: i}] = hunkNum
+			hunkNum++
+		}
+	}
+	hunkID := func(loc MovedLocation) int {
+		return hunkIDs[MovedLocation{File: loc.File, HunkIndex: loc.HunkIndex}]
+	}
+
+	sb.WriteString("\n\n<moved-code>\n")
+	for _, b := range blocks {
+		fmt.Fprintf(sb, "- %d lines moved from H%d (%s:%d) to H%d (%s:%d)\n",
+			b.Lines, hunkID(b.From), b.From.File, b.From.Line, hunkID(b.To), b.To.File, b.To.Line)
+	}
+	sb.WriteString("</moved-code>")
+}
+
+// fileDetails describes the file's operation for the prompt, with the old
+// path of renames and copies, similarity and mode changes.
+func fileDetails(file FileDiff) string {
+	details := operationName(file.Operation)
+	if file.Operation == FileRenamed || file.Operation == FileCopied {
+		details += " from " + file.OldPath
+	}
+	if notes := file.Notes(); len(notes) > 0 {
+		details += ", " + strings.Join(notes, ", ")
 	}
-	return file.OldPath
+	return details
 }
 
 func operationName(op FileOp) string {
@@ -104,17 +140,6 @@ func operationName(op FileOp) string {
 	}
 }
 
-func linePrefix(lt LineType) string {
-	switch lt {
-	case LineAdded:
-		return "+"
-	case LineDeleted:
-		return "-"
-	default:
-		return " "
-	}
-}
-
 // hasPerCommitDiffs returns true if any commit has a non-empty diff.
 func hasPerCommitDiffs(commits []CommitBrief) bool {
 	for _, c := range commits {
@@ -131,7 +156,7 @@ func formatDiffSummary(sb *strings.Builder, diff *Diff) {
 	for _, file := range diff.Files {
 		adds, dels := file.Stats()
 		fmt.Fprintf(sb, "  %s (%s): +%d/-%d\n",
-			filePath(file), operationName(file.Operation), adds, dels)
+			file.Path(), operationName(file.Operation), adds, dels)
 	}
 	sb.WriteString("\n")
 }
@@ -508,3 +508,38 @@ func TestDefaultFormatter_Format_PartialPerCommitDiffs(t *testing.T) {
 	assert.NotContains(t, result, "COMMIT 2 [def456]")
 	assert.Contains(t, result, "</commit-diffs>")
 }
+
+func TestDefaultFormatter_Format_MovedCode(t *testing.T) {
+	t.Parallel()
+
+	formatter := &diffview.DefaultFormatter{}
+
+	result := formatter.Format(diffview.ClassificationInput{Diff: *movedFixture("")})
+
+	assert.Contains(t, result, "<moved-code>\n- 7 lines moved from H2 (config.go:40) to H3 (parse.go:10)\n</moved-code>")
+}
+
+func TestDefaultFormatter_Format_RenameAndModeChange(t *testing.T) {
+	t.Parallel()
+
+	input := diffview.ClassificationInput{
+		Repo: "testrepo",
+		Diff: diffview.Diff{
+			Files: []diffview.FileDiff{
+				{
+					OldPath:    "old.sh",
+					NewPath:    "new.sh",
+					Operation:  diffview.FileRenamed,
+					Similarity: 90,
+					OldMode:    0o100644,
+					NewMode:    0o100755,
+				},
+			},
+		},
+	}
+
+	formatter := &diffview.DefaultFormatter{}
+	result := formatter.Format(input)
+
+	assert.Contains(t, result, "=== FILE: new.sh (renamed from old.sh, 90% similar, mode 100644 → 100755) ===")
+}
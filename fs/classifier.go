@@ -16,16 +16,33 @@ var _ diffview.StoryClassifier = (*Classifier)(nil)
 
 // Classifier wraps a StoryClassifier with file-based caching.
 type Classifier struct {
-	inner    diffview.StoryClassifier
-	cacheDir string
+	inner       diffview.StoryClassifier
+	cacheDir    string
+	diffOptions diffview.DiffOptions
+}
+
+// ClassifierOption configures a Classifier.
+type ClassifierOption func(*Classifier)
+
+// WithDiffOptions sets the options the classified diffs were generated
+// with. They are part of the cache key, so a diff generated with other
+// options isn't given a cached classification.
+func WithDiffOptions(opts diffview.DiffOptions) ClassifierOption {
+	return func(c *Classifier) {
+		c.diffOptions = opts
+	}
 }
 
 // NewClassifier creates a new caching classifier.
-func NewClassifier(inner diffview.StoryClassifier, cacheDir string) *Classifier {
-	return &Classifier{
+func NewClassifier(inner diffview.StoryClassifier, cacheDir string, opts ...ClassifierOption) *Classifier {
+	c := &Classifier{
 		inner:    inner,
 		cacheDir: cacheDir,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // Classify returns a cached classification or delegates to inner classifier.
@@ -49,10 +66,16 @@ func (c *Classifier) Classify(ctx context.Context, input diffview.Classification
 	return result, nil
 }
 
+// hashInput returns the cache key of input and the diff options it was
+// generated with.
 func (c *Classifier) hashInput(input diffview.ClassificationInput) string {
+	h := sha256.New()
 	data, _ := json.Marshal(input)
-	sum := sha256.Sum256(data)
-	return hex.EncodeToString(sum[:])
+	h.Write(data)
+	opts, _ := json.Marshal(c.diffOptions)
+	h.Write([]byte("\x00diff options:"))
+	h.Write(opts)
+	return hex.EncodeToString(h.Sum(nil))
 }
 
 func (c *Classifier) cachePath(hash string) string {
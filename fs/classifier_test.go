@@ -128,6 +128,43 @@ func TestClassifier_DifferentInput_CallsInnerAgain(t *testing.T) {
 	assert.Equal(t, 2, callCount, "first input should still be cached")
 }
 
+func TestClassifier_DiffOptions_PartOfCacheKey(t *testing.T) {
+	t.Parallel()
+
+	cacheDir := t.TempDir()
+	callCount := 0
+	inner := &mock.StoryClassifier{
+		ClassifyFn: func(ctx context.Context, input diffview.ClassificationInput) (*diffview.StoryClassification, error) {
+			callCount++
+			return &diffview.StoryClassification{ChangeType: "feature"}, nil
+		},
+	}
+	input := diffview.ClassificationInput{
+		Diff: diffview.Diff{
+			Files: []diffview.FileDiff{{NewPath: "file.go"}},
+		},
+	}
+
+	_, err := fs.NewClassifier(inner, cacheDir).Classify(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, 1, callCount)
+
+	// Default options share the cache entry
+	_, err = fs.NewClassifier(inner, cacheDir, fs.WithDiffOptions(diffview.DiffOptions{})).Classify(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, 1, callCount, "default options should hit the cache")
+
+	// Other options don't
+	withContext := fs.NewClassifier(inner, cacheDir, fs.WithDiffOptions(diffview.DiffOptions{Context: 10}))
+	_, err = withContext.Classify(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, 2, callCount, "different options should trigger new inner call")
+
+	_, err = withContext.Classify(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, 2, callCount, "same options should hit the cache")
+}
+
 func TestDefaultCacheDir_UsesXDGIfSet(t *testing.T) {
 	// Can't use t.Parallel with t.Setenv
 	t.Setenv("XDG_CACHE_HOME", "/custom/cache")
@@ -0,0 +1,50 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/lipgloss"
+)
+
+// DefaultConfigPath returns the path of the diffstory config file.
+// Uses XDG_CONFIG_HOME if set, otherwise falls back to ~/.config/diffstory.
+func DefaultConfigPath() string {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "diffstory", "config.toml")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".config", "diffstory", "config.toml")
+}
+
+// LoadConfig reads a TOML config file. A missing file yields an empty Config.
+// Relative theme file paths are resolved against the config file's directory.
+func LoadConfig(path string) (diffview.Config, error) {
+	var cfg diffview.Config
+	if path == "" {
+		return cfg, nil
+	}
+
+	md, err := toml.DecodeFile(path, &cfg)
+	if errors.Is(err, os.ErrNotExist) {
+		return diffview.Config{}, nil
+	}
+	if err != nil {
+		return diffview.Config{}, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return diffview.Config{}, fmt.Errorf("config %s: unknown key %q", path, undecoded[0].String())
+	}
+
+	if lipgloss.IsThemeFile(cfg.Theme) && !filepath.IsAbs(cfg.Theme) {
+		cfg.Theme = filepath.Join(filepath.Dir(path), cfg.Theme)
+	}
+	return cfg, nil
+}
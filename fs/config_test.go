@@ -0,0 +1,110 @@
+package fs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("missing file yields empty config", func(t *testing.T) {
+		t.Parallel()
+
+		cfg, err := fs.LoadConfig(filepath.Join(t.TempDir(), "config.toml"))
+		require.NoError(t, err)
+		assert.Empty(t, cfg.Theme)
+	})
+
+	t.Run("preset theme", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "config.toml")
+		require.NoError(t, os.WriteFile(path, []byte(`theme = "dracula"`), 0o600))
+
+		cfg, err := fs.LoadConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, "dracula", cfg.Theme)
+	})
+
+	t.Run("relative theme file resolves against config dir", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.toml")
+		require.NoError(t, os.WriteFile(path, []byte(`theme = "themes/mine.toml"`), 0o600))
+
+		cfg, err := fs.LoadConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "themes", "mine.toml"), cfg.Theme)
+	})
+
+	t.Run("key bindings per TUI", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "config.toml")
+		require.NoError(t, os.WriteFile(path, []byte(`
+[keys.story]
+next_section = ["]"]
+
+[keys.eval]
+next_case = ["J", "down"]
+`), 0o600))
+
+		cfg, err := fs.LoadConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"]"}, cfg.Keys.Story["next_section"])
+		assert.Equal(t, []string{"J", "down"}, cfg.Keys.Eval["next_case"])
+		assert.Empty(t, cfg.Keys.Viewer)
+	})
+
+	t.Run("git backend", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "config.toml")
+		require.NoError(t, os.WriteFile(path, []byte(`git = "go-git"`), 0o600))
+
+		cfg, err := fs.LoadConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, "go-git", cfg.Git)
+	})
+
+	t.Run("diff options", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "config.toml")
+		require.NoError(t, os.WriteFile(path, []byte(`
+[diff]
+algorithm = "histogram"
+context = 5
+rename_threshold = 70
+exclude = ["vendor", "*.pb.go"]
+`), 0o600))
+
+		cfg, err := fs.LoadConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, diffview.DiffOptions{
+			Algorithm:       diffview.DiffAlgorithmHistogram,
+			Context:         5,
+			RenameThreshold: 70,
+			Exclude:         []string{"vendor", "*.pb.go"},
+		}, cfg.Diff)
+	})
+
+	t.Run("unknown key is an error", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "config.toml")
+		require.NoError(t, os.WriteFile(path, []byte(`them = "light"`), 0o600))
+
+		_, err := fs.LoadConfig(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "them")
+	})
+}
@@ -0,0 +1,51 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/fwojciec/diffstory"
+)
+
+// Compile-time interface verification.
+var _ diffview.FileVersionSource = (*WorkingTree)(nil)
+
+// WorkingTree reads the new version of a diff's files from a directory,
+// typically the repository the diff was taken from.
+type WorkingTree struct {
+	dir string
+}
+
+// NewWorkingTree creates a WorkingTree rooted at dir.
+func NewWorkingTree(dir string) *WorkingTree {
+	return &WorkingTree{dir: dir}
+}
+
+// FileVersions implements diffview.FileVersionSource. Only the new side is
+// filled in. Deleted files, paths that leave the directory and files that
+// can't be read are left out.
+func (w *WorkingTree) FileVersions(ctx context.Context, diff *diffview.Diff) (map[string]diffview.FileVersions, error) {
+	if diff == nil {
+		return nil, nil
+	}
+	versions := make(map[string]diffview.FileVersions)
+	for _, file := range diff.Files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if file.IsBinary || file.Operation == diffview.FileDeleted {
+			continue
+		}
+		path := file.Path()
+		if !filepath.IsLocal(path) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(w.dir, path))
+		if err != nil {
+			continue
+		}
+		versions[path] = diffview.FileVersions{New: string(data)}
+	}
+	return versions, nil
+}
@@ -0,0 +1,36 @@
+package fs_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkingTree_FileVersions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "pkg"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", "main.go"), []byte("package main\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "gone.go"), []byte("package gone\n"), 0o600))
+
+	diff := &diffview.Diff{Files: []diffview.FileDiff{
+		{OldPath: "pkg/main.go", NewPath: "pkg/main.go"},
+		{OldPath: "gone.go", Operation: diffview.FileDeleted},
+		{OldPath: "missing.go", NewPath: "missing.go"},
+		{OldPath: "../outside.go", NewPath: "../outside.go"},
+	}}
+
+	versions, err := fs.NewWorkingTree(dir).FileVersions(context.Background(), diff)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]diffview.FileVersions{
+		"pkg/main.go": {New: "package main\n"},
+	}, versions)
+}
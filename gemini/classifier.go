@@ -302,6 +302,8 @@ For each hunk, determine:
 - **category**: refactoring (restructure without behavior change), systematic (mechanical changes like renames), core (essential logic change), noise (formatting, whitespace)
 - **collapsed**: whether to collapse in a diff viewer (true for noise, often true for systematic; never collapse tests - they verify intent and are essential for review)
 
+When the input has a <moved-code> section, the listed blocks were deleted in one hunk and added unchanged (apart from indentation) in another. Don't read moved code as new logic: classify hunks that only move code as **systematic** and collapse them, with collapse_text naming where the code moved. A hunk that moves code and also changes it keeps the category of its real change.
+
 Group hunks into sections with meaningful roles that tell the story of the change.
 
 ## Rules
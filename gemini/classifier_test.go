@@ -173,6 +173,7 @@ func TestBuildClassificationPrompt_IncludesInstructions(t *testing.T) {
 	assert.Contains(t, prompt, "core-periphery")
 	assert.Contains(t, prompt, "sections")
 	assert.Contains(t, prompt, "hunk_index")
+	assert.Contains(t, prompt, "<moved-code>")
 }
 
 func TestBuildClassificationConfig_UsesDefaultTemperature(t *testing.T) {
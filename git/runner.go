@@ -3,8 +3,12 @@ package git
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/fwojciec/diffstory"
@@ -14,11 +18,27 @@ import (
 var _ diffview.GitRunner = (*Runner)(nil)
 
 // Runner executes git commands via shell.
-type Runner struct{}
+type Runner struct {
+	diffOptions diffview.DiffOptions
+}
+
+// RunnerOption configures a Runner.
+type RunnerOption func(*Runner)
+
+// WithDiffOptions sets how diffs are generated.
+func WithDiffOptions(opts diffview.DiffOptions) RunnerOption {
+	return func(r *Runner) {
+		r.diffOptions = opts
+	}
+}
 
 // NewRunner creates a new git runner.
-func NewRunner() *Runner {
-	return &Runner{}
+func NewRunner(opts ...RunnerOption) *Runner {
+	r := &Runner{}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // Log returns commit hashes from the repository at repoPath, limited to n commits.
@@ -46,7 +66,8 @@ func (r *Runner) Log(ctx context.Context, repoPath string, limit int) ([]string,
 
 // Show returns the diff for a specific commit hash.
 func (r *Runner) Show(ctx context.Context, repoPath string, hash string) (string, error) {
-	args := []string{"-C", repoPath, "show", "--format=", hash}
+	args := append([]string{"-C", repoPath, "show", "--format="}, r.diffArgs()...)
+	args = append(append(args, hash), r.pathspecArgs()...)
 	cmd := exec.CommandContext(ctx, "git", args...)
 	output, err := cmd.Output()
 	if err != nil {
@@ -98,6 +119,25 @@ func (r *Runner) MergeCommits(ctx context.Context, repoPath string, limit int) (
 	return hashes, nil
 }
 
+// CountCommits returns the number of commits between base and head (base
+// exclusive, head inclusive).
+func (r *Runner) CountCommits(ctx context.Context, repoPath, base, head string) (int, error) {
+	args := []string{"-C", repoPath, "rev-list", "--count", fmt.Sprintf("%s..%s", base, head)}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return 0, fmt.Errorf("git rev-list failed: %s", string(exitErr.Stderr))
+		}
+		return 0, fmt.Errorf("git rev-list failed: %w", err)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return 0, fmt.Errorf("git rev-list failed: unexpected output %q", output)
+	}
+	return n, nil
+}
+
 // CommitsInRange returns commits between base and head (base exclusive, head inclusive).
 func (r *Runner) CommitsInRange(ctx context.Context, repoPath, base, head string) ([]diffview.CommitBrief, error) {
 	// Use null byte as separator between hash and subject for safe parsing
@@ -141,9 +181,10 @@ func (r *Runner) DiffRange(ctx context.Context, repoPath, base, head string) (st
 }
 
 // Diff returns the diff for a raw range specification.
-// The rangeSpec is passed directly to git diff.
+// The rangeSpec is passed directly to git diff, after the diff options.
 func (r *Runner) Diff(ctx context.Context, repoPath, rangeSpec string) (string, error) {
-	args := []string{"-C", repoPath, "diff", rangeSpec}
+	args := append([]string{"-C", repoPath, "diff"}, r.diffArgs()...)
+	args = append(append(args, rangeSpec), r.pathspecArgs()...)
 	cmd := exec.CommandContext(ctx, "git", args...)
 	output, err := cmd.Output()
 	if err != nil {
@@ -204,3 +245,190 @@ func (r *Runner) DefaultBranch(ctx context.Context, repoPath string) (string, er
 	branch := strings.TrimPrefix(ref, "refs/remotes/origin/")
 	return branch, nil
 }
+
+// Upstream returns the upstream tracking branch of the current branch.
+// Returns an error if the current branch has no upstream.
+func (r *Runner) Upstream(ctx context.Context, repoPath string) (string, error) {
+	output, err := r.output(ctx, repoPath, nil, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// Branches returns the local branches, then the remote-tracking branches,
+// each sorted by name, without symbolic refs such as origin/HEAD.
+func (r *Runner) Branches(ctx context.Context, repoPath string) ([]string, error) {
+	output, err := r.output(ctx, repoPath, nil, "for-each-ref", "--format=%(refname)%00%(symref)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+	var local, remote []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		ref, symref, _ := strings.Cut(line, "\x00")
+		if symref != "" {
+			continue
+		}
+		if name, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			local = append(local, name)
+		} else if name, ok := strings.CutPrefix(ref, "refs/remotes/"); ok {
+			remote = append(remote, name)
+		}
+	}
+	return append(local, remote...), nil
+}
+
+// ShowFile returns the full content of path at rev, or in the index when
+// rev is empty.
+func (r *Runner) ShowFile(ctx context.Context, repoPath, rev, path string) (string, error) {
+	args := []string{"-C", repoPath, "show", rev + ":" + path}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("git show failed: %s", string(exitErr.Stderr))
+		}
+		return "", fmt.Errorf("git show failed: %w", err)
+	}
+	return string(output), nil
+}
+
+// StagedDiff returns the diff of the changes staged in the index against HEAD.
+func (r *Runner) StagedDiff(ctx context.Context, repoPath string) (string, error) {
+	return r.diff(ctx, repoPath, nil, "--cached")
+}
+
+// WorktreeDiff returns the diff between rev and the working tree. Untracked
+// files are marked intent-to-add in a copy of the index, so they show as
+// added without touching the repository's index.
+func (r *Runner) WorktreeDiff(ctx context.Context, repoPath, rev string) (string, error) {
+	untracked, err := r.output(ctx, repoPath, nil, "ls-files", "-z", "--others", "--exclude-standard")
+	if err != nil {
+		return "", err
+	}
+	if untracked == "" {
+		return r.diff(ctx, repoPath, nil, rev)
+	}
+	paths := strings.Split(strings.TrimSuffix(untracked, "\x00"), "\x00")
+
+	index, err := r.output(ctx, repoPath, nil, "rev-parse", "--path-format=absolute", "--git-path", "index")
+	if err != nil {
+		return "", err
+	}
+	dir, err := os.MkdirTemp("", "diffstory-index-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	tmpIndex := filepath.Join(dir, "index")
+	if err := copyIndex(tmpIndex, strings.TrimSpace(index)); err != nil {
+		return "", err
+	}
+
+	env := []string{"GIT_INDEX_FILE=" + tmpIndex}
+	if _, err := r.output(ctx, repoPath, env, append([]string{"add", "--intent-to-add", "--"}, paths...)...); err != nil {
+		return "", err
+	}
+	return r.diff(ctx, repoPath, env, rev)
+}
+
+// ApplyPatch applies patch with git apply, to the index with opts.Cached
+// and in reverse with opts.Reverse. The patch is checked as a whole first,
+// so nothing is changed when any of it doesn't apply.
+func (r *Runner) ApplyPatch(ctx context.Context, repoPath, patch string, opts diffview.ApplyOptions) error {
+	args := []string{"-C", repoPath, "apply"}
+	if opts.Cached {
+		args = append(args, "--cached")
+	}
+	if opts.Reverse {
+		args = append(args, "--reverse")
+	}
+	args = append(args, "-")
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stdin = strings.NewReader(patch)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git apply failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// diff runs git diff with the diff options and args, limited to the
+// pathspecs of the options.
+func (r *Runner) diff(ctx context.Context, repoPath string, env []string, args ...string) (string, error) {
+	diffArgs := append([]string{"diff"}, r.diffArgs()...)
+	diffArgs = append(append(diffArgs, args...), r.pathspecArgs()...)
+	return r.output(ctx, repoPath, env, diffArgs...)
+}
+
+// diffArgs returns the flags for git diff and git show: the diff options,
+// after flags that keep the output parseable whatever the user's git
+// config says (color, external diff drivers and path prefixes).
+func (r *Runner) diffArgs() []string {
+	o := r.diffOptions
+	args := []string{"--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/"}
+	if o.Algorithm != "" {
+		args = append(args, "--diff-algorithm="+string(o.Algorithm))
+	}
+	if o.Context > 0 {
+		args = append(args, fmt.Sprintf("-U%d", o.Context))
+	}
+	switch {
+	case o.NoRenames:
+		args = append(args, "--no-renames")
+	case o.RenameThreshold > 0:
+		args = append(args, fmt.Sprintf("--find-renames=%d%%", o.RenameThreshold))
+	default:
+		args = append(args, "--find-renames")
+	}
+	if o.CopyThreshold > 0 {
+		args = append(args, fmt.Sprintf("--find-copies=%d%%", o.CopyThreshold))
+	}
+	return args
+}
+
+// pathspecArgs returns the pathspecs limiting diffs to the included paths
+// without the excluded ones, after "--", or nothing if there are none.
+func (r *Runner) pathspecArgs() []string {
+	o := r.diffOptions
+	if len(o.Include) == 0 && len(o.Exclude) == 0 {
+		return nil
+	}
+	args := append([]string{"--"}, o.Include...)
+	for _, spec := range o.Exclude {
+		args = append(args, ":(exclude)"+spec)
+	}
+	return args
+}
+
+// copyIndex copies the index at src to dst. A missing index, as in a
+// repository without commits, is not copied; git creates it when needed.
+func copyIndex(dst, src string) error {
+	data, err := os.ReadFile(src)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+	if err := os.WriteFile(dst, data, 0o600); err != nil {
+		return fmt.Errorf("failed to copy index: %w", err)
+	}
+	return nil
+}
+
+// output runs git with args in repoPath, with env added to the environment,
+// and returns its standard output.
+func (r *Runner) output(ctx context.Context, repoPath string, env []string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", repoPath}, args...)...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("git %s failed: %s", args[0], string(exitErr.Stderr))
+		}
+		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return string(output), nil
+}
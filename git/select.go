@@ -0,0 +1,30 @@
+package git
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/gogit"
+)
+
+// SelectRunner returns the git backend named by name (the --git flag), then
+// $DIFFSTORY_GIT, then the config file, generating diffs with diffOpts:
+// "exec" (the default) runs the git command, "go-git" reads the repository
+// in-process.
+func SelectRunner(name string, cfg diffview.Config, diffOpts diffview.DiffOptions) (diffview.GitRunner, error) {
+	if name == "" {
+		name = os.Getenv("DIFFSTORY_GIT")
+	}
+	if name == "" {
+		name = cfg.Git
+	}
+	switch name {
+	case "", "exec":
+		return NewRunner(WithDiffOptions(diffOpts)), nil
+	case "go-git":
+		return gogit.NewRunner(gogit.WithDiffOptions(diffOpts)), nil
+	default:
+		return nil, fmt.Errorf("unknown git backend %q (use exec or go-git)", name)
+	}
+}
@@ -0,0 +1,42 @@
+package git_test
+
+import (
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/git"
+	"github.com/fwojciec/diffstory/gogit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectRunner(t *testing.T) {
+	// Can't use t.Parallel with t.Setenv
+	t.Setenv("DIFFSTORY_GIT", "")
+
+	runner, err := git.SelectRunner("", diffview.Config{}, diffview.DiffOptions{})
+	require.NoError(t, err)
+	assert.IsType(t, &git.Runner{}, runner)
+
+	runner, err = git.SelectRunner("", diffview.Config{Git: "go-git"}, diffview.DiffOptions{})
+	require.NoError(t, err)
+	assert.IsType(t, &gogit.Runner{}, runner)
+
+	runner, err = git.SelectRunner("exec", diffview.Config{Git: "go-git"}, diffview.DiffOptions{})
+	require.NoError(t, err)
+	assert.IsType(t, &git.Runner{}, runner, "flag should override config")
+
+	_, err = git.SelectRunner("libgit2", diffview.Config{}, diffview.DiffOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown git backend")
+}
+
+func TestSelectRunner_Environment(t *testing.T) {
+	// Can't use t.Parallel with t.Setenv
+	t.Setenv("DIFFSTORY_GIT", "go-git")
+
+	runner, err := git.SelectRunner("", diffview.Config{Git: "exec"}, diffview.DiffOptions{})
+
+	require.NoError(t, err)
+	assert.IsType(t, &gogit.Runner{}, runner, "environment should override config")
+}
@@ -0,0 +1,263 @@
+package gitdiff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/diffstory"
+)
+
+// chunk is a run of diff input that is either a single combined diff file
+// (diff --cc or diff --combined) or any number of ordinary git diff files.
+type chunk struct {
+	text     string
+	combined bool
+}
+
+// splitCombined splits diff input into chunks so combined diff files, which
+// go-gitdiff skips, can be parsed separately while keeping file order. A
+// combined diff file ends at the first line that can't belong to it, such as
+// the next commit's header in git log --cc output.
+func splitCombined(input string) []chunk {
+	var chunks []chunk
+	var current strings.Builder
+	combined := false
+	parents := 0 // Parents of the current combined hunk, 0 before the first
+	flush := func() {
+		if current.Len() > 0 {
+			chunks = append(chunks, chunk{text: current.String(), combined: combined})
+			current.Reset()
+		}
+	}
+	for line := range strings.Lines(input) {
+		switch {
+		case isCombinedHeader(line):
+			flush()
+			combined = true
+			parents = 0
+		case !combined:
+		case strings.HasPrefix(line, "@@@"):
+			parents = len(line) - len(strings.TrimLeft(line, "@")) - 1
+		case parents == 0 && !isCombinedFileHeader(line),
+			parents > 0 && !isCombinedHunkLine(line, parents):
+			flush()
+			combined = false
+		}
+		current.WriteString(line)
+	}
+	flush()
+	return chunks
+}
+
+// isCombinedFileHeader reports whether line may appear between a combined
+// diff header and its first hunk.
+func isCombinedFileHeader(line string) bool {
+	for _, prefix := range []string{"index ", "mode ", "new file mode ", "deleted file mode ", "--- ", "+++ ", "Binary files "} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCombinedHunkLine reports whether line may appear in a combined hunk with
+// the given number of parents: a line with a prefix column per parent, or a
+// "\ No newline at end of file" marker.
+func isCombinedHunkLine(line string, parents int) bool {
+	if strings.HasPrefix(line, `\`) {
+		return true
+	}
+	if len(line) < parents {
+		return false
+	}
+	return strings.Trim(line[:parents], " +-") == ""
+}
+
+// isCombinedHeader reports whether line starts a combined diff file.
+func isCombinedHeader(line string) bool {
+	return strings.HasPrefix(line, "diff --cc ") || strings.HasPrefix(line, "diff --combined ")
+}
+
+// parseCombinedFile parses one combined diff file, as printed by git for
+// merge commits: a hunk header has one range per parent, and each line has
+// one prefix column per parent.
+func parseCombinedFile(text string) (diffview.FileDiff, error) {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	header := lines[0]
+	path := strings.TrimPrefix(strings.TrimPrefix(header, "diff --cc "), "diff --combined ")
+	fd := diffview.FileDiff{
+		OldPath:   path,
+		NewPath:   path,
+		Operation: diffview.FileModified,
+	}
+
+	var hunk *diffview.Hunk
+	var parentNums []int
+	newNum := 0
+	finishHunk := func() {
+		if hunk != nil {
+			fd.Hunks = append(fd.Hunks, *hunk)
+			hunk = nil
+		}
+	}
+
+	for _, line := range lines[1:] {
+		if hunk == nil || strings.HasPrefix(line, "@@") {
+			switch {
+			case strings.HasPrefix(line, "@@"):
+				finishHunk()
+				h, err := parseCombinedHunkHeader(line)
+				if err != nil {
+					return diffview.FileDiff{}, err
+				}
+				hunk = &h
+				parentNums = make([]int, len(h.Parents))
+				for i, p := range h.Parents {
+					parentNums[i] = p.Start
+				}
+				newNum = h.NewStart
+			case strings.HasPrefix(line, "--- "):
+				fd.OldPath = combinedPath(strings.TrimPrefix(line, "--- "), "a/")
+				if fd.OldPath == "" {
+					fd.Operation = diffview.FileAdded
+				}
+			case strings.HasPrefix(line, "+++ "):
+				fd.NewPath = combinedPath(strings.TrimPrefix(line, "+++ "), "b/")
+				if fd.NewPath == "" {
+					fd.Operation = diffview.FileDeleted
+				}
+			case strings.HasPrefix(line, "Binary files "):
+				fd.IsBinary = true
+			default:
+				fd.Extended = append(fd.Extended, line)
+				if strings.HasPrefix(line, "new file mode ") {
+					fd.Operation = diffview.FileAdded
+				}
+				if strings.HasPrefix(line, "deleted file mode ") {
+					fd.Operation = diffview.FileDeleted
+				}
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, `\`) {
+			if n := len(hunk.Lines); n > 0 {
+				hunk.Lines[n-1].NoNewline = true
+			}
+			continue
+		}
+		parents := len(hunk.Parents)
+		if len(line) < parents {
+			continue
+		}
+		l, ok := combinedLine(line[:parents], line[parents:]+"\n")
+		if !ok {
+			continue
+		}
+		for i, o := range l.Origins {
+			inParent := o == diffview.LineDeleted || (l.Type != diffview.LineDeleted && o == diffview.LineContext)
+			if !inParent {
+				continue
+			}
+			if i == 0 {
+				l.OldLineNum = parentNums[i]
+			}
+			parentNums[i]++
+		}
+		if l.Type != diffview.LineDeleted {
+			l.NewLineNum = newNum
+			newNum++
+		}
+		hunk.Lines = append(hunk.Lines, l)
+	}
+	finishHunk()
+	return fd, nil
+}
+
+// combinedPath returns the file path from a ---/+++ line, or "" for
+// /dev/null.
+func combinedPath(path, prefix string) string {
+	if path == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(path, prefix)
+}
+
+// combinedLine builds a line from its prefix columns and content. Reports
+// false when a column isn't a diff prefix.
+func combinedLine(columns, content string) (diffview.Line, bool) {
+	l := diffview.Line{
+		Type:    diffview.LineContext,
+		Content: content,
+		Origins: make([]diffview.LineType, len(columns)),
+	}
+	for i, c := range columns {
+		switch c {
+		case ' ':
+			l.Origins[i] = diffview.LineContext
+		case '+':
+			l.Origins[i] = diffview.LineAdded
+			if l.Type == diffview.LineContext {
+				l.Type = diffview.LineAdded
+			}
+		case '-':
+			l.Origins[i] = diffview.LineDeleted
+			l.Type = diffview.LineDeleted
+		default:
+			return diffview.Line{}, false
+		}
+	}
+	return l, true
+}
+
+// parseCombinedHunkHeader parses a header such as
+// "@@@ -1,5 -1,5 +1,6 @@@ func main()", with one more @ than parents.
+func parseCombinedHunkHeader(line string) (diffview.Hunk, error) {
+	marker := line[:len(line)-len(strings.TrimLeft(line, "@"))]
+	rest, section, ok := strings.Cut(strings.TrimPrefix(line, marker+" "), " "+marker)
+	if !ok || len(marker) < 3 {
+		return diffview.Hunk{}, fmt.Errorf("invalid combined hunk header: %q", line)
+	}
+
+	fields := strings.Fields(rest)
+	parents := len(marker) - 1
+	if len(fields) != parents+1 {
+		return diffview.Hunk{}, fmt.Errorf("invalid combined hunk header: %q", line)
+	}
+	var h diffview.Hunk
+	for _, f := range fields[:parents] {
+		start, count, err := ParseRange(f, "-")
+		if err != nil {
+			return diffview.Hunk{}, fmt.Errorf("invalid combined hunk header: %q: %w", line, err)
+		}
+		h.Parents = append(h.Parents, diffview.ParentRange{Start: start, Count: count})
+	}
+	start, count, err := ParseRange(fields[parents], "+")
+	if err != nil {
+		return diffview.Hunk{}, fmt.Errorf("invalid combined hunk header: %q: %w", line, err)
+	}
+	h.NewStart, h.NewCount = start, count
+	h.OldStart, h.OldCount = h.Parents[0].Start, h.Parents[0].Count
+	h.Section = strings.TrimPrefix(section, " ")
+	return h, nil
+}
+
+// ParseRange parses a range such as "-1,5" or "+3"; the count defaults to 1.
+func ParseRange(s, sign string) (start, count int, err error) {
+	s, ok := strings.CutPrefix(s, sign)
+	if !ok {
+		return 0, 0, fmt.Errorf("range %q must start with %q", s, sign)
+	}
+	startStr, countStr, hasCount := strings.Cut(s, ",")
+	if start, err = strconv.Atoi(startStr); err != nil {
+		return 0, 0, err
+	}
+	count = 1
+	if hasCount {
+		if count, err = strconv.Atoi(countStr); err != nil {
+			return 0, 0, err
+		}
+	}
+	return start, count, nil
+}
@@ -0,0 +1,184 @@
+package gitdiff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/gitdiff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// combinedDiff is git show output for a merge whose conflict on line 2 was
+// resolved by hand, with an extra line added in the merge itself.
+const combinedDiff = `diff --cc f.txt
+index 7b37c73,94235ab..d3c8fbd
+--- a/f.txt
++++ b/f.txt
+@@@ -1,5 -1,5 +1,6 @@@ func main()
+  a
+- B2
+ -B1
+++B3
+  c
+  d
+ -e
+ +E
+++extra
+`
+
+func TestParser_Parse_CombinedDiff(t *testing.T) {
+	t.Parallel()
+
+	diff, err := gitdiff.NewParser().Parse(strings.NewReader(combinedDiff))
+
+	require.NoError(t, err)
+	require.Len(t, diff.Files, 1)
+	f := diff.Files[0]
+	assert.Equal(t, "f.txt", f.OldPath)
+	assert.Equal(t, "f.txt", f.NewPath)
+	assert.Equal(t, diffview.FileModified, f.Operation)
+	assert.Equal(t, []string{"index 7b37c73,94235ab..d3c8fbd"}, f.Extended)
+
+	require.Len(t, f.Hunks, 1)
+	h := f.Hunks[0]
+	assert.True(t, h.IsCombined())
+	assert.Equal(t, []diffview.ParentRange{{Start: 1, Count: 5}, {Start: 1, Count: 5}}, h.Parents)
+	assert.Equal(t, 1, h.OldStart)
+	assert.Equal(t, 5, h.OldCount)
+	assert.Equal(t, 1, h.NewStart)
+	assert.Equal(t, 6, h.NewCount)
+	assert.Equal(t, "func main()", h.Section)
+
+	type want struct {
+		lineType diffview.LineType
+		prefix   string
+		content  string
+		old, new int
+	}
+	expected := []want{
+		{diffview.LineContext, "  ", "a\n", 1, 1},
+		{diffview.LineDeleted, "- ", "B2\n", 2, 0},
+		{diffview.LineDeleted, " -", "B1\n", 0, 0},
+		{diffview.LineAdded, "++", "B3\n", 0, 2},
+		{diffview.LineContext, "  ", "c\n", 3, 3},
+		{diffview.LineContext, "  ", "d\n", 4, 4},
+		{diffview.LineDeleted, " -", "e\n", 0, 0},
+		{diffview.LineAdded, " +", "E\n", 5, 5},
+		{diffview.LineAdded, "++", "extra\n", 0, 6},
+	}
+	require.Len(t, h.Lines, len(expected))
+	for i, w := range expected {
+		l := h.Lines[i]
+		assert.Equal(t, w.lineType, l.Type, "line %d type", i)
+		assert.Equal(t, w.prefix, l.Prefix(), "line %d prefix", i)
+		assert.Equal(t, w.content, l.Content, "line %d content", i)
+		assert.Equal(t, w.old, l.OldLineNum, "line %d old", i)
+		assert.Equal(t, w.new, l.NewLineNum, "line %d new", i)
+	}
+}
+
+func TestParser_Parse_CombinedDiffBetweenGitDiffs(t *testing.T) {
+	t.Parallel()
+
+	gitFile := func(name string) string {
+		return "diff --git a/" + name + " b/" + name + `
+index 1234567..abcdefg 100644
+--- a/` + name + `
++++ b/` + name + `
+@@ -1 +1 @@
+-old
++new
+`
+	}
+	input := gitFile("first.go") + combinedDiff + gitFile("last.go")
+
+	diff, err := gitdiff.NewParser().Parse(strings.NewReader(input))
+
+	require.NoError(t, err)
+	require.Len(t, diff.Files, 3)
+	assert.Equal(t, "first.go", diff.Files[0].NewPath)
+	assert.Equal(t, "f.txt", diff.Files[1].NewPath)
+	assert.True(t, diff.Files[1].Hunks[0].IsCombined())
+	assert.Equal(t, "last.go", diff.Files[2].NewPath)
+	assert.False(t, diff.Files[2].Hunks[0].IsCombined())
+}
+
+func TestParser_Parse_CombinedDiffLog(t *testing.T) {
+	t.Parallel()
+
+	// git log -p --cc output for two merge commits
+	input := `commit f96500c01355b22558d3c1d413d66a64dee9bbc1
+Merge: 0a3b7b7 c3ffdbd
+Author: A <a@b>
+Date:   Sun Oct 18 17:44:27 2026 +0000
+
+    Merge side again
+
+diff --cc g.txt
+index 975fbec,587be6b..b680253
+--- a/g.txt
++++ b/g.txt
+@@@ -1,1 -1,1 +1,1 @@@
+- y
+ -x
+++z
+
+commit 94d96dfbe5f16fe3716f71e115fba8b8a8fd075e
+Merge: a4d3933 bee0ac6
+Author: A <a@b>
+Date:   Sun Oct 18 17:44:27 2026 +0000
+
+    Merge side
+
+diff --cc f.txt
+index 3b6f40a,f4ea702..343809c
+--- a/f.txt
++++ b/f.txt
+@@@ -1,3 -1,3 +1,3 @@@
+  a
+- B2
+ -B1
+++B3
+  c
+`
+
+	diff, err := gitdiff.NewParser().Parse(strings.NewReader(input))
+
+	require.NoError(t, err)
+	require.Len(t, diff.Files, 2)
+	assert.Equal(t, "g.txt", diff.Files[0].NewPath)
+	require.Len(t, diff.Files[0].Hunks, 1)
+	assert.Len(t, diff.Files[0].Hunks[0].Lines, 3, "the next commit's header isn't hunk content")
+	assert.Equal(t, "f.txt", diff.Files[1].NewPath)
+	require.Len(t, diff.Files[1].Hunks, 1)
+	assert.Len(t, diff.Files[1].Hunks[0].Lines, 5)
+}
+
+func TestParser_Parse_CombinedDiffInvalidHunkHeader(t *testing.T) {
+	t.Parallel()
+
+	input := "diff --cc f.txt\n--- a/f.txt\n+++ b/f.txt\n@@@ -1,x -1,5 +1,6 @@@\n  a\n"
+
+	_, err := gitdiff.NewParser().Parse(strings.NewReader(input))
+
+	require.Error(t, err)
+}
+
+func TestParseRange(t *testing.T) {
+	t.Parallel()
+
+	start, count, err := gitdiff.ParseRange("-1,5", "-")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 5}, []int{start, count})
+
+	start, count, err = gitdiff.ParseRange("+3", "+")
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 1}, []int{start, count}, "count defaults to 1")
+
+	_, _, err = gitdiff.ParseRange("+3", "-")
+	require.Error(t, err)
+	_, _, err = gitdiff.ParseRange("-1,x", "-")
+	require.Error(t, err)
+}
@@ -1,8 +1,10 @@
-// Package gitdiff implements diff parsing using bluekeyes/go-gitdiff.
+// Package gitdiff implements diff parsing using bluekeyes/go-gitdiff, plus
+// the combined diff format git prints for merge commits.
 package gitdiff
 
 import (
 	"io"
+	"strings"
 
 	"github.com/bluekeyes/go-gitdiff/gitdiff"
 	"github.com/fwojciec/diffstory"
@@ -11,7 +13,8 @@ import (
 // Compile-time interface verification.
 var _ diffview.Parser = (*Parser)(nil)
 
-// Parser parses unified diff content using go-gitdiff.
+// Parser parses unified diff content using go-gitdiff. Combined diffs
+// (diff --cc), which go-gitdiff skips, are parsed in this package.
 type Parser struct{}
 
 // NewParser creates a new Parser.
@@ -21,18 +24,39 @@ func NewParser() *Parser {
 
 // Parse reads diff content and returns the parsed result.
 func (p *Parser) Parse(r io.Reader) (*diffview.Diff, error) {
-	files, _, err := gitdiff.Parse(r)
+	data, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
 
 	result := &diffview.Diff{
-		Files: make([]diffview.FileDiff, 0, len(files)),
+		Files: []diffview.FileDiff{},
 	}
 
-	for _, f := range files {
-		fileDiff := convertFile(f)
-		result.Files = append(result.Files, fileDiff)
+	for _, c := range splitCombined(string(data)) {
+		if c.combined {
+			fileDiff, err := parseCombinedFile(c.text)
+			if err != nil {
+				return nil, err
+			}
+			result.Files = append(result.Files, fileDiff)
+			continue
+		}
+
+		files, _, err := gitdiff.Parse(strings.NewReader(c.text))
+		if err != nil {
+			return nil, err
+		}
+		// go-gitdiff parses one file per "diff --git" line, so the raw
+		// headers line up with its files unless the input is malformed
+		extended := extendedHeaders(c.text)
+		for i, f := range files {
+			fileDiff := convertFile(f)
+			if len(extended) == len(files) {
+				fileDiff.Extended = extended[i]
+			}
+			result.Files = append(result.Files, fileDiff)
+		}
 	}
 
 	return result, nil
@@ -45,8 +69,9 @@ func convertFile(f *gitdiff.File) diffview.FileDiff {
 		IsBinary: f.IsBinary,
 		OldMode:  f.OldMode,
 		NewMode:  f.NewMode,
-		// Extended field is not populated - go-gitdiff parses extended headers
-		// into structured fields rather than exposing raw header lines.
+	}
+	if f.IsRename || f.IsCopy {
+		fd.Similarity = f.Score
 	}
 
 	// Determine file operation
@@ -116,3 +141,36 @@ func convertFragment(frag *gitdiff.TextFragment) diffview.Hunk {
 
 	return hunk
 }
+
+// extendedHeaders returns the raw extended header lines (index, mode,
+// similarity, rename and copy lines) of each "diff --git" file in text, in
+// order. go-gitdiff only exposes them as structured fields.
+func extendedHeaders(text string) [][]string {
+	var result [][]string
+	inHeader := false
+	for line := range strings.Lines(text) {
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			result = append(result, nil)
+			inHeader = true
+		case !inHeader:
+		case isExtendedHeaderEnd(line):
+			inHeader = false
+		default:
+			result[len(result)-1] = append(result[len(result)-1], line)
+		}
+	}
+	return result
+}
+
+// isExtendedHeaderEnd reports whether line ends the extended headers of a
+// file: the start of its patch, or of its binary content.
+func isExtendedHeaderEnd(line string) bool {
+	for _, prefix := range []string{"--- ", "+++ ", "@@ ", "Binary files ", "GIT binary patch"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -203,6 +203,8 @@ rename to new.go
 	assert.Equal(t, "old.go", f.OldPath)
 	assert.Equal(t, "new.go", f.NewPath)
 	assert.Equal(t, diffview.FileRenamed, f.Operation)
+	assert.Equal(t, 100, f.Similarity)
+	assert.Equal(t, []string{"similarity index 100%", "rename from old.go", "rename to new.go"}, f.Extended)
 	assert.Empty(t, f.Hunks)
 }
 
@@ -226,6 +228,7 @@ copy to copy.go
 	assert.Equal(t, "original.go", f.OldPath)
 	assert.Equal(t, "copy.go", f.NewPath)
 	assert.Equal(t, diffview.FileCopied, f.Operation)
+	assert.Equal(t, 100, f.Similarity)
 	assert.Empty(t, f.Hunks)
 }
 
@@ -349,5 +352,42 @@ new mode 100755
 	assert.Equal(t, "script.sh", f.OldPath)
 	assert.Equal(t, "script.sh", f.NewPath)
 	assert.Equal(t, diffview.FileModified, f.Operation)
+	assert.Equal(t, []string{"old mode 100644", "new mode 100755"}, f.Extended)
+	assert.True(t, f.ModeChanged())
+	assert.Zero(t, f.Similarity)
 	assert.Empty(t, f.Hunks)
 }
+
+func TestParser_Parse_ExtendedHeadersPerFile(t *testing.T) {
+	t.Parallel()
+
+	input := `diff --git a/old.go b/new.go
+similarity index 90%
+rename from old.go
+rename to new.go
+index 1234567..abcdefg 100644
+--- a/old.go
++++ b/new.go
+@@ -1 +1 @@
+-package old
++package new
+diff --git a/main.go b/main.go
+index 2345678..bcdefgh 100644
+--- a/main.go
++++ b/main.go
+@@ -1 +1 @@
+-old
++new
+`
+
+	diff, err := gitdiff.NewParser().Parse(strings.NewReader(input))
+
+	require.NoError(t, err)
+	require.Len(t, diff.Files, 2)
+	assert.Equal(t, []string{
+		"similarity index 90%", "rename from old.go", "rename to new.go", "index 1234567..abcdefg 100644",
+	}, diff.Files[0].Extended)
+	assert.Equal(t, 90, diff.Files[0].Similarity)
+	assert.Equal(t, []string{"index 2345678..bcdefgh 100644"}, diff.Files[1].Extended)
+	assert.False(t, diff.Files[1].ModeChanged(), "the index mode is the same on both sides")
+}
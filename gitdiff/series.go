@@ -0,0 +1,88 @@
+package gitdiff
+
+import (
+	"io"
+	"strings"
+
+	"github.com/bluekeyes/go-gitdiff/gitdiff"
+	"github.com/fwojciec/diffstory"
+)
+
+// Compile-time interface verification.
+var _ diffview.SeriesParser = (*Parser)(nil)
+
+// ParseSeries reads git format-patch output, one or more patches in mbox
+// format, and returns a commit per patch with its subject and body as the
+// message, its author and its diff.
+func (p *Parser) ParseSeries(r io.Reader) ([]diffview.CommitBrief, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []diffview.CommitBrief
+	for _, message := range splitMbox(string(data)) {
+		preamble, patch := splitPreamble(message)
+		header, err := gitdiff.ParsePatchHeader(preamble)
+		if err != nil {
+			return nil, err
+		}
+		diff, err := p.Parse(strings.NewReader(patch))
+		if err != nil {
+			return nil, err
+		}
+
+		commit := diffview.CommitBrief{
+			Hash:    header.SHA,
+			Message: header.Message(),
+			Diff:    diff,
+		}
+		if header.Author != nil {
+			commit.Author = header.Author.String()
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// splitMbox splits mbox content into messages at "From " separator lines.
+// Content without separators is a single message.
+func splitMbox(content string) []string {
+	var messages []string
+	var current strings.Builder
+	for line := range strings.Lines(content) {
+		if isMboxSeparator(line) && strings.TrimSpace(current.String()) != "" {
+			messages = append(messages, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
+	}
+	if strings.TrimSpace(current.String()) != "" {
+		messages = append(messages, current.String())
+	}
+	return messages
+}
+
+// isMboxSeparator reports whether line starts an mbox message, as in
+// "From 1a2b3c... Mon Sep 17 00:00:00 2001" written by git format-patch.
+func isMboxSeparator(line string) bool {
+	rest, ok := strings.CutPrefix(line, "From ")
+	if !ok {
+		return false
+	}
+	fields := strings.Fields(rest)
+	return len(fields) > 1 && strings.Count(rest, ":") >= 2
+}
+
+// splitPreamble splits a message into the mail headers and commit message
+// before its first diff, and the patch.
+func splitPreamble(message string) (preamble, patch string) {
+	offset := 0
+	for line := range strings.Lines(message) {
+		if strings.HasPrefix(line, "diff --git ") || isCombinedHeader(line) {
+			return message[:offset], message[offset:]
+		}
+		offset += len(line)
+	}
+	return message, ""
+}
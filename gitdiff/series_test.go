@@ -0,0 +1,98 @@
+package gitdiff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/gitdiff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParser_ParseSeries(t *testing.T) {
+	t.Parallel()
+
+	input := `From f9e0e8e98e1305e2752ee14e34d090a4b25ae572 Mon Sep 17 00:00:00 2001
+From: Ada Lovelace <ada@example.com>
+Date: Sun, 18 Oct 2026 16:15:47 +0000
+Subject: [PATCH 1/2] Add greeting
+
+Greet the user on startup.
+---
+ g.txt | 1 +
+ 1 file changed, 1 insertion(+)
+ create mode 100644 g.txt
+
+diff --git a/g.txt b/g.txt
+new file mode 100644
+index 0000000..587be6b
+--- /dev/null
++++ b/g.txt
+@@ -0,0 +1 @@
++hello
+-- 
+2.39.5
+
+
+From eb4008d431f3ba4cde8be974ba441fe32f15a786 Mon Sep 17 00:00:00 2001
+From: Grace Hopper <grace@example.com>
+Date: Sun, 18 Oct 2026 16:16:02 +0000
+Subject: [PATCH 2/2] Say goodbye
+
+---
+ g.txt | 1 +
+ 1 file changed, 1 insertion(+)
+
+diff --git a/g.txt b/g.txt
+index 587be6b..b0b5b3a 100644
+--- a/g.txt
++++ b/g.txt
+@@ -1 +1,2 @@
+ hello
++goodbye
+-- 
+2.39.5
+
+`
+
+	commits, err := gitdiff.NewParser().ParseSeries(strings.NewReader(input))
+
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+
+	assert.Equal(t, "f9e0e8e98e1305e2752ee14e34d090a4b25ae572", commits[0].Hash)
+	assert.Equal(t, "Ada Lovelace <ada@example.com>", commits[0].Author)
+	assert.Equal(t, "Add greeting\n\nGreet the user on startup.", commits[0].Message)
+	require.Len(t, commits[0].Diff.Files, 1)
+	assert.Equal(t, diffview.FileAdded, commits[0].Diff.Files[0].Operation)
+
+	assert.Equal(t, "Grace Hopper <grace@example.com>", commits[1].Author)
+	assert.Equal(t, "Say goodbye", commits[1].Message)
+	require.Len(t, commits[1].Diff.Files, 1)
+	hunk := commits[1].Diff.Files[0].Hunks[0]
+	require.Len(t, hunk.Lines, 2, "the signature after the patch is not part of the hunk")
+	assert.Equal(t, "goodbye\n", hunk.Lines[1].Content)
+}
+
+func TestParser_ParseSeries_SingleMessage(t *testing.T) {
+	t.Parallel()
+
+	input := `From: Ada Lovelace <ada@example.com>
+Subject: [PATCH] Fix typo
+
+diff --git a/README b/README
+--- a/README
++++ b/README
+@@ -1 +1 @@
+-teh
++the
+`
+
+	commits, err := gitdiff.NewParser().ParseSeries(strings.NewReader(input))
+
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	assert.Equal(t, "Fix typo", commits[0].Message)
+	assert.Equal(t, "README", commits[0].Diff.Files[0].NewPath)
+}
@@ -0,0 +1,60 @@
+// Package github converts review comments into GitHub API payloads.
+package github
+
+import "github.com/fwojciec/diffstory"
+
+// Review event types accepted by the GitHub pull request reviews API.
+const (
+	EventComment = "COMMENT"
+)
+
+// Diff sides as used by GitHub review comments.
+const (
+	SideLeft  = "LEFT"  // Old version of the file
+	SideRight = "RIGHT" // New version of the file
+)
+
+// Review is the request body for creating a pull request review
+// (POST /repos/{owner}/{repo}/pulls/{pull_number}/reviews).
+type Review struct {
+	CommitID string          `json:"commit_id,omitempty"`
+	Body     string          `json:"body,omitempty"`
+	Event    string          `json:"event"`
+	Comments []ReviewComment `json:"comments"`
+}
+
+// ReviewComment is a single line comment within a Review.
+type ReviewComment struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Side string `json:"side"`
+	Body string `json:"body"`
+}
+
+// NewReview builds a review payload from line comments.
+// The review is submitted as a plain comment so it neither approves nor
+// requests changes.
+func NewReview(body string, comments []diffview.Comment) Review {
+	reviewComments := make([]ReviewComment, 0, len(comments))
+	for _, c := range comments {
+		reviewComments = append(reviewComments, ReviewComment{
+			Path: c.File,
+			Line: c.Line,
+			Side: side(c.Side),
+			Body: c.Body,
+		})
+	}
+	return Review{
+		Body:     body,
+		Event:    EventComment,
+		Comments: reviewComments,
+	}
+}
+
+// side maps a comment side to GitHub's LEFT/RIGHT convention.
+func side(s diffview.CommentSide) string {
+	if s == diffview.SideOld {
+		return SideLeft
+	}
+	return SideRight
+}
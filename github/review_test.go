@@ -0,0 +1,58 @@
+package github_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReview(t *testing.T) {
+	t.Parallel()
+
+	t.Run("maps comment sides to LEFT and RIGHT", func(t *testing.T) {
+		t.Parallel()
+
+		comments := []diffview.Comment{
+			{File: "main.go", Side: diffview.SideOld, Line: 20, Body: "Removed too early?"},
+			{File: "main.go", Side: diffview.SideNew, Line: 3, Body: "Nice."},
+		}
+
+		review := github.NewReview("Summary", comments)
+
+		assert.Equal(t, github.EventComment, review.Event)
+		assert.Equal(t, "Summary", review.Body)
+		assert.Equal(t, []github.ReviewComment{
+			{Path: "main.go", Line: 20, Side: github.SideLeft, Body: "Removed too early?"},
+			{Path: "main.go", Line: 3, Side: github.SideRight, Body: "Nice."},
+		}, review.Comments)
+	})
+
+	t.Run("encodes as the GitHub API payload", func(t *testing.T) {
+		t.Parallel()
+
+		review := github.NewReview("", []diffview.Comment{
+			{File: "main.go", Side: diffview.SideNew, Line: 3, Body: "Nice."},
+		})
+
+		data, err := json.Marshal(review)
+
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"event": "COMMENT",
+			"comments": [{"path": "main.go", "line": 3, "side": "RIGHT", "body": "Nice."}]
+		}`, string(data))
+	})
+
+	t.Run("encodes empty comments as an empty array", func(t *testing.T) {
+		t.Parallel()
+
+		data, err := json.Marshal(github.NewReview("", nil))
+
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"event": "COMMENT", "comments": []}`, string(data))
+	})
+}
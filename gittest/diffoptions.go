@@ -0,0 +1,159 @@
+package gittest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunnerDiffOptions runs the conformance suite for the diff options
+// every diffview.GitRunner supports: context lines, rename detection and
+// pathspecs. newRunner is called once per test with the options under
+// test, and diffs are compared with git run with the matching flags.
+func TestRunnerDiffOptions(t *testing.T, newRunner func(diffview.DiffOptions) diffview.GitRunner) {
+	t.Helper()
+
+	t.Run("context lines", func(t *testing.T) {
+		t.Parallel()
+		dir := newHistoryRepo(t)
+
+		diff, err := newRunner(diffview.DiffOptions{Context: 8}).Diff(context.Background(), dir, "HEAD~1..HEAD")
+
+		require.NoError(t, err)
+		AssertSameDiff(t, Git(t, dir, "diff", "-U8", "HEAD~1..HEAD"), diff)
+	})
+
+	t.Run("pathspecs", func(t *testing.T) {
+		t.Parallel()
+		dir := newPathsRepo(t)
+		opts := diffview.DiffOptions{Include: []string{"src", "*.md"}, Exclude: []string{"*_test.go"}}
+
+		diff, err := newRunner(opts).Show(context.Background(), dir, RevParse(t, dir, "HEAD"))
+
+		require.NoError(t, err)
+		AssertSameDiff(t, Git(t, dir, "show", "--format=", "HEAD", "--", "src", "*.md", ":(exclude)*_test.go"), diff)
+		assert.Contains(t, diff, "src/a.go")
+		assert.NotContains(t, diff, "src/a_test.go")
+		assert.NotContains(t, diff, "other.go")
+	})
+
+	t.Run("pathspecs limit renames", func(t *testing.T) {
+		t.Parallel()
+		dir := newPathsRepo(t)
+		opts := diffview.DiffOptions{Include: []string{"src"}}
+
+		diff, err := newRunner(opts).Show(context.Background(), dir, RevParse(t, dir, "HEAD"))
+
+		require.NoError(t, err)
+		AssertSameDiff(t, Git(t, dir, "show", "--format=", "HEAD", "--", "src"), diff)
+		assert.Contains(t, diff, "new file mode")
+		assert.NotContains(t, diff, "rename from")
+	})
+
+	t.Run("no renames", func(t *testing.T) {
+		t.Parallel()
+		dir := newPathsRepo(t)
+
+		diff, err := newRunner(diffview.DiffOptions{NoRenames: true}).Show(context.Background(), dir, RevParse(t, dir, "HEAD"))
+
+		require.NoError(t, err)
+		AssertSameDiff(t, Git(t, dir, "show", "--format=", "--no-renames", "HEAD"), diff)
+		assert.NotContains(t, diff, "rename from")
+	})
+
+	t.Run("rename threshold", func(t *testing.T) {
+		t.Parallel()
+		dir := newPathsRepo(t)
+		head := RevParse(t, dir, "HEAD")
+
+		loose, err := newRunner(diffview.DiffOptions{RenameThreshold: 60}).Show(context.Background(), dir, head)
+		require.NoError(t, err)
+		strict, err := newRunner(diffview.DiffOptions{RenameThreshold: 95}).Show(context.Background(), dir, head)
+		require.NoError(t, err)
+
+		AssertSameDiff(t, Git(t, dir, "show", "--format=", "--find-renames=60%", "HEAD"), loose)
+		AssertSameDiff(t, Git(t, dir, "show", "--format=", "--find-renames=95%", "HEAD"), strict)
+		assert.Contains(t, loose, "rename from")
+		assert.NotContains(t, strict, "rename from")
+	})
+
+	t.Run("combined diff", func(t *testing.T) {
+		t.Parallel()
+		dir := newMergeRepo(t)
+		opts := diffview.DiffOptions{Context: 1, Exclude: []string{"main.txt"}}
+
+		diff, err := newRunner(opts).Show(context.Background(), dir, RevParse(t, dir, "HEAD"))
+
+		require.NoError(t, err)
+		AssertSameDiff(t, Git(t, dir, "show", "--format=", "-U1", "HEAD", "--", ":(exclude)main.txt"), diff)
+	})
+
+	t.Run("staged and worktree diffs", func(t *testing.T) {
+		t.Parallel()
+		dir := newPathsRepo(t)
+		WriteFile(t, dir, "src/a.go", "package src\n\nfunc A() {}\n")
+		WriteFile(t, dir, "other.go", "package other\n\nfunc Other() {}\n")
+		Git(t, dir, "add", "-A")
+		WriteFile(t, dir, "src/b.go", "package src\n")
+		runner := newRunner(diffview.DiffOptions{Include: []string{"src"}})
+
+		staged, err := runner.StagedDiff(context.Background(), dir)
+		require.NoError(t, err)
+		worktree, err := runner.WorktreeDiff(context.Background(), dir, "HEAD")
+		require.NoError(t, err)
+
+		AssertSameDiff(t, Git(t, dir, "diff", "--cached", "--", "src"), staged)
+		assert.Contains(t, worktree, "src/a.go")
+		assert.Contains(t, worktree, "src/b.go")
+		assert.NotContains(t, worktree, "other.go")
+	})
+
+	t.Run("ignores diff settings in git config", func(t *testing.T) {
+		t.Parallel()
+		dir := newHistoryRepo(t)
+		want := Git(t, dir, "diff", "HEAD~1..HEAD")
+		script := filepath.Join(t.TempDir(), "external-diff")
+		require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\necho external\n"), 0o755))
+		Git(t, dir, "config", "diff.noprefix", "true")
+		Git(t, dir, "config", "diff.mnemonicPrefix", "true")
+		Git(t, dir, "config", "color.ui", "always")
+		Git(t, dir, "config", "diff.external", script)
+
+		diff, err := newRunner(diffview.DiffOptions{}).Diff(context.Background(), dir, "HEAD~1..HEAD")
+
+		require.NoError(t, err)
+		AssertSameDiff(t, want, diff)
+		assert.Contains(t, diff, "--- a/list.txt")
+		assert.NotContains(t, diff, "\x1b[")
+	})
+}
+
+// newPathsRepo returns a repository whose HEAD changes src/a.go,
+// src/a_test.go, other.go and README.md, and moves docs/guide.txt to
+// src/guide.txt with two of its ten lines changed.
+func newPathsRepo(t *testing.T) string {
+	t.Helper()
+	dir := NewRepo(t)
+	WriteFile(t, dir, "src/a.go", "package src\n")
+	WriteFile(t, dir, "src/a_test.go", "package src_test\n")
+	WriteFile(t, dir, "other.go", "package other\n")
+	guide := numberedLines(10)
+	WriteFile(t, dir, "docs/guide.txt", joinLines(guide))
+	commit(t, dir, "Add files")
+
+	WriteFile(t, dir, "src/a.go", "package src\n\n// A is changed.\n")
+	WriteFile(t, dir, "src/a_test.go", "package src_test\n\n// Changed too.\n")
+	WriteFile(t, dir, "other.go", "package other\n\n// Other is changed.\n")
+	WriteFile(t, dir, "README.md", "# Test Repo\n\nChanged.\n")
+	guide[0] = "first changed"
+	guide[9] = "last changed"
+	require.NoError(t, os.Remove(filepath.Join(dir, "docs/guide.txt")))
+	WriteFile(t, dir, "src/guide.txt", joinLines(guide))
+	commit(t, dir, "Change files")
+	return dir
+}
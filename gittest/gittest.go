@@ -0,0 +1,86 @@
+// Package gittest provides fixture repositories and a conformance suite for
+// diffview.GitRunner implementations.
+package gittest
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/gitdiff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NewRepo creates a repository in a temporary directory with "main" as the
+// current branch and one commit adding README.md.
+func NewRepo(tb testing.TB) string {
+	tb.Helper()
+
+	dir := tb.TempDir()
+	Git(tb, dir, "init", "-b", "main")
+	Git(tb, dir, "config", "user.email", "test@example.com")
+	Git(tb, dir, "config", "user.name", "Test User")
+	WriteFile(tb, dir, "README.md", "# Test Repo\n")
+	Git(tb, dir, "add", ".")
+	Git(tb, dir, "commit", "-m", "Initial commit")
+	return dir
+}
+
+// Git runs git with args in dir and returns its combined output, failing
+// the test if it fails.
+func Git(tb testing.TB, dir string, args ...string) string {
+	tb.Helper()
+	output, err := tryGit(dir, args...)
+	require.NoError(tb, err, "command git %v failed: %s", args, output)
+	return output
+}
+
+// tryGit runs git with args in dir and returns its combined output.
+func tryGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
+// RevParse returns the commit hash rev resolves to in dir.
+func RevParse(tb testing.TB, dir, rev string) string {
+	tb.Helper()
+	return strings.TrimSpace(Git(tb, dir, "rev-parse", rev))
+}
+
+// WriteFile writes content to name in dir, creating its directories.
+func WriteFile(tb testing.TB, dir, name, content string) {
+	tb.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(tb, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(tb, os.WriteFile(path, []byte(content), 0o644))
+}
+
+// AssertSameDiff asserts that two diffs make the same changes. Hunk
+// section headings, extended headers and similarity indexes are ignored:
+// they depend on heuristics that implementations don't share.
+func AssertSameDiff(tb testing.TB, want, got string) {
+	tb.Helper()
+	assert.Equal(tb, normalizeDiff(tb, want), normalizeDiff(tb, got))
+}
+
+// normalizeDiff parses text and clears what AssertSameDiff ignores.
+func normalizeDiff(tb testing.TB, text string) []diffview.FileDiff {
+	tb.Helper()
+	diff, err := gitdiff.NewParser().Parse(strings.NewReader(text))
+	require.NoError(tb, err)
+	files := diff.Files
+	for i := range files {
+		files[i].Extended = nil
+		files[i].Similarity = 0
+		for j := range files[i].Hunks {
+			files[i].Hunks[j].Section = ""
+		}
+	}
+	return files
+}
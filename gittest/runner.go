@@ -0,0 +1,954 @@
+package gittest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/gitdiff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunner runs the conformance suite for a diffview.GitRunner against
+// fixture repositories built with the git command. newRunner is called
+// once per test. Diffs are compared with what git itself prints using
+// AssertSameDiff.
+func TestRunner(t *testing.T, newRunner func() diffview.GitRunner) {
+	t.Helper()
+
+	t.Run("Log", func(t *testing.T) {
+		t.Parallel()
+		testLog(t, newRunner)
+	})
+	t.Run("Show", func(t *testing.T) {
+		t.Parallel()
+		testShow(t, newRunner)
+	})
+	t.Run("Message", func(t *testing.T) {
+		t.Parallel()
+		testMessage(t, newRunner)
+	})
+	t.Run("MergeCommits", func(t *testing.T) {
+		t.Parallel()
+		testMergeCommits(t, newRunner)
+	})
+	t.Run("CommitsInRange", func(t *testing.T) {
+		t.Parallel()
+		testCommitsInRange(t, newRunner)
+	})
+	t.Run("CountCommits", func(t *testing.T) {
+		t.Parallel()
+		testCountCommits(t, newRunner)
+	})
+	t.Run("DiffRange", func(t *testing.T) {
+		t.Parallel()
+		testDiffRange(t, newRunner)
+	})
+	t.Run("Diff", func(t *testing.T) {
+		t.Parallel()
+		testDiff(t, newRunner)
+	})
+	t.Run("CurrentBranch", func(t *testing.T) {
+		t.Parallel()
+		testCurrentBranch(t, newRunner)
+	})
+	t.Run("MergeBase", func(t *testing.T) {
+		t.Parallel()
+		testMergeBase(t, newRunner)
+	})
+	t.Run("DefaultBranch", func(t *testing.T) {
+		t.Parallel()
+		testDefaultBranch(t, newRunner)
+	})
+	t.Run("Upstream", func(t *testing.T) {
+		t.Parallel()
+		testUpstream(t, newRunner)
+	})
+	t.Run("Branches", func(t *testing.T) {
+		t.Parallel()
+		testBranches(t, newRunner)
+	})
+	t.Run("ShowFile", func(t *testing.T) {
+		t.Parallel()
+		testShowFile(t, newRunner)
+	})
+	t.Run("StagedDiff", func(t *testing.T) {
+		t.Parallel()
+		testStagedDiff(t, newRunner)
+	})
+	t.Run("WorktreeDiff", func(t *testing.T) {
+		t.Parallel()
+		testWorktreeDiff(t, newRunner)
+	})
+	t.Run("ApplyPatch", func(t *testing.T) {
+		t.Parallel()
+		testApplyPatch(t, newRunner)
+	})
+}
+
+// commit stages everything in dir and commits it with message.
+func commit(t *testing.T, dir, message string) {
+	t.Helper()
+	Git(t, dir, "add", "-A")
+	Git(t, dir, "commit", "-m", message)
+}
+
+// numberedLines returns n lines "line 1" to "line n".
+func numberedLines(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+	return lines
+}
+
+// joinLines joins lines into file content ending in a newline.
+func joinLines(lines []string) string {
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// newHistoryRepo returns a repository whose main branch has a commit
+// changing two distant lines of list.txt, removing README.md and adding
+// run.sh after the initial commit.
+func newHistoryRepo(t *testing.T) string {
+	t.Helper()
+	dir := NewRepo(t)
+	lines := numberedLines(20)
+	WriteFile(t, dir, "list.txt", joinLines(lines))
+	commit(t, dir, "Add list")
+
+	lines[1] = "first change"
+	lines[18] = "second change"
+	WriteFile(t, dir, "list.txt", joinLines(lines))
+	WriteFile(t, dir, "run.sh", "#!/bin/sh\necho run\n")
+	require.NoError(t, os.Chmod(filepath.Join(dir, "run.sh"), 0o755))
+	require.NoError(t, os.Remove(filepath.Join(dir, "README.md")))
+	commit(t, dir, "Change list\n\nWith a body.")
+	return dir
+}
+
+// newMergeRepo returns a repository whose HEAD merges a feature branch
+// into main. Both sides changed the first line of conflict.txt and the
+// merge resolved it with a line of its own; main.txt and feature.txt were
+// each changed on one side only.
+func newMergeRepo(t *testing.T) string {
+	t.Helper()
+	dir := NewRepo(t)
+	WriteFile(t, dir, "conflict.txt", joinLines(append([]string{"original"}, numberedLines(10)...)))
+	WriteFile(t, dir, "main.txt", "main\n")
+	commit(t, dir, "Add files")
+
+	Git(t, dir, "checkout", "-b", "feature")
+	WriteFile(t, dir, "conflict.txt", joinLines(append([]string{"feature"}, numberedLines(10)...)))
+	WriteFile(t, dir, "feature.txt", "feature\n")
+	commit(t, dir, "Feature change")
+
+	Git(t, dir, "checkout", "main")
+	WriteFile(t, dir, "conflict.txt", joinLines(append([]string{"main"}, numberedLines(10)...)))
+	WriteFile(t, dir, "main.txt", "main changed\n")
+	commit(t, dir, "Main change")
+
+	if out, err := tryGit(dir, "merge", "--no-ff", "-m", "Merge feature", "feature"); err == nil {
+		t.Fatalf("expected a merge conflict, got: %s", out)
+	}
+	WriteFile(t, dir, "conflict.txt", joinLines(append([]string{"resolved"}, numberedLines(10)...)))
+	Git(t, dir, "add", "conflict.txt")
+	Git(t, dir, "commit", "--no-edit")
+	return dir
+}
+
+func testLog(t *testing.T, newRunner func() diffview.GitRunner) {
+	t.Run("returns hashes newest first", func(t *testing.T) {
+		t.Parallel()
+		dir := newHistoryRepo(t)
+
+		hashes, err := newRunner().Log(context.Background(), dir, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{RevParse(t, dir, "HEAD"), RevParse(t, dir, "HEAD~1"), RevParse(t, dir, "HEAD~2")}, hashes)
+	})
+
+	t.Run("respects limit", func(t *testing.T) {
+		t.Parallel()
+		dir := newHistoryRepo(t)
+
+		hashes, err := newRunner().Log(context.Background(), dir, 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{RevParse(t, dir, "HEAD")}, hashes)
+	})
+}
+
+func testShow(t *testing.T, newRunner func() diffview.GitRunner) {
+	t.Run("matches git for a commit", func(t *testing.T) {
+		t.Parallel()
+		dir := newHistoryRepo(t)
+
+		diff, err := newRunner().Show(context.Background(), dir, RevParse(t, dir, "HEAD"))
+
+		require.NoError(t, err)
+		AssertSameDiff(t, Git(t, dir, "show", "--format=", "HEAD"), diff)
+	})
+
+	t.Run("matches git for the root commit", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+
+		diff, err := newRunner().Show(context.Background(), dir, RevParse(t, dir, "HEAD"))
+
+		require.NoError(t, err)
+		AssertSameDiff(t, Git(t, dir, "show", "--format=", "HEAD"), diff)
+	})
+
+	t.Run("detects renames", func(t *testing.T) {
+		t.Parallel()
+		dir := newHistoryRepo(t)
+		Git(t, dir, "mv", "list.txt", "renamed.txt")
+		commit(t, dir, "Rename list")
+
+		diff, err := newRunner().Show(context.Background(), dir, RevParse(t, dir, "HEAD"))
+
+		require.NoError(t, err)
+		AssertSameDiff(t, Git(t, dir, "show", "--format=", "HEAD"), diff)
+		assert.Contains(t, diff, "rename to renamed.txt")
+	})
+
+	t.Run("shows the combined diff of a merge", func(t *testing.T) {
+		t.Parallel()
+		dir := newMergeRepo(t)
+
+		diff, err := newRunner().Show(context.Background(), dir, RevParse(t, dir, "HEAD"))
+
+		require.NoError(t, err)
+		AssertSameDiff(t, Git(t, dir, "show", "--format=", "HEAD"), diff)
+		assert.Contains(t, diff, "diff --cc conflict.txt")
+		assert.NotContains(t, diff, "main.txt")
+		assert.NotContains(t, diff, "feature.txt")
+	})
+
+	t.Run("returns error for unknown commit", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+
+		_, err := newRunner().Show(context.Background(), dir, "0123456789abcdef0123456789abcdef01234567")
+
+		require.Error(t, err)
+	})
+}
+
+func testMessage(t *testing.T, newRunner func() diffview.GitRunner) {
+	dir := newHistoryRepo(t)
+
+	message, err := newRunner().Message(context.Background(), dir, RevParse(t, dir, "HEAD"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "Change list\n\nWith a body.", message)
+}
+
+func testMergeCommits(t *testing.T, newRunner func() diffview.GitRunner) {
+	t.Run("returns merge commits from history", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+
+		// Create a feature branch with commits
+		Git(t, dir, "checkout", "-b", "feature-1")
+		WriteFile(t, dir, "feature.txt", "feature content\n")
+		commit(t, dir, "Add feature")
+
+		// Merge back to main
+		Git(t, dir, "checkout", "main")
+		Git(t, dir, "merge", "--no-ff", "-m", "Merge feature-1", "feature-1")
+		first := RevParse(t, dir, "HEAD")
+
+		// Create and merge another branch
+		Git(t, dir, "checkout", "-b", "feature-2")
+		WriteFile(t, dir, "feature2.txt", "feature 2 content\n")
+		commit(t, dir, "Add feature 2")
+		Git(t, dir, "checkout", "main")
+		Git(t, dir, "merge", "--no-ff", "-m", "Merge feature-2", "feature-2")
+
+		hashes, err := newRunner().MergeCommits(context.Background(), dir, 10)
+
+		require.NoError(t, err)
+		// Most recent merge first
+		assert.Equal(t, []string{RevParse(t, dir, "HEAD"), first}, hashes)
+	})
+
+	t.Run("respects limit", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+
+		// Create three merges
+		for i := 1; i <= 3; i++ {
+			branchName := fmt.Sprintf("feature-%d", i)
+			Git(t, dir, "checkout", "-b", branchName)
+			WriteFile(t, dir, fmt.Sprintf("file%d.txt", i), "content\n")
+			commit(t, dir, "Commit on "+branchName)
+			Git(t, dir, "checkout", "main")
+			Git(t, dir, "merge", "--no-ff", "-m", "Merge "+branchName, branchName)
+		}
+
+		hashes, err := newRunner().MergeCommits(context.Background(), dir, 2)
+
+		require.NoError(t, err)
+		assert.Len(t, hashes, 2)
+	})
+
+	t.Run("returns empty slice when no merge commits", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+
+		hashes, err := newRunner().MergeCommits(context.Background(), dir, 10)
+
+		require.NoError(t, err)
+		assert.Empty(t, hashes)
+	})
+}
+
+func testCommitsInRange(t *testing.T, newRunner func() diffview.GitRunner) {
+	t.Run("returns commits between base and head", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+
+		// Create a feature branch with multiple commits
+		Git(t, dir, "checkout", "-b", "feature")
+		WriteFile(t, dir, "file1.txt", "content 1\n")
+		commit(t, dir, "First feature commit")
+		WriteFile(t, dir, "file2.txt", "content 2\n")
+		commit(t, dir, "Second feature commit")
+
+		commits, err := newRunner().CommitsInRange(context.Background(), dir, RevParse(t, dir, "main"), RevParse(t, dir, "feature"))
+
+		require.NoError(t, err)
+		// Commits are returned in reverse chronological order (newest first)
+		assert.Equal(t, []diffview.CommitBrief{
+			{Hash: RevParse(t, dir, "feature"), Message: "Second feature commit"},
+			{Hash: RevParse(t, dir, "feature~1"), Message: "First feature commit"},
+		}, commits)
+	})
+
+	t.Run("returns the commits of a merged branch", func(t *testing.T) {
+		t.Parallel()
+		dir := newMergeRepo(t)
+
+		commits, err := newRunner().CommitsInRange(context.Background(), dir, "HEAD^1", "HEAD^2")
+
+		require.NoError(t, err)
+		assert.Equal(t, []diffview.CommitBrief{{Hash: RevParse(t, dir, "feature"), Message: "Feature change"}}, commits)
+	})
+
+	t.Run("returns empty slice when no commits in range", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+		head := RevParse(t, dir, "HEAD")
+
+		commits, err := newRunner().CommitsInRange(context.Background(), dir, head, head)
+
+		require.NoError(t, err)
+		assert.Empty(t, commits)
+	})
+}
+
+func testCountCommits(t *testing.T, newRunner func() diffview.GitRunner) {
+	t.Run("counts commits between base and head", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+		Git(t, dir, "checkout", "-b", "feature")
+		WriteFile(t, dir, "file1.txt", "content 1\n")
+		commit(t, dir, "First feature commit")
+		WriteFile(t, dir, "file2.txt", "content 2\n")
+		commit(t, dir, "Second feature commit")
+
+		n, err := newRunner().CountCommits(context.Background(), dir, "main", "feature")
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+	})
+
+	t.Run("counts the commits of a merged branch", func(t *testing.T) {
+		t.Parallel()
+		dir := newMergeRepo(t)
+
+		n, err := newRunner().CountCommits(context.Background(), dir, "HEAD^1", "HEAD^2")
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("returns zero when no commits in range", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+
+		n, err := newRunner().CountCommits(context.Background(), dir, "HEAD", "HEAD")
+
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+}
+
+func testDiffRange(t *testing.T, newRunner func() diffview.GitRunner) {
+	t.Run("returns diff between base and head", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+
+		// Create a feature branch with changes
+		Git(t, dir, "checkout", "-b", "feature")
+		WriteFile(t, dir, "newfile.txt", "new content\n")
+		commit(t, dir, "Add newfile")
+
+		diff, err := newRunner().DiffRange(context.Background(), dir, "main", "feature")
+
+		require.NoError(t, err)
+		assert.Contains(t, diff, "newfile.txt")
+		assert.Contains(t, diff, "+new content")
+		AssertSameDiff(t, Git(t, dir, "diff", "main...feature"), diff)
+	})
+
+	t.Run("leaves out changes on base since the merge base", func(t *testing.T) {
+		t.Parallel()
+		dir := newMergeRepo(t)
+
+		diff, err := newRunner().DiffRange(context.Background(), dir, "HEAD^1", "HEAD^2")
+
+		require.NoError(t, err)
+		assert.NotContains(t, diff, "main.txt")
+		AssertSameDiff(t, Git(t, dir, "diff", "HEAD^1...HEAD^2"), diff)
+	})
+
+	t.Run("returns empty diff when no changes", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+
+		diff, err := newRunner().DiffRange(context.Background(), dir, "main", "main")
+
+		require.NoError(t, err)
+		assert.Empty(t, diff)
+	})
+}
+
+func testDiff(t *testing.T, newRunner func() diffview.GitRunner) {
+	for _, spec := range []string{"HEAD~2..HEAD", "HEAD~2...HEAD", "HEAD~1..", "HEAD..HEAD~1"} {
+		t.Run("matches git for "+spec, func(t *testing.T) {
+			t.Parallel()
+			dir := newHistoryRepo(t)
+
+			diff, err := newRunner().Diff(context.Background(), dir, spec)
+
+			require.NoError(t, err)
+			AssertSameDiff(t, Git(t, dir, "diff", spec), diff)
+		})
+	}
+
+	t.Run("compares a revision with tracked files", func(t *testing.T) {
+		t.Parallel()
+		dir := newHistoryRepo(t)
+		WriteFile(t, dir, "run.sh", "#!/bin/sh\necho changed\n")
+		WriteFile(t, dir, "untracked.txt", "untracked\n")
+
+		diff, err := newRunner().Diff(context.Background(), dir, "HEAD~1")
+
+		require.NoError(t, err)
+		AssertSameDiff(t, Git(t, dir, "diff", "HEAD~1"), diff)
+		assert.NotContains(t, diff, "untracked.txt")
+	})
+
+	t.Run("returns error for unknown revision", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+
+		_, err := newRunner().Diff(context.Background(), dir, "main..missing")
+
+		require.Error(t, err)
+	})
+}
+
+func testCurrentBranch(t *testing.T, newRunner func() diffview.GitRunner) {
+	t.Run("returns current branch name", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+
+		branch, err := newRunner().CurrentBranch(context.Background(), dir)
+
+		require.NoError(t, err)
+		assert.Equal(t, "main", branch)
+	})
+
+	t.Run("returns feature branch when checked out", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+		Git(t, dir, "checkout", "-b", "my-feature")
+
+		branch, err := newRunner().CurrentBranch(context.Background(), dir)
+
+		require.NoError(t, err)
+		assert.Equal(t, "my-feature", branch)
+	})
+
+	t.Run("returns HEAD when detached", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+		Git(t, dir, "checkout", "--detach")
+
+		branch, err := newRunner().CurrentBranch(context.Background(), dir)
+
+		require.NoError(t, err)
+		assert.Equal(t, "HEAD", branch)
+	})
+}
+
+func testMergeBase(t *testing.T, newRunner func() diffview.GitRunner) {
+	t.Run("returns common ancestor of two refs", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+		mainHead := RevParse(t, dir, "HEAD")
+
+		// Create a feature branch with changes
+		Git(t, dir, "checkout", "-b", "feature")
+		WriteFile(t, dir, "feature.txt", "feature content\n")
+		commit(t, dir, "Feature commit")
+
+		base, err := newRunner().MergeBase(context.Background(), dir, "main", "feature")
+
+		require.NoError(t, err)
+		assert.Equal(t, mainHead, base)
+	})
+
+	t.Run("returns the fork point of diverged branches", func(t *testing.T) {
+		t.Parallel()
+		dir := newMergeRepo(t)
+
+		base, err := newRunner().MergeBase(context.Background(), dir, "HEAD^1", "HEAD^2")
+
+		require.NoError(t, err)
+		assert.Equal(t, RevParse(t, dir, "HEAD~2"), base)
+	})
+
+	t.Run("returns same commit when refs are identical", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+
+		base, err := newRunner().MergeBase(context.Background(), dir, "main", "main")
+
+		require.NoError(t, err)
+		assert.Equal(t, RevParse(t, dir, "HEAD"), base)
+	})
+}
+
+// newRemoteRepo returns a repository on branch with an origin remote it
+// has pushed branch to.
+func newRemoteRepo(t *testing.T, branch string) string {
+	t.Helper()
+	remoteDir := t.TempDir()
+	Git(t, remoteDir, "init", "-b", branch, "--bare")
+
+	dir := NewRepo(t)
+	Git(t, dir, "branch", "-m", branch)
+	Git(t, dir, "remote", "add", "origin", remoteDir)
+	Git(t, dir, "push", "-u", "origin", branch)
+	return dir
+}
+
+func testDefaultBranch(t *testing.T, newRunner func() diffview.GitRunner) {
+	t.Run("returns default branch from origin/HEAD", func(t *testing.T) {
+		t.Parallel()
+		dir := newRemoteRepo(t, "main")
+		// Set origin/HEAD to point to main (simulates what GitHub does)
+		Git(t, dir, "remote", "set-head", "origin", "main")
+
+		branch, err := newRunner().DefaultBranch(context.Background(), dir)
+
+		require.NoError(t, err)
+		assert.Equal(t, "main", branch)
+	})
+
+	t.Run("returns master when that is the default branch", func(t *testing.T) {
+		t.Parallel()
+		dir := newRemoteRepo(t, "master")
+		Git(t, dir, "remote", "set-head", "origin", "master")
+
+		branch, err := newRunner().DefaultBranch(context.Background(), dir)
+
+		require.NoError(t, err)
+		assert.Equal(t, "master", branch)
+	})
+
+	t.Run("returns error when no remote configured", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+
+		_, err := newRunner().DefaultBranch(context.Background(), dir)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no remote")
+	})
+
+	t.Run("returns error when remote exists but origin/HEAD not set", func(t *testing.T) {
+		t.Parallel()
+		dir := newRemoteRepo(t, "main")
+
+		_, err := newRunner().DefaultBranch(context.Background(), dir)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "origin/HEAD not set")
+	})
+}
+
+func testUpstream(t *testing.T, newRunner func() diffview.GitRunner) {
+	t.Run("returns remote upstream", func(t *testing.T) {
+		t.Parallel()
+		dir := newRemoteRepo(t, "main")
+		Git(t, dir, "checkout", "-b", "feature")
+		Git(t, dir, "push", "-u", "origin", "feature")
+
+		upstream, err := newRunner().Upstream(context.Background(), dir)
+
+		require.NoError(t, err)
+		assert.Equal(t, "origin/feature", upstream)
+	})
+
+	t.Run("returns local upstream", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+		Git(t, dir, "checkout", "-b", "parent")
+		Git(t, dir, "checkout", "--track", "-b", "child", "parent")
+
+		upstream, err := newRunner().Upstream(context.Background(), dir)
+
+		require.NoError(t, err)
+		assert.Equal(t, "parent", upstream)
+	})
+
+	t.Run("returns error without upstream", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+
+		_, err := newRunner().Upstream(context.Background(), dir)
+
+		require.Error(t, err)
+	})
+}
+
+func testBranches(t *testing.T, newRunner func() diffview.GitRunner) {
+	t.Run("returns local then remote branches", func(t *testing.T) {
+		t.Parallel()
+		dir := newRemoteRepo(t, "main")
+		Git(t, dir, "remote", "set-head", "origin", "main")
+		Git(t, dir, "branch", "zeta")
+		Git(t, dir, "branch", "feature/alpha")
+		Git(t, dir, "push", "origin", "zeta")
+
+		branches, err := newRunner().Branches(context.Background(), dir)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"feature/alpha", "main", "zeta", "origin/main", "origin/zeta"}, branches)
+	})
+}
+
+func testShowFile(t *testing.T, newRunner func() diffview.GitRunner) {
+	t.Run("returns file content at revision", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+		WriteFile(t, dir, "README.md", "# Changed\n")
+		commit(t, dir, "Change readme")
+		runner := newRunner()
+		ctx := context.Background()
+
+		old, err := runner.ShowFile(ctx, dir, "HEAD~1", "README.md")
+		require.NoError(t, err)
+		assert.Equal(t, "# Test Repo\n", old)
+
+		current, err := runner.ShowFile(ctx, dir, "HEAD", "README.md")
+		require.NoError(t, err)
+		assert.Equal(t, "# Changed\n", current)
+	})
+
+	t.Run("reads the index for an empty revision", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+		WriteFile(t, dir, "README.md", "# Staged\n")
+		Git(t, dir, "add", "README.md")
+		WriteFile(t, dir, "README.md", "# Unstaged\n")
+
+		content, err := newRunner().ShowFile(context.Background(), dir, "", "README.md")
+
+		require.NoError(t, err)
+		assert.Equal(t, "# Staged\n", content)
+	})
+
+	t.Run("returns error for missing path", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+
+		_, err := newRunner().ShowFile(context.Background(), dir, "HEAD", "missing.txt")
+
+		require.Error(t, err)
+	})
+}
+
+func testStagedDiff(t *testing.T, newRunner func() diffview.GitRunner) {
+	t.Run("returns staged changes only", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+		WriteFile(t, dir, "README.md", "# Staged\n")
+		WriteFile(t, dir, "new.txt", "new\n")
+		Git(t, dir, "add", "README.md", "new.txt")
+		WriteFile(t, dir, "README.md", "# Unstaged\n")
+
+		diff, err := newRunner().StagedDiff(context.Background(), dir)
+
+		require.NoError(t, err)
+		assert.Contains(t, diff, "+# Staged")
+		assert.NotContains(t, diff, "Unstaged")
+		AssertSameDiff(t, Git(t, dir, "diff", "--cached"), diff)
+	})
+
+	t.Run("shows everything as added before the first commit", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		Git(t, dir, "init", "-b", "main")
+		WriteFile(t, dir, "first.txt", "first\n")
+		Git(t, dir, "add", "first.txt")
+
+		diff, err := newRunner().StagedDiff(context.Background(), dir)
+
+		require.NoError(t, err)
+		AssertSameDiff(t, Git(t, dir, "diff", "--cached"), diff)
+	})
+}
+
+func testWorktreeDiff(t *testing.T, newRunner func() diffview.GitRunner) {
+	t.Run("includes staged, unstaged and untracked changes", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+		WriteFile(t, dir, ".gitignore", "*.log\n")
+		Git(t, dir, "add", ".gitignore")
+		WriteFile(t, dir, "README.md", "# Changed\n")
+		WriteFile(t, dir, "new.go", "package main\n")
+		WriteFile(t, dir, "debug.log", "ignored\n")
+
+		diff, err := newRunner().WorktreeDiff(context.Background(), dir, "HEAD")
+
+		require.NoError(t, err)
+		assert.Contains(t, diff, "+*.log")
+		assert.Contains(t, diff, "+# Changed")
+		assert.Contains(t, diff, "diff --git a/new.go b/new.go\nnew file mode 100644")
+		assert.Contains(t, diff, "+package main")
+		assert.NotContains(t, diff, "debug.log")
+	})
+
+	t.Run("matches git diff with untracked files added", func(t *testing.T) {
+		t.Parallel()
+		dir := newHistoryRepo(t)
+		WriteFile(t, dir, ".gitignore", "build/\n")
+		WriteFile(t, dir, "build/out.txt", "ignored\n")
+		WriteFile(t, dir, "nested/new.txt", "nested\n")
+		require.NoError(t, os.Chmod(filepath.Join(dir, "run.sh"), 0o644))
+		require.NoError(t, os.Remove(filepath.Join(dir, "list.txt")))
+
+		diff, err := newRunner().WorktreeDiff(context.Background(), dir, "HEAD~1")
+		require.NoError(t, err)
+
+		Git(t, dir, "add", "--intent-to-add", ".gitignore", "nested/new.txt")
+		AssertSameDiff(t, Git(t, dir, "diff", "HEAD~1"), diff)
+	})
+
+	t.Run("leaves the index unchanged", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+		WriteFile(t, dir, "new.go", "package main\n")
+
+		_, err := newRunner().WorktreeDiff(context.Background(), dir, "HEAD")
+
+		require.NoError(t, err)
+		assert.Equal(t, "?? new.go\n", Git(t, dir, "status", "--porcelain"))
+	})
+
+	t.Run("is stable across runs", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+		WriteFile(t, dir, "a.go", "package a\n")
+		WriteFile(t, dir, "b.go", "package b\n")
+		runner := newRunner()
+
+		first, err := runner.WorktreeDiff(context.Background(), dir, "HEAD")
+		require.NoError(t, err)
+		second, err := runner.WorktreeDiff(context.Background(), dir, "HEAD")
+		require.NoError(t, err)
+
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("compares against an earlier commit", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+		WriteFile(t, dir, "committed.go", "package main\n")
+		commit(t, dir, "Add committed.go")
+		WriteFile(t, dir, "untracked.go", "package main\n")
+
+		diff, err := newRunner().WorktreeDiff(context.Background(), dir, "HEAD~1")
+
+		require.NoError(t, err)
+		assert.Contains(t, diff, "committed.go")
+		assert.Contains(t, diff, "untracked.go")
+	})
+}
+
+func testApplyPatch(t *testing.T, newRunner func() diffview.GitRunner) {
+	patch := `diff --git a/README.md b/README.md
+--- a/README.md
++++ b/README.md
+@@ -1,1 +1,1 @@
+-# Test Repo
++# Patched
+`
+
+	t.Run("stages a patch", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+		WriteFile(t, dir, "README.md", "# Patched\n")
+
+		err := newRunner().ApplyPatch(context.Background(), dir, patch, diffview.ApplyOptions{Cached: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, "M  README.md\n", Git(t, dir, "status", "--porcelain"))
+	})
+
+	t.Run("unstages a patch", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+		WriteFile(t, dir, "README.md", "# Patched\n")
+		Git(t, dir, "add", "README.md")
+
+		err := newRunner().ApplyPatch(context.Background(), dir, patch, diffview.ApplyOptions{Cached: true, Reverse: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, " M README.md\n", Git(t, dir, "status", "--porcelain"))
+	})
+
+	t.Run("discards a patch", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+		WriteFile(t, dir, "README.md", "# Patched\n")
+
+		err := newRunner().ApplyPatch(context.Background(), dir, patch, diffview.ApplyOptions{Reverse: true})
+
+		require.NoError(t, err)
+		assert.Empty(t, Git(t, dir, "status", "--porcelain"))
+	})
+
+	t.Run("reports patches that don't apply", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+
+		err := newRunner().ApplyPatch(context.Background(), dir, patch, diffview.ApplyOptions{Reverse: true})
+
+		require.Error(t, err)
+	})
+
+	t.Run("applies nothing when part of a patch doesn't apply", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+		WriteFile(t, dir, "README.md", "# Patched\n")
+		broken := patch + `diff --git a/missing.txt b/missing.txt
+--- a/missing.txt
++++ b/missing.txt
+@@ -1,1 +1,1 @@
+-old
++new
+`
+
+		err := newRunner().ApplyPatch(context.Background(), dir, broken, diffview.ApplyOptions{Cached: true})
+
+		require.Error(t, err)
+		assert.Equal(t, " M README.md\n", Git(t, dir, "status", "--porcelain"))
+	})
+
+	t.Run("stages one hunk of a worktree diff", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+		lines := numberedLines(20)
+		WriteFile(t, dir, "list.txt", joinLines(lines))
+		commit(t, dir, "Add list")
+		lines[1] = "first change"
+		lines[18] = "second change"
+		WriteFile(t, dir, "list.txt", joinLines(lines))
+		WriteFile(t, dir, "untracked.txt", "new\n")
+
+		runner := newRunner()
+		ctx := context.Background()
+		text, err := runner.WorktreeDiff(ctx, dir, "HEAD")
+		require.NoError(t, err)
+		diff, err := gitdiff.NewParser().Parse(strings.NewReader(text))
+		require.NoError(t, err)
+		applier := diffview.NewGitHunkApplier(runner, dir)
+
+		err = applier.ApplyHunks(ctx, diff, []diffview.HunkRef{
+			{File: "list.txt", HunkIndex: 1},
+			{File: "untracked.txt", HunkIndex: 0},
+		}, diffview.HunkStage)
+
+		require.NoError(t, err)
+		staged := Git(t, dir, "diff", "--cached")
+		assert.Contains(t, staged, "+second change")
+		assert.NotContains(t, staged, "first change")
+		assert.Contains(t, staged, "+++ b/untracked.txt")
+	})
+
+	t.Run("stages files under top-level a and b directories", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+		WriteFile(t, dir, "a/x.go", "package a\n")
+		WriteFile(t, dir, "b/x.go", "package b\n")
+		WriteFile(t, dir, "x.go", "package x\n")
+		commit(t, dir, "Add x.go three times")
+		WriteFile(t, dir, "a/x.go", "package a // changed\n")
+		WriteFile(t, dir, "b/x.go", "package b // changed\n")
+
+		runner := newRunner()
+		ctx := context.Background()
+		text, err := runner.WorktreeDiff(ctx, dir, "HEAD")
+		require.NoError(t, err)
+		diff, err := gitdiff.NewParser().Parse(strings.NewReader(text))
+		require.NoError(t, err)
+		applier := diffview.NewGitHunkApplier(runner, dir)
+
+		err = applier.ApplyHunks(ctx, diff, []diffview.HunkRef{
+			{File: "a/x.go", HunkIndex: 0},
+			{File: "b/x.go", HunkIndex: 0},
+		}, diffview.HunkStage)
+
+		require.NoError(t, err)
+		assert.Equal(t, "M  a/x.go\nM  b/x.go\n", Git(t, dir, "status", "--porcelain"))
+	})
+
+	t.Run("discards a hunk after an unstaged one", func(t *testing.T) {
+		t.Parallel()
+		dir := NewRepo(t)
+		lines := numberedLines(20)
+		WriteFile(t, dir, "list.txt", joinLines(lines))
+		commit(t, dir, "Add list")
+		lines[1] = "first change"
+		lines[18] = "second change"
+		WriteFile(t, dir, "list.txt", joinLines(lines))
+
+		runner := newRunner()
+		ctx := context.Background()
+		text, err := runner.WorktreeDiff(ctx, dir, "HEAD")
+		require.NoError(t, err)
+		diff, err := gitdiff.NewParser().Parse(strings.NewReader(text))
+		require.NoError(t, err)
+
+		err = diffview.NewGitHunkApplier(runner, dir).ApplyHunks(ctx, diff, []diffview.HunkRef{{File: "list.txt", HunkIndex: 1}}, diffview.HunkDiscard)
+
+		require.NoError(t, err)
+		lines[18] = "line 19"
+		content, err := os.ReadFile(filepath.Join(dir, "list.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, joinLines(lines), string(content))
+	})
+}
@@ -0,0 +1,393 @@
+package gogit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bluekeyes/go-gitdiff/gitdiff"
+	"github.com/fwojciec/diffstory"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+)
+
+// ApplyPatch applies patch to the working tree, or to the index with
+// opts.Cached, and in reverse with opts.Reverse. Like git apply, a hunk is
+// looked for at its line numbers first and then further and further away
+// from them. The patch is checked as a whole first, so nothing is changed
+// when any of it doesn't apply.
+func (r *Runner) ApplyPatch(ctx context.Context, repoPath, patch string, opts diffview.ApplyOptions) error {
+	repo, err := open(repoPath)
+	if err != nil {
+		return err
+	}
+	files, _, err := gitdiff.Parse(strings.NewReader(patch))
+	if err != nil {
+		return fmt.Errorf("failed to parse patch: %w", err)
+	}
+
+	var target applyTarget
+	if opts.Cached {
+		target, err = newIndexTarget(repo)
+	} else {
+		target, err = newWorktreeTarget(repo)
+	}
+	if err != nil {
+		return err
+	}
+
+	results := make([]appliedFile, 0, len(files))
+	for _, f := range files {
+		c := newFileChange(f, opts.Reverse)
+		result, err := c.apply(target)
+		if err != nil {
+			return fmt.Errorf("patch does not apply: %s: %w", c.name(), err)
+		}
+		results = append(results, result)
+	}
+
+	for _, result := range results {
+		if result.removed != "" {
+			if err := target.remove(result.removed); err != nil {
+				return err
+			}
+		}
+		if result.name != "" {
+			if err := target.write(result.name, result.content, result.mode); err != nil {
+				return err
+			}
+		}
+	}
+	return target.save()
+}
+
+// fileChange is a file of a patch, turned around when applied in reverse.
+type fileChange struct {
+	oldName, newName string            // Empty when the file is created or deleted
+	newMode          filemode.FileMode // Zero to keep the file's mode
+	fragments        []fragment
+	binary           bool
+}
+
+// fragment is a hunk of a fileChange.
+type fragment struct {
+	oldStart int      // First old line, or the line new lines go after if old is empty
+	old, new []string // Lines with their newlines
+}
+
+// newFileChange returns the change f makes, or undoes if reverse is set.
+func newFileChange(f *gitdiff.File, reverse bool) fileChange {
+	c := fileChange{oldName: f.OldName, newName: f.NewName, newMode: filemode.FileMode(f.NewMode), binary: f.IsBinary}
+	if f.IsNew {
+		c.oldName = ""
+	}
+	if f.IsDelete {
+		c.newName = ""
+	}
+	if reverse {
+		c.oldName, c.newName = c.newName, c.oldName
+		c.newMode = filemode.FileMode(f.OldMode)
+	}
+
+	for _, frag := range f.TextFragments {
+		fr := fragment{oldStart: int(frag.OldPosition)}
+		if reverse {
+			fr.oldStart = int(frag.NewPosition)
+		}
+		for _, line := range frag.Lines {
+			inOld, inNew := line.Old(), line.New()
+			if reverse {
+				inOld, inNew = inNew, inOld
+			}
+			if inOld {
+				fr.old = append(fr.old, line.Line)
+			}
+			if inNew {
+				fr.new = append(fr.new, line.Line)
+			}
+		}
+		c.fragments = append(c.fragments, fr)
+	}
+	return c
+}
+
+// name returns the name of the changed file for messages.
+func (c fileChange) name() string {
+	if c.newName != "" {
+		return c.newName
+	}
+	return c.oldName
+}
+
+// appliedFile is the result of applying a fileChange: the file to write,
+// if any, and the file to remove first, if any.
+type appliedFile struct {
+	name    string
+	content string
+	mode    filemode.FileMode
+	removed string
+}
+
+// apply applies the change to the file in target, without writing it.
+func (c fileChange) apply(target applyTarget) (appliedFile, error) {
+	if c.binary {
+		return appliedFile{}, errors.New("binary patches are not supported")
+	}
+
+	var content string
+	mode := filemode.Regular
+	if c.oldName != "" {
+		var exists bool
+		var err error
+		content, mode, exists, err = target.read(c.oldName)
+		if err != nil {
+			return appliedFile{}, err
+		}
+		if !exists {
+			return appliedFile{}, fmt.Errorf("does not exist in %s", target)
+		}
+	}
+	if c.newName != "" && c.newName != c.oldName {
+		if _, _, exists, err := target.read(c.newName); err != nil || exists {
+			return appliedFile{}, fmt.Errorf("already exists in %s", target)
+		}
+	}
+
+	lines, err := applyFragments(splitLines(content), c.fragments)
+	if err != nil {
+		return appliedFile{}, err
+	}
+	result := appliedFile{content: strings.Join(lines, "")}
+	if c.oldName != c.newName {
+		result.removed = c.oldName
+	}
+	if c.newName == "" {
+		if result.content != "" {
+			return appliedFile{}, errors.New("deleted file still has content")
+		}
+		return result, nil
+	}
+
+	result.name = c.newName
+	result.mode = mode
+	if c.newMode != 0 {
+		result.mode = c.newMode
+	}
+	return result, nil
+}
+
+// applyFragments applies fragments to lines. Each fragment is looked for at
+// its start line, moved by the offset of the fragments before it, then at
+// increasing distances before and after it.
+func applyFragments(lines []string, fragments []fragment) ([]string, error) {
+	var out []string
+	next := 0   // First line not copied to out yet
+	offset := 0 // Distance of the last fragment from its start line
+	for _, f := range fragments {
+		start := f.oldStart - 1
+		if len(f.old) == 0 {
+			start = f.oldStart
+		}
+		at, ok := findLines(lines, f.old, start+offset, next)
+		if !ok {
+			return nil, fmt.Errorf("hunk at line %d does not match", f.oldStart)
+		}
+		out = append(out, lines[next:at]...)
+		out = append(out, f.new...)
+		next = at + len(f.old)
+		offset = at - start
+	}
+	return append(out, lines[next:]...), nil
+}
+
+// findLines returns the index of want in lines closest to pos and not
+// before first.
+func findLines(lines, want []string, pos, first int) (int, bool) {
+	last := len(lines) - len(want)
+	for d := 0; pos-d >= first || pos+d <= last; d++ {
+		for _, at := range []int{pos - d, pos + d} {
+			if at >= first && at <= last && matchLines(lines[at:], want) {
+				return at, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// matchLines reports whether lines starts with want.
+func matchLines(lines, want []string) bool {
+	for i, line := range want {
+		if lines[i] != line {
+			return false
+		}
+	}
+	return true
+}
+
+// splitLines splits content into lines, keeping their newlines.
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// applyTarget is where a patch is applied: the index or the working tree.
+type applyTarget interface {
+	fmt.Stringer
+	read(name string) (content string, mode filemode.FileMode, exists bool, err error)
+	write(name, content string, mode filemode.FileMode) error
+	remove(name string) error
+	save() error
+}
+
+// indexTarget applies patches to the index, storing new content as blobs.
+type indexTarget struct {
+	repo *git.Repository
+	idx  *index.Index
+}
+
+func newIndexTarget(repo *git.Repository) (*indexTarget, error) {
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	return &indexTarget{repo: repo, idx: idx}, nil
+}
+
+func (t *indexTarget) String() string { return "index" }
+
+func (t *indexTarget) read(name string) (string, filemode.FileMode, bool, error) {
+	e, err := t.idx.Entry(name)
+	if errors.Is(err, index.ErrEntryNotFound) || (err == nil && e.IntentToAdd) {
+		return "", 0, false, nil
+	}
+	if err != nil {
+		return "", 0, false, err
+	}
+	blob, err := t.repo.BlobObject(e.Hash)
+	if err != nil {
+		return "", 0, false, fmt.Errorf("failed to read %s from the index: %w", name, err)
+	}
+	content, err := blobContents(blob)
+	return content, e.Mode, true, err
+}
+
+// write stores content and points the entry for name at it. The entry has
+// no stat data, so git compares it with the working tree by content.
+func (t *indexTarget) write(name, content string, mode filemode.FileMode) error {
+	hash, err := writeBlob(t.repo.Storer, []byte(content))
+	if err != nil {
+		return fmt.Errorf("failed to store %s: %w", name, err)
+	}
+
+	e, err := t.idx.Entry(name)
+	if err != nil {
+		e = t.idx.Add(name)
+	}
+	*e = index.Entry{Name: name, Hash: hash, Mode: mode}
+	return nil
+}
+
+func (t *indexTarget) remove(name string) error {
+	_, err := t.idx.Remove(name)
+	return err
+}
+
+// save writes the index through index.lock, as git does, so it is never
+// seen half written and concurrent git commands aren't overwritten.
+func (t *indexTarget) save() error {
+	dotGit, ok := t.repo.Storer.(interface{ Filesystem() billy.Filesystem })
+	if !ok {
+		return t.repo.Storer.SetIndex(t.idx)
+	}
+	dir := dotGit.Filesystem()
+	f, err := dir.OpenFile("index.lock", os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to lock index: %w", err)
+	}
+	err = index.NewEncoder(f).Encode(t.idx)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = dir.Remove("index.lock")
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	if err := dir.Rename("index.lock", "index"); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// worktreeTarget applies patches to the files of the working tree.
+type worktreeTarget struct {
+	root string
+}
+
+func newWorktreeTarget(repo *git.Repository) (*worktreeTarget, error) {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open working tree: %w", err)
+	}
+	return &worktreeTarget{root: wt.Filesystem.Root()}, nil
+}
+
+func (t *worktreeTarget) String() string { return "working tree" }
+
+func (t *worktreeTarget) path(name string) string {
+	return filepath.Join(t.root, filepath.FromSlash(name))
+}
+
+func (t *worktreeTarget) read(name string) (string, filemode.FileMode, bool, error) {
+	info, err := os.Lstat(t.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", 0, false, nil
+	}
+	if err != nil {
+		return "", 0, false, err
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(t.path(name))
+		return filepath.ToSlash(target), filemode.Symlink, true, err
+	}
+	content, err := os.ReadFile(t.path(name))
+	return string(content), fileMode(info), true, err
+}
+
+func (t *worktreeTarget) write(name, content string, mode filemode.FileMode) error {
+	p := t.path(name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	if mode == filemode.Symlink {
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return os.Symlink(filepath.FromSlash(content), p)
+	}
+	perm := os.FileMode(0o644)
+	if mode == filemode.Executable {
+		perm = 0o755
+	}
+	if err := os.WriteFile(p, []byte(content), perm); err != nil {
+		return err
+	}
+	return os.Chmod(p, perm)
+}
+
+func (t *worktreeTarget) remove(name string) error {
+	return os.Remove(t.path(name))
+}
+
+func (t *worktreeTarget) save() error { return nil }
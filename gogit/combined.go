@@ -0,0 +1,386 @@
+package gogit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// combinedDiff returns the dense combined diff of a merge commit, like git
+// show prints: only files that differ from every parent, and only hunks
+// that don't just repeat one side of the merge.
+func (r *Runner) combinedDiff(ctx context.Context, commit *object.Commit, tree *object.Tree) (string, error) {
+	if err := r.checkDiffOptions(); err != nil {
+		return "", err
+	}
+	parents := make([]*object.Tree, commit.NumParents())
+	for i := range parents {
+		parent, err := commit.Parent(i)
+		if err != nil {
+			return "", fmt.Errorf("failed to read parent of %s: %w", commit.Hash, err)
+		}
+		if parents[i], err = parent.Tree(); err != nil {
+			return "", fmt.Errorf("failed to read tree of %s: %w", parent.Hash, err)
+		}
+	}
+
+	// A path is in the combined diff when every parent changed it.
+	counts := map[string]int{}
+	for _, parent := range parents {
+		changes, err := object.DiffTreeWithOptions(ctx, parent, tree, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to compare trees: %w", err)
+		}
+		for _, c := range changes {
+			counts[changeName(c)]++
+		}
+	}
+	var paths []string
+	for path, n := range counts {
+		if n == len(parents) && r.diffOptions.MatchPath(path) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var sb strings.Builder
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		result, err := combinedVersion(tree, path)
+		if err != nil {
+			return "", err
+		}
+		versions := make([]combinedFile, len(parents))
+		for i, parent := range parents {
+			if versions[i], err = combinedVersion(parent, path); err != nil {
+				return "", err
+			}
+		}
+		writeCombinedFile(&sb, path, versions, result, r.contextLines())
+	}
+	return sb.String(), nil
+}
+
+// changeName returns the path a tree change is about.
+func changeName(c *object.Change) string {
+	if c.To.Name != "" {
+		return c.To.Name
+	}
+	return c.From.Name
+}
+
+// combinedFile is one version of a file in a combined diff. A missing
+// file has a zero hash and no content.
+type combinedFile struct {
+	hash    plumbing.Hash
+	mode    filemode.FileMode
+	content string
+}
+
+// combinedVersion returns the version of path in tree.
+func combinedVersion(tree *object.Tree, path string) (combinedFile, error) {
+	entry, err := tree.FindEntry(path)
+	if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+		return combinedFile{}, nil
+	}
+	if err != nil {
+		return combinedFile{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	f := combinedFile{hash: entry.Hash, mode: entry.Mode}
+	if entry.Mode == filemode.Submodule {
+		f.content = fmt.Sprintf("Subproject commit %s\n", entry.Hash)
+		return f, nil
+	}
+	blob, err := tree.TreeEntryFile(entry)
+	if err != nil {
+		return combinedFile{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if f.content, err = blob.Contents(); err != nil {
+		return combinedFile{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// isBinary reports whether content looks binary the way git decides it: a
+// NUL byte in its first 8000 bytes.
+func isBinary(content string) bool {
+	return strings.IndexByte(content[:min(len(content), 8000)], 0) >= 0
+}
+
+// combinedLine is a line of the merge result, with the parents it is new
+// to and the parent lines lost right before it.
+type combinedLine struct {
+	text  string
+	added uint // Bit i is set when parent i doesn't have the line
+	lost  []lostLine
+}
+
+// lostLine is a parent line that isn't in the merge result.
+type lostLine struct {
+	text    string
+	parents uint // Bit i is set when parent i had the line
+}
+
+// writeCombinedFile writes the combined diff of one file to sb, with
+// context lines around each hunk.
+func writeCombinedFile(sb *strings.Builder, path string, parents []combinedFile, result combinedFile, contextLines int) {
+	fmt.Fprintf(sb, "diff --cc %s\n", path)
+	hashes := make([]string, len(parents))
+	for i, p := range parents {
+		hashes[i] = p.hash.String()[:7]
+	}
+	fmt.Fprintf(sb, "index %s..%s\n", strings.Join(hashes, ","), result.hash.String()[:7])
+	switch {
+	case result.mode == 0:
+		fmt.Fprintf(sb, "deleted file mode %o\n", uint32(parents[0].mode))
+	case allMissing(parents):
+		fmt.Fprintf(sb, "new file mode %o\n", uint32(result.mode))
+	}
+
+	binary := isBinary(result.content)
+	for _, p := range parents {
+		binary = binary || isBinary(p.content)
+	}
+	if binary {
+		sb.WriteString("Binary files differ\n")
+		return
+	}
+
+	lines := combineLines(parents, result.content)
+	hunks := combinedHunks(lines, len(parents), contextLines)
+	if len(hunks) == 0 {
+		return
+	}
+	if allMissing(parents) {
+		sb.WriteString("--- /dev/null\n")
+	} else {
+		fmt.Fprintf(sb, "--- a/%s\n", path)
+	}
+	if result.mode == 0 {
+		sb.WriteString("+++ /dev/null\n")
+	} else {
+		fmt.Fprintf(sb, "+++ b/%s\n", path)
+	}
+	for _, h := range hunks {
+		writeCombinedHunk(sb, lines, len(parents), h)
+	}
+}
+
+// allMissing reports whether none of files exist.
+func allMissing(files []combinedFile) bool {
+	for _, f := range files {
+		if f.mode != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// combineLines diffs every parent against the result. The returned slice
+// has one more entry than the result has lines, holding the lines lost at
+// the end.
+func combineLines(parents []combinedFile, result string) []combinedLine {
+	text := splitLines(result)
+	lines := make([]combinedLine, len(text)+1)
+	for i, t := range text {
+		lines[i].text = t
+	}
+
+	for i, parent := range parents {
+		bit := uint(1) << i
+		at := 0
+		for _, d := range diff.Do(parent.content, result) {
+			chunk := splitLines(d.Text)
+			switch d.Type {
+			case diffmatchpatch.DiffEqual:
+				at += len(chunk)
+			case diffmatchpatch.DiffInsert:
+				for range chunk {
+					lines[at].added |= bit
+					at++
+				}
+			case diffmatchpatch.DiffDelete:
+				lines[at].lost = addLost(lines[at].lost, chunk, bit)
+			}
+		}
+	}
+	return lines
+}
+
+// addLost merges the lines a parent lost into those other parents lost at
+// the same place, so a line lost by several parents is shown once.
+func addLost(lost []lostLine, chunk []string, bit uint) []lostLine {
+	next := 0
+	for _, text := range chunk {
+		matched := false
+		for i := next; i < len(lost); i++ {
+			if lost[i].text == text && lost[i].parents&bit == 0 {
+				lost[i].parents |= bit
+				next = i + 1
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			lost = append(lost, lostLine{text: text, parents: bit})
+			next = len(lost)
+		}
+	}
+	return lost
+}
+
+// combinedHunk is a range of entries [start, end) of combineLines shown as
+// a hunk, with the lines lost before each of them.
+type combinedHunk struct {
+	start, end int
+}
+
+// combinedHunks returns the hunks of lines. Changes are grouped when they
+// are close enough for their context to touch, and a group is dropped when
+// all of its changes are against the same parents but not all of them:
+// then the merge just took one side, which isn't interesting. Hunks have
+// context lines around their changes.
+func combinedHunks(lines []combinedLine, parents, contextLines int) []combinedHunk {
+	all := uint(1)<<parents - 1
+	changed := func(i int) bool { return lines[i].added != 0 || len(lines[i].lost) > 0 }
+
+	var groups []combinedHunk
+	for i := range lines {
+		if !changed(i) {
+			continue
+		}
+		if n := len(groups); n > 0 && i-groups[n-1].end < 2*contextLines {
+			groups[n-1].end = i + 1
+			continue
+		}
+		groups = append(groups, combinedHunk{start: i, end: i + 1})
+	}
+
+	var hunks []combinedHunk
+	for _, g := range groups {
+		if !interesting(lines[g.start:g.end], all) {
+			continue
+		}
+		h := combinedHunk{
+			start: max(g.start-contextLines, 0),
+			end:   min(g.end+contextLines, len(lines)),
+		}
+		if n := len(hunks); n > 0 && h.start <= hunks[n-1].end {
+			hunks[n-1].end = h.end
+			continue
+		}
+		hunks = append(hunks, h)
+	}
+	return hunks
+}
+
+// interesting reports whether lines differ from the parents in more than
+// one way, or from all of them.
+func interesting(lines []combinedLine, all uint) bool {
+	var same uint
+	check := func(parents uint) bool {
+		if same == 0 {
+			same = parents
+		}
+		return parents != same
+	}
+	for _, l := range lines {
+		if l.added != 0 && check(l.added) {
+			return true
+		}
+		for _, lost := range l.lost {
+			if check(lost.parents) {
+				return true
+			}
+		}
+	}
+	return same == all
+}
+
+// writeCombinedHunk writes a hunk header with one range per parent, then
+// its lines with one prefix column per parent.
+func writeCombinedHunk(sb *strings.Builder, lines []combinedLine, parents int, h combinedHunk) {
+	last := len(lines) - 1
+	ranges := make([]string, 0, parents+1)
+	for p := range parents {
+		bit := uint(1) << p
+		before, count := 0, 0
+		for i := 0; i < h.end; i++ {
+			n := countLost(lines[i].lost, bit)
+			if i < last && lines[i].added&bit == 0 {
+				n++
+			}
+			if i < h.start {
+				before += n
+			} else {
+				count += n
+			}
+		}
+		ranges = append(ranges, "-"+hunkRange(before, count))
+	}
+	ranges = append(ranges, "+"+hunkRange(h.start, min(h.end, last)-h.start))
+	marker := strings.Repeat("@", parents+1)
+	fmt.Fprintf(sb, "%s %s %s\n", marker, strings.Join(ranges, " "), marker)
+
+	for i := h.start; i < h.end; i++ {
+		for _, lost := range lines[i].lost {
+			writeCombinedText(sb, prefixes(lost.parents, parents, '-'), lost.text)
+		}
+		if i < last {
+			writeCombinedText(sb, prefixes(lines[i].added, parents, '+'), lines[i].text)
+		}
+	}
+}
+
+// countLost returns how many of lost were lines of the parent with bit.
+func countLost(lost []lostLine, bit uint) int {
+	n := 0
+	for _, l := range lost {
+		if l.parents&bit != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// hunkRange formats a hunk range of count lines after the first before
+// lines, as in unified diff headers.
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// prefixes returns the prefix columns of a line: mark for the parents in
+// set and a space for the others.
+func prefixes(set uint, parents int, mark byte) string {
+	b := make([]byte, parents)
+	for p := range b {
+		b[p] = ' '
+		if set&(1<<p) != 0 {
+			b[p] = mark
+		}
+	}
+	return string(b)
+}
+
+// writeCombinedText writes a diff line. Like git, combined diffs don't
+// mark a missing final newline.
+func writeCombinedText(sb *strings.Builder, prefix, text string) {
+	sb.WriteString(prefix)
+	sb.WriteString(text)
+	if !strings.HasSuffix(text, "\n") {
+		sb.WriteString("\n")
+	}
+}
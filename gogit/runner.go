@@ -0,0 +1,577 @@
+// Package gogit provides git operations in pure Go with go-git, without
+// running the git command.
+package gogit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// Compile-time interface verification.
+var _ diffview.GitRunner = (*Runner)(nil)
+
+// renameScore is the default similarity in percent above which a deleted
+// and an added file are shown as a rename, as in git diff.
+const renameScore = 50
+
+// Runner reads repositories with go-git. Unlike the git command, it starts
+// no processes and doesn't depend on the user's git config: there are no
+// prefix, color or external diff driver settings to change its output.
+//
+// Each call opens the repository, so a Runner is safe for concurrent use.
+type Runner struct {
+	diffOptions diffview.DiffOptions
+}
+
+// RunnerOption configures a Runner.
+type RunnerOption func(*Runner)
+
+// WithDiffOptions sets how diffs are generated. go-git has only the Myers
+// algorithm and doesn't detect copies, so diffs fail with other algorithms
+// or a copy threshold.
+func WithDiffOptions(opts diffview.DiffOptions) RunnerOption {
+	return func(r *Runner) {
+		r.diffOptions = opts
+	}
+}
+
+// NewRunner creates a new go-git runner.
+func NewRunner(opts ...RunnerOption) *Runner {
+	r := &Runner{}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Log returns commit hashes reachable from HEAD, newest first, limited to n commits.
+func (r *Runner) Log(ctx context.Context, repoPath string, limit int) ([]string, error) {
+	return r.log(ctx, repoPath, limit, func(*object.Commit) bool { return true })
+}
+
+// Show returns the diff of a commit against its parent, or of a root commit
+// against the empty tree. Merge commits get a combined diff of the changes
+// that differ from every parent, as from git show.
+func (r *Runner) Show(ctx context.Context, repoPath string, hash string) (string, error) {
+	repo, err := open(repoPath)
+	if err != nil {
+		return "", err
+	}
+	commit, err := resolveCommit(repo, hash)
+	if err != nil {
+		return "", err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to read tree of %s: %w", hash, err)
+	}
+
+	switch commit.NumParents() {
+	case 0:
+		return r.diffTrees(ctx, nil, tree)
+	case 1:
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return "", fmt.Errorf("failed to read parent of %s: %w", hash, err)
+		}
+		parentTree, err := parent.Tree()
+		if err != nil {
+			return "", fmt.Errorf("failed to read tree of %s: %w", parent.Hash, err)
+		}
+		return r.diffTrees(ctx, parentTree, tree)
+	default:
+		return r.combinedDiff(ctx, commit, tree)
+	}
+}
+
+// Message returns the commit message for a specific commit hash.
+func (r *Runner) Message(ctx context.Context, repoPath string, hash string) (string, error) {
+	repo, err := open(repoPath)
+	if err != nil {
+		return "", err
+	}
+	commit, err := resolveCommit(repo, hash)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(commit.Message), nil
+}
+
+// MergeCommits returns merge commit hashes reachable from HEAD, newest
+// first, limited to n commits.
+func (r *Runner) MergeCommits(ctx context.Context, repoPath string, limit int) ([]string, error) {
+	return r.log(ctx, repoPath, limit, func(c *object.Commit) bool { return c.NumParents() > 1 })
+}
+
+// log returns the hashes of up to limit commits reachable from HEAD for
+// which keep returns true, newest first.
+func (r *Runner) log(ctx context.Context, repoPath string, limit int, keep func(*object.Commit) bool) ([]string, error) {
+	repo, err := open(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := repo.Log(&git.LogOptions{Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log: %w", err)
+	}
+	defer iter.Close()
+
+	var hashes []string
+	err = iter.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(hashes) >= limit {
+			return storer.ErrStop
+		}
+		if keep(c) {
+			hashes = append(hashes, c.Hash.String())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log: %w", err)
+	}
+	return hashes, nil
+}
+
+// CommitsInRange returns commits between base and head (base exclusive, head
+// inclusive), newest first, with their subject lines as messages.
+func (r *Runner) CommitsInRange(ctx context.Context, repoPath, base, head string) ([]diffview.CommitBrief, error) {
+	var commits []diffview.CommitBrief
+	err := forEachInRange(ctx, repoPath, base, head, func(c *object.Commit) {
+		commits = append(commits, diffview.CommitBrief{
+			Hash:    c.Hash.String(),
+			Message: subject(c.Message),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+// CountCommits returns the number of commits between base and head (base
+// exclusive, head inclusive).
+func (r *Runner) CountCommits(ctx context.Context, repoPath, base, head string) (int, error) {
+	n := 0
+	err := forEachInRange(ctx, repoPath, base, head, func(*object.Commit) { n++ })
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// forEachInRange calls fn for every commit reachable from head but not from
+// base, newest first.
+func forEachInRange(ctx context.Context, repoPath, base, head string, fn func(*object.Commit)) error {
+	repo, err := open(repoPath)
+	if err != nil {
+		return err
+	}
+	baseCommit, err := resolveCommit(repo, base)
+	if err != nil {
+		return err
+	}
+	headCommit, err := resolveCommit(repo, head)
+	if err != nil {
+		return err
+	}
+
+	// Commits reachable from base are excluded, along with their history
+	excluded := make(map[plumbing.Hash]bool)
+	err = object.NewCommitPreorderIter(baseCommit, nil, nil).ForEach(func(c *object.Commit) error {
+		excluded[c.Hash] = true
+		return ctx.Err()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read log: %w", err)
+	}
+
+	err = object.NewCommitIterCTime(headCommit, excluded, nil).ForEach(func(c *object.Commit) error {
+		fn(c)
+		return ctx.Err()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read log: %w", err)
+	}
+	return nil
+}
+
+// subject returns the first paragraph of a commit message joined into one
+// line, like git log's %s.
+func subject(message string) string {
+	paragraph, _, _ := strings.Cut(strings.TrimSpace(message), "\n\n")
+	lines := strings.Split(paragraph, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.Join(lines, " ")
+}
+
+// DiffRange returns the diff of the changes on head since it diverged from
+// base, like git diff base...head.
+func (r *Runner) DiffRange(ctx context.Context, repoPath, base, head string) (string, error) {
+	return r.Diff(ctx, repoPath, base+"..."+head)
+}
+
+// Diff returns the diff for a range specification: "a...b" for the changes
+// on b since its merge base with a, "a..b" for the changes between a and b,
+// and a single revision for the changes between it and the tracked files of
+// the working tree. An omitted end of a range is HEAD.
+func (r *Runner) Diff(ctx context.Context, repoPath, rangeSpec string) (string, error) {
+	repo, err := open(repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	if from, to, ok := strings.Cut(rangeSpec, "..."); ok {
+		fromCommit, toCommit, err := resolveRange(repo, from, to)
+		if err != nil {
+			return "", err
+		}
+		base, err := mergeBase(fromCommit, toCommit)
+		if err != nil {
+			return "", err
+		}
+		return r.diffCommits(ctx, base, toCommit)
+	}
+	if from, to, ok := strings.Cut(rangeSpec, ".."); ok {
+		fromCommit, toCommit, err := resolveRange(repo, from, to)
+		if err != nil {
+			return "", err
+		}
+		return r.diffCommits(ctx, fromCommit, toCommit)
+	}
+
+	commit, err := resolveCommit(repo, rangeSpec)
+	if err != nil {
+		return "", err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to read tree of %s: %w", rangeSpec, err)
+	}
+	return r.diffWorktree(ctx, repo, tree, false)
+}
+
+// resolveRange resolves both ends of a range, defaulting to HEAD.
+func resolveRange(repo *git.Repository, from, to string) (*object.Commit, *object.Commit, error) {
+	if from == "" {
+		from = "HEAD"
+	}
+	if to == "" {
+		to = "HEAD"
+	}
+	fromCommit, err := resolveCommit(repo, from)
+	if err != nil {
+		return nil, nil, err
+	}
+	toCommit, err := resolveCommit(repo, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fromCommit, toCommit, nil
+}
+
+// CurrentBranch returns the name of the currently checked out branch, or
+// "HEAD" when it is detached.
+func (r *Runner) CurrentBranch(ctx context.Context, repoPath string) (string, error) {
+	repo, err := open(repoPath)
+	if err != nil {
+		return "", err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	if !head.Name().IsBranch() {
+		return "HEAD", nil
+	}
+	return head.Name().Short(), nil
+}
+
+// MergeBase returns the best common ancestor commit between two refs.
+func (r *Runner) MergeBase(ctx context.Context, repoPath, ref1, ref2 string) (string, error) {
+	repo, err := open(repoPath)
+	if err != nil {
+		return "", err
+	}
+	c1, err := resolveCommit(repo, ref1)
+	if err != nil {
+		return "", err
+	}
+	c2, err := resolveCommit(repo, ref2)
+	if err != nil {
+		return "", err
+	}
+	base, err := mergeBase(c1, c2)
+	if err != nil {
+		return "", err
+	}
+	return base.Hash.String(), nil
+}
+
+// mergeBase returns the best common ancestor of two commits.
+func mergeBase(c1, c2 *object.Commit) (*object.Commit, error) {
+	bases, err := c1.MergeBase(c2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find merge base: %w", err)
+	}
+	if len(bases) == 0 {
+		return nil, fmt.Errorf("no merge base between %s and %s", c1.Hash, c2.Hash)
+	}
+	return bases[0], nil
+}
+
+// DefaultBranch returns the default branch name from origin/HEAD.
+// Returns an error if no remote is configured.
+func (r *Runner) DefaultBranch(ctx context.Context, repoPath string) (string, error) {
+	repo, err := open(repoPath)
+	if err != nil {
+		return "", err
+	}
+	ref, err := repo.Reference(plumbing.NewRemoteHEADReferenceName("origin"), false)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) || (err == nil && ref.Type() != plumbing.SymbolicReference) {
+		return "", fmt.Errorf("no remote configured: origin/HEAD not set")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read origin/HEAD: %w", err)
+	}
+	return strings.TrimPrefix(ref.Target().String(), "refs/remotes/origin/"), nil
+}
+
+// Upstream returns the upstream tracking branch of the current branch.
+// Returns an error if the current branch has no upstream, or its upstream
+// branch doesn't exist.
+func (r *Runner) Upstream(ctx context.Context, repoPath string) (string, error) {
+	repo, err := open(repoPath)
+	if err != nil {
+		return "", err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	if !head.Name().IsBranch() {
+		return "", fmt.Errorf("HEAD is not on a branch")
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return "", fmt.Errorf("failed to read config: %w", err)
+	}
+	branch, ok := cfg.Branches[head.Name().Short()]
+	if !ok || branch.Remote == "" || branch.Merge == "" {
+		return "", fmt.Errorf("no upstream configured for branch %s", head.Name().Short())
+	}
+
+	// A local upstream is tracked as itself, a remote one through its
+	// remote-tracking branch
+	name := branch.Merge
+	if branch.Remote != "." {
+		name = plumbing.NewRemoteReferenceName(branch.Remote, branch.Merge.Short())
+	}
+	if _, err := repo.Reference(name, false); err != nil {
+		return "", fmt.Errorf("upstream branch %s not found: %w", name.Short(), err)
+	}
+	return name.Short(), nil
+}
+
+// Branches returns the local branches, then the remote-tracking branches,
+// each sorted by name, without symbolic refs such as origin/HEAD.
+func (r *Runner) Branches(ctx context.Context, repoPath string) ([]string, error) {
+	repo, err := open(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read references: %w", err)
+	}
+	var local, remote []string
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() == plumbing.SymbolicReference {
+			return nil
+		}
+		switch {
+		case ref.Name().IsBranch():
+			local = append(local, ref.Name().Short())
+		case ref.Name().IsRemote():
+			remote = append(remote, strings.TrimPrefix(ref.Name().String(), "refs/remotes/"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read references: %w", err)
+	}
+	sort.Strings(local)
+	sort.Strings(remote)
+	return append(local, remote...), nil
+}
+
+// ShowFile returns the full content of path at rev, or in the index when
+// rev is empty.
+func (r *Runner) ShowFile(ctx context.Context, repoPath, rev, path string) (string, error) {
+	repo, err := open(repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	if rev == "" {
+		idx, err := repo.Storer.Index()
+		if err != nil {
+			return "", fmt.Errorf("failed to read index: %w", err)
+		}
+		entry, err := idx.Entry(path)
+		if err != nil {
+			return "", fmt.Errorf("%s is not in the index", path)
+		}
+		blob, err := repo.BlobObject(entry.Hash)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s from the index: %w", path, err)
+		}
+		return blobContents(blob)
+	}
+
+	commit, err := resolveCommit(repo, rev)
+	if err != nil {
+		return "", err
+	}
+	file, err := commit.File(path)
+	if err != nil {
+		return "", fmt.Errorf("%s does not exist in %s", path, rev)
+	}
+	return file.Contents()
+}
+
+// blobContents returns the content of blob.
+func blobContents(blob *object.Blob) (string, error) {
+	reader, err := blob.Reader()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// open opens the repository containing repoPath.
+func open(repoPath string) (*git.Repository, error) {
+	repo, err := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository %s: %w", repoPath, err)
+	}
+	return repo, nil
+}
+
+// resolveCommit resolves rev, as in HEAD~2, main or a hash, to a commit.
+func resolveCommit(repo *git.Repository, rev string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", rev, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", rev, err)
+	}
+	return commit, nil
+}
+
+// diffCommits returns the diff between the trees of two commits.
+func (r *Runner) diffCommits(ctx context.Context, from, to *object.Commit) (string, error) {
+	fromTree, err := from.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to read tree of %s: %w", from.Hash, err)
+	}
+	toTree, err := to.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to read tree of %s: %w", to.Hash, err)
+	}
+	return r.diffTrees(ctx, fromTree, toTree)
+}
+
+// diffTrees returns the diff between two trees in git's format, limited to
+// the paths the diff options let in. A nil tree is empty.
+func (r *Runner) diffTrees(ctx context.Context, from, to *object.Tree) (string, error) {
+	if err := r.checkDiffOptions(); err != nil {
+		return "", err
+	}
+	changes, err := object.DiffTreeWithOptions(ctx, from, to, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to compare trees: %w", err)
+	}
+
+	// Like git, limit the paths before pairing renames, so a file moved
+	// into the included paths shows as added.
+	var kept object.Changes
+	for _, c := range changes {
+		if r.diffOptions.MatchPath(changeName(c)) {
+			kept = append(kept, c)
+		}
+	}
+	if !r.diffOptions.NoRenames {
+		score := renameScore
+		if r.diffOptions.RenameThreshold > 0 {
+			score = r.diffOptions.RenameThreshold
+		}
+		kept, err = object.DetectRenames(kept, &object.DiffTreeOptions{
+			DetectRenames: true,
+			RenameScore:   uint(score),
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to detect renames: %w", err)
+		}
+		// Renames are moved out of order; git sorts files by path.
+		sort.SliceStable(kept, func(i, j int) bool {
+			return changeName(kept[i]) < changeName(kept[j])
+		})
+	}
+
+	patch, err := kept.PatchContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to create patch: %w", err)
+	}
+	var sb strings.Builder
+	if err := diff.NewUnifiedEncoder(&sb, r.contextLines()).Encode(patch); err != nil {
+		return "", fmt.Errorf("failed to encode patch: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// checkDiffOptions returns an error for diff options go-git can't honor.
+func (r *Runner) checkDiffOptions() error {
+	switch r.diffOptions.Algorithm {
+	case "", diffview.DiffAlgorithmMyers:
+	default:
+		return fmt.Errorf("go-git backend doesn't support the %s diff algorithm", r.diffOptions.Algorithm)
+	}
+	if r.diffOptions.CopyThreshold > 0 {
+		return errors.New("go-git backend doesn't detect copies")
+	}
+	return nil
+}
+
+// contextLines returns the number of context lines around changes.
+func (r *Runner) contextLines() int {
+	if r.diffOptions.Context > 0 {
+		return r.diffOptions.Context
+	}
+	return diff.DefaultContextLines
+}
@@ -0,0 +1,41 @@
+package gogit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/gittest"
+	"github.com/fwojciec/diffstory/gogit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunner(t *testing.T) {
+	t.Parallel()
+
+	gittest.TestRunner(t, func() diffview.GitRunner { return gogit.NewRunner() })
+}
+
+func TestRunner_DiffOptions(t *testing.T) {
+	t.Parallel()
+
+	gittest.TestRunnerDiffOptions(t, func(opts diffview.DiffOptions) diffview.GitRunner {
+		return gogit.NewRunner(gogit.WithDiffOptions(opts))
+	})
+
+	t.Run("rejects unsupported options", func(t *testing.T) {
+		t.Parallel()
+		dir := gittest.NewRepo(t)
+
+		_, err := gogit.NewRunner(gogit.WithDiffOptions(diffview.DiffOptions{Algorithm: diffview.DiffAlgorithmHistogram})).
+			Show(context.Background(), dir, "HEAD")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "histogram")
+
+		_, err = gogit.NewRunner(gogit.WithDiffOptions(diffview.DiffOptions{CopyThreshold: 50})).
+			Show(context.Background(), dir, "HEAD")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "copies")
+	})
+}
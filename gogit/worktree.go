@@ -0,0 +1,417 @@
+package gogit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// StagedDiff returns the diff of the changes staged in the index against
+// HEAD, or against the empty tree before the first commit.
+func (r *Runner) StagedDiff(ctx context.Context, repoPath string) (string, error) {
+	repo, err := open(repoPath)
+	if err != nil {
+		return "", err
+	}
+	head, err := headTree(repo)
+	if err != nil {
+		return "", err
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return "", fmt.Errorf("failed to read index: %w", err)
+	}
+
+	var files []treeFile
+	for _, e := range idx.Entries {
+		// Unmerged and intent-to-add entries have nothing staged
+		if e.Stage != 0 || e.IntentToAdd {
+			continue
+		}
+		files = append(files, treeFile{path: e.Name, mode: e.Mode, hash: e.Hash})
+	}
+	objects := newOverlay(repo.Storer)
+	staged, err := writeTree(objects, files)
+	if err != nil {
+		return "", err
+	}
+	return r.diffTrees(ctx, head, staged)
+}
+
+// WorktreeDiff returns the diff between rev and the working tree. Untracked
+// files that aren't ignored are shown as added, as if they had been added
+// with git add --intent-to-add; the index is left unchanged.
+func (r *Runner) WorktreeDiff(ctx context.Context, repoPath, rev string) (string, error) {
+	repo, err := open(repoPath)
+	if err != nil {
+		return "", err
+	}
+	commit, err := resolveCommit(repo, rev)
+	if err != nil {
+		return "", err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to read tree of %s: %w", rev, err)
+	}
+	return r.diffWorktree(ctx, repo, tree, true)
+}
+
+// headTree returns the tree of HEAD, or nil before the first commit.
+func headTree(repo *git.Repository) (*object.Tree, error) {
+	head, err := repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	return commit.Tree()
+}
+
+// diffWorktree returns the diff between tree and the tracked files of the
+// working tree, and its untracked files too if untracked is set.
+func (r *Runner) diffWorktree(ctx context.Context, repo *git.Repository, tree *object.Tree, untracked bool) (string, error) {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to open working tree: %w", err)
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return "", fmt.Errorf("failed to read index: %w", err)
+	}
+	root := wt.Filesystem.Root()
+	objects := newOverlay(repo.Storer)
+
+	files, err := trackedFiles(objects, root, idx, indexTime(repo))
+	if err != nil {
+		return "", err
+	}
+	if untracked {
+		more, err := untrackedFiles(objects, root, idx, wt.Excludes)
+		if err != nil {
+			return "", err
+		}
+		files = append(files, more...)
+	}
+
+	worktree, err := writeTree(objects, files)
+	if err != nil {
+		return "", err
+	}
+	return r.diffTrees(ctx, tree, worktree)
+}
+
+// trackedFiles returns the files of the index as they are in the working
+// tree under root, leaving out deleted ones. Like git, it trusts the index
+// for files whose size and modification time match the index entry, unless
+// they were modified as late as the index itself (indexTime).
+func trackedFiles(objects *overlay, root string, idx *index.Index, indexTime int64) ([]treeFile, error) {
+	var files []treeFile
+	seen := make(map[string]bool)
+	for _, e := range idx.Entries {
+		if seen[e.Name] {
+			continue // other stages of an unmerged path
+		}
+		seen[e.Name] = true
+
+		if e.Mode == filemode.Submodule || e.SkipWorktree {
+			files = append(files, treeFile{path: e.Name, mode: e.Mode, hash: e.Hash})
+			continue
+		}
+		info, err := os.Lstat(filepath.Join(root, filepath.FromSlash(e.Name)))
+		if errors.Is(err, fs.ErrNotExist) || (err == nil && info.IsDir()) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", e.Name, err)
+		}
+
+		mode := fileMode(info)
+		modTime := info.ModTime()
+		if e.Stage == 0 && !e.IntentToAdd && mode == e.Mode && int64(e.Size) == info.Size() &&
+			modTime.Equal(e.ModifiedAt) && modTime.UnixNano() < indexTime {
+			files = append(files, treeFile{path: e.Name, mode: mode, hash: e.Hash})
+			continue
+		}
+		file, err := readFile(objects, root, e.Name, info)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// untrackedFiles returns the files under root that are neither in the index
+// nor ignored. Ignore rules come from .gitignore files, .git/info/exclude,
+// the global and system excludes files and excludes. Nested repositories are
+// skipped.
+func untrackedFiles(objects *overlay, root string, idx *index.Index, excludes []gitignore.Pattern) ([]treeFile, error) {
+	tracked := make(map[string]bool, len(idx.Entries))
+	for _, e := range idx.Entries {
+		tracked[e.Name] = true
+	}
+
+	rootFS := osfs.New("/")
+	system, _ := gitignore.LoadSystemPatterns(rootFS)
+	global, _ := gitignore.LoadGlobalPatterns(rootFS)
+	patterns, err := gitignore.ReadPatterns(osfs.New(root), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ignore rules: %w", err)
+	}
+	matcher := gitignore.NewMatcher(slices.Concat(system, global, patterns, excludes))
+
+	var files []treeFile
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if d.Name() == ".git" {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if matcher.Match(strings.Split(name, "/"), true) || tracked[name] || isRepository(p) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if tracked[name] || matcher.Match(strings.Split(name, "/"), false) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && info.Mode()&fs.ModeSymlink == 0 {
+			return nil
+		}
+		file, err := readFile(objects, root, name, info)
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+	return files, nil
+}
+
+// isRepository reports whether dir is the root of a nested repository.
+func isRepository(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// readFile stores the content of the file at name under root as a blob, or
+// the target of a symlink, and returns it as a tree file.
+func readFile(objects *overlay, root, name string, info fs.FileInfo) (treeFile, error) {
+	p := filepath.Join(root, filepath.FromSlash(name))
+	var content []byte
+	var err error
+	if info.Mode()&fs.ModeSymlink != 0 {
+		var target string
+		target, err = os.Readlink(p)
+		content = []byte(filepath.ToSlash(target))
+	} else {
+		content, err = os.ReadFile(p)
+	}
+	if err != nil {
+		return treeFile{}, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	hash, err := writeBlob(objects, content)
+	if err != nil {
+		return treeFile{}, err
+	}
+	return treeFile{path: name, mode: fileMode(info), hash: hash}, nil
+}
+
+// fileMode returns the git mode of a file in the working tree.
+func fileMode(info fs.FileInfo) filemode.FileMode {
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		return filemode.Symlink
+	case info.Mode()&0o111 != 0:
+		return filemode.Executable
+	default:
+		return filemode.Regular
+	}
+}
+
+// indexTime returns the modification time of the repository's index in
+// nanoseconds, or 0 if it can't be read, so that no entry is trusted.
+func indexTime(repo *git.Repository) int64 {
+	dotGit, ok := repo.Storer.(interface{ Filesystem() billy.Filesystem })
+	if !ok {
+		return 0
+	}
+	info, err := dotGit.Filesystem().Stat("index")
+	if err != nil {
+		return 0
+	}
+	return info.ModTime().UnixNano()
+}
+
+// treeFile is a file to write into a tree.
+type treeFile struct {
+	path string
+	mode filemode.FileMode
+	hash plumbing.Hash
+}
+
+// writeTree writes the trees holding files to objects and returns the root.
+func writeTree(objects *overlay, files []treeFile) (*object.Tree, error) {
+	root := &treeDir{}
+	for _, f := range files {
+		dir := root
+		parts := strings.Split(f.path, "/")
+		for _, part := range parts[:len(parts)-1] {
+			dir = dir.subdir(part)
+		}
+		dir.files = append(dir.files, object.TreeEntry{Name: parts[len(parts)-1], Mode: f.mode, Hash: f.hash})
+	}
+	hash, err := root.write(objects)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write tree: %w", err)
+	}
+	return object.GetTree(objects, hash)
+}
+
+// treeDir is a directory of a tree being written.
+type treeDir struct {
+	files []object.TreeEntry
+	dirs  map[string]*treeDir
+}
+
+// subdir returns the subdirectory name, creating it if needed.
+func (d *treeDir) subdir(name string) *treeDir {
+	if d.dirs == nil {
+		d.dirs = make(map[string]*treeDir)
+	}
+	sub, ok := d.dirs[name]
+	if !ok {
+		sub = &treeDir{}
+		d.dirs[name] = sub
+	}
+	return sub
+}
+
+// write writes the directory and its subdirectories as tree objects and
+// returns the directory's hash.
+func (d *treeDir) write(objects storer.EncodedObjectStorer) (plumbing.Hash, error) {
+	entries := d.files
+	for name, sub := range d.dirs {
+		hash, err := sub.write(objects)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		entries = append(entries, object.TreeEntry{Name: name, Mode: filemode.Dir, Hash: hash})
+	}
+	sort.Sort(object.TreeEntrySorter(entries))
+
+	obj := objects.NewEncodedObject()
+	if err := (&object.Tree{Entries: entries}).Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return objects.SetEncodedObject(obj)
+}
+
+// overlay keeps objects created for the index and working tree in memory
+// and reads all others from the repository, which is never written to.
+type overlay struct {
+	storer.EncodedObjectStorer
+	objects map[plumbing.Hash]plumbing.EncodedObject
+}
+
+// newOverlay creates an overlay over the objects of a repository.
+func newOverlay(s storer.EncodedObjectStorer) *overlay {
+	return &overlay{EncodedObjectStorer: s, objects: make(map[plumbing.Hash]plumbing.EncodedObject)}
+}
+
+// NewEncodedObject returns a new in-memory object.
+func (o *overlay) NewEncodedObject() plumbing.EncodedObject {
+	return &plumbing.MemoryObject{}
+}
+
+// SetEncodedObject keeps obj in memory.
+func (o *overlay) SetEncodedObject(obj plumbing.EncodedObject) (plumbing.Hash, error) {
+	hash := obj.Hash()
+	o.objects[hash] = obj
+	return hash, nil
+}
+
+// EncodedObject returns the object with the given type and hash, from
+// memory first.
+func (o *overlay) EncodedObject(t plumbing.ObjectType, h plumbing.Hash) (plumbing.EncodedObject, error) {
+	if obj, ok := o.objects[h]; ok && (t == plumbing.AnyObject || obj.Type() == t) {
+		return obj, nil
+	}
+	return o.EncodedObjectStorer.EncodedObject(t, h)
+}
+
+// HasEncodedObject returns nil if the object exists in memory or in the
+// repository.
+func (o *overlay) HasEncodedObject(h plumbing.Hash) error {
+	if _, ok := o.objects[h]; ok {
+		return nil
+	}
+	return o.EncodedObjectStorer.HasEncodedObject(h)
+}
+
+// EncodedObjectSize returns the size of the object with hash h.
+func (o *overlay) EncodedObjectSize(h plumbing.Hash) (int64, error) {
+	if obj, ok := o.objects[h]; ok {
+		return obj.Size(), nil
+	}
+	return o.EncodedObjectStorer.EncodedObjectSize(h)
+}
+
+// writeBlob stores content as a blob in objects and returns its hash.
+func writeBlob(objects storer.EncodedObjectStorer, content []byte) (plumbing.Hash, error) {
+	obj := objects.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := w.Write(content); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return objects.SetEncodedObject(obj)
+}
@@ -0,0 +1,113 @@
+package html
+
+import (
+	"html/template"
+	"strings"
+
+	"github.com/fwojciec/diffstory"
+)
+
+// charStyle is the styling of a single byte of a line.
+type charStyle struct {
+	color   string
+	bold    bool
+	changed bool
+}
+
+// renderCode renders a line as escaped HTML, combining syntax token colors
+// with word-level change highlights. Tokens or segments whose text does not
+// match the line are ignored.
+func renderCode(text string, tokens []diffview.Token, segments []diffview.Segment) template.HTML {
+	styles := make([]charStyle, len(text))
+
+	if concatTokens(tokens) == text {
+		pos := 0
+		for _, tok := range tokens {
+			for k := range len(tok.Text) {
+				styles[pos+k].color = tok.Style.Foreground
+				styles[pos+k].bold = tok.Style.Bold
+			}
+			pos += len(tok.Text)
+		}
+	}
+	if concatSegments(segments) == text {
+		pos := 0
+		for _, seg := range segments {
+			for k := range len(seg.Text) {
+				styles[pos+k].changed = seg.Changed
+			}
+			pos += len(seg.Text)
+		}
+	}
+
+	var b strings.Builder
+	for start := 0; start < len(text); {
+		end := start + 1
+		for end < len(text) && styles[end] == styles[start] {
+			end++
+		}
+		writeRun(&b, text[start:end], styles[start])
+		start = end
+	}
+	return template.HTML(b.String()) // Text and colors are escaped by writeRun
+}
+
+// writeRun writes a run of equally styled text.
+// Colors that are not "#RRGGBB" hex strings are dropped.
+func writeRun(b *strings.Builder, text string, style charStyle) {
+	escaped := template.HTMLEscapeString(text)
+	if !isHexColor(style.color) {
+		style.color = ""
+	}
+	if style.changed {
+		b.WriteString("<mark>")
+	}
+	if style.color != "" || style.bold {
+		b.WriteString(`<span style="`)
+		if style.color != "" {
+			b.WriteString("color:")
+			b.WriteString(style.color)
+			b.WriteString(";")
+		}
+		if style.bold {
+			b.WriteString("font-weight:bold;")
+		}
+		b.WriteString(`">`)
+		b.WriteString(escaped)
+		b.WriteString("</span>")
+	} else {
+		b.WriteString(escaped)
+	}
+	if style.changed {
+		b.WriteString("</mark>")
+	}
+}
+
+func concatTokens(tokens []diffview.Token) string {
+	var b strings.Builder
+	for _, tok := range tokens {
+		b.WriteString(tok.Text)
+	}
+	return b.String()
+}
+
+func concatSegments(segments []diffview.Segment) string {
+	var b strings.Builder
+	for _, seg := range segments {
+		b.WriteString(seg.Text)
+	}
+	return b.String()
+}
+
+// isHexColor reports whether s is a "#RRGGBB" color.
+func isHexColor(s string) bool {
+	if len(s) != 7 || s[0] != '#' {
+		return false
+	}
+	for _, c := range s[1:] {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
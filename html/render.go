@@ -0,0 +1,251 @@
+// Package html renders story walkthroughs as self-contained HTML documents.
+package html
+
+import (
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/diffstory"
+)
+
+// Renderer renders a Diff and its StoryClassification as a single offline
+// HTML file: an intro slide followed by one slide per story section.
+type Renderer struct {
+	theme            diffview.Theme
+	languageDetector diffview.LanguageDetector
+	tokenizer        diffview.Tokenizer
+	wordDiffer       diffview.WordDiffer
+}
+
+// Option configures a Renderer.
+type Option func(*Renderer)
+
+// WithLanguageDetector sets the language detector used for syntax highlighting.
+func WithLanguageDetector(d diffview.LanguageDetector) Option {
+	return func(r *Renderer) {
+		r.languageDetector = d
+	}
+}
+
+// WithTokenizer sets the tokenizer used for syntax highlighting.
+func WithTokenizer(t diffview.Tokenizer) Option {
+	return func(r *Renderer) {
+		r.tokenizer = t
+	}
+}
+
+// WithWordDiffer sets the word differ used for word-level highlights.
+func WithWordDiffer(d diffview.WordDiffer) Option {
+	return func(r *Renderer) {
+		r.wordDiffer = d
+	}
+}
+
+// NewRenderer creates a Renderer that styles output with the theme's colors.
+func NewRenderer(theme diffview.Theme, opts ...Option) *Renderer {
+	r := &Renderer{theme: theme}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Render writes the story walkthrough for diff as an HTML document to w.
+func (r *Renderer) Render(w io.Writer, diff *diffview.Diff, story *diffview.StoryClassification) error {
+	tmpl, err := template.New("page").Funcs(template.FuncMap{
+		"inc": func(i int) int { return i + 1 },
+	}).Parse(pageHTML)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, r.buildPage(diff, story))
+}
+
+// page is the data passed to pageTemplate.
+type page struct {
+	Title   string
+	Palette diffview.Palette
+	Styles  diffview.Styles
+	Slides  []slide
+}
+
+// slide is either the intro slide or a section of the story.
+type slide struct {
+	Title       string
+	Role        string
+	Explanation string
+	Intro       *intro
+	Files       []fileView
+}
+
+type intro struct {
+	Summary    string
+	ChangeType string
+	Narrative  string
+	Evolution  string
+	Diagram    *diagram
+	Sections   []diffview.Section
+}
+
+// diagram is the narrative flow of section roles.
+// Hub is set for core-periphery narratives; otherwise Nodes form a linear flow.
+type diagram struct {
+	Hub   string
+	Nodes []string
+}
+
+type fileView struct {
+	Path  string
+	Hunks []hunkView
+}
+
+type hunkView struct {
+	Header       string
+	Collapsed    bool
+	CollapseText string
+	Category     string
+	Lines        []lineView
+}
+
+type lineView struct {
+	Class  string
+	Old    string
+	New    string
+	Prefix string
+	Code   template.HTML
+}
+
+func (r *Renderer) buildPage(diff *diffview.Diff, story *diffview.StoryClassification) page {
+	p := page{
+		Title:   "Story",
+		Palette: r.theme.Palette(),
+		Styles:  r.theme.Styles(),
+	}
+	if story != nil && story.Summary != "" {
+		p.Title = story.Summary
+	}
+
+	intro := &intro{}
+	if story != nil {
+		intro.Summary = story.Summary
+		intro.ChangeType = story.ChangeType
+		intro.Narrative = story.Narrative
+		intro.Evolution = story.Evolution
+		intro.Diagram = narrativeDiagram(story.Narrative, story.Sections)
+		intro.Sections = story.Sections
+	}
+	p.Slides = append(p.Slides, slide{Title: "Overview", Intro: intro})
+
+	if story == nil || len(story.Sections) == 0 {
+		// Without sections, show every hunk on a single slide
+		var refs []diffview.HunkRef
+		if diff != nil {
+			for _, file := range diff.Files {
+				for i := range file.Hunks {
+					refs = append(refs, diffview.HunkRef{File: file.Path(), HunkIndex: i})
+				}
+			}
+		}
+		p.Slides = append(p.Slides, slide{Title: "All changes", Files: r.buildFiles(diff, refs)})
+		return p
+	}
+
+	for _, section := range story.Sections {
+		p.Slides = append(p.Slides, slide{
+			Title:       section.Title,
+			Role:        section.Role,
+			Explanation: section.Explanation,
+			Files:       r.buildFiles(diff, section.Hunks),
+		})
+	}
+	return p
+}
+
+// buildFiles groups the referenced hunks by file, preserving reference order.
+func (r *Renderer) buildFiles(diff *diffview.Diff, refs []diffview.HunkRef) []fileView {
+	if diff == nil {
+		return nil
+	}
+	var files []fileView
+	index := make(map[string]int)
+	for _, ref := range refs {
+		hunk, ok := diff.FindHunk(ref.File, ref.HunkIndex)
+		if !ok {
+			continue
+		}
+		i, seen := index[ref.File]
+		if !seen {
+			i = len(files)
+			index[ref.File] = i
+			files = append(files, fileView{Path: ref.File})
+		}
+		files[i].Hunks = append(files[i].Hunks, r.buildHunk(ref, hunk))
+	}
+	return files
+}
+
+func (r *Renderer) buildHunk(ref diffview.HunkRef, hunk diffview.Hunk) hunkView {
+	view := hunkView{
+		Header:       hunk.Header(),
+		Collapsed:    ref.Collapsed || ref.Category == "noise",
+		CollapseText: ref.CollapseText,
+		Category:     ref.Category,
+	}
+
+	var language string
+	if r.languageDetector != nil {
+		language = r.languageDetector.DetectFromPath(ref.File)
+	}
+	tokens := diffview.TokenizeHunkLines(hunk.Lines, language, r.tokenizer)
+	segments := diffview.PairSegments(hunk.Lines, r.wordDiffer)
+
+	for i, line := range hunk.Lines {
+		lv := lineView{Prefix: line.Prefix()}
+		switch line.Type {
+		case diffview.LineAdded:
+			lv.Class = "added"
+		case diffview.LineDeleted:
+			lv.Class = "deleted"
+		default:
+			lv.Class = "context"
+		}
+		if line.OldLineNum > 0 {
+			lv.Old = strconv.Itoa(line.OldLineNum)
+		}
+		if line.NewLineNum > 0 {
+			lv.New = strconv.Itoa(line.NewLineNum)
+		}
+
+		var lineTokens []diffview.Token
+		if i < len(tokens) {
+			lineTokens = tokens[i]
+		}
+		lv.Code = renderCode(strings.TrimSuffix(line.Content, "\n"), lineTokens, segments[i])
+		view.Lines = append(view.Lines, lv)
+	}
+	return view
+}
+
+// narrativeDiagram returns the flow of section roles for narratives that have one.
+func narrativeDiagram(narrative string, sections []diffview.Section) *diagram {
+	roles := diffview.SectionRoles(sections)
+	if len(roles) == 0 {
+		return nil
+	}
+	switch narrative {
+	case "cause-effect", "entry-implementation", "before-after", "rule-instances":
+		return &diagram{Nodes: roles}
+	case "core-periphery":
+		d := &diagram{Hub: "core"}
+		for _, role := range roles {
+			if role != "core" {
+				d.Nodes = append(d.Nodes, role)
+			}
+		}
+		return d
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,229 @@
+package html_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/html"
+	"github.com/fwojciec/diffstory/lipgloss"
+	"github.com/fwojciec/diffstory/mock"
+	"github.com/fwojciec/diffstory/worddiff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDiff() *diffview.Diff {
+	return &diffview.Diff{
+		Files: []diffview.FileDiff{
+			{
+				NewPath:   "main.go",
+				Operation: diffview.FileModified,
+				Hunks: []diffview.Hunk{
+					{
+						OldStart: 1, OldCount: 2, NewStart: 1, NewCount: 2,
+						Lines: []diffview.Line{
+							{Type: diffview.LineContext, Content: "package main", OldLineNum: 1, NewLineNum: 1},
+							{Type: diffview.LineDeleted, Content: "x := 1 < 2", OldLineNum: 2},
+							{Type: diffview.LineAdded, Content: "x := 1 < 3", NewLineNum: 2},
+						},
+					},
+					{
+						OldStart: 40, OldCount: 1, NewStart: 40, NewCount: 1,
+						Lines: []diffview.Line{
+							{Type: diffview.LineAdded, Content: "// generated", NewLineNum: 40},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func testStory() *diffview.StoryClassification {
+	return &diffview.StoryClassification{
+		ChangeType: "bugfix",
+		Narrative:  "cause-effect",
+		Summary:    "Fix <comparison>",
+		Evolution:  "Single commit.",
+		Sections: []diffview.Section{
+			{
+				Role:        "fix",
+				Title:       "Compare against 3",
+				Explanation: "Off by one.",
+				Hunks:       []diffview.HunkRef{{File: "main.go", HunkIndex: 0, Category: "core"}},
+			},
+			{
+				Role:  "supporting",
+				Title: "Generated code",
+				Hunks: []diffview.HunkRef{
+					{File: "main.go", HunkIndex: 1, Category: "noise", Collapsed: true, CollapseText: "Regenerated file"},
+				},
+			},
+		},
+	}
+}
+
+func render(t *testing.T, r *html.Renderer, diff *diffview.Diff, story *diffview.StoryClassification) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, r.Render(&buf, diff, story))
+	return buf.String()
+}
+
+func TestRenderer_Render(t *testing.T) {
+	t.Parallel()
+
+	t.Run("renders intro and one slide per section", func(t *testing.T) {
+		t.Parallel()
+
+		out := render(t, html.NewRenderer(lipgloss.TestTheme()), testDiff(), testStory())
+
+		assert.Equal(t, 3, strings.Count(out, `<section class="slide`))
+		assert.Contains(t, out, `<h1>Fix &lt;comparison&gt;</h1>`)
+		assert.Contains(t, out, `<h2>Evolution</h2><p>Single commit.</p>`)
+		assert.Contains(t, out, `<a href="#slide-1">[fix] Compare against 3</a>`)
+		assert.Contains(t, out, `<a href="#slide-2">[supporting] Generated code</a>`)
+		assert.Contains(t, out, `<h1>Compare against 3</h1>`)
+		assert.Contains(t, out, `<p>Off by one.</p>`)
+	})
+
+	t.Run("renders narrative diagram", func(t *testing.T) {
+		t.Parallel()
+
+		out := render(t, html.NewRenderer(lipgloss.TestTheme()), testDiff(), testStory())
+
+		assert.Contains(t, out, `<span class="node">fix</span><span class="arrow">→</span><span class="node">supporting</span>`)
+	})
+
+	t.Run("embeds palette colors", func(t *testing.T) {
+		t.Parallel()
+
+		out := render(t, html.NewRenderer(lipgloss.TestTheme()), testDiff(), testStory())
+
+		assert.Contains(t, out, "--bg: #000000;")
+		assert.Contains(t, out, "--fg: #ffffff;")
+		assert.NotContains(t, out, "<link")
+		assert.NotContains(t, out, "<script src")
+	})
+
+	t.Run("collapses noise hunks with collapse text", func(t *testing.T) {
+		t.Parallel()
+
+		out := render(t, html.NewRenderer(lipgloss.TestTheme()), testDiff(), testStory())
+
+		assert.Contains(t, out, `<details class="collapsed">`)
+		assert.Contains(t, out, `<summary>@@ -40,1 &#43;40,1 @@ — Regenerated file</summary>`)
+		assert.Contains(t, out, `<details open>`)
+	})
+
+	t.Run("escapes code lines", func(t *testing.T) {
+		t.Parallel()
+
+		out := render(t, html.NewRenderer(lipgloss.TestTheme()), testDiff(), testStory())
+
+		assert.Contains(t, out, `<td class="code">-x := 1 &lt; 2</td>`)
+	})
+
+	t.Run("applies syntax token colors", func(t *testing.T) {
+		t.Parallel()
+
+		r := html.NewRenderer(lipgloss.TestTheme(),
+			html.WithLanguageDetector(&mock.LanguageDetector{
+				DetectFromPathFn: func(string) string { return "go" },
+			}),
+			html.WithTokenizer(&mock.Tokenizer{
+				TokenizeLinesFn: func(_, source string) [][]diffview.Token {
+					lines := strings.Split(source, "\n")
+					tokens := make([][]diffview.Token, len(lines))
+					for i, line := range lines {
+						keyword, rest, _ := strings.Cut(line, " ")
+						tokens[i] = []diffview.Token{
+							{Text: keyword, Style: diffview.Style{Foreground: "#ff00ff", Bold: true}},
+							{Text: " " + rest},
+						}
+					}
+					return tokens
+				},
+			}),
+		)
+
+		out := render(t, r, testDiff(), testStory())
+
+		assert.Contains(t, out, `<span style="color:#ff00ff;font-weight:bold;">package</span> main`)
+	})
+
+	t.Run("drops colors that are not hex", func(t *testing.T) {
+		t.Parallel()
+
+		r := html.NewRenderer(lipgloss.TestTheme(),
+			html.WithLanguageDetector(&mock.LanguageDetector{
+				DetectFromPathFn: func(string) string { return "go" },
+			}),
+			html.WithTokenizer(&mock.Tokenizer{
+				TokenizeLinesFn: func(_, source string) [][]diffview.Token {
+					lines := strings.Split(source, "\n")
+					tokens := make([][]diffview.Token, len(lines))
+					for i, line := range lines {
+						tokens[i] = []diffview.Token{{Text: line, Style: diffview.Style{Foreground: "red;x:url(y)"}}}
+					}
+					return tokens
+				},
+			}),
+		)
+
+		out := render(t, r, testDiff(), testStory())
+
+		assert.NotContains(t, out, "url(y)")
+	})
+
+	t.Run("highlights changed words", func(t *testing.T) {
+		t.Parallel()
+
+		r := html.NewRenderer(lipgloss.TestTheme(),
+			html.WithWordDiffer(&mock.WordDiffer{
+				DiffFn: func(old, new string) ([]diffview.Segment, []diffview.Segment) {
+					return []diffview.Segment{{Text: old[:len(old)-1]}, {Text: old[len(old)-1:], Changed: true}},
+						[]diffview.Segment{{Text: new[:len(new)-1]}, {Text: new[len(new)-1:], Changed: true}}
+				},
+			}),
+		)
+
+		out := render(t, r, testDiff(), testStory())
+
+		assert.Contains(t, out, `-x := 1 &lt; <mark>2</mark>`)
+		assert.Contains(t, out, `&#43;x := 1 &lt; <mark>3</mark>`)
+	})
+
+	t.Run("pairs changed lines by similarity", func(t *testing.T) {
+		t.Parallel()
+
+		// A line inserted before the changed one would be paired with it by
+		// position
+		diff := testDiff()
+		diff.Files[0].Hunks[0].Lines = []diffview.Line{
+			{Type: diffview.LineDeleted, Content: "x := 1 < 2", OldLineNum: 1},
+			{Type: diffview.LineAdded, Content: "// compare with three", NewLineNum: 1},
+			{Type: diffview.LineAdded, Content: "x := 1 < 3", NewLineNum: 2},
+		}
+		r := html.NewRenderer(lipgloss.TestTheme(), html.WithWordDiffer(worddiff.NewDiffer()))
+
+		out := render(t, r, diff, testStory())
+
+		assert.Contains(t, out, `-x := 1 &lt; <mark>2</mark>`)
+		assert.Contains(t, out, `&#43;x := 1 &lt; <mark>3</mark>`)
+		assert.Contains(t, out, `&#43;// compare with three`)
+	})
+
+	t.Run("shows all hunks without a story", func(t *testing.T) {
+		t.Parallel()
+
+		out := render(t, html.NewRenderer(lipgloss.TestTheme()), testDiff(), nil)
+
+		assert.Equal(t, 2, strings.Count(out, `<section class="slide`))
+		assert.Contains(t, out, "(No classification available)")
+		assert.Contains(t, out, "<h1>All changes</h1>")
+		assert.Equal(t, 2, strings.Count(out, "<details open>"))
+	})
+}
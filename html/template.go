@@ -0,0 +1,139 @@
+package html
+
+// pageHTML is the template for a story walkthrough. The output embeds all
+// styles and scripts so it can be opened offline or attached to a PR.
+const pageHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+:root {
+  --bg: {{.Palette.Background}};
+  --fg: {{.Palette.Foreground}};
+  --ui-bg: {{.Palette.UIBackground}};
+  --ui-fg: {{.Palette.UIForeground}};
+  --accent: {{.Palette.UIAccent}};
+  --context: {{.Palette.Context}};
+  --file-fg: {{.Styles.FileHeader.Foreground}};
+  --added-bg: {{.Styles.Added.Background}};
+  --deleted-bg: {{.Styles.Deleted.Background}};
+  --added-gutter: {{.Styles.AddedGutter.Background}};
+  --deleted-gutter: {{.Styles.DeletedGutter.Background}};
+  --added-mark: {{.Styles.AddedHighlight.Background}};
+  --deleted-mark: {{.Styles.DeletedHighlight.Background}};
+}
+* { box-sizing: border-box; }
+body { margin: 0; background: var(--bg); color: var(--fg); font: 15px/1.5 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; }
+main { max-width: 1100px; margin: 0 auto; padding: 1.5rem 1.5rem 5rem; }
+nav { position: fixed; bottom: 0; left: 0; right: 0; display: flex; gap: 1rem; align-items: center; justify-content: center; padding: .5rem; background: var(--ui-bg); color: var(--ui-fg); }
+nav button { background: none; border: 1px solid var(--ui-fg); color: var(--fg); border-radius: 4px; padding: .2rem .8rem; cursor: pointer; }
+nav button:disabled { opacity: .4; cursor: default; }
+.slide { display: none; }
+.slide.active { display: block; }
+h1, h2 { margin: .5rem 0; }
+.role { color: var(--accent); font-size: .8em; text-transform: uppercase; letter-spacing: .05em; }
+.meta { color: var(--ui-fg); }
+.diagram { display: flex; flex-wrap: wrap; align-items: center; gap: .5rem; margin: 1rem 0; }
+.node { border: 1px solid var(--ui-fg); border-radius: 8px; padding: .2rem .8rem; }
+.node.hub { border-color: var(--accent); color: var(--accent); }
+.arrow { color: var(--ui-fg); }
+.toc a { color: var(--fg); }
+.file { margin: 1rem 0; border: 1px solid var(--ui-bg); border-radius: 6px; overflow: hidden; }
+.file > h3 { margin: 0; padding: .4rem .8rem; font: 600 14px ui-monospace, SFMono-Regular, Menlo, monospace; background: var(--ui-bg); color: var(--file-fg); }
+details > summary { cursor: pointer; padding: .2rem .8rem; font: 13px ui-monospace, SFMono-Regular, Menlo, monospace; color: var(--accent); }
+details.collapsed > summary { color: var(--context); }
+table { border-collapse: collapse; width: 100%; font: 13px/1.4 ui-monospace, SFMono-Regular, Menlo, monospace; }
+td { padding: 0 .5rem; white-space: pre; vertical-align: top; }
+td.num { width: 1%; text-align: right; color: var(--context); user-select: none; }
+td.code { white-space: pre-wrap; word-break: break-all; }
+tr.context td.code { color: var(--context); }
+tr.added td.code { background: var(--added-bg); }
+tr.deleted td.code { background: var(--deleted-bg); }
+tr.added td.num { background: var(--added-gutter); color: var(--fg); }
+tr.deleted td.num { background: var(--deleted-gutter); color: var(--fg); }
+tr.added mark { background: var(--added-mark); color: inherit; }
+tr.deleted mark { background: var(--deleted-mark); color: inherit; }
+@media print { .slide { display: block; page-break-after: always; } nav { display: none; } }
+</style>
+</head>
+<body>
+<main>
+{{range $i, $slide := .Slides}}
+<section class="slide{{if eq $i 0}} active{{end}}" id="slide-{{$i}}">
+{{with .Intro}}
+<h1>{{if .Summary}}{{.Summary}}{{else}}Story{{end}}</h1>
+{{if or .ChangeType .Narrative}}<p class="meta">{{if .ChangeType}}<strong>{{.ChangeType}}</strong>{{end}}{{if and .ChangeType .Narrative}} · {{end}}{{.Narrative}}</p>{{end}}
+{{with .Diagram}}
+<div class="diagram">
+{{if .Hub}}<span class="node hub">{{.Hub}}</span>{{range .Nodes}}<span class="arrow">↔</span><span class="node">{{.}}</span>{{end}}
+{{else}}{{range $j, $node := .Nodes}}{{if $j}}<span class="arrow">→</span>{{end}}<span class="node">{{$node}}</span>{{end}}{{end}}
+</div>
+{{end}}
+{{if .Evolution}}<h2>Evolution</h2><p>{{.Evolution}}</p>{{end}}
+{{if .Sections}}
+<h2>Sections</h2>
+<ol class="toc">
+{{range $j, $section := .Sections}}<li><a href="#slide-{{inc $j}}">{{if $section.Role}}[{{$section.Role}}] {{end}}{{$section.Title}}</a></li>
+{{end}}
+</ol>
+{{else}}
+<p class="meta">(No classification available)</p>
+{{end}}
+{{else}}
+{{if .Role}}<div class="role">{{.Role}}</div>{{end}}
+<h1>{{.Title}}</h1>
+{{if .Explanation}}<p>{{.Explanation}}</p>{{end}}
+{{range .Files}}
+<div class="file">
+<h3>{{.Path}}</h3>
+{{range .Hunks}}
+<details{{if .Collapsed}} class="collapsed"{{else}} open{{end}}>
+<summary>{{.Header}}{{if .Collapsed}} — {{if .CollapseText}}{{.CollapseText}}{{else}}collapsed{{end}}{{end}}</summary>
+<table>
+{{range .Lines}}<tr class="{{.Class}}"><td class="num">{{.Old}}</td><td class="num">{{.New}}</td><td class="code">{{.Prefix}}{{.Code}}</td></tr>
+{{end}}</table>
+</details>
+{{end}}
+</div>
+{{end}}
+{{end}}
+</section>
+{{end}}
+</main>
+<nav>
+<button id="prev" type="button">← prev</button>
+<span id="position"></span>
+<button id="next" type="button">next →</button>
+</nav>
+<script>
+(function () {
+  var slides = document.querySelectorAll(".slide");
+  var current = 0;
+  function show(i) {
+    current = Math.max(0, Math.min(slides.length - 1, i));
+    slides.forEach(function (s, j) { s.classList.toggle("active", j === current); });
+    document.getElementById("prev").disabled = current === 0;
+    document.getElementById("next").disabled = current === slides.length - 1;
+    document.getElementById("position").textContent = (current + 1) + " / " + slides.length;
+    history.replaceState(null, "", "#slide-" + current);
+    window.scrollTo(0, 0);
+  }
+  function fromHash() {
+    var m = /^#slide-(\d+)$/.exec(location.hash);
+    show(m ? parseInt(m[1], 10) : 0);
+  }
+  document.getElementById("prev").onclick = function () { show(current - 1); };
+  document.getElementById("next").onclick = function () { show(current + 1); };
+  document.addEventListener("keydown", function (e) {
+    if (e.key === "ArrowRight" || e.key === "s") { show(current + 1); }
+    if (e.key === "ArrowLeft" || e.key === "S") { show(current - 1); }
+  });
+  window.addEventListener("hashchange", fromHash);
+  fromHash();
+})();
+</script>
+</body>
+</html>
+`
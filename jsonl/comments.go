@@ -0,0 +1,88 @@
+package jsonl
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fwojciec/diffstory"
+)
+
+// Compile-time interface verification.
+var _ diffview.CommentStore = (*CommentStore)(nil)
+
+// CommentStore persists and retrieves review Comment records as JSONL.
+type CommentStore struct{}
+
+// NewCommentStore creates a new CommentStore.
+func NewCommentStore() *CommentStore {
+	return &CommentStore{}
+}
+
+// Load reads comments from a JSONL file. Returns empty slice if file doesn't exist.
+func (s *CommentStore) Load(path string) ([]diffview.Comment, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var comments []diffview.Comment
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var c diffview.Comment
+		if err := json.Unmarshal([]byte(line), &c); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		comments = append(comments, c)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// Save writes comments to a JSONL file, creating parent directories if needed.
+func (s *CommentStore) Save(path string, comments []diffview.Comment) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for _, c := range comments {
+		data, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			return err
+		}
+		if _, err := f.WriteString("\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
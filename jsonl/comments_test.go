@@ -0,0 +1,107 @@
+package jsonl_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/jsonl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommentStore_Load(t *testing.T) {
+	t.Parallel()
+
+	t.Run("loads valid comments file", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "comments.jsonl")
+		content := `{"file":"main.go","side":"new","line":12,"body":"Why not reuse the helper?","created_at":"2025-01-15T10:30:00Z"}
+{"file":"util.go","side":"old","line":3,"body":"This was load-bearing","created_at":"2025-01-15T10:31:00Z"}`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+		store := jsonl.NewCommentStore()
+		comments, err := store.Load(path)
+
+		require.NoError(t, err)
+		require.Len(t, comments, 2)
+		assert.Equal(t, "main.go", comments[0].File)
+		assert.Equal(t, diffview.SideNew, comments[0].Side)
+		assert.Equal(t, 12, comments[0].Line)
+		assert.Equal(t, "Why not reuse the helper?", comments[0].Body)
+		assert.Equal(t, diffview.SideOld, comments[1].Side)
+	})
+
+	t.Run("returns empty slice for non-existent file", func(t *testing.T) {
+		t.Parallel()
+
+		store := jsonl.NewCommentStore()
+		comments, err := store.Load("/nonexistent/comments.jsonl")
+
+		require.NoError(t, err)
+		assert.Empty(t, comments)
+	})
+
+	t.Run("returns error for malformed JSON", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "bad.jsonl")
+		content := `{"file":"main.go","side":"new","line":1,"body":"ok"}
+not valid json`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+		store := jsonl.NewCommentStore()
+		_, err := store.Load(path)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "line 2")
+	})
+}
+
+func TestCommentStore_Save(t *testing.T) {
+	t.Parallel()
+
+	t.Run("round-trips comments", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "nested", "comments.jsonl")
+
+		comments := []diffview.Comment{
+			{
+				File:      "main.go",
+				Side:      diffview.SideNew,
+				Line:      7,
+				Body:      "First line\nSecond line",
+				CreatedAt: time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC),
+			},
+		}
+
+		store := jsonl.NewCommentStore()
+		require.NoError(t, store.Save(path, comments))
+
+		loaded, err := store.Load(path)
+		require.NoError(t, err)
+		assert.Equal(t, comments, loaded)
+	})
+
+	t.Run("overwrites existing file", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "comments.jsonl")
+		require.NoError(t, os.WriteFile(path, []byte("old content"), 0o644))
+
+		store := jsonl.NewCommentStore()
+		require.NoError(t, store.Save(path, []diffview.Comment{}))
+
+		loaded, err := store.Load(path)
+		require.NoError(t, err)
+		assert.Empty(t, loaded)
+	})
+}
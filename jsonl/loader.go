@@ -1,4 +1,4 @@
-// Package jsonl provides JSONL file handling for eval cases and judgments.
+// Package jsonl provides JSONL file handling for eval cases, judgments and review comments.
 package jsonl
 
 import (
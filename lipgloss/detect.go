@@ -0,0 +1,107 @@
+package lipgloss
+
+import (
+	"io"
+	"time"
+
+	charmlipgloss "github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// PresetAuto selects the light or dark preset to match the terminal background.
+const PresetAuto = "auto"
+
+// DefaultDetectTimeout bounds how long background detection may delay startup.
+// Terminals that don't answer the OSC 11 query are assumed to be dark.
+const DefaultDetectTimeout = 300 * time.Millisecond
+
+// Terminal is a terminal that can be queried for its background color.
+// Reads must stop at the read deadline, so that a terminal that never
+// answers leaves no read behind; *os.File for a terminal device does.
+type Terminal interface {
+	io.ReadWriter
+	SetReadDeadline(t time.Time) error
+}
+
+// DetectOption configures terminal background detection.
+type DetectOption func(*detector)
+
+type detector struct {
+	renderer *charmlipgloss.Renderer
+	terminal Terminal
+	timeout  time.Duration
+}
+
+// WithDetectRenderer answers detection from r instead of querying the
+// terminal, as when its background was set with SetHasDarkBackground.
+func WithDetectRenderer(r *charmlipgloss.Renderer) DetectOption {
+	return func(d *detector) {
+		d.renderer = r
+	}
+}
+
+// WithDetectTerminal sets the terminal to query. Defaults to the controlling
+// terminal, put into raw mode for the query.
+func WithDetectTerminal(t Terminal) DetectOption {
+	return func(d *detector) {
+		d.terminal = t
+	}
+}
+
+// WithDetectTimeout sets how long to wait for the terminal to answer.
+func WithDetectTimeout(timeout time.Duration) DetectOption {
+	return func(d *detector) {
+		d.timeout = timeout
+	}
+}
+
+// HasDarkBackground reports whether the terminal has a dark background.
+// Returns true if the terminal doesn't answer within the timeout; no read
+// of the terminal is left pending by then.
+func HasDarkBackground(opts ...DetectOption) bool {
+	d := &detector{timeout: DefaultDetectTimeout}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.renderer != nil {
+		return d.renderer.HasDarkBackground()
+	}
+
+	tty := d.terminal
+	if tty == nil {
+		t, restore, err := openTerminal()
+		if err != nil {
+			return true
+		}
+		defer restore()
+		tty = t
+	}
+	if err := tty.SetReadDeadline(time.Now().Add(d.timeout)); err != nil {
+		return true // Reads can't be bounded
+	}
+	defer func() { _ = tty.SetReadDeadline(time.Time{}) }()
+
+	// The terminal's state is managed here, so termenv only sends the query
+	// and reads the answer, which fails at the deadline.
+	output := termenv.NewOutput(fdTerminal{tty}, termenv.WithUnsafe())
+	return output.HasDarkBackground()
+}
+
+// fdTerminal adapts a Terminal to termenv.File. termenv only needs the file
+// descriptor for the terminal state handled by openTerminal.
+type fdTerminal struct {
+	Terminal
+}
+
+// Fd implements termenv.File.
+func (fdTerminal) Fd() uintptr {
+	return ^uintptr(0)
+}
+
+// DetectTheme returns the light or dark preset matching the terminal background.
+func DetectTheme(opts ...DetectOption) *Theme {
+	if HasDarkBackground(opts...) {
+		return NewTheme(githubDarkPalette())
+	}
+	return NewTheme(githubLightPalette())
+}
@@ -0,0 +1,13 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package lipgloss
+
+import (
+	"errors"
+	"os"
+)
+
+// openTerminal reports that the terminal can't be queried on this platform.
+func openTerminal() (tty *os.File, restore func(), err error) {
+	return nil, nil, errors.New("background detection is not supported on this platform")
+}
@@ -0,0 +1,104 @@
+package lipgloss_test
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	charmlipgloss "github.com/charmbracelet/lipgloss"
+	"github.com/fwojciec/diffstory/lipgloss"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectTheme(t *testing.T) {
+	t.Parallel()
+
+	t.Run("dark background selects dark preset", func(t *testing.T) {
+		t.Parallel()
+
+		theme := lipgloss.DetectTheme(lipgloss.WithDetectRenderer(backgroundRenderer(true)))
+		assert.Equal(t, lipgloss.DefaultTheme().Palette(), theme.Palette())
+	})
+
+	t.Run("light background selects light preset", func(t *testing.T) {
+		t.Parallel()
+
+		theme := lipgloss.DetectTheme(lipgloss.WithDetectRenderer(backgroundRenderer(false)))
+		light, _ := lipgloss.PresetPalette(lipgloss.PresetLight)
+		assert.Equal(t, light, theme.Palette())
+	})
+
+	t.Run("non-terminal output assumes dark", func(t *testing.T) {
+		t.Parallel()
+
+		dark := lipgloss.HasDarkBackground(lipgloss.WithDetectRenderer(charmlipgloss.NewRenderer(&bytes.Buffer{})))
+		assert.True(t, dark)
+	})
+}
+
+func TestHasDarkBackground(t *testing.T) {
+	// Can't use t.Parallel with t.Setenv
+	t.Setenv("TERM", "xterm")
+	t.Setenv("COLORFGBG", "")
+
+	t.Run("silent terminal assumes dark and leaves no read behind", func(t *testing.T) {
+		tty, peer := pipeTerminal(t)
+		go func() { _, _ = io.Copy(io.Discard, peer) }()
+
+		dark := lipgloss.HasDarkBackground(
+			lipgloss.WithDetectTerminal(tty),
+			lipgloss.WithDetectTimeout(20*time.Millisecond),
+		)
+		assert.True(t, dark)
+
+		// A write through the pipe only completes when something reads it
+		require.NoError(t, peer.SetWriteDeadline(time.Now().Add(50*time.Millisecond)))
+		_, err := peer.Write([]byte("x"))
+		assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	})
+
+	t.Run("answered query reports the background", func(t *testing.T) {
+		tty, peer := pipeTerminal(t)
+		go func() {
+			buf := make([]byte, 64)
+			// Answer once both queries, background color and cursor position, arrived
+			for n := 0; n < len("\x1b]11;?\x1b\\\x1b[6n"); {
+				m, err := peer.Read(buf)
+				if err != nil {
+					return
+				}
+				n += m
+			}
+			_, _ = peer.Write([]byte("\x1b]11;rgb:ffff/ffff/ffff\x1b\\\x1b[1;1R"))
+		}()
+
+		dark := lipgloss.HasDarkBackground(
+			lipgloss.WithDetectTerminal(tty),
+			lipgloss.WithDetectTimeout(time.Second),
+		)
+		assert.False(t, dark)
+	})
+}
+
+// pipeTerminal returns a terminal whose other end is peer.
+func pipeTerminal(t *testing.T) (tty, peer net.Conn) {
+	t.Helper()
+	tty, peer = net.Pipe()
+	t.Cleanup(func() {
+		_ = tty.Close()
+		_ = peer.Close()
+	})
+	return tty, peer
+}
+
+// backgroundRenderer returns a renderer that reports the given background
+// without querying a terminal.
+func backgroundRenderer(dark bool) *charmlipgloss.Renderer {
+	r := charmlipgloss.NewRenderer(&bytes.Buffer{})
+	r.SetHasDarkBackground(dark)
+	return r
+}
@@ -0,0 +1,48 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package lipgloss
+
+import (
+	"errors"
+	"os"
+
+	"github.com/charmbracelet/x/term"
+	"golang.org/x/sys/unix"
+)
+
+// openTerminal opens the controlling terminal in raw mode, so the answer to
+// the query is neither echoed nor line-buffered. restore puts the terminal
+// back and closes it. Fails when the process isn't in the foreground, where
+// changing the terminal's mode would stop it.
+func openTerminal() (tty *os.File, restore func(), err error) {
+	f, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Control leaves the file non-blocking, unlike Fd, so read deadlines work
+	conn, err := f.SyscallConn()
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+	var state *term.State
+	ctrlErr := conn.Control(func(fd uintptr) {
+		pgrp, perr := unix.IoctlGetInt(int(fd), unix.TIOCGPGRP)
+		if perr != nil || pgrp != unix.Getpgrp() {
+			err = errors.New("not the foreground process")
+			return
+		}
+		state, err = term.MakeRaw(fd)
+	})
+	if err == nil {
+		err = ctrlErr
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+	return f, func() {
+		_ = conn.Control(func(fd uintptr) { _ = term.Restore(fd, state) })
+		_ = f.Close()
+	}, nil
+}
@@ -0,0 +1,87 @@
+package lipgloss
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/fwojciec/diffstory"
+)
+
+// themeFile is the contents of a user theme file.
+//
+// A theme starts from the Base preset (dark if unset); Palette colors replace
+// the preset's colors and Styles overrides replace the colors derived from the
+// palette. All fields are optional.
+type themeFile struct {
+	Base    string           `json:"base" toml:"base"`
+	Palette diffview.Palette `json:"palette" toml:"palette"`
+	Styles  diffview.Styles  `json:"styles" toml:"styles"`
+}
+
+// LoadThemeFile reads a theme from a TOML (.toml) or JSON (.json) file.
+func LoadThemeFile(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading theme: %w", err)
+	}
+
+	// First pass finds the base preset; the second decodes the palette on top
+	// of it so that colors missing from the file keep their preset values.
+	var header themeFile
+	if err := decodeTheme(path, data, &header); err != nil {
+		return nil, err
+	}
+	base := header.Base
+	if base == "" {
+		base = PresetDark
+	}
+	palette, ok := PresetPalette(base)
+	if !ok {
+		return nil, fmt.Errorf("theme %s: %w %q as base", path, ErrUnknownTheme, base)
+	}
+
+	file := themeFile{Palette: palette}
+	if err := decodeTheme(path, data, &file); err != nil {
+		return nil, err
+	}
+	return NewTheme(file.Palette, WithStyleOverrides(file.Styles)), nil
+}
+
+// decodeTheme decodes data according to the extension of path.
+func decodeTheme(path string, data []byte, v *themeFile) error {
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		var md toml.MetaData
+		md, err = toml.NewDecoder(bytes.NewReader(data)).Decode(v)
+		if undecoded := md.Undecoded(); err == nil && len(undecoded) > 0 {
+			err = fmt.Errorf("unknown key %q", undecoded[0].String())
+		}
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(v)
+	default:
+		return fmt.Errorf("theme %s: unsupported file type (use .toml or .json)", path)
+	}
+	if err != nil {
+		return fmt.Errorf("parsing theme %s: %w", path, err)
+	}
+	return nil
+}
+
+// IsThemeFile reports whether name looks like a theme file path rather
+// than a preset name.
+func IsThemeFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".toml", ".json":
+		return true
+	default:
+		return false
+	}
+}
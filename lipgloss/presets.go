@@ -0,0 +1,243 @@
+package lipgloss
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fwojciec/diffstory"
+)
+
+// ErrUnknownTheme is returned when a theme name is neither a preset nor a theme file.
+var ErrUnknownTheme = errors.New("unknown theme")
+
+// Preset theme names.
+const (
+	PresetDark           = "dark" // GitHub-inspired dark theme (the default)
+	PresetLight          = "light"
+	PresetHighContrast   = "high-contrast"
+	PresetSolarizedDark  = "solarized-dark"
+	PresetSolarizedLight = "solarized-light"
+	PresetDracula        = "dracula"
+)
+
+// PresetNames returns the names of the built-in themes.
+func PresetNames() []string {
+	return []string{
+		PresetDark,
+		PresetLight,
+		PresetHighContrast,
+		PresetSolarizedDark,
+		PresetSolarizedLight,
+		PresetDracula,
+	}
+}
+
+// PresetPalette returns the palette of a built-in theme.
+func PresetPalette(name string) (diffview.Palette, bool) {
+	switch name {
+	case PresetDark:
+		return githubDarkPalette(), true
+	case PresetLight:
+		return githubLightPalette(), true
+	case PresetHighContrast:
+		return highContrastPalette(), true
+	case PresetSolarizedDark:
+		return solarizedDarkPalette(), true
+	case PresetSolarizedLight:
+		return solarizedLightPalette(), true
+	case PresetDracula:
+		return draculaPalette(), true
+	default:
+		return diffview.Palette{}, false
+	}
+}
+
+// ResolveTheme returns the theme for name: a preset name, or the path of a
+// .toml or .json theme file. An empty name or "auto" detects the terminal
+// background and returns the light or dark preset; opts configure detection.
+func ResolveTheme(name string, opts ...DetectOption) (*Theme, error) {
+	if name == "" || name == PresetAuto {
+		return DetectTheme(opts...), nil
+	}
+	if p, ok := PresetPalette(name); ok {
+		return NewTheme(p), nil
+	}
+	if IsThemeFile(name) {
+		return LoadThemeFile(name)
+	}
+	return nil, fmt.Errorf("%w %q (use %s, or a .toml or .json file)",
+		ErrUnknownTheme, name, strings.Join(append(PresetNames(), PresetAuto), ", "))
+}
+
+// SelectTheme returns the theme named by name, typically a --theme flag,
+// then $DIFFSTORY_THEME, then the config file, resolved with ResolveTheme.
+func SelectTheme(name string, cfg diffview.Config, opts ...DetectOption) (*Theme, error) {
+	return ResolveTheme(themeName(name, cfg), opts...)
+}
+
+// SelectPrintTheme is like SelectTheme for output that is printed or
+// exported rather than shown in a TUI. The terminal is never queried, since
+// a pager reading the same terminal would compete for its answer: an empty
+// name or "auto" selects the dark preset.
+func SelectPrintTheme(name string, cfg diffview.Config) (*Theme, error) {
+	name = themeName(name, cfg)
+	if name == "" || name == PresetAuto {
+		return DefaultTheme(), nil
+	}
+	return ResolveTheme(name)
+}
+
+// themeName returns name, then $DIFFSTORY_THEME, then the config theme,
+// whichever is set first.
+func themeName(name string, cfg diffview.Config) string {
+	if name == "" {
+		name = os.Getenv("DIFFSTORY_THEME")
+	}
+	if name == "" {
+		name = cfg.Theme
+	}
+	return name
+}
+
+// githubLightPalette returns a GitHub-inspired light theme color palette.
+// Based on GitHub's Primer design system light mode colors.
+func githubLightPalette() diffview.Palette {
+	return diffview.Palette{
+		// Base colors - GitHub light mode canvas
+		Background: "#ffffff",
+		Foreground: "#1f2328",
+
+		// Diff colors
+		Added:    "#1a7f37",
+		Deleted:  "#cf222e",
+		Modified: "#9a6700",
+		Context:  "#656d76",
+
+		// Syntax highlighting colors - GitHub light mode syntax
+		Keyword:     "#cf222e",
+		String:      "#0a3069",
+		Number:      "#0550ae",
+		Comment:     "#6e7781",
+		Operator:    "#cf222e",
+		Function:    "#8250df",
+		Type:        "#953800",
+		Constant:    "#0550ae",
+		Punctuation: "#57606a",
+
+		// UI colors - GitHub light mode surfaces
+		UIBackground: "#f6f8fa",
+		UIForeground: "#656d76",
+		UIAccent:     "#0969da",
+	}
+}
+
+// highContrastPalette returns a dark palette with maximum contrast for
+// low-vision users and washed-out displays.
+func highContrastPalette() diffview.Palette {
+	return diffview.Palette{
+		Background: "#000000",
+		Foreground: "#ffffff",
+
+		Added:    "#00ff5f",
+		Deleted:  "#ff3030",
+		Modified: "#ffd700",
+		Context:  "#d0d0d0",
+
+		Keyword:     "#ff87ff",
+		String:      "#87ffff",
+		Number:      "#ffaf00",
+		Comment:     "#bcbcbc",
+		Operator:    "#ffffff",
+		Function:    "#87afff",
+		Type:        "#ffd700",
+		Constant:    "#ffaf00",
+		Punctuation: "#e4e4e4",
+
+		UIBackground: "#1c1c1c",
+		UIForeground: "#e4e4e4",
+		UIAccent:     "#00d7ff",
+	}
+}
+
+// solarizedDarkPalette returns Ethan Schoonover's Solarized dark palette.
+func solarizedDarkPalette() diffview.Palette {
+	return diffview.Palette{
+		Background: "#002b36", // base03
+		Foreground: "#93a1a1", // base1
+
+		Added:    "#859900", // green
+		Deleted:  "#dc322f", // red
+		Modified: "#b58900", // yellow
+		Context:  "#839496", // base0
+
+		Keyword:     "#859900", // green
+		String:      "#2aa198", // cyan
+		Number:      "#d33682", // magenta
+		Comment:     "#586e75", // base01
+		Operator:    "#859900", // green
+		Function:    "#268bd2", // blue
+		Type:        "#b58900", // yellow
+		Constant:    "#cb4b16", // orange
+		Punctuation: "#839496", // base0
+
+		UIBackground: "#073642", // base02
+		UIForeground: "#586e75", // base01
+		UIAccent:     "#268bd2", // blue
+	}
+}
+
+// solarizedLightPalette returns Ethan Schoonover's Solarized light palette.
+func solarizedLightPalette() diffview.Palette {
+	return diffview.Palette{
+		Background: "#fdf6e3", // base3
+		Foreground: "#586e75", // base01
+
+		Added:    "#859900", // green
+		Deleted:  "#dc322f", // red
+		Modified: "#b58900", // yellow
+		Context:  "#657b83", // base00
+
+		Keyword:     "#859900", // green
+		String:      "#2aa198", // cyan
+		Number:      "#d33682", // magenta
+		Comment:     "#93a1a1", // base1
+		Operator:    "#859900", // green
+		Function:    "#268bd2", // blue
+		Type:        "#b58900", // yellow
+		Constant:    "#cb4b16", // orange
+		Punctuation: "#657b83", // base00
+
+		UIBackground: "#eee8d5", // base2
+		UIForeground: "#93a1a1", // base1
+		UIAccent:     "#268bd2", // blue
+	}
+}
+
+// draculaPalette returns the Dracula theme palette.
+func draculaPalette() diffview.Palette {
+	return diffview.Palette{
+		Background: "#282a36",
+		Foreground: "#f8f8f2",
+
+		Added:    "#50fa7b", // green
+		Deleted:  "#ff5555", // red
+		Modified: "#f1fa8c", // yellow
+		Context:  "#6272a4", // comment
+
+		Keyword:     "#ff79c6", // pink
+		String:      "#f1fa8c", // yellow
+		Number:      "#bd93f9", // purple
+		Comment:     "#6272a4", // comment
+		Operator:    "#ff79c6", // pink
+		Function:    "#50fa7b", // green
+		Type:        "#8be9fd", // cyan
+		Constant:    "#bd93f9", // purple
+		Punctuation: "#f8f8f2",
+
+		UIBackground: "#44475a", // current line
+		UIForeground: "#6272a4",
+		UIAccent:     "#bd93f9", // purple
+	}
+}
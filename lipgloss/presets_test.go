@@ -0,0 +1,216 @@
+package lipgloss_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/lipgloss"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresetPalette(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range lipgloss.PresetNames() {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p, ok := lipgloss.PresetPalette(name)
+			require.True(t, ok)
+			// Every preset defines the colors styles are derived from
+			assert.NotEmpty(t, p.Background)
+			assert.NotEmpty(t, p.Foreground)
+			assert.NotEmpty(t, p.Added)
+			assert.NotEmpty(t, p.Deleted)
+			assert.NotEmpty(t, p.Keyword)
+			assert.NotEmpty(t, p.UIAccent)
+		})
+	}
+}
+
+func TestResolveTheme(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty name detects the terminal background", func(t *testing.T) {
+		t.Parallel()
+
+		theme, err := lipgloss.ResolveTheme("", lipgloss.WithDetectRenderer(backgroundRenderer(false)))
+		require.NoError(t, err)
+		assert.Equal(t, diffview.Color("#ffffff"), theme.Palette().Background)
+	})
+
+	t.Run("auto detects the terminal background", func(t *testing.T) {
+		t.Parallel()
+
+		theme, err := lipgloss.ResolveTheme(lipgloss.PresetAuto, lipgloss.WithDetectRenderer(backgroundRenderer(true)))
+		require.NoError(t, err)
+		assert.Equal(t, lipgloss.DefaultTheme().Palette(), theme.Palette())
+	})
+
+	t.Run("explicit preset ignores the terminal background", func(t *testing.T) {
+		t.Parallel()
+
+		theme, err := lipgloss.ResolveTheme(lipgloss.PresetDark, lipgloss.WithDetectRenderer(backgroundRenderer(false)))
+		require.NoError(t, err)
+		assert.Equal(t, lipgloss.DefaultTheme().Palette(), theme.Palette())
+	})
+
+	t.Run("preset name", func(t *testing.T) {
+		t.Parallel()
+
+		theme, err := lipgloss.ResolveTheme(lipgloss.PresetLight)
+		require.NoError(t, err)
+		assert.Equal(t, diffview.Color("#ffffff"), theme.Palette().Background)
+	})
+
+	t.Run("unknown name lists presets", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := lipgloss.ResolveTheme("neon")
+		require.ErrorIs(t, err, lipgloss.ErrUnknownTheme)
+		assert.Contains(t, err.Error(), "solarized-dark")
+	})
+}
+
+func TestSelectTheme(t *testing.T) {
+	// Can't use t.Parallel with t.Setenv
+	light := lipgloss.WithDetectRenderer(backgroundRenderer(false))
+	cfg := diffview.Config{Theme: lipgloss.PresetLight}
+
+	t.Run("flag wins over environment and config", func(t *testing.T) {
+		t.Setenv("DIFFSTORY_THEME", lipgloss.PresetLight)
+
+		theme, err := lipgloss.SelectTheme(lipgloss.PresetDark, cfg, light)
+		require.NoError(t, err)
+		assert.Equal(t, lipgloss.DefaultTheme().Palette(), theme.Palette())
+	})
+
+	t.Run("environment wins over config", func(t *testing.T) {
+		t.Setenv("DIFFSTORY_THEME", lipgloss.PresetDark)
+
+		theme, err := lipgloss.SelectTheme("", cfg, light)
+		require.NoError(t, err)
+		assert.Equal(t, lipgloss.DefaultTheme().Palette(), theme.Palette())
+	})
+
+	t.Run("config", func(t *testing.T) {
+		t.Setenv("DIFFSTORY_THEME", "")
+
+		theme, err := lipgloss.SelectTheme("", diffview.Config{Theme: lipgloss.PresetDark}, light)
+		require.NoError(t, err)
+		assert.Equal(t, lipgloss.DefaultTheme().Palette(), theme.Palette())
+	})
+
+	t.Run("nothing set detects the terminal background", func(t *testing.T) {
+		t.Setenv("DIFFSTORY_THEME", "")
+
+		theme, err := lipgloss.SelectTheme("", diffview.Config{}, light)
+		require.NoError(t, err)
+		assert.Equal(t, diffview.Color("#ffffff"), theme.Palette().Background)
+	})
+}
+
+func TestSelectPrintTheme(t *testing.T) {
+	// Can't use t.Parallel with t.Setenv
+
+	t.Run("nothing set uses the dark preset", func(t *testing.T) {
+		t.Setenv("DIFFSTORY_THEME", "")
+
+		theme, err := lipgloss.SelectPrintTheme("", diffview.Config{})
+		require.NoError(t, err)
+		assert.Equal(t, lipgloss.DefaultTheme().Palette(), theme.Palette())
+	})
+
+	t.Run("auto uses the dark preset", func(t *testing.T) {
+		t.Setenv("DIFFSTORY_THEME", lipgloss.PresetAuto)
+
+		theme, err := lipgloss.SelectPrintTheme("", diffview.Config{})
+		require.NoError(t, err)
+		assert.Equal(t, lipgloss.DefaultTheme().Palette(), theme.Palette())
+	})
+
+	t.Run("explicit theme from config", func(t *testing.T) {
+		t.Setenv("DIFFSTORY_THEME", "")
+
+		theme, err := lipgloss.SelectPrintTheme("", diffview.Config{Theme: lipgloss.PresetLight})
+		require.NoError(t, err)
+		assert.Equal(t, diffview.Color("#ffffff"), theme.Palette().Background)
+	})
+}
+
+func TestLoadThemeFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("toml palette on top of base preset", func(t *testing.T) {
+		t.Parallel()
+
+		path := writeTheme(t, "mine.toml", `
+base = "light"
+
+[palette]
+added = "#00aa00"
+ui_accent = "#123456"
+
+[styles.hunk_header]
+background = "#eeeeee"
+`)
+		theme, err := lipgloss.ResolveTheme(path)
+		require.NoError(t, err)
+
+		palette := theme.Palette()
+		assert.Equal(t, diffview.Color("#00aa00"), palette.Added)
+		assert.Equal(t, diffview.Color("#123456"), palette.UIAccent)
+		// Colors missing from the file come from the base preset
+		assert.Equal(t, diffview.Color("#ffffff"), palette.Background)
+
+		styles := theme.Styles()
+		assert.Equal(t, "#123456", styles.HunkHeader.Foreground)
+		assert.Equal(t, "#eeeeee", styles.HunkHeader.Background)
+	})
+
+	t.Run("json defaults to dark base", func(t *testing.T) {
+		t.Parallel()
+
+		path := writeTheme(t, "mine.json", `{"palette": {"keyword": "#ff0000"}}`)
+		theme, err := lipgloss.LoadThemeFile(path)
+		require.NoError(t, err)
+
+		assert.Equal(t, diffview.Color("#ff0000"), theme.Palette().Keyword)
+		assert.Equal(t, diffview.Color("#0d1117"), theme.Palette().Background)
+	})
+
+	t.Run("unknown key is an error", func(t *testing.T) {
+		t.Parallel()
+
+		path := writeTheme(t, "typo.toml", "[palette]\nbackgroud = \"#000000\"\n")
+		_, err := lipgloss.LoadThemeFile(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "backgroud")
+	})
+
+	t.Run("unknown base is an error", func(t *testing.T) {
+		t.Parallel()
+
+		path := writeTheme(t, "base.json", `{"base": "neon"}`)
+		_, err := lipgloss.LoadThemeFile(path)
+		require.ErrorIs(t, err, lipgloss.ErrUnknownTheme)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := lipgloss.LoadThemeFile(filepath.Join(t.TempDir(), "missing.toml"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func writeTheme(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
@@ -26,12 +26,48 @@ func (t *Theme) Palette() diffview.Palette {
 	return t.palette
 }
 
+// ThemeOption configures a Theme.
+type ThemeOption func(*Theme)
+
+// WithStyleOverrides replaces derived styles with the non-empty colors in s.
+// Empty foreground or background values keep the color derived from the palette.
+func WithStyleOverrides(s diffview.Styles) ThemeOption {
+	return func(t *Theme) {
+		overridePair(&t.styles.Added, s.Added)
+		overridePair(&t.styles.Deleted, s.Deleted)
+		overridePair(&t.styles.Context, s.Context)
+		overridePair(&t.styles.HunkHeader, s.HunkHeader)
+		overridePair(&t.styles.FileHeader, s.FileHeader)
+		overridePair(&t.styles.FileSeparator, s.FileSeparator)
+		overridePair(&t.styles.LineNumber, s.LineNumber)
+		overridePair(&t.styles.AddedGutter, s.AddedGutter)
+		overridePair(&t.styles.DeletedGutter, s.DeletedGutter)
+		overridePair(&t.styles.AddedHighlight, s.AddedHighlight)
+		overridePair(&t.styles.DeletedHighlight, s.DeletedHighlight)
+		overridePair(&t.styles.Moved, s.Moved)
+	}
+}
+
 // NewTheme creates a Theme from a Palette, deriving all styles from the palette colors.
-func NewTheme(p diffview.Palette) *Theme {
-	return &Theme{
+func NewTheme(p diffview.Palette, opts ...ThemeOption) *Theme {
+	t := &Theme{
 		palette: p,
 		styles:  stylesFromPalette(p),
 	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// overridePair copies the non-empty colors of src into dst.
+func overridePair(dst *diffview.ColorPair, src diffview.ColorPair) {
+	if src.Foreground != "" {
+		dst.Foreground = src.Foreground
+	}
+	if src.Background != "" {
+		dst.Background = src.Background
+	}
 }
 
 // stylesFromPalette derives Styles from a Palette.
@@ -77,6 +113,10 @@ func stylesFromPalette(p diffview.Palette) diffview.Styles {
 			Foreground: string(p.Foreground),                               // Same as code line foreground (neutral)
 			Background: blendWithBackground(p.Deleted, p.Background, 0.35), // Same as gutter
 		},
+		Moved: diffview.ColorPair{
+			Foreground: string(p.Foreground),
+			Background: blendWithBackground(p.Modified, p.Background, 0.15), // Same intensity as added/deleted lines
+		},
 	}
 }
 
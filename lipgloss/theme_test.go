@@ -165,6 +165,21 @@ func TestNewTheme(t *testing.T) {
 		assert.NotEmpty(t, styles.DeletedHighlight.Background)
 		assert.Equal(t, styles.DeletedGutter.Background, styles.DeletedHighlight.Background) // Same as gutter
 	})
+
+	t.Run("derives moved style from modified color", func(t *testing.T) {
+		t.Parallel()
+
+		palette := diffview.Palette{
+			Background: "#000000",
+			Foreground: "#cdd6f4",
+			Modified:   "#ffff00",
+		}
+
+		styles := lipgloss.NewTheme(palette).Styles()
+
+		assert.Equal(t, "#cdd6f4", styles.Moved.Foreground)
+		assert.Equal(t, "#262600", styles.Moved.Background) // 15% blend, like added/deleted lines
+	})
 }
 
 func TestDefaultTheme(t *testing.T) {
@@ -278,3 +293,21 @@ func TestTestTheme(t *testing.T) {
 		assert.Equal(t, string(palette.Foreground), styles.Deleted.Foreground)
 	})
 }
+
+func TestNewTheme_WithStyleOverrides(t *testing.T) {
+	t.Parallel()
+
+	theme := lipgloss.NewTheme(diffview.Palette{
+		Background: "#000000",
+		Foreground: "#ffffff",
+		UIAccent:   "#0000ff",
+	}, lipgloss.WithStyleOverrides(diffview.Styles{
+		HunkHeader: diffview.ColorPair{Background: "#222222"},
+	}))
+	styles := theme.Styles()
+
+	// Only the non-empty override replaces the derived color
+	assert.Equal(t, "#0000ff", styles.HunkHeader.Foreground)
+	assert.Equal(t, "#222222", styles.HunkHeader.Background)
+	assert.Equal(t, "#ffffff", styles.Added.Foreground)
+}
@@ -0,0 +1,25 @@
+package markdown
+
+import (
+	"os"
+
+	"github.com/fwojciec/diffstory"
+)
+
+// Compile-time interface verification.
+var _ diffview.StoryExporter = (*Exporter)(nil)
+
+// Exporter writes stories as Markdown files.
+type Exporter struct {
+	opts []StoryOption
+}
+
+// NewExporter creates an Exporter that formats stories with the given options.
+func NewExporter(opts ...StoryOption) *Exporter {
+	return &Exporter{opts: opts}
+}
+
+// Export writes the story to path as Markdown, replacing any existing file.
+func (e *Exporter) Export(path string, diff *diffview.Diff, story *diffview.StoryClassification) error {
+	return os.WriteFile(path, []byte(FormatStory(diff, story, e.opts...)), 0o644)
+}
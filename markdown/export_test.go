@@ -0,0 +1,26 @@
+package markdown_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/markdown"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExporter_Export(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "story.md")
+	story := &diffview.StoryClassification{Summary: "Fix login"}
+
+	err := markdown.NewExporter().Export(path, storyDiff(), story)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, markdown.FormatStory(storyDiff(), story), string(data))
+}
@@ -0,0 +1,95 @@
+// Package markdown renders diffs, stories and review comments as Markdown.
+package markdown
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fwojciec/diffstory"
+)
+
+// sectionKey identifies a hunk within a story section lookup.
+type sectionKey struct {
+	file      string
+	hunkIndex int
+}
+
+// FormatReview renders review comments as a Markdown review.
+// Comments are grouped by the story section containing their hunk, in section
+// order; comments outside any section (or without a story) are listed last.
+// Each comment quotes the line it is anchored to when that line is in diff.
+func FormatReview(diff *diffview.Diff, story *diffview.StoryClassification, comments []diffview.Comment) string {
+	var b strings.Builder
+	b.WriteString("# Review\n")
+
+	if story != nil && story.Summary != "" {
+		fmt.Fprintf(&b, "\n%s\n", story.Summary)
+	}
+
+	if len(comments) == 0 {
+		b.WriteString("\nNo comments.\n")
+		return b.String()
+	}
+
+	var sections []diffview.Section
+	hunkToSection := make(map[sectionKey]int)
+	if story != nil {
+		sections = story.Sections
+		for i, section := range sections {
+			for _, ref := range section.Hunks {
+				hunkToSection[sectionKey{file: ref.File, hunkIndex: ref.HunkIndex}] = i
+			}
+		}
+	}
+
+	grouped := make([][]diffview.Comment, len(sections))
+	var other []diffview.Comment
+	for _, c := range comments {
+		if diff != nil {
+			if hunkIdx, _, ok := diff.FindLine(c.File, c.Side, c.Line); ok {
+				if i, ok := hunkToSection[sectionKey{file: c.File, hunkIndex: hunkIdx}]; ok {
+					grouped[i] = append(grouped[i], c)
+					continue
+				}
+			}
+		}
+		other = append(other, c)
+	}
+
+	for i, section := range sections {
+		if len(grouped[i]) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %d. %s\n", i+1, section.Title)
+		for _, c := range grouped[i] {
+			writeComment(&b, diff, c)
+		}
+	}
+
+	if len(other) > 0 {
+		if len(sections) > 0 {
+			b.WriteString("\n## Other comments\n")
+		} else {
+			b.WriteString("\n## Comments\n")
+		}
+		for _, c := range other {
+			writeComment(&b, diff, c)
+		}
+	}
+
+	return b.String()
+}
+
+// writeComment writes a single comment with a snippet of the line it annotates.
+func writeComment(b *strings.Builder, diff *diffview.Diff, c diffview.Comment) {
+	fmt.Fprintf(b, "\n### `%s:%d` (%s)\n\n", c.File, c.Line, c.Side)
+
+	if diff != nil {
+		if _, line, ok := diff.FindLine(c.File, c.Side, c.Line); ok {
+			fmt.Fprintf(b, "```diff\n%s%s\n```\n\n", line.Prefix(), strings.TrimSuffix(line.Content, "\n"))
+		}
+	}
+
+	b.WriteString(strings.TrimSpace(c.Body))
+	b.WriteString("\n")
+}
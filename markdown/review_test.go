@@ -0,0 +1,104 @@
+package markdown_test
+
+import (
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/markdown"
+	"github.com/stretchr/testify/assert"
+)
+
+func reviewDiff() *diffview.Diff {
+	return &diffview.Diff{
+		Files: []diffview.FileDiff{
+			{
+				OldPath:   "main.go",
+				NewPath:   "main.go",
+				Operation: diffview.FileModified,
+				Hunks: []diffview.Hunk{
+					{Lines: []diffview.Line{
+						{Type: diffview.LineAdded, Content: "func run() error {", NewLineNum: 3},
+					}},
+					{Lines: []diffview.Line{
+						{Type: diffview.LineDeleted, Content: "// TODO", OldLineNum: 20},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestFormatReview(t *testing.T) {
+	t.Parallel()
+
+	t.Run("groups comments by story section", func(t *testing.T) {
+		t.Parallel()
+
+		story := &diffview.StoryClassification{
+			Summary: "Extract run function",
+			Sections: []diffview.Section{
+				{Title: "Core change", Hunks: []diffview.HunkRef{{File: "main.go", HunkIndex: 0}}},
+				{Title: "Cleanup", Hunks: []diffview.HunkRef{{File: "main.go", HunkIndex: 1}}},
+			},
+		}
+		comments := []diffview.Comment{
+			{File: "main.go", Side: diffview.SideOld, Line: 20, Body: "Was this TODO resolved?"},
+			{File: "main.go", Side: diffview.SideNew, Line: 3, Body: "Nice extraction."},
+		}
+
+		got := markdown.FormatReview(reviewDiff(), story, comments)
+
+		expected := "# Review\n" +
+			"\nExtract run function\n" +
+			"\n## 1. Core change\n" +
+			"\n### `main.go:3` (new)\n\n" +
+			"```diff\n+func run() error {\n```\n\n" +
+			"Nice extraction.\n" +
+			"\n## 2. Cleanup\n" +
+			"\n### `main.go:20` (old)\n\n" +
+			"```diff\n-// TODO\n```\n\n" +
+			"Was this TODO resolved?\n"
+		assert.Equal(t, expected, got)
+	})
+
+	t.Run("lists comments outside sections last", func(t *testing.T) {
+		t.Parallel()
+
+		story := &diffview.StoryClassification{
+			Sections: []diffview.Section{
+				{Title: "Core change", Hunks: []diffview.HunkRef{{File: "main.go", HunkIndex: 0}}},
+			},
+		}
+		comments := []diffview.Comment{
+			{File: "main.go", Side: diffview.SideOld, Line: 20, Body: "Unsectioned"},
+			{File: "other.go", Side: diffview.SideNew, Line: 1, Body: "Stale"},
+		}
+
+		got := markdown.FormatReview(reviewDiff(), story, comments)
+
+		assert.NotContains(t, got, "## 1. Core change")
+		assert.Contains(t, got, "## Other comments")
+		assert.Contains(t, got, "### `other.go:1` (new)\n\nStale\n")
+	})
+
+	t.Run("uses a single group without a story", func(t *testing.T) {
+		t.Parallel()
+
+		comments := []diffview.Comment{
+			{File: "main.go", Side: diffview.SideNew, Line: 3, Body: "Looks good"},
+		}
+
+		got := markdown.FormatReview(reviewDiff(), nil, comments)
+
+		assert.Contains(t, got, "## Comments\n")
+		assert.Contains(t, got, "Looks good")
+	})
+
+	t.Run("notes when there are no comments", func(t *testing.T) {
+		t.Parallel()
+
+		got := markdown.FormatReview(reviewDiff(), nil, nil)
+
+		assert.Equal(t, "# Review\n\nNo comments.\n", got)
+	})
+}
@@ -0,0 +1,133 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fwojciec/diffstory"
+)
+
+// StoryOption configures FormatStory.
+type StoryOption func(*storyConfig)
+
+type storyConfig struct {
+	snippets bool
+}
+
+// WithDiffSnippets includes a fenced diff snippet for each expanded hunk.
+// Collapsed hunks are always summarized instead.
+func WithDiffSnippets() StoryOption {
+	return func(cfg *storyConfig) {
+		cfg.snippets = true
+	}
+}
+
+// FormatStory renders a story classification as Markdown suitable for a pull
+// request description: the summary, change type, narrative and evolution,
+// followed by each section with its explanation and the hunks it covers.
+func FormatStory(diff *diffview.Diff, story *diffview.StoryClassification, opts ...StoryOption) string {
+	cfg := &storyConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	var b strings.Builder
+	if story == nil {
+		b.WriteString("# Changes\n\n(No classification available)\n")
+		return b.String()
+	}
+
+	if story.Summary != "" {
+		fmt.Fprintf(&b, "# %s\n", story.Summary)
+	} else {
+		b.WriteString("# Changes\n")
+	}
+
+	var meta []string
+	if story.ChangeType != "" {
+		meta = append(meta, fmt.Sprintf("**Change type:** %s", story.ChangeType))
+	}
+	if story.Narrative != "" {
+		meta = append(meta, fmt.Sprintf("**Narrative:** %s", story.Narrative))
+	}
+	if len(meta) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", strings.Join(meta, " · "))
+	}
+
+	if story.Evolution != "" {
+		fmt.Fprintf(&b, "\n## Evolution\n\n%s\n", story.Evolution)
+	}
+
+	for i, section := range story.Sections {
+		fmt.Fprintf(&b, "\n## %d. %s", i+1, section.Title)
+		if section.Role != "" {
+			fmt.Fprintf(&b, " (%s)", section.Role)
+		}
+		b.WriteString("\n")
+
+		if section.Explanation != "" {
+			fmt.Fprintf(&b, "\n%s\n", section.Explanation)
+		}
+
+		if len(section.Hunks) == 0 {
+			continue
+		}
+		b.WriteString("\n")
+		for _, ref := range section.Hunks {
+			writeHunkRef(&b, diff, ref, cfg)
+		}
+	}
+
+	return b.String()
+}
+
+// writeHunkRef writes a list item for a hunk, followed by its snippet if enabled.
+func writeHunkRef(b *strings.Builder, diff *diffview.Diff, ref diffview.HunkRef, cfg *storyConfig) {
+	var hunk diffview.Hunk
+	var found bool
+	if diff != nil {
+		hunk, found = diff.FindHunk(ref.File, ref.HunkIndex)
+	}
+
+	fmt.Fprintf(b, "- `%s`", ref.File)
+	if found {
+		fmt.Fprintf(b, " %s", lineRange(hunk))
+	}
+
+	collapsed := ref.Collapsed || ref.Category == "noise"
+	if collapsed {
+		if ref.CollapseText != "" {
+			fmt.Fprintf(b, " — %s", ref.CollapseText)
+		} else {
+			b.WriteString(" — collapsed")
+		}
+	}
+	b.WriteString("\n")
+
+	if cfg.snippets && found && !collapsed {
+		writeSnippet(b, hunk)
+	}
+}
+
+// writeSnippet writes a hunk as a fenced diff block, indented under its list item.
+func writeSnippet(b *strings.Builder, hunk diffview.Hunk) {
+	b.WriteString("\n  ```diff\n")
+	fmt.Fprintf(b, "  %s\n", hunk.Range())
+	for _, line := range hunk.Lines {
+		fmt.Fprintf(b, "  %s%s\n", line.Prefix(), strings.TrimSuffix(line.Content, "\n"))
+	}
+	b.WriteString("  ```\n\n")
+}
+
+// lineRange describes the lines a hunk covers in the new file, or in the
+// old file for hunks that only delete lines.
+func lineRange(hunk diffview.Hunk) string {
+	start, count, prefix := hunk.NewStart, hunk.NewCount, ""
+	if count == 0 {
+		start, count, prefix = hunk.OldStart, hunk.OldCount, "old "
+	}
+	if count <= 1 {
+		return fmt.Sprintf("(%sline %d)", prefix, start)
+	}
+	return fmt.Sprintf("(%slines %d–%d)", prefix, start, start+count-1)
+}
@@ -0,0 +1,124 @@
+package markdown_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/markdown"
+	"github.com/stretchr/testify/assert"
+)
+
+func storyDiff() *diffview.Diff {
+	return &diffview.Diff{
+		Files: []diffview.FileDiff{
+			{
+				OldPath:   "parser.go",
+				NewPath:   "parser.go",
+				Operation: diffview.FileModified,
+				Hunks: []diffview.Hunk{
+					{
+						OldStart: 10, OldCount: 2, NewStart: 10, NewCount: 3,
+						Lines: []diffview.Line{
+							{Type: diffview.LineContext, Content: "if err != nil {"},
+							{Type: diffview.LineDeleted, Content: "\treturn nil"},
+							{Type: diffview.LineAdded, Content: "\treturn err"},
+							{Type: diffview.LineAdded, Content: "\t// propagate"},
+						},
+					},
+				},
+			},
+			{
+				OldPath:   "go.sum",
+				NewPath:   "go.sum",
+				Operation: diffview.FileModified,
+				Hunks: []diffview.Hunk{
+					{OldStart: 4, OldCount: 1, NewStart: 4, NewCount: 0},
+				},
+			},
+		},
+	}
+}
+
+func storyFixture() *diffview.StoryClassification {
+	return &diffview.StoryClassification{
+		ChangeType: "bugfix",
+		Narrative:  "cause-effect",
+		Summary:    "Propagate parse errors",
+		Evolution:  "First commit added logging, second replaced it with error propagation.",
+		Sections: []diffview.Section{
+			{
+				Role:        "fix",
+				Title:       "Return the error",
+				Explanation: "Callers need to see parse failures.",
+				Hunks:       []diffview.HunkRef{{File: "parser.go", HunkIndex: 0, Category: "core"}},
+			},
+			{
+				Role:  "supporting",
+				Title: "Dependencies",
+				Hunks: []diffview.HunkRef{
+					{File: "go.sum", HunkIndex: 0, Category: "noise", Collapsed: true, CollapseText: "Checksum update"},
+				},
+			},
+		},
+	}
+}
+
+func TestFormatStory(t *testing.T) {
+	t.Parallel()
+
+	t.Run("renders story as PR description", func(t *testing.T) {
+		t.Parallel()
+
+		got := markdown.FormatStory(storyDiff(), storyFixture())
+
+		expected := "# Propagate parse errors\n" +
+			"\n**Change type:** bugfix · **Narrative:** cause-effect\n" +
+			"\n## Evolution\n\nFirst commit added logging, second replaced it with error propagation.\n" +
+			"\n## 1. Return the error (fix)\n" +
+			"\nCallers need to see parse failures.\n" +
+			"\n- `parser.go` (lines 10–12)\n" +
+			"\n## 2. Dependencies (supporting)\n" +
+			"\n- `go.sum` (old line 4) — Checksum update\n"
+		assert.Equal(t, expected, got)
+	})
+
+	t.Run("includes snippets for expanded hunks only", func(t *testing.T) {
+		t.Parallel()
+
+		got := markdown.FormatStory(storyDiff(), storyFixture(), markdown.WithDiffSnippets())
+
+		assert.Contains(t, got, "- `parser.go` (lines 10–12)\n"+
+			"\n  ```diff\n"+
+			"  @@ -10,2 +10,3 @@\n"+
+			"   if err != nil {\n"+
+			"  -\treturn nil\n"+
+			"  +\treturn err\n"+
+			"  +\t// propagate\n"+
+			"  ```\n")
+		assert.Equal(t, 1, strings.Count(got, "```diff"))
+	})
+
+	t.Run("lists unknown hunks without line ranges", func(t *testing.T) {
+		t.Parallel()
+
+		story := &diffview.StoryClassification{
+			Sections: []diffview.Section{
+				{Title: "Gone", Hunks: []diffview.HunkRef{{File: "missing.go", HunkIndex: 3}}},
+			},
+		}
+
+		got := markdown.FormatStory(storyDiff(), story, markdown.WithDiffSnippets())
+
+		assert.Contains(t, got, "- `missing.go`\n")
+		assert.NotContains(t, got, "```diff")
+	})
+
+	t.Run("handles missing classification", func(t *testing.T) {
+		t.Parallel()
+
+		got := markdown.FormatStory(storyDiff(), nil)
+
+		assert.Equal(t, "# Changes\n\n(No classification available)\n", got)
+	})
+}
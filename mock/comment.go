@@ -0,0 +1,22 @@
+package mock
+
+import (
+	"github.com/fwojciec/diffstory"
+)
+
+// Compile-time interface verification.
+var _ diffview.CommentStore = (*CommentStore)(nil)
+
+// CommentStore is a mock implementation of diffview.CommentStore.
+type CommentStore struct {
+	LoadFn func(path string) ([]diffview.Comment, error)
+	SaveFn func(path string, comments []diffview.Comment) error
+}
+
+func (s *CommentStore) Load(path string) ([]diffview.Comment, error) {
+	return s.LoadFn(path)
+}
+
+func (s *CommentStore) Save(path string, comments []diffview.Comment) error {
+	return s.SaveFn(path, comments)
+}
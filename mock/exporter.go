@@ -0,0 +1,17 @@
+package mock
+
+import (
+	"github.com/fwojciec/diffstory"
+)
+
+// Compile-time interface verification.
+var _ diffview.StoryExporter = (*StoryExporter)(nil)
+
+// StoryExporter is a mock implementation of diffview.StoryExporter.
+type StoryExporter struct {
+	ExportFn func(path string, diff *diffview.Diff, story *diffview.StoryClassification) error
+}
+
+func (e *StoryExporter) Export(path string, diff *diffview.Diff, story *diffview.StoryClassification) error {
+	return e.ExportFn(path, diff, story)
+}
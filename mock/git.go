@@ -19,11 +19,20 @@ type GitRunner struct {
 	// PR-level extraction methods
 	MergeCommitsFn   func(ctx context.Context, repoPath string, limit int) ([]string, error)
 	CommitsInRangeFn func(ctx context.Context, repoPath, base, head string) ([]diffview.CommitBrief, error)
+	CountCommitsFn   func(ctx context.Context, repoPath, base, head string) (int, error)
 	DiffRangeFn      func(ctx context.Context, repoPath, base, head string) (string, error)
 	DiffFn           func(ctx context.Context, repoPath, rangeSpec string) (string, error)
 	CurrentBranchFn  func(ctx context.Context, repoPath string) (string, error)
 	MergeBaseFn      func(ctx context.Context, repoPath, ref1, ref2 string) (string, error)
 	DefaultBranchFn  func(ctx context.Context, repoPath string) (string, error)
+	UpstreamFn       func(ctx context.Context, repoPath string) (string, error)
+	BranchesFn       func(ctx context.Context, repoPath string) ([]string, error)
+	ShowFileFn       func(ctx context.Context, repoPath, rev, path string) (string, error)
+
+	// Uncommitted changes
+	StagedDiffFn   func(ctx context.Context, repoPath string) (string, error)
+	WorktreeDiffFn func(ctx context.Context, repoPath, rev string) (string, error)
+	ApplyPatchFn   func(ctx context.Context, repoPath, patch string, opts diffview.ApplyOptions) error
 }
 
 func (g *GitRunner) Log(ctx context.Context, repoPath string, limit int) ([]string, error) {
@@ -46,6 +55,10 @@ func (g *GitRunner) CommitsInRange(ctx context.Context, repoPath, base, head str
 	return g.CommitsInRangeFn(ctx, repoPath, base, head)
 }
 
+func (g *GitRunner) CountCommits(ctx context.Context, repoPath, base, head string) (int, error) {
+	return g.CountCommitsFn(ctx, repoPath, base, head)
+}
+
 func (g *GitRunner) DiffRange(ctx context.Context, repoPath, base, head string) (string, error) {
 	return g.DiffRangeFn(ctx, repoPath, base, head)
 }
@@ -65,3 +78,27 @@ func (g *GitRunner) MergeBase(ctx context.Context, repoPath, ref1, ref2 string)
 func (g *GitRunner) DefaultBranch(ctx context.Context, repoPath string) (string, error) {
 	return g.DefaultBranchFn(ctx, repoPath)
 }
+
+func (g *GitRunner) Upstream(ctx context.Context, repoPath string) (string, error) {
+	return g.UpstreamFn(ctx, repoPath)
+}
+
+func (g *GitRunner) Branches(ctx context.Context, repoPath string) ([]string, error) {
+	return g.BranchesFn(ctx, repoPath)
+}
+
+func (g *GitRunner) ShowFile(ctx context.Context, repoPath, rev, path string) (string, error) {
+	return g.ShowFileFn(ctx, repoPath, rev, path)
+}
+
+func (g *GitRunner) StagedDiff(ctx context.Context, repoPath string) (string, error) {
+	return g.StagedDiffFn(ctx, repoPath)
+}
+
+func (g *GitRunner) WorktreeDiff(ctx context.Context, repoPath, rev string) (string, error) {
+	return g.WorktreeDiffFn(ctx, repoPath, rev)
+}
+
+func (g *GitRunner) ApplyPatch(ctx context.Context, repoPath, patch string, opts diffview.ApplyOptions) error {
+	return g.ApplyPatchFn(ctx, repoPath, patch, opts)
+}
@@ -18,3 +18,15 @@ type Parser struct {
 func (p *Parser) Parse(r io.Reader) (*diffview.Diff, error) {
 	return p.ParseFn(r)
 }
+
+// Compile-time interface verification.
+var _ diffview.SeriesParser = (*SeriesParser)(nil)
+
+// SeriesParser is a mock implementation of diffview.SeriesParser.
+type SeriesParser struct {
+	ParseSeriesFn func(r io.Reader) ([]diffview.CommitBrief, error)
+}
+
+func (p *SeriesParser) ParseSeries(r io.Reader) ([]diffview.CommitBrief, error) {
+	return p.ParseSeriesFn(r)
+}
@@ -0,0 +1,19 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/fwojciec/diffstory"
+)
+
+// Compile-time interface verification.
+var _ diffview.HunkApplier = (*HunkApplier)(nil)
+
+// HunkApplier is a mock implementation of diffview.HunkApplier.
+type HunkApplier struct {
+	ApplyHunksFn func(ctx context.Context, diff *diffview.Diff, refs []diffview.HunkRef, action diffview.HunkAction) error
+}
+
+func (a *HunkApplier) ApplyHunks(ctx context.Context, diff *diffview.Diff, refs []diffview.HunkRef, action diffview.HunkAction) error {
+	return a.ApplyHunksFn(ctx, diff, refs, action)
+}
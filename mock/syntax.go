@@ -0,0 +1,34 @@
+package mock
+
+import (
+	"github.com/fwojciec/diffstory"
+)
+
+// Compile-time interface verification.
+var (
+	_ diffview.Tokenizer        = (*Tokenizer)(nil)
+	_ diffview.LanguageDetector = (*LanguageDetector)(nil)
+)
+
+// Tokenizer is a mock implementation of diffview.Tokenizer.
+type Tokenizer struct {
+	TokenizeFn      func(language, source string) []diffview.Token
+	TokenizeLinesFn func(language, source string) [][]diffview.Token
+}
+
+func (t *Tokenizer) Tokenize(language, source string) []diffview.Token {
+	return t.TokenizeFn(language, source)
+}
+
+func (t *Tokenizer) TokenizeLines(language, source string) [][]diffview.Token {
+	return t.TokenizeLinesFn(language, source)
+}
+
+// LanguageDetector is a mock implementation of diffview.LanguageDetector.
+type LanguageDetector struct {
+	DetectFromPathFn func(path string) string
+}
+
+func (d *LanguageDetector) DetectFromPath(path string) string {
+	return d.DetectFromPathFn(path)
+}
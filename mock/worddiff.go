@@ -0,0 +1,25 @@
+package mock
+
+import (
+	"github.com/fwojciec/diffstory"
+)
+
+// Compile-time interface verification.
+var (
+	_ diffview.WordDiffer           = (*WordDiffer)(nil)
+	_ diffview.WhitespaceWordDiffer = (*WordDiffer)(nil)
+)
+
+// WordDiffer is a mock implementation of diffview.WordDiffer.
+type WordDiffer struct {
+	DiffFn                   func(old, new string) (oldSegs, newSegs []diffview.Segment)
+	DiffIgnoringWhitespaceFn func(old, new string) (oldSegs, newSegs []diffview.Segment)
+}
+
+func (d *WordDiffer) Diff(old, new string) (oldSegs, newSegs []diffview.Segment) {
+	return d.DiffFn(old, new)
+}
+
+func (d *WordDiffer) DiffIgnoringWhitespace(old, new string) (oldSegs, newSegs []diffview.Segment) {
+	return d.DiffIgnoringWhitespaceFn(old, new)
+}
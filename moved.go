@@ -0,0 +1,199 @@
+package diffview
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultMinMovedLines is the smallest block, counted in non-blank lines,
+// that DetectMovedBlocks reports as moved. Shorter matches are usually
+// coincidental: closing braces, blank lines and common one-liners.
+const DefaultMinMovedLines = 3
+
+// MovedBlock is a run of lines deleted in one place and added unchanged
+// elsewhere in the diff, like git diff --color-moved.
+type MovedBlock struct {
+	From  MovedLocation // First deleted line of the block
+	To    MovedLocation // First added line of the block
+	Lines int           // Number of lines in the block
+}
+
+// MovedLocation identifies the first line of one side of a moved block.
+type MovedLocation struct {
+	File      string // Path as used in HunkRef
+	HunkIndex int    // Index of the hunk within the file
+	LineIndex int    // Index of the line within Hunk.Lines
+	Line      int    // Old line number for From, new line number for To
+}
+
+// DetectMovedBlocks finds blocks of added lines that match a contiguous run
+// of deleted lines, across hunks and files. Lines are compared with
+// whitespace runs collapsed, so re-indented code still counts as moved.
+// Each deleted line belongs to at most one block, and only blocks of at
+// least minLines non-blank lines are reported. Blocks are found from lines
+// with a letter or digit; blank and punctuation-only lines only extend them.
+//
+// Matches within a single hunk are ignored: a deletion followed by the same
+// lines in one hunk is re-indented code rather than a move.
+func DetectMovedBlocks(diff *Diff, minLines int) []MovedBlock {
+	if diff == nil {
+		return nil
+	}
+
+	deleted := changedLines(diff, LineDeleted)
+	added := changedLines(diff, LineAdded)
+
+	// Index deleted lines by normalized content. Blank and punctuation-only
+	// lines can't start a block, so they aren't indexed.
+	index := make(map[string][]int)
+	for i, line := range deleted {
+		if isMovedSeed(line.text) {
+			index[line.text] = append(index[line.text], i)
+		}
+	}
+
+	used := make([]bool, len(deleted))
+	var blocks []MovedBlock
+	claimed := 0 // Added lines before this index belong to a block
+	for i := 0; i < len(added); {
+		// Pick the longest run of unused deleted lines matching from here
+		bestStart, bestLen := -1, 0
+		for _, d := range movedCandidates(index, added[i].text, used) {
+			if used[d] || sameHunk(deleted[d].loc, added[i].loc) {
+				continue
+			}
+			if n := matchLength(deleted, added, used, d, i); n > bestLen {
+				bestStart, bestLen = d, n
+			}
+		}
+		if bestStart < 0 {
+			i++
+			continue
+		}
+
+		// Lines that can't seed a block, like a closing brace, still join
+		// the block they lead into.
+		start := i
+		for start > claimed && bestStart > 0 && !used[bestStart-1] &&
+			follows(deleted[bestStart-1].loc, deleted[bestStart].loc) &&
+			follows(added[start-1].loc, added[start].loc) &&
+			deleted[bestStart-1].text == added[start-1].text {
+			bestStart--
+			start--
+			bestLen++
+		}
+		if nonBlankLines(added[start:start+bestLen]) < minLines {
+			i++
+			continue
+		}
+
+		for k := range bestLen {
+			used[bestStart+k] = true
+		}
+		blocks = append(blocks, MovedBlock{
+			From:  deleted[bestStart].loc,
+			To:    added[start].loc,
+			Lines: bestLen,
+		})
+		i = start + bestLen
+		claimed = i
+	}
+	return blocks
+}
+
+// maxMovedCandidates caps the deleted lines examined for each added line,
+// so diffs with many copies of one line stay linear.
+const maxMovedCandidates = 32
+
+// movedCandidates returns up to maxMovedCandidates deleted lines indexed
+// under text. Used lines at the front of the list are dropped from the index
+// as blocks claim them, so the usual in-order moves don't crowd out the
+// cap.
+func movedCandidates(index map[string][]int, text string, used []bool) []int {
+	candidates := index[text]
+	for len(candidates) > 0 && used[candidates[0]] {
+		candidates = candidates[1:]
+	}
+	index[text] = candidates
+	return candidates[:min(len(candidates), maxMovedCandidates)]
+}
+
+// isMovedSeed reports whether a line can start a moved block: it must have
+// a letter or digit, so braces and blank lines don't seed coincidental
+// matches.
+func isMovedSeed(text string) bool {
+	return strings.IndexFunc(text, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) >= 0
+}
+
+// changedLine is an added or deleted line with its normalized content.
+type changedLine struct {
+	loc  MovedLocation
+	text string
+}
+
+// changedLines returns all lines of type lt in diff order.
+func changedLines(diff *Diff, lt LineType) []changedLine {
+	var lines []changedLine
+	for _, file := range diff.Files {
+		path := file.Path()
+		for h, hunk := range file.Hunks {
+			for i, line := range hunk.Lines {
+				if line.Type != lt {
+					continue
+				}
+				num := line.NewLineNum
+				if lt == LineDeleted {
+					num = line.OldLineNum
+				}
+				lines = append(lines, changedLine{
+					loc:  MovedLocation{File: path, HunkIndex: h, LineIndex: i, Line: num},
+					text: normalizeMovedLine(line.Content),
+				})
+			}
+		}
+	}
+	return lines
+}
+
+// matchLength returns how many lines match starting at deleted[d] and
+// added[a]. A block only continues through lines that directly follow each
+// other in their hunk.
+func matchLength(deleted, added []changedLine, used []bool, d, a int) int {
+	n := 1
+	for d+n < len(deleted) && a+n < len(added) &&
+		!used[d+n] &&
+		follows(deleted[d+n-1].loc, deleted[d+n].loc) &&
+		follows(added[a+n-1].loc, added[a+n].loc) &&
+		deleted[d+n].text == added[a+n].text {
+		n++
+	}
+	return n
+}
+
+// follows reports whether b is the line directly after a in the same hunk.
+func follows(a, b MovedLocation) bool {
+	return sameHunk(a, b) && b.LineIndex == a.LineIndex+1
+}
+
+// sameHunk reports whether a and b are in the same hunk.
+func sameHunk(a, b MovedLocation) bool {
+	return a.File == b.File && a.HunkIndex == b.HunkIndex
+}
+
+// nonBlankLines counts the lines with content.
+func nonBlankLines(lines []changedLine) int {
+	n := 0
+	for _, line := range lines {
+		if line.text != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// normalizeMovedLine trims the line and collapses inner whitespace runs.
+func normalizeMovedLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,199 @@
+package diffview_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/stretchr/testify/assert"
+)
+
+// movedFixture builds a diff moving parseConfig from config.go to parse.go.
+func movedFixture(addedIndent string) *diffview.Diff {
+	body := []string{
+		"func parseConfig(path string) (*Config, error) {",
+		"\tdata, err := os.ReadFile(path)",
+		"\tif err != nil {",
+		"\t\treturn nil, err",
+		"\t}",
+		"\treturn decode(data)",
+		"}",
+	}
+	var deleted, added []diffview.Line
+	for i, content := range body {
+		deleted = append(deleted, diffview.Line{Type: diffview.LineDeleted, Content: content + "\n", OldLineNum: 40 + i})
+		added = append(added, diffview.Line{Type: diffview.LineAdded, Content: addedIndent + content + "\n", NewLineNum: 10 + i})
+	}
+	return &diffview.Diff{Files: []diffview.FileDiff{
+		{
+			OldPath: "config.go", NewPath: "config.go",
+			Hunks: []diffview.Hunk{
+				{Lines: []diffview.Line{{Type: diffview.LineAdded, Content: "// unrelated\n", NewLineNum: 3}}},
+				{Lines: append([]diffview.Line{{Type: diffview.LineContext, Content: "\n", OldLineNum: 39, NewLineNum: 39}}, deleted...)},
+			},
+		},
+		{
+			NewPath: "parse.go", Operation: diffview.FileAdded,
+			Hunks: []diffview.Hunk{{Lines: added}},
+		},
+	}}
+}
+
+func TestDetectMovedBlocks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("finds block moved across files", func(t *testing.T) {
+		t.Parallel()
+
+		blocks := diffview.DetectMovedBlocks(movedFixture(""), diffview.DefaultMinMovedLines)
+
+		assert.Equal(t, []diffview.MovedBlock{{
+			From:  diffview.MovedLocation{File: "config.go", HunkIndex: 1, LineIndex: 1, Line: 40},
+			To:    diffview.MovedLocation{File: "parse.go", HunkIndex: 0, LineIndex: 0, Line: 10},
+			Lines: 7,
+		}}, blocks)
+	})
+
+	t.Run("ignores indentation changes", func(t *testing.T) {
+		t.Parallel()
+
+		blocks := diffview.DetectMovedBlocks(movedFixture("\t"), diffview.DefaultMinMovedLines)
+
+		assert.Len(t, blocks, 1)
+		assert.Equal(t, 7, blocks[0].Lines)
+	})
+
+	t.Run("skips blocks shorter than the minimum", func(t *testing.T) {
+		t.Parallel()
+
+		blocks := diffview.DetectMovedBlocks(movedFixture(""), 8)
+
+		assert.Empty(t, blocks)
+	})
+
+	t.Run("splits block where the moved code changed", func(t *testing.T) {
+		t.Parallel()
+
+		diff := movedFixture("")
+		diff.Files[1].Hunks[0].Lines[3].Content = "\t\treturn nil, fmt.Errorf(\"read config: %w\", err)\n"
+
+		blocks := diffview.DetectMovedBlocks(diff, diffview.DefaultMinMovedLines)
+
+		// Lines 0-2 and 4-6 each match on their own
+		assert.Len(t, blocks, 2)
+		assert.Equal(t, 3, blocks[0].Lines)
+		assert.Equal(t, 3, blocks[1].Lines)
+		assert.Equal(t, 44, blocks[1].From.Line)
+	})
+
+	t.Run("ignores re-indented code within one hunk", func(t *testing.T) {
+		t.Parallel()
+
+		moved := movedFixture("\t")
+		lines := append(moved.Files[1].Hunks[0].Lines, moved.Files[0].Hunks[1].Lines[1:]...)
+		diff := &diffview.Diff{Files: []diffview.FileDiff{{NewPath: "a.go", Hunks: []diffview.Hunk{{Lines: lines}}}}}
+
+		assert.Empty(t, diffview.DetectMovedBlocks(diff, diffview.DefaultMinMovedLines))
+	})
+
+	t.Run("each deleted line moves once", func(t *testing.T) {
+		t.Parallel()
+
+		diff := movedFixture("")
+		copied := diff.Files[1]
+		copied.NewPath = "copy.go"
+		diff.Files = append(diff.Files, copied)
+
+		blocks := diffview.DetectMovedBlocks(diff, diffview.DefaultMinMovedLines)
+
+		assert.Len(t, blocks, 1)
+		assert.Equal(t, "parse.go", blocks[0].To.File)
+	})
+
+	t.Run("block may start with a punctuation-only line", func(t *testing.T) {
+		t.Parallel()
+
+		diff := movedFixture("")
+		for _, hunk := range []*diffview.Hunk{&diff.Files[0].Hunks[1], &diff.Files[1].Hunks[0]} {
+			hunk.Lines[len(hunk.Lines)-7].Content = "{\n"
+		}
+
+		blocks := diffview.DetectMovedBlocks(diff, diffview.DefaultMinMovedLines)
+
+		assert.Len(t, blocks, 1)
+		assert.Equal(t, 7, blocks[0].Lines)
+		assert.Equal(t, 10, blocks[0].To.Line)
+	})
+
+	t.Run("punctuation-only lines don't form blocks", func(t *testing.T) {
+		t.Parallel()
+
+		diff := movedFixture("")
+		for _, hunk := range []*diffview.Hunk{&diff.Files[0].Hunks[1], &diff.Files[1].Hunks[0]} {
+			for i := range hunk.Lines {
+				if hunk.Lines[i].Type != diffview.LineContext {
+					hunk.Lines[i].Content = "}\n"
+				}
+			}
+		}
+
+		assert.Empty(t, diffview.DetectMovedBlocks(diff, diffview.DefaultMinMovedLines))
+	})
+
+	t.Run("nil diff", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Empty(t, diffview.DetectMovedBlocks(nil, diffview.DefaultMinMovedLines))
+	})
+}
+
+func BenchmarkDetectMovedBlocks(b *testing.B) {
+	// Common lines repeated across files used to compare every added line
+	// with every deleted copy.
+	repeated := func(content string, n int) *diffview.Diff {
+		var deleted, added []diffview.Line
+		for i := range n {
+			deleted = append(deleted, diffview.Line{Type: diffview.LineDeleted, Content: content, OldLineNum: i + 1})
+			added = append(added, diffview.Line{Type: diffview.LineAdded, Content: content, NewLineNum: i + 1})
+		}
+		return &diffview.Diff{Files: []diffview.FileDiff{
+			{OldPath: "a.go", Operation: diffview.FileDeleted, Hunks: []diffview.Hunk{{Lines: deleted}}},
+			{NewPath: "b.go", Operation: diffview.FileAdded, Hunks: []diffview.Hunk{{Lines: added}}},
+		}}
+	}
+	// Every fourth line is unique and the added side is reversed, so runs
+	// of the common line keep matching many candidates that go nowhere.
+	interleaved := func(n int) *diffview.Diff {
+		var deleted, added []diffview.Line
+		for i := range n {
+			content := "return nil\n"
+			if i%4 == 0 {
+				content = fmt.Sprintf("x%d := f()\n", i)
+			}
+			deleted = append(deleted, diffview.Line{Type: diffview.LineDeleted, Content: content, OldLineNum: i + 1})
+			added = append(added, diffview.Line{Type: diffview.LineAdded, Content: content, NewLineNum: n - i})
+		}
+		slices.Reverse(added)
+		return &diffview.Diff{Files: []diffview.FileDiff{
+			{OldPath: "a.go", Operation: diffview.FileDeleted, Hunks: []diffview.Hunk{{Lines: deleted}}},
+			{NewPath: "b.go", Operation: diffview.FileAdded, Hunks: []diffview.Hunk{{Lines: added}}},
+		}}
+	}
+
+	benchmarks := []struct {
+		name string
+		diff *diffview.Diff
+	}{
+		{name: "braces_20000", diff: repeated("}\n", 20000)},
+		{name: "return_nil_20000", diff: repeated("return nil\n", 20000)},
+		{name: "interleaved_20000", diff: interleaved(20000)},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			for b.Loop() {
+				diffview.DetectMovedBlocks(bm.diff, diffview.DefaultMinMovedLines)
+			}
+		})
+	}
+}
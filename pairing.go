@@ -0,0 +1,160 @@
+package diffview
+
+import "strings"
+
+// minUnchangedShare is the least share of a paired line's text that must be
+// unchanged for word-level highlights to be shown; below it they are more
+// noise than signal.
+const minUnchangedShare = 0.30
+
+// PairSegments pairs the deleted and added lines of each change run of lines
+// by similarity (see AlignLines) and returns their word-level segments by
+// line index. Pairs that share too little content get no segments, and
+// neither do lines left unpaired.
+func PairSegments(lines []Line, wordDiffer WordDiffer) map[int][]Segment {
+	if wordDiffer == nil {
+		return nil
+	}
+
+	result := make(map[int][]Segment)
+	for i := 0; i < len(lines); i++ {
+		if lines[i].Type != LineDeleted {
+			continue
+		}
+
+		deleteStart, deleteEnd := i, i
+		for deleteEnd < len(lines) && lines[deleteEnd].Type == LineDeleted {
+			deleteEnd++
+		}
+		addStart, addEnd := deleteEnd, deleteEnd
+		for addEnd < len(lines) && lines[addEnd].Type == LineAdded {
+			addEnd++
+		}
+
+		for _, pair := range AlignLines(lines[deleteStart:deleteEnd], lines[addStart:addEnd], wordDiffer) {
+			delIdx, addIdx := deleteStart+pair.Deleted, addStart+pair.Added
+			oldSegs, newSegs := wordDiffer.Diff(
+				strings.TrimSuffix(lines[delIdx].Content, "\n"),
+				strings.TrimSuffix(lines[addIdx].Content, "\n"),
+			)
+			if mostlyUnchanged(oldSegs) && mostlyUnchanged(newSegs) {
+				result[delIdx] = oldSegs
+				result[addIdx] = newSegs
+			}
+		}
+
+		i = addEnd - 1
+	}
+	return result
+}
+
+// mostlyUnchanged reports whether at least minUnchangedShare of the segment
+// text is unchanged.
+func mostlyUnchanged(segments []Segment) bool {
+	unchanged, total := unchangedLength(segments)
+	return total > 0 && float64(unchanged)/float64(total) >= minUnchangedShare
+}
+
+// minPairSimilarity is the least similarity for a deleted and an added line
+// to be paired for word-level highlighting. It matches the threshold the
+// word differ uses before falling back to a whole-line replacement.
+const minPairSimilarity = 0.4
+
+// maxAlignCells caps the number of deleted×added line comparisons made for
+// one change run. Larger runs are paired positionally.
+const maxAlignCells = 128 * 128
+
+// LinePair is a deleted line and the added line it became, as indices into
+// the deleted and added runs.
+type LinePair struct {
+	Deleted, Added int
+}
+
+// AlignLines pairs the deleted and added lines of one change run. Pairs keep
+// their order and maximize the summed similarity, so a line inserted in the
+// middle of a block doesn't shift every later pairing. Lines too dissimilar
+// to anything stay unpaired and render as plain deletions and additions.
+// Runs with more than 128×128 deleted×added line pairs are paired by
+// position instead, without comparing lines.
+func AlignLines(deleted, added []Line, wordDiffer WordDiffer) []LinePair {
+	m, n := len(deleted), len(added)
+	// A single pair needs no alignment; PairSegments checks its word diff.
+	if m*n == 1 || m*n > maxAlignCells {
+		return positionalPairs(m, n)
+	}
+
+	sim := make([]float64, m*n)
+	for i, d := range deleted {
+		oldContent := strings.TrimSuffix(d.Content, "\n")
+		for j, a := range added {
+			sim[i*n+j] = LineSimilarity(wordDiffer, oldContent, strings.TrimSuffix(a.Content, "\n"))
+		}
+	}
+
+	// best[i][j] is the highest total similarity aligning deleted[i:] with
+	// added[j:]; take[i][j] records whether that pairs deleted[i] with added[j].
+	stride := n + 1
+	best := make([]float64, (m+1)*stride)
+	take := make([]bool, (m+1)*stride)
+	for i := m - 1; i >= 0; i-- {
+		for j := n - 1; j >= 0; j-- {
+			v := max(best[(i+1)*stride+j], best[i*stride+j+1])
+			if s := sim[i*n+j]; s >= minPairSimilarity && s+best[(i+1)*stride+j+1] > v {
+				v = s + best[(i+1)*stride+j+1]
+				take[i*stride+j] = true
+			}
+			best[i*stride+j] = v
+		}
+	}
+
+	var pairs []LinePair
+	for i, j := 0, 0; i < m && j < n; {
+		switch {
+		case take[i*stride+j]:
+			pairs = append(pairs, LinePair{Deleted: i, Added: j})
+			i++
+			j++
+		case best[(i+1)*stride+j] >= best[i*stride+j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
+
+// positionalPairs pairs the i-th deleted line with the i-th added line.
+func positionalPairs(m, n int) []LinePair {
+	pairs := make([]LinePair, min(m, n))
+	for i := range pairs {
+		pairs[i] = LinePair{Deleted: i, Added: i}
+	}
+	return pairs
+}
+
+// LineSimilarity scores how alike two lines are, from 0 to 1. Differs that
+// implement SimilarityScorer score it themselves; otherwise the
+// score is the share of unchanged text in the word diff.
+func LineSimilarity(wordDiffer WordDiffer, old, new string) float64 {
+	if s, ok := wordDiffer.(SimilarityScorer); ok {
+		return s.Similarity(old, new)
+	}
+	oldSegs, newSegs := wordDiffer.Diff(old, new)
+	oldUnchanged, oldTotal := unchangedLength(oldSegs)
+	newUnchanged, newTotal := unchangedLength(newSegs)
+	if oldTotal+newTotal == 0 {
+		return 1
+	}
+	return float64(oldUnchanged+newUnchanged) / float64(oldTotal+newTotal)
+}
+
+// unchangedLength returns the unchanged and total text length of segments.
+func unchangedLength(segments []Segment) (unchanged, total int) {
+	for _, seg := range segments {
+		total += len(seg.Text)
+		if !seg.Changed {
+			unchanged += len(seg.Text)
+		}
+	}
+	return unchanged, total
+}
@@ -0,0 +1,73 @@
+package diffview_test
+
+import (
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/worddiff"
+	"github.com/stretchr/testify/assert"
+)
+
+// changeRun returns a change run deleting deleted and adding added.
+func changeRun(deleted, added []string) []diffview.Line {
+	lines := make([]diffview.Line, 0, len(deleted)+len(added))
+	for _, content := range deleted {
+		lines = append(lines, diffview.Line{Type: diffview.LineDeleted, Content: content + "\n"})
+	}
+	for _, content := range added {
+		lines = append(lines, diffview.Line{Type: diffview.LineAdded, Content: content + "\n"})
+	}
+	return lines
+}
+
+func TestAlignLines(t *testing.T) {
+	t.Parallel()
+
+	t.Run("pairs by similarity around inserted lines", func(t *testing.T) {
+		t.Parallel()
+		lines := changeRun(
+			[]string{"total := sum(values)", "return total, nil"},
+			[]string{"total := sum(values, weights)", "if total < 0 {", "\treturn 0, errNegative", "}", "return total, err"},
+		)
+
+		pairs := diffview.AlignLines(lines[:2], lines[2:], worddiff.NewDiffer())
+
+		assert.Equal(t, []diffview.LinePair{{Deleted: 0, Added: 0}, {Deleted: 1, Added: 4}}, pairs)
+	})
+
+	t.Run("leaves dissimilar lines unpaired", func(t *testing.T) {
+		t.Parallel()
+		lines := changeRun(
+			[]string{"x := compute(a, b)", "// TODO: remove"},
+			[]string{"x := compute(a, b, c)", "defer cleanup()"},
+		)
+
+		pairs := diffview.AlignLines(lines[:2], lines[2:], worddiff.NewDiffer())
+
+		assert.Equal(t, []diffview.LinePair{{Deleted: 0, Added: 0}}, pairs)
+	})
+}
+
+func TestPairSegments(t *testing.T) {
+	t.Parallel()
+
+	t.Run("segments paired lines only", func(t *testing.T) {
+		t.Parallel()
+		lines := append([]diffview.Line{{Type: diffview.LineContext, Content: "{\n"}}, changeRun(
+			[]string{"x := compute(a, b)", "// TODO: remove"},
+			[]string{"x := compute(a, b, c)", "defer cleanup()"},
+		)...)
+
+		segments := diffview.PairSegments(lines, worddiff.NewDiffer())
+
+		assert.Len(t, segments, 2)
+		assert.NotEmpty(t, segments[1])
+		assert.NotEmpty(t, segments[3])
+	})
+
+	t.Run("no word differ", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Nil(t, diffview.PairSegments(changeRun([]string{"a"}, []string{"b"}), nil))
+	})
+}
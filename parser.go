@@ -1,9 +1,121 @@
 package diffview
 
-import "io"
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
 
 // Parser parses diff content into domain types.
 type Parser interface {
 	// Parse reads diff content and returns the parsed result.
 	Parse(r io.Reader) (*Diff, error)
 }
+
+// SeriesParser parses a patch series, such as git format-patch mbox output,
+// into one commit per patch with its message, author and diff.
+type SeriesParser interface {
+	ParseSeries(r io.Reader) ([]CommitBrief, error)
+}
+
+// DiffFormat is the format of diff input.
+type DiffFormat int
+
+// Diff formats.
+const (
+	FormatUnified DiffFormat = iota // Plain unified diff: diff -u, hg diff, svn diff
+	FormatGit                       // git diff output, with "diff --git" headers
+	FormatMbox                      // git format-patch mail, one patch per message
+)
+
+// DetectFormat guesses the format of diff input. Mail headers at the start
+// mean an mbox patch series; otherwise any "diff --git" or "diff --cc"
+// header means git output, and anything else is a plain unified diff.
+func DetectFormat(input []byte) DiffFormat {
+	scanner := bufio.NewScanner(bytes.NewReader(input))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(input)+1)
+	first := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if first && line != "" {
+			first = false
+			if isMailStart(line) {
+				return FormatMbox
+			}
+		}
+		if strings.HasPrefix(line, "diff --git ") || strings.HasPrefix(line, "diff --cc ") || strings.HasPrefix(line, "diff --combined ") {
+			return FormatGit
+		}
+	}
+	return FormatUnified
+}
+
+// isMailStart reports whether line opens a mail message: an mbox "From "
+// separator or a mail header.
+func isMailStart(line string) bool {
+	for _, prefix := range []string{"From ", "From: ", "Subject: ", "Date: "} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Compile-time interface verification.
+var (
+	_ Parser       = (*AutoParser)(nil)
+	_ SeriesParser = (*AutoParser)(nil)
+)
+
+// AutoParser parses diff input in any supported format, choosing a parser
+// with DetectFormat.
+type AutoParser struct {
+	git     Parser
+	unified Parser
+	series  SeriesParser
+}
+
+// NewAutoParser creates an AutoParser that reads git output with git,
+// plain unified diffs with unified and mbox patch series with series.
+func NewAutoParser(git, unified Parser, series SeriesParser) *AutoParser {
+	return &AutoParser{git: git, unified: unified, series: series}
+}
+
+// Parse reads diff content in any supported format. A patch series is
+// returned as the SeriesDiff of its patches.
+func (p *AutoParser) Parse(r io.Reader) (*Diff, error) {
+	input, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	switch DetectFormat(input) {
+	case FormatMbox:
+		commits, err := p.series.ParseSeries(bytes.NewReader(input))
+		if err != nil {
+			return nil, err
+		}
+		return SeriesDiff(commits), nil
+	case FormatGit:
+		return p.git.Parse(bytes.NewReader(input))
+	default:
+		return p.unified.Parse(bytes.NewReader(input))
+	}
+}
+
+// ParseSeries reads a patch series. Other input is returned as a single
+// commit without a hash or message.
+func (p *AutoParser) ParseSeries(r io.Reader) ([]CommitBrief, error) {
+	input, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if DetectFormat(input) == FormatMbox {
+		return p.series.ParseSeries(bytes.NewReader(input))
+	}
+	diff, err := p.Parse(bytes.NewReader(input))
+	if err != nil {
+		return nil, err
+	}
+	return []CommitBrief{{Diff: diff}}, nil
+}
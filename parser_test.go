@@ -0,0 +1,87 @@
+package diffview_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  diffview.DiffFormat
+	}{
+		{"git diff", "diff --git a/x b/x\n--- a/x\n+++ b/x\n", diffview.FormatGit},
+		{"combined diff", "diff --cc x\n", diffview.FormatGit},
+		{"diff -u", "--- x.orig\n+++ x\n@@ -1 +1 @@\n", diffview.FormatUnified},
+		{"svn diff", "Index: x\n=====\n--- x\t(revision 1)\n", diffview.FormatUnified},
+		{"format-patch", "From 1a2b Mon Sep 17 00:00:00 2001\nFrom: a <a@b>\n\ndiff --git a/x b/x\n", diffview.FormatMbox},
+		{"mail without separator", "\nFrom: a <a@b>\nSubject: [PATCH] x\n", diffview.FormatMbox},
+		{"empty", "", diffview.FormatUnified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, diffview.DetectFormat([]byte(tt.input)))
+		})
+	}
+}
+
+func TestAutoParser(t *testing.T) {
+	t.Parallel()
+
+	gitDiff := &diffview.Diff{Files: []diffview.FileDiff{{NewPath: "git.go"}}}
+	unifiedDiff := &diffview.Diff{Files: []diffview.FileDiff{{NewPath: "unified.go"}}}
+	commits := []diffview.CommitBrief{
+		{Hash: "1", Message: "First", Diff: &diffview.Diff{Files: []diffview.FileDiff{{NewPath: "a.go"}}}},
+		{Hash: "2", Message: "Second", Diff: &diffview.Diff{Files: []diffview.FileDiff{{NewPath: "b.go"}}}},
+	}
+	newParser := func() *diffview.AutoParser {
+		return diffview.NewAutoParser(
+			&mock.Parser{ParseFn: func(io.Reader) (*diffview.Diff, error) { return gitDiff, nil }},
+			&mock.Parser{ParseFn: func(io.Reader) (*diffview.Diff, error) { return unifiedDiff, nil }},
+			&mock.SeriesParser{ParseSeriesFn: func(io.Reader) ([]diffview.CommitBrief, error) { return commits, nil }},
+		)
+	}
+	mbox := "From 1 Mon Sep 17 00:00:00 2001\nSubject: [PATCH] x\n"
+
+	t.Run("parses each format with its parser", func(t *testing.T) {
+		t.Parallel()
+
+		diff, err := newParser().Parse(strings.NewReader("diff --git a/x b/x\n"))
+		require.NoError(t, err)
+		assert.Equal(t, gitDiff, diff)
+
+		diff, err = newParser().Parse(strings.NewReader("--- x\n+++ x\n"))
+		require.NoError(t, err)
+		assert.Equal(t, unifiedDiff, diff)
+
+		diff, err = newParser().Parse(strings.NewReader(mbox))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a.go", "b.go"}, []string{diff.Files[0].Path(), diff.Files[1].Path()})
+	})
+
+	t.Run("parses a series into commits", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := newParser().ParseSeries(strings.NewReader(mbox))
+		require.NoError(t, err)
+		assert.Equal(t, commits, got)
+	})
+
+	t.Run("parses a plain diff as a single commit", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := newParser().ParseSeries(strings.NewReader("--- x\n+++ x\n"))
+		require.NoError(t, err)
+		assert.Equal(t, []diffview.CommitBrief{{Diff: unifiedDiff}}, got)
+	})
+}
@@ -0,0 +1,185 @@
+package diffview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// ErrNoHunks is returned when there are no hunks to apply.
+var ErrNoHunks = errors.New("no hunks to apply")
+
+// HunkAction is a change made to a repository from hunks of its diff, like
+// the choices of git add -p.
+type HunkAction int
+
+// Hunk actions.
+const (
+	HunkStage   HunkAction = iota // Add the hunks to the index
+	HunkUnstage                   // Remove the hunks from the index
+	HunkDiscard                   // Revert the hunks in the working tree
+)
+
+// String returns the past tense of the action, as shown next to hunks it
+// was applied to.
+func (a HunkAction) String() string {
+	switch a {
+	case HunkStage:
+		return "staged"
+	case HunkUnstage:
+		return "unstaged"
+	case HunkDiscard:
+		return "discarded"
+	default:
+		return fmt.Sprintf("HunkAction(%d)", int(a))
+	}
+}
+
+// ApplyOptions selects where and in which direction git apply applies a
+// patch.
+type ApplyOptions struct {
+	Cached  bool // Apply to the index instead of the working tree (--cached)
+	Reverse bool // Undo the patch instead of applying it (--reverse)
+}
+
+// HunkApplier applies hunks of a diff of uncommitted changes to the
+// repository the diff was taken from.
+type HunkApplier interface {
+	ApplyHunks(ctx context.Context, diff *Diff, refs []HunkRef, action HunkAction) error
+}
+
+// Compile-time interface verification.
+var _ HunkApplier = (*GitHunkApplier)(nil)
+
+// GitHunkApplier applies hunks by generating a patch of them and passing it
+// to git apply.
+type GitHunkApplier struct {
+	runner   GitRunner
+	repoPath string
+}
+
+// NewGitHunkApplier creates a GitHunkApplier for the repository at repoPath.
+func NewGitHunkApplier(runner GitRunner, repoPath string) *GitHunkApplier {
+	return &GitHunkApplier{runner: runner, repoPath: repoPath}
+}
+
+// ApplyHunks stages, unstages or discards the hunks of diff named by refs.
+// Returns ErrNoHunks when none of them can be applied.
+func (a *GitHunkApplier) ApplyHunks(ctx context.Context, diff *Diff, refs []HunkRef, action HunkAction) error {
+	patch := Patch(diff, refs)
+	if patch == "" {
+		return ErrNoHunks
+	}
+	var opts ApplyOptions
+	switch action {
+	case HunkStage:
+		opts = ApplyOptions{Cached: true}
+	case HunkUnstage:
+		opts = ApplyOptions{Cached: true, Reverse: true}
+	case HunkDiscard:
+		opts = ApplyOptions{Reverse: true}
+	default:
+		return fmt.Errorf("unknown hunk action %d", int(action))
+	}
+	return a.runner.ApplyPatch(ctx, a.repoPath, patch, opts)
+}
+
+// Patch returns a patch with only the hunks of diff named by refs, in diff
+// order, that git apply accepts. New start lines are recomputed for the
+// hunks left out, so any subset of a file's hunks applies cleanly. Binary
+// files and combined hunks can't be applied and are skipped. Returns "" if
+// no hunk is selected.
+func Patch(diff *Diff, refs []HunkRef) string {
+	if diff == nil {
+		return ""
+	}
+	selected := make(map[string]map[int]bool)
+	for _, ref := range refs {
+		if selected[ref.File] == nil {
+			selected[ref.File] = make(map[int]bool)
+		}
+		selected[ref.File][ref.HunkIndex] = true
+	}
+
+	var sb strings.Builder
+	for _, file := range diff.Files {
+		hunks := selected[file.Path()]
+		if len(hunks) == 0 || file.IsBinary {
+			continue
+		}
+		var body strings.Builder
+		skipped := 0 // lines added minus lines removed by hunks left out
+		for i, hunk := range file.Hunks {
+			if !hunks[i] || hunk.IsCombined() {
+				skipped += hunk.NewCount - hunk.OldCount
+				continue
+			}
+			writeHunk(&body, hunk, hunk.NewStart-skipped)
+		}
+		if body.Len() == 0 {
+			continue
+		}
+		writeFileHeader(&sb, file)
+		sb.WriteString(body.String())
+	}
+	return sb.String()
+}
+
+// writeFileHeader writes the git header of file, with the mode lines git
+// apply needs to create, delete or rename it.
+func writeFileHeader(sb *strings.Builder, file FileDiff) {
+	oldPath, newPath := file.OldPath, file.NewPath
+	if oldPath == "" {
+		oldPath = newPath
+	}
+	if newPath == "" {
+		newPath = oldPath
+	}
+	fmt.Fprintf(sb, "diff --git a/%s b/%s\n", oldPath, newPath)
+
+	from, to := "a/"+oldPath, "b/"+newPath
+	switch file.Operation {
+	case FileAdded:
+		fmt.Fprintf(sb, "new file mode %s\n", patchMode(file.NewMode))
+		from = "/dev/null"
+	case FileDeleted:
+		fmt.Fprintf(sb, "deleted file mode %s\n", patchMode(file.OldMode))
+		to = "/dev/null"
+	case FileRenamed:
+		fmt.Fprintf(sb, "rename from %s\nrename to %s\n", oldPath, newPath)
+	case FileCopied:
+		fmt.Fprintf(sb, "copy from %s\ncopy to %s\n", oldPath, newPath)
+	}
+	fmt.Fprintf(sb, "--- %s\n+++ %s\n", from, to)
+}
+
+// patchMode formats a file mode for a patch header, defaulting to a regular
+// file when the mode is unknown.
+func patchMode(mode fs.FileMode) string {
+	if mode == 0 {
+		return "100644"
+	}
+	return fmt.Sprintf("%o", uint32(mode))
+}
+
+// writeHunk writes hunk with its new side starting at newStart.
+func writeHunk(sb *strings.Builder, hunk Hunk, newStart int) {
+	hunk.NewStart = newStart
+	sb.WriteString(hunk.Range())
+	if hunk.Section != "" {
+		sb.WriteString(" " + hunk.Section)
+	}
+	sb.WriteString("\n")
+	for _, line := range hunk.Lines {
+		sb.WriteString(line.Prefix())
+		sb.WriteString(line.Content)
+		if !strings.HasSuffix(line.Content, "\n") {
+			sb.WriteString("\n")
+		}
+		if line.NoNewline {
+			sb.WriteString("\\ No newline at end of file\n")
+		}
+	}
+}
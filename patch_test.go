@@ -0,0 +1,143 @@
+package diffview_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func patchDiff() *diffview.Diff {
+	return &diffview.Diff{Files: []diffview.FileDiff{
+		{
+			OldPath: "main.go", NewPath: "main.go", Operation: diffview.FileModified,
+			Hunks: []diffview.Hunk{
+				{OldStart: 1, OldCount: 1, NewStart: 1, NewCount: 3, Section: "package main", Lines: []diffview.Line{
+					{Type: diffview.LineContext, Content: "package main\n"},
+					{Type: diffview.LineAdded, Content: "\n"},
+					{Type: diffview.LineAdded, Content: "import \"fmt\"\n"},
+				}},
+				{OldStart: 10, OldCount: 2, NewStart: 12, NewCount: 2, Lines: []diffview.Line{
+					{Type: diffview.LineContext, Content: "func main() {\n"},
+					{Type: diffview.LineDeleted, Content: "\tprintln(\"hi\")\n"},
+					{Type: diffview.LineAdded, Content: "\tfmt.Println(\"hi\")", NoNewline: true},
+				}},
+			},
+		},
+		{
+			NewPath: "new.txt", Operation: diffview.FileAdded, NewMode: 0o100755,
+			Hunks: []diffview.Hunk{{OldStart: 0, OldCount: 0, NewStart: 1, NewCount: 1, Lines: []diffview.Line{
+				{Type: diffview.LineAdded, Content: "new\n"},
+			}}},
+		},
+	}}
+}
+
+func TestPatch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("moves later hunks up when earlier ones are left out", func(t *testing.T) {
+		t.Parallel()
+
+		patch := diffview.Patch(patchDiff(), []diffview.HunkRef{{File: "main.go", HunkIndex: 1}})
+
+		assert.Equal(t, "diff --git a/main.go b/main.go\n"+
+			"--- a/main.go\n"+
+			"+++ b/main.go\n"+
+			"@@ -10,2 +10,2 @@\n"+
+			" func main() {\n"+
+			"-\tprintln(\"hi\")\n"+
+			"+\tfmt.Println(\"hi\")\n"+
+			"\\ No newline at end of file\n", patch)
+	})
+
+	t.Run("writes new files with their mode", func(t *testing.T) {
+		t.Parallel()
+
+		patch := diffview.Patch(patchDiff(), []diffview.HunkRef{{File: "new.txt", HunkIndex: 0}})
+
+		assert.Equal(t, "diff --git a/new.txt b/new.txt\n"+
+			"new file mode 100755\n"+
+			"--- /dev/null\n"+
+			"+++ b/new.txt\n"+
+			"@@ -0,0 +1,1 @@\n"+
+			"+new\n", patch)
+	})
+
+	t.Run("keeps diff order", func(t *testing.T) {
+		t.Parallel()
+
+		patch := diffview.Patch(patchDiff(), []diffview.HunkRef{
+			{File: "new.txt", HunkIndex: 0},
+			{File: "main.go", HunkIndex: 1},
+			{File: "main.go", HunkIndex: 0},
+		})
+
+		assert.Regexp(t, `(?s)^diff --git a/main.go.*@@ -1,1 \+1,3 @@ package main.*@@ -10,2 \+12,2 @@.*diff --git a/new.txt`, patch)
+	})
+
+	t.Run("returns nothing without selected hunks", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Empty(t, diffview.Patch(patchDiff(), []diffview.HunkRef{{File: "other.go"}}))
+		assert.Empty(t, diffview.Patch(nil, nil))
+	})
+}
+
+func TestGitHunkApplier_ApplyHunks(t *testing.T) {
+	t.Parallel()
+
+	refs := []diffview.HunkRef{{File: "new.txt", HunkIndex: 0}}
+	tests := []struct {
+		action diffview.HunkAction
+		want   diffview.ApplyOptions
+	}{
+		{diffview.HunkStage, diffview.ApplyOptions{Cached: true}},
+		{diffview.HunkUnstage, diffview.ApplyOptions{Cached: true, Reverse: true}},
+		{diffview.HunkDiscard, diffview.ApplyOptions{Reverse: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.action.String(), func(t *testing.T) {
+			t.Parallel()
+
+			runner := &mock.GitRunner{
+				ApplyPatchFn: func(_ context.Context, repoPath, patch string, opts diffview.ApplyOptions) error {
+					assert.Equal(t, "/repo", repoPath)
+					assert.Contains(t, patch, "+++ b/new.txt")
+					assert.Equal(t, tt.want, opts)
+					return nil
+				},
+			}
+
+			err := diffview.NewGitHunkApplier(runner, "/repo").ApplyHunks(context.Background(), patchDiff(), refs, tt.action)
+
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("no hunks", func(t *testing.T) {
+		t.Parallel()
+
+		err := diffview.NewGitHunkApplier(&mock.GitRunner{}, "/repo").ApplyHunks(context.Background(), patchDiff(), nil, diffview.HunkStage)
+
+		require.ErrorIs(t, err, diffview.ErrNoHunks)
+	})
+
+	t.Run("git error", func(t *testing.T) {
+		t.Parallel()
+
+		runner := &mock.GitRunner{
+			ApplyPatchFn: func(context.Context, string, string, diffview.ApplyOptions) error {
+				return errors.New("patch does not apply")
+			},
+		}
+
+		err := diffview.NewGitHunkApplier(runner, "/repo").ApplyHunks(context.Background(), patchDiff(), refs, diffview.HunkStage)
+
+		require.EqualError(t, err, "patch does not apply")
+	})
+}
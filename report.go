@@ -0,0 +1,165 @@
+package diffview
+
+// ReportSchemaVersion is the version of the Report JSON schema.
+// It changes only when fields are removed, renamed or change meaning;
+// new fields may be added within a version.
+const ReportSchemaVersion = 1
+
+// Report is the machine-readable form of a classified diff.
+// See docs/json-output.md for the documented schema.
+type Report struct {
+	SchemaVersion int             `json:"schema_version"`
+	Repo          string          `json:"repo,omitempty"`
+	Branch        string          `json:"branch,omitempty"`
+	ChangeType    string          `json:"change_type"`
+	Narrative     string          `json:"narrative"`
+	Summary       string          `json:"summary"`
+	Evolution     string          `json:"evolution,omitempty"`
+	Stats         ReportStats     `json:"stats"`
+	Sections      []ReportSection `json:"sections"`
+	Warnings      []ReportWarning `json:"warnings"`
+}
+
+// ReportStats counts files and changed lines.
+type ReportStats struct {
+	Files   int `json:"files"`
+	Added   int `json:"added"`
+	Deleted int `json:"deleted"`
+}
+
+// ReportSection is a story section with its hunks resolved against the diff.
+type ReportSection struct {
+	Role        string       `json:"role"`
+	Title       string       `json:"title"`
+	Explanation string       `json:"explanation"`
+	Stats       ReportStats  `json:"stats"`
+	Hunks       []ReportHunk `json:"hunks"`
+}
+
+// ReportHunk is a hunk reference resolved against the diff.
+// Line ranges and stats are zero when Resolved is false.
+type ReportHunk struct {
+	File         string `json:"file"`
+	OldPath      string `json:"old_path,omitempty"` // Set for renames and copies
+	Operation    string `json:"operation,omitempty"`
+	HunkIndex    int    `json:"hunk_index"`
+	Resolved     bool   `json:"resolved"` // Whether the reference matched a hunk in the diff
+	Category     string `json:"category,omitempty"`
+	Collapsed    bool   `json:"collapsed"`
+	CollapseText string `json:"collapse_text,omitempty"`
+	OldStart     int    `json:"old_start"`
+	OldCount     int    `json:"old_count"`
+	NewStart     int    `json:"new_start"`
+	NewCount     int    `json:"new_count"`
+	Added        int    `json:"added"`
+	Deleted      int    `json:"deleted"`
+}
+
+// ReportWarning describes a problem with the classification.
+type ReportWarning struct {
+	Section   int              `json:"section"`
+	File      string           `json:"file"`
+	HunkIndex int              `json:"hunk_index"`
+	Reason    ValidationReason `json:"reason"`
+	Message   string           `json:"message"`
+}
+
+// NewReport builds a Report for the classification of input.Diff.
+// A nil story yields a report with stats but no sections.
+func NewReport(input ClassificationInput, story *StoryClassification) Report {
+	diff := &input.Diff
+	report := Report{
+		SchemaVersion: ReportSchemaVersion,
+		Repo:          input.Repo,
+		Branch:        input.Branch,
+		Sections:      []ReportSection{},
+		Warnings:      []ReportWarning{},
+	}
+
+	report.Stats.Files = len(diff.Files)
+	for _, file := range diff.Files {
+		added, deleted := file.Stats()
+		report.Stats.Added += added
+		report.Stats.Deleted += deleted
+	}
+
+	if story == nil {
+		return report
+	}
+
+	report.ChangeType = story.ChangeType
+	report.Narrative = story.Narrative
+	report.Summary = story.Summary
+	report.Evolution = story.Evolution
+
+	for _, section := range story.Sections {
+		rs := ReportSection{
+			Role:        section.Role,
+			Title:       section.Title,
+			Explanation: section.Explanation,
+			Hunks:       make([]ReportHunk, 0, len(section.Hunks)),
+		}
+		files := make(map[string]bool)
+		for _, ref := range section.Hunks {
+			hunk := resolveHunk(diff, ref)
+			rs.Hunks = append(rs.Hunks, hunk)
+			rs.Stats.Added += hunk.Added
+			rs.Stats.Deleted += hunk.Deleted
+			if hunk.Resolved {
+				files[hunk.File] = true
+			}
+		}
+		rs.Stats.Files = len(files)
+		report.Sections = append(report.Sections, rs)
+	}
+
+	for _, e := range ValidateClassification(diff, story) {
+		report.Warnings = append(report.Warnings, ReportWarning{
+			Section:   e.Section,
+			File:      e.HunkRef.File,
+			HunkIndex: e.HunkRef.HunkIndex,
+			Reason:    e.Reason,
+			Message:   e.Error(),
+		})
+	}
+
+	return report
+}
+
+// resolveHunk looks up a hunk reference in the diff.
+func resolveHunk(diff *Diff, ref HunkRef) ReportHunk {
+	rh := ReportHunk{
+		File:         ref.File,
+		HunkIndex:    ref.HunkIndex,
+		Category:     ref.Category,
+		Collapsed:    ref.Collapsed,
+		CollapseText: ref.CollapseText,
+	}
+	for _, file := range diff.Files {
+		if file.Path() != ref.File {
+			continue
+		}
+		rh.Operation = operationName(file.Operation)
+		if file.Operation == FileRenamed || file.Operation == FileCopied {
+			rh.OldPath = file.OldPath
+		}
+		if ref.HunkIndex < 0 || ref.HunkIndex >= len(file.Hunks) {
+			return rh
+		}
+
+		hunk := file.Hunks[ref.HunkIndex]
+		rh.Resolved = true
+		rh.OldStart, rh.OldCount = hunk.OldStart, hunk.OldCount
+		rh.NewStart, rh.NewCount = hunk.NewStart, hunk.NewCount
+		for _, line := range hunk.Lines {
+			switch line.Type {
+			case LineAdded:
+				rh.Added++
+			case LineDeleted:
+				rh.Deleted++
+			}
+		}
+		return rh
+	}
+	return rh
+}
@@ -0,0 +1,160 @@
+package diffview_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reportInput() diffview.ClassificationInput {
+	return diffview.ClassificationInput{
+		Repo:   "diffstory",
+		Branch: "feature",
+		Diff: diffview.Diff{
+			Files: []diffview.FileDiff{
+				{
+					OldPath:   "old.go",
+					NewPath:   "auth.go",
+					Operation: diffview.FileRenamed,
+					Hunks: []diffview.Hunk{
+						{
+							OldStart: 10, OldCount: 2, NewStart: 10, NewCount: 3,
+							Lines: []diffview.Line{
+								{Type: diffview.LineContext, Content: "func login() {"},
+								{Type: diffview.LineDeleted, Content: "\treturn nil"},
+								{Type: diffview.LineAdded, Content: "\tcheck()"},
+								{Type: diffview.LineAdded, Content: "\treturn nil"},
+							},
+						},
+					},
+				},
+				{
+					NewPath:   "auth_test.go",
+					Operation: diffview.FileAdded,
+					Hunks: []diffview.Hunk{
+						{
+							NewStart: 1, NewCount: 1,
+							Lines: []diffview.Line{
+								{Type: diffview.LineAdded, Content: "package auth"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	t.Parallel()
+
+	story := &diffview.StoryClassification{
+		ChangeType: "bugfix",
+		Narrative:  "fix-then-test",
+		Summary:    "Check credentials on login",
+		Sections: []diffview.Section{
+			{
+				Role:  "fix",
+				Title: "Add check",
+				Hunks: []diffview.HunkRef{{File: "auth.go", HunkIndex: 0, Category: "core"}},
+			},
+			{
+				Role:  "test",
+				Title: "Cover it",
+				Hunks: []diffview.HunkRef{
+					{File: "auth_test.go", HunkIndex: 0, Collapsed: true, CollapseText: "Package clause"},
+					{File: "missing.go", HunkIndex: 0},
+				},
+			},
+		},
+	}
+
+	report := diffview.NewReport(reportInput(), story)
+
+	assert.Equal(t, diffview.ReportSchemaVersion, report.SchemaVersion)
+	assert.Equal(t, "diffstory", report.Repo)
+	assert.Equal(t, "feature", report.Branch)
+	assert.Equal(t, "bugfix", report.ChangeType)
+	assert.Equal(t, diffview.ReportStats{Files: 2, Added: 3, Deleted: 1}, report.Stats)
+
+	require.Len(t, report.Sections, 2)
+	fix := report.Sections[0]
+	assert.Equal(t, diffview.ReportStats{Files: 1, Added: 2, Deleted: 1}, fix.Stats)
+	require.Len(t, fix.Hunks, 1)
+	assert.Equal(t, diffview.ReportHunk{
+		File:      "auth.go",
+		OldPath:   "old.go",
+		Operation: "renamed",
+		HunkIndex: 0,
+		Resolved:  true,
+		Category:  "core",
+		OldStart:  10,
+		OldCount:  2,
+		NewStart:  10,
+		NewCount:  3,
+		Added:     2,
+		Deleted:   1,
+	}, fix.Hunks[0])
+
+	test := report.Sections[1]
+	assert.Equal(t, diffview.ReportStats{Files: 1, Added: 1}, test.Stats)
+	require.Len(t, test.Hunks, 2)
+	assert.True(t, test.Hunks[0].Collapsed)
+	assert.Equal(t, "Package clause", test.Hunks[0].CollapseText)
+	assert.False(t, test.Hunks[1].Resolved)
+
+	require.Len(t, report.Warnings, 1)
+	assert.Equal(t, 1, report.Warnings[0].Section)
+	assert.Equal(t, "missing.go", report.Warnings[0].File)
+	assert.Equal(t, diffview.ErrFileNotFound, report.Warnings[0].Reason)
+	assert.Contains(t, report.Warnings[0].Message, "not found in diff")
+}
+
+func TestNewReport_TopLevelAAndBDirectories(t *testing.T) {
+	t.Parallel()
+
+	input := diffview.ClassificationInput{
+		Diff: diffview.Diff{
+			Files: []diffview.FileDiff{
+				{
+					OldPath:   "a/old.go",
+					NewPath:   "b/new.go",
+					Operation: diffview.FileRenamed,
+					Hunks:     []diffview.Hunk{{OldStart: 1, OldCount: 1, NewStart: 1, NewCount: 1}},
+				},
+			},
+		},
+	}
+	story := &diffview.StoryClassification{
+		Sections: []diffview.Section{
+			{Role: "core", Hunks: []diffview.HunkRef{{File: "b/new.go", HunkIndex: 0}}},
+		},
+	}
+
+	report := diffview.NewReport(input, story)
+
+	require.Len(t, report.Sections, 1)
+	require.Len(t, report.Sections[0].Hunks, 1)
+	hunk := report.Sections[0].Hunks[0]
+	assert.True(t, hunk.Resolved)
+	assert.Equal(t, "b/new.go", hunk.File)
+	assert.Equal(t, "a/old.go", hunk.OldPath)
+}
+
+func TestNewReport_NilStory(t *testing.T) {
+	t.Parallel()
+
+	report := diffview.NewReport(reportInput(), nil)
+
+	assert.Equal(t, 2, report.Stats.Files)
+	assert.Empty(t, report.Sections)
+
+	// Empty lists encode as [] rather than null so consumers can iterate.
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"sections":[]`)
+	assert.Contains(t, string(data), `"warnings":[]`)
+}
@@ -0,0 +1,319 @@
+package diffview
+
+import "sort"
+
+// SeriesDiff returns the diff of a patch series as a whole, as if its
+// commits were squashed: each file appears once, in the order the series
+// first changes it, with the hunks of later patches composed onto earlier
+// ones so that line numbers refer to the file before the series and after
+// it. A file added and later deleted, or changed and changed back, by the
+// series is left out.
+func SeriesDiff(commits []CommitBrief) *Diff {
+	var files []*FileDiff
+	byPath := make(map[string]*FileDiff) // Files by their path after the patches so far
+	dropped := make(map[*FileDiff]bool)
+	for _, c := range commits {
+		if c.Diff == nil {
+			continue
+		}
+		for _, file := range c.Diff.Files {
+			key := file.Path()
+			if file.OldPath != "" && file.Operation != FileCopied {
+				key = file.OldPath
+			}
+			acc, seen := byPath[key]
+			if !seen {
+				file.Hunks = append([]Hunk(nil), file.Hunks...)
+				files = append(files, &file)
+				byPath[file.Path()] = &file
+				continue
+			}
+			delete(byPath, key)
+			if acc.Operation == FileAdded && file.Operation == FileDeleted {
+				dropped[acc] = true
+				continue
+			}
+			composeFile(acc, file)
+			if acc.Operation == FileModified && len(acc.Hunks) == 0 && !acc.IsBinary && !acc.ModeChanged() {
+				dropped[acc] = true // Changed and changed back
+				continue
+			}
+			byPath[acc.Path()] = acc
+		}
+	}
+
+	diff := &Diff{Files: []FileDiff{}}
+	for _, file := range files {
+		if !dropped[file] {
+			diff.Files = append(diff.Files, *file)
+		}
+	}
+	return diff
+}
+
+// composeFile applies next, a later change of the same file, on top of acc.
+func composeFile(acc *FileDiff, next FileDiff) {
+	acc.Hunks = composeHunks(acc.Hunks, next.Hunks)
+	acc.NewPath = next.NewPath
+	acc.NewMode = next.NewMode
+	acc.IsBinary = acc.IsBinary || next.IsBinary
+	switch {
+	case next.Operation == FileDeleted:
+		acc.Operation = FileDeleted
+	case acc.Operation == FileAdded || acc.Operation == FileCopied:
+		// Still new to the series
+	case acc.OldPath != acc.NewPath:
+		acc.Operation = FileRenamed
+	default:
+		// Modified, deleted and added back, or renamed back
+		acc.Operation = FileModified
+	}
+}
+
+// composeHunks composes hunks a, from the old file to an intermediate one,
+// with hunks b, from the intermediate file to the new one, into hunks from
+// the old file to the new one. Hunks of a and b that overlap or touch in the
+// intermediate file become a single hunk.
+func composeHunks(a, b []Hunk) []Hunk {
+	if len(a) == 0 {
+		return append([]Hunk(nil), b...)
+	}
+	if len(b) == 0 {
+		return append([]Hunk(nil), a...)
+	}
+
+	// Intermediate lines known from either side, for filling in lines one
+	// side doesn't cover
+	middle := make(map[int]Line)
+	for _, h := range a {
+		pos := newSpan(h).start
+		for _, line := range h.Lines {
+			if line.Type != LineDeleted {
+				middle[pos] = line
+				pos++
+			}
+		}
+	}
+	for _, h := range b {
+		pos := oldSpan(h).start
+		for _, line := range h.Lines {
+			if line.Type != LineAdded {
+				middle[pos] = line
+				pos++
+			}
+		}
+	}
+
+	var composed []Hunk
+	ai, bi := 0, 0
+	deltaA, deltaB := 0, 0 // Lines added minus removed by the hunks before
+	for ai < len(a) || bi < len(b) {
+		// Start a group with the hunk that comes first in the intermediate
+		// file, then take every hunk overlapping or touching the group
+		var group span
+		if bi >= len(b) || (ai < len(a) && newSpan(a[ai]).start <= oldSpan(b[bi]).start) {
+			group = newSpan(a[ai])
+		} else {
+			group = oldSpan(b[bi])
+		}
+		aStart, bStart := ai, bi
+		for {
+			if ai < len(a) && newSpan(a[ai]).start <= group.end {
+				group = group.union(newSpan(a[ai]))
+				ai++
+				continue
+			}
+			if bi < len(b) && oldSpan(b[bi]).start <= group.end {
+				group = group.union(oldSpan(b[bi]))
+				bi++
+				continue
+			}
+			break
+		}
+
+		if hunk, ok := composeGroup(a[aStart:ai], b[bStart:bi], group, middle, group.start-deltaA, group.start+deltaB); ok {
+			composed = append(composed, hunk)
+		}
+		for _, h := range a[aStart:ai] {
+			deltaA += h.NewCount - h.OldCount
+		}
+		for _, h := range b[bStart:bi] {
+			deltaB += h.NewCount - h.OldCount
+		}
+	}
+	return composed
+}
+
+// composeGroup composes hunks a and b covering the intermediate lines of
+// group into one hunk starting at oldStart and newStart. It reports false
+// if the changes cancel out.
+func composeGroup(a, b []Hunk, group span, middle map[int]Line, oldStart, newStart int) (Hunk, bool) {
+	aLines := expandHunks(a, group, middle, newSpan, LineDeleted)
+	bLines := expandHunks(b, group, middle, oldSpan, LineAdded)
+
+	var lines []Line
+	emit := func(line Line, typ LineType) {
+		line.Type = typ
+		lines = append(lines, line)
+	}
+	i, j := 0, 0
+	for i < len(aLines) || j < len(bLines) {
+		// Lines only in the old file, then lines only in the new file, come
+		// before the next intermediate line
+		if i < len(aLines) && aLines[i].Type == LineDeleted {
+			emit(aLines[i], LineDeleted)
+			i++
+			continue
+		}
+		if j < len(bLines) && bLines[j].Type == LineAdded {
+			emit(bLines[j], LineAdded)
+			j++
+			continue
+		}
+		if i >= len(aLines) || j >= len(bLines) {
+			break // Malformed hunks that disagree on the intermediate file
+		}
+		switch {
+		case aLines[i].Type == LineContext && bLines[j].Type == LineContext:
+			emit(bLines[j], LineContext)
+		case aLines[i].Type == LineContext:
+			emit(bLines[j], LineDeleted)
+		case bLines[j].Type == LineContext:
+			emit(aLines[i], LineAdded)
+		}
+		// A line added by a and deleted by b is in neither file
+		i, j = i+1, j+1
+	}
+
+	hunk := Hunk{OldStart: oldStart, NewStart: newStart}
+	for _, h := range append(append([]Hunk(nil), a...), b...) {
+		if h.Section != "" {
+			hunk.Section = h.Section
+			break
+		}
+	}
+	changed := false
+	for _, line := range normalizeChanges(lines) {
+		line.OldLineNum, line.NewLineNum = 0, 0
+		if line.Type != LineAdded {
+			line.OldLineNum = oldStart + hunk.OldCount
+			hunk.OldCount++
+		}
+		if line.Type != LineDeleted {
+			line.NewLineNum = newStart + hunk.NewCount
+			hunk.NewCount++
+		}
+		changed = changed || line.Type != LineContext
+		hunk.Lines = append(hunk.Lines, line)
+	}
+	if !changed {
+		return Hunk{}, false
+	}
+	if hunk.OldCount == 0 {
+		hunk.OldStart--
+	}
+	if hunk.NewCount == 0 {
+		hunk.NewStart--
+	}
+	return hunk, true
+}
+
+// normalizeChanges rewrites each run of changed lines as its deleted lines
+// followed by its added lines, turning lines deleted and added back at the
+// start or end of the run into context.
+func normalizeChanges(lines []Line) []Line {
+	var out []Line
+	for i := 0; i < len(lines); {
+		if lines[i].Type == LineContext {
+			out = append(out, lines[i])
+			i++
+			continue
+		}
+		var deleted, added []Line
+		for ; i < len(lines) && lines[i].Type != LineContext; i++ {
+			if lines[i].Type == LineDeleted {
+				deleted = append(deleted, lines[i])
+			} else {
+				added = append(added, lines[i])
+			}
+		}
+		prefix := 0
+		for prefix < len(deleted) && prefix < len(added) && deleted[prefix].Content == added[prefix].Content {
+			prefix++
+		}
+		suffix := 0
+		for suffix < len(deleted)-prefix && suffix < len(added)-prefix &&
+			deleted[len(deleted)-1-suffix].Content == added[len(added)-1-suffix].Content {
+			suffix++
+		}
+		for _, line := range added[:prefix] {
+			line.Type = LineContext
+			out = append(out, line)
+		}
+		out = append(out, deleted[prefix:len(deleted)-suffix]...)
+		out = append(out, added[prefix:len(added)-suffix]...)
+		for _, line := range added[len(added)-suffix:] {
+			line.Type = LineContext
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// expandHunks returns the lines of hunks over the intermediate lines of
+// group, with the lines between the hunks filled in from middle as context.
+// side returns the span of a hunk in the intermediate file, and skip is the
+// type of the hunk lines that aren't in it.
+func expandHunks(hunks []Hunk, group span, middle map[int]Line, side func(Hunk) span, skip LineType) []Line {
+	var lines []Line
+	pos := group.start
+	fill := func(end int) {
+		for ; pos < end; pos++ {
+			line := middle[pos]
+			line.Type = LineContext
+			lines = append(lines, line)
+		}
+	}
+	sorted := append([]Hunk(nil), hunks...)
+	sort.SliceStable(sorted, func(i, j int) bool { return side(sorted[i]).start < side(sorted[j]).start })
+	for _, h := range sorted {
+		fill(side(h).start)
+		for _, line := range h.Lines {
+			lines = append(lines, line)
+			if line.Type != skip {
+				pos++
+			}
+		}
+	}
+	fill(group.end)
+	return lines
+}
+
+// span is a half-open range of line numbers.
+type span struct {
+	start, end int
+}
+
+// union returns the smallest span covering s and t.
+func (s span) union(t span) span {
+	return span{start: min(s.start, t.start), end: max(s.end, t.end)}
+}
+
+// oldSpan returns the lines of the old file a hunk covers. An empty range
+// sits after its start line, as in "-3,0".
+func oldSpan(h Hunk) span {
+	return rangeSpan(h.OldStart, h.OldCount)
+}
+
+// newSpan returns the lines of the new file a hunk covers.
+func newSpan(h Hunk) span {
+	return rangeSpan(h.NewStart, h.NewCount)
+}
+
+// rangeSpan returns the span of a hunk range with the given start and count.
+func rangeSpan(start, count int) span {
+	if count == 0 {
+		return span{start: start + 1, end: start + 1}
+	}
+	return span{start: start, end: start + count}
+}
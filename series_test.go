@@ -0,0 +1,153 @@
+package diffview_test
+
+import (
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seriesHunk returns a hunk starting at oldStart and newStart with lines
+// given with their diff prefix, like "+added".
+func seriesHunk(oldStart, newStart int, lines ...string) diffview.Hunk {
+	h := diffview.Hunk{OldStart: oldStart, NewStart: newStart}
+	for _, l := range lines {
+		line := diffview.Line{Content: l[1:]}
+		switch l[0] {
+		case '+':
+			line.Type = diffview.LineAdded
+		case '-':
+			line.Type = diffview.LineDeleted
+		}
+		if line.Type != diffview.LineAdded {
+			line.OldLineNum = oldStart + h.OldCount
+			h.OldCount++
+		}
+		if line.Type != diffview.LineDeleted {
+			line.NewLineNum = newStart + h.NewCount
+			h.NewCount++
+		}
+		h.Lines = append(h.Lines, line)
+	}
+	return h
+}
+
+func TestSeriesDiff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("composes a file added and then changed", func(t *testing.T) {
+		t.Parallel()
+		commits := []diffview.CommitBrief{
+			{Diff: &diffview.Diff{Files: []diffview.FileDiff{{
+				NewPath: "a.go", Operation: diffview.FileAdded, NewMode: 0o100644,
+				Hunks: []diffview.Hunk{seriesHunk(0, 1, "+one", "+two", "+three")},
+			}}}},
+			{},
+			{Diff: &diffview.Diff{Files: []diffview.FileDiff{{
+				OldPath: "a.go", NewPath: "a.go", Operation: diffview.FileModified, NewMode: 0o100755,
+				Hunks: []diffview.Hunk{seriesHunk(1, 1, " one", "-two", "+TWO", " three")},
+			}}}},
+		}
+
+		diff := diffview.SeriesDiff(commits)
+
+		require.Len(t, diff.Files, 1)
+		a := diff.Files[0]
+		assert.Equal(t, diffview.FileAdded, a.Operation, "a file added in the series stays added")
+		assert.EqualValues(t, 0o100755, a.NewMode)
+		assert.Equal(t, []diffview.Hunk{seriesHunk(0, 1, "+one", "+TWO", "+three")}, a.Hunks)
+		assert.Len(t, commits[0].Diff.Files[0].Hunks[0].Lines, 3, "the commits are not modified")
+	})
+
+	t.Run("orders hunks by position in the file", func(t *testing.T) {
+		t.Parallel()
+		commits := []diffview.CommitBrief{
+			{Diff: &diffview.Diff{Files: []diffview.FileDiff{{
+				OldPath: "b.go", NewPath: "b.go",
+				Hunks: []diffview.Hunk{seriesHunk(9, 9, " l9", "-l10", "+ten", " l11")},
+			}}}},
+			{Diff: &diffview.Diff{Files: []diffview.FileDiff{{
+				OldPath: "b.go", NewPath: "b.go",
+				Hunks: []diffview.Hunk{seriesHunk(1, 1, " l1", " l2", "+new", " l3")},
+			}}}},
+		}
+
+		diff := diffview.SeriesDiff(commits)
+
+		require.Len(t, diff.Files, 1)
+		assert.Equal(t, []diffview.Hunk{
+			seriesHunk(1, 1, " l1", " l2", "+new", " l3"),
+			// Old lines are numbered before the series, new lines after it
+			seriesHunk(9, 10, " l9", "-l10", "+ten", " l11"),
+		}, diff.Files[0].Hunks)
+	})
+
+	t.Run("merges overlapping hunks", func(t *testing.T) {
+		t.Parallel()
+		commits := []diffview.CommitBrief{
+			{Diff: &diffview.Diff{Files: []diffview.FileDiff{{
+				OldPath: "c.go", NewPath: "c.go",
+				Hunks: []diffview.Hunk{seriesHunk(4, 4, " l4", "-l5", "+five", " l6")},
+			}}}},
+			{Diff: &diffview.Diff{Files: []diffview.FileDiff{{
+				OldPath: "c.go", NewPath: "c.go",
+				Hunks: []diffview.Hunk{seriesHunk(5, 5, " five", "-l6", "+six", " l7")},
+			}}}},
+		}
+
+		diff := diffview.SeriesDiff(commits)
+
+		require.Len(t, diff.Files, 1)
+		assert.Equal(t, []diffview.Hunk{
+			seriesHunk(4, 4, " l4", "-l5", "-l6", "+five", "+six", " l7"),
+		}, diff.Files[0].Hunks)
+	})
+
+	t.Run("drops changes that cancel out", func(t *testing.T) {
+		t.Parallel()
+		commits := []diffview.CommitBrief{
+			{Diff: &diffview.Diff{Files: []diffview.FileDiff{{
+				OldPath: "d.go", NewPath: "d.go",
+				Hunks: []diffview.Hunk{seriesHunk(1, 1, " x", "-y", "+Y")},
+			}}}},
+			{Diff: &diffview.Diff{Files: []diffview.FileDiff{{
+				OldPath: "d.go", NewPath: "d.go",
+				Hunks: []diffview.Hunk{seriesHunk(1, 1, " x", "-Y", "+y")},
+			}}}},
+		}
+
+		diff := diffview.SeriesDiff(commits)
+
+		assert.Empty(t, diff.Files, "a file changed back is left out")
+	})
+
+	t.Run("deletions and renames", func(t *testing.T) {
+		t.Parallel()
+		commits := []diffview.CommitBrief{
+			{Diff: &diffview.Diff{Files: []diffview.FileDiff{
+				{NewPath: "tmp.go", Operation: diffview.FileAdded, Hunks: []diffview.Hunk{seriesHunk(0, 1, "+tmp")}},
+				{OldPath: "d.go", NewPath: "d.go", Hunks: []diffview.Hunk{seriesHunk(1, 1, " x", "-y", "+Y")}},
+				{OldPath: "old.go", NewPath: "mid.go", Operation: diffview.FileRenamed},
+			}}},
+			{Diff: &diffview.Diff{Files: []diffview.FileDiff{
+				{OldPath: "tmp.go", Operation: diffview.FileDeleted, Hunks: []diffview.Hunk{seriesHunk(1, 0, "-tmp")}},
+				{OldPath: "d.go", Operation: diffview.FileDeleted, Hunks: []diffview.Hunk{seriesHunk(1, 0, "-x", "-Y")}},
+				{OldPath: "mid.go", NewPath: "new.go", Operation: diffview.FileRenamed},
+				{NewPath: "e.go", Operation: diffview.FileAdded},
+			}}},
+		}
+
+		diff := diffview.SeriesDiff(commits)
+
+		require.Len(t, diff.Files, 3, "a file added and deleted by the series is left out")
+		d := diff.Files[0]
+		assert.Equal(t, "d.go", d.Path())
+		assert.Equal(t, diffview.FileDeleted, d.Operation)
+		assert.Equal(t, []diffview.Hunk{seriesHunk(1, 0, "-x", "-y")}, d.Hunks)
+		renamed := diff.Files[1]
+		assert.Equal(t, diffview.FileRenamed, renamed.Operation)
+		assert.Equal(t, "old.go → new.go", renamed.DisplayPath())
+		assert.Equal(t, "e.go", diff.Files[2].Path())
+	})
+}
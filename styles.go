@@ -6,55 +6,57 @@ type Color string
 
 // Palette defines semantic colors for a theme.
 // All colors are hex strings in "#RRGGBB" format.
+// Field tags name the keys used in theme files.
 type Palette struct {
 	// Base colors
-	Background Color // Primary background
-	Foreground Color // Primary foreground/text
+	Background Color `json:"background" toml:"background"` // Primary background
+	Foreground Color `json:"foreground" toml:"foreground"` // Primary foreground/text
 
 	// Diff colors
-	Added    Color // Added lines and text
-	Deleted  Color // Deleted lines and text
-	Modified Color // Modified content
-	Context  Color // Unchanged context lines
+	Added    Color `json:"added" toml:"added"`       // Added lines and text
+	Deleted  Color `json:"deleted" toml:"deleted"`   // Deleted lines and text
+	Modified Color `json:"modified" toml:"modified"` // Modified content
+	Context  Color `json:"context" toml:"context"`   // Unchanged context lines
 
 	// Syntax highlighting colors
-	Keyword     Color // Language keywords (if, for, func, etc.)
-	String      Color // String literals
-	Number      Color // Numeric literals
-	Comment     Color // Comments
-	Operator    Color // Operators (+, -, =, etc.)
-	Function    Color // Function names
-	Type        Color // Type names
-	Constant    Color // Constants and boolean literals
-	Punctuation Color // Brackets, semicolons, etc.
+	Keyword     Color `json:"keyword" toml:"keyword"`         // Language keywords (if, for, func, etc.)
+	String      Color `json:"string" toml:"string"`           // String literals
+	Number      Color `json:"number" toml:"number"`           // Numeric literals
+	Comment     Color `json:"comment" toml:"comment"`         // Comments
+	Operator    Color `json:"operator" toml:"operator"`       // Operators (+, -, =, etc.)
+	Function    Color `json:"function" toml:"function"`       // Function names
+	Type        Color `json:"type" toml:"type"`               // Type names
+	Constant    Color `json:"constant" toml:"constant"`       // Constants and boolean literals
+	Punctuation Color `json:"punctuation" toml:"punctuation"` // Brackets, semicolons, etc.
 
 	// UI colors
-	UIBackground Color // Secondary background (panels, sidebars)
-	UIForeground Color // Secondary foreground (dimmed text)
-	UIAccent     Color // Accent color (highlights, focus)
+	UIBackground Color `json:"ui_background" toml:"ui_background"` // Secondary background (panels, sidebars)
+	UIForeground Color `json:"ui_foreground" toml:"ui_foreground"` // Secondary foreground (dimmed text)
+	UIAccent     Color `json:"ui_accent" toml:"ui_accent"`         // Accent color (highlights, focus)
 }
 
 // ColorPair represents a foreground and background color combination.
 // Colors should be hex strings in "#RRGGBB" format (e.g., "#ff0000" for red).
 // Empty strings are valid and indicate no color override (use terminal default).
 type ColorPair struct {
-	Foreground string
-	Background string
+	Foreground string `json:"foreground" toml:"foreground"`
+	Background string `json:"background" toml:"background"`
 }
 
 // Styles contains color pairs for all visual elements in a diff.
 type Styles struct {
-	Added            ColorPair // Style for added lines (+)
-	Deleted          ColorPair // Style for deleted lines (-)
-	Context          ColorPair // Style for context lines (unchanged)
-	HunkHeader       ColorPair // Style for hunk headers (@@ ... @@)
-	FileHeader       ColorPair // Style for file headers (--- a/... +++ b/...)
-	FileSeparator    ColorPair // Style for separator lines between files
-	LineNumber       ColorPair // Style for line numbers in the gutter (context lines)
-	AddedGutter      ColorPair // Style for gutter on added lines (stronger background)
-	DeletedGutter    ColorPair // Style for gutter on deleted lines (stronger background)
-	AddedHighlight   ColorPair // Style for changed text within added lines (word-level diff)
-	DeletedHighlight ColorPair // Style for changed text within deleted lines (word-level diff)
+	Added            ColorPair `json:"added" toml:"added"`                         // Style for added lines (+)
+	Deleted          ColorPair `json:"deleted" toml:"deleted"`                     // Style for deleted lines (-)
+	Context          ColorPair `json:"context" toml:"context"`                     // Style for context lines (unchanged)
+	HunkHeader       ColorPair `json:"hunk_header" toml:"hunk_header"`             // Style for hunk headers (@@ ... @@)
+	FileHeader       ColorPair `json:"file_header" toml:"file_header"`             // Style for file headers (--- a/... +++ b/...)
+	FileSeparator    ColorPair `json:"file_separator" toml:"file_separator"`       // Style for separator lines between files
+	LineNumber       ColorPair `json:"line_number" toml:"line_number"`             // Style for line numbers in the gutter (context lines)
+	AddedGutter      ColorPair `json:"added_gutter" toml:"added_gutter"`           // Style for gutter on added lines (stronger background)
+	DeletedGutter    ColorPair `json:"deleted_gutter" toml:"deleted_gutter"`       // Style for gutter on deleted lines (stronger background)
+	AddedHighlight   ColorPair `json:"added_highlight" toml:"added_highlight"`     // Style for changed text within added lines (word-level diff)
+	DeletedHighlight ColorPair `json:"deleted_highlight" toml:"deleted_highlight"` // Style for changed text within deleted lines (word-level diff)
+	Moved            ColorPair `json:"moved" toml:"moved"`                         // Style for lines moved unchanged from elsewhere in the diff
 }
 
 // Theme provides styles for rendering diffs.
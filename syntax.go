@@ -1,5 +1,7 @@
 package diffview
 
+import "strings"
+
 // Token represents a syntax-highlighted segment of code.
 type Token struct {
 	Text  string // The text content of this token
@@ -32,3 +34,49 @@ type LanguageDetector interface {
 	// Accepts paths with or without "a/" or "b/" prefixes (common in diffs).
 	DetectFromPath(path string) string
 }
+
+// MaxHunkSizeForTokenization is the maximum total size (in bytes) of hunk
+// content that TokenizeHunkLines will tokenize. Real code with multi-line
+// comments is small; larger hunks are likely data files or minified code
+// where syntax highlighting provides little value.
+const MaxHunkSizeForTokenization = 16 * 1024 // 16KB
+
+// MaxTokenizeLineLength is the maximum length of a single line that is
+// tokenized. Lines longer than this are likely data, not code.
+const MaxTokenizeLineLength = 1000
+
+// TokenizeHunkLines tokenizes all lines in a hunk together with full context,
+// returning per-line tokens. This correctly handles multi-line constructs like
+// /* */ comments and JSDoc that span multiple lines.
+// Returns nil if tokenizer is nil, language is empty, language is unsupported,
+// or hunk content exceeds size limits (likely not normal code).
+func TokenizeHunkLines(lines []Line, language string, tokenizer Tokenizer) [][]Token {
+	if tokenizer == nil || language == "" || len(lines) == 0 {
+		return nil
+	}
+
+	// Pre-check total size to avoid allocations for large hunks
+	totalSize := 0
+	for _, line := range lines {
+		lineLen := len(line.Content)
+		if lineLen > MaxTokenizeLineLength {
+			return nil // Long line suggests data file, not code
+		}
+		totalSize += lineLen
+		if totalSize > MaxHunkSizeForTokenization {
+			return nil
+		}
+	}
+
+	// Build the full hunk content by joining all line contents
+	var sb strings.Builder
+	sb.Grow(totalSize) // Pre-allocate to avoid reallocations
+	for i, line := range lines {
+		sb.WriteString(strings.TrimSuffix(line.Content, "\n"))
+		if i < len(lines)-1 {
+			sb.WriteString("\n")
+		}
+	}
+
+	return tokenizer.TokenizeLines(language, sb.String())
+}
@@ -0,0 +1,206 @@
+// Package unidiff parses plain unified diffs, as printed by diff -u, hg diff
+// and svn diff, without relying on git's extended headers.
+package unidiff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/gitdiff"
+)
+
+// Compile-time interface verification.
+var _ diffview.Parser = (*Parser)(nil)
+
+// Parser parses plain unified diffs. Lines outside file patches, such as
+// svn's "Index:" lines or hg's "diff -r" lines, are kept as the following
+// file's extended headers.
+type Parser struct{}
+
+// NewParser creates a new Parser.
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+// Parse reads unified diff content and returns the parsed result.
+func (p *Parser) Parse(r io.Reader) (*diffview.Diff, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &diffview.Diff{Files: []diffview.FileDiff{}}
+	var extended []string
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			file := newFile(headerPath(line[4:]), headerPath(lines[i+1][4:]))
+			file.Extended = extended
+			extended = nil
+			next, err := parseHunks(&file, lines, i+2)
+			if err != nil {
+				return nil, err
+			}
+			inferOperation(&file)
+			result.Files = append(result.Files, file)
+			i = next - 1
+		case strings.HasPrefix(line, "Binary files ") && strings.HasSuffix(line, " differ"):
+			oldPath, newPath, ok := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(line, "Binary files "), " differ"), " and ")
+			if !ok {
+				continue
+			}
+			file := newFile(headerPath(oldPath), headerPath(newPath))
+			file.IsBinary = true
+			file.Extended = extended
+			extended = nil
+			result.Files = append(result.Files, file)
+		case line == "" || strings.HasPrefix(line, "==="):
+			// Separators between files
+		default:
+			extended = append(extended, line)
+		}
+	}
+	return result, nil
+}
+
+// headerPath returns the path of a ---/+++ or "Binary files" header, without
+// the timestamp or "(revision N)" suffix after a tab. Returns "" for
+// /dev/null.
+func headerPath(s string) string {
+	path, _, _ := strings.Cut(s, "\t")
+	path = strings.TrimSpace(path)
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		path = unquoted
+	}
+	if path == "/dev/null" {
+		return ""
+	}
+	return path
+}
+
+// newFile builds a file from its old and new paths. The same path under two
+// different first directories, such as "a/x" and "b/x" or
+// "project.orig/x" and "project/x", is stripped of them like patch -p1
+// does, as are the "a/" and "b/" of added and deleted files. Files whose
+// paths still differ are renames.
+func newFile(oldPath, newPath string) diffview.FileDiff {
+	file := diffview.FileDiff{OldPath: oldPath, NewPath: newPath, Operation: diffview.FileModified}
+	switch {
+	case oldPath == "":
+		file.Operation = diffview.FileAdded
+		file.NewPath = strings.TrimPrefix(newPath, "b/")
+	case newPath == "":
+		file.Operation = diffview.FileDeleted
+		file.OldPath = strings.TrimPrefix(oldPath, "a/")
+	default:
+		oldDir, oldRest, oldOK := strings.Cut(oldPath, "/")
+		newDir, newRest, newOK := strings.Cut(newPath, "/")
+		if oldOK && newOK && oldDir != newDir && oldRest == newRest && oldRest != "" {
+			file.OldPath, file.NewPath = oldRest, newRest
+		}
+		if file.OldPath != file.NewPath {
+			file.Operation = diffview.FileRenamed
+		}
+	}
+	return file
+}
+
+// inferOperation marks a modified file whose only hunk starts from or ends
+// in nothing as added or deleted, for tools such as svn that don't use
+// /dev/null for missing files.
+func inferOperation(file *diffview.FileDiff) {
+	if file.Operation != diffview.FileModified || len(file.Hunks) != 1 {
+		return
+	}
+	h := file.Hunks[0]
+	switch {
+	case h.OldStart == 0 && h.OldCount == 0:
+		file.Operation = diffview.FileAdded
+	case h.NewStart == 0 && h.NewCount == 0:
+		file.Operation = diffview.FileDeleted
+	}
+}
+
+// parseHunks parses the hunks of file starting at lines[start] and returns
+// the index of the first line after them. Hunk lines are counted from the
+// header, so deleted lines that look like "--- " headers are read correctly.
+func parseHunks(file *diffview.FileDiff, lines []string, start int) (int, error) {
+	i := start
+	for i < len(lines) && strings.HasPrefix(lines[i], "@@ ") {
+		hunk, err := parseHunkHeader(lines[i])
+		if err != nil {
+			return 0, err
+		}
+		i++
+
+		oldNum, newNum := hunk.OldStart, hunk.NewStart
+		oldLeft, newLeft := hunk.OldCount, hunk.NewCount
+		for i < len(lines) && (oldLeft > 0 || newLeft > 0 || strings.HasPrefix(lines[i], `\`)) {
+			line := lines[i]
+			i++
+			if strings.HasPrefix(line, `\`) {
+				if n := len(hunk.Lines); n > 0 {
+					hunk.Lines[n-1].NoNewline = true
+				}
+				continue
+			}
+
+			var l diffview.Line
+			switch {
+			case strings.HasPrefix(line, "+"):
+				l = diffview.Line{Type: diffview.LineAdded, NewLineNum: newNum}
+				newNum++
+				newLeft--
+			case strings.HasPrefix(line, "-"):
+				l = diffview.Line{Type: diffview.LineDeleted, OldLineNum: oldNum}
+				oldNum++
+				oldLeft--
+			case strings.HasPrefix(line, " ") || line == "":
+				// Some tools strip the space of empty context lines
+				l = diffview.Line{Type: diffview.LineContext, OldLineNum: oldNum, NewLineNum: newNum}
+				oldNum++
+				newNum++
+				oldLeft--
+				newLeft--
+			default:
+				return 0, fmt.Errorf("unexpected line in hunk: %q", line)
+			}
+			if line != "" {
+				line = line[1:]
+			}
+			l.Content = line + "\n"
+			hunk.Lines = append(hunk.Lines, l)
+		}
+		file.Hunks = append(file.Hunks, hunk)
+	}
+	return i, nil
+}
+
+// parseHunkHeader parses a header such as "@@ -1,5 +1,6 @@ func main()".
+func parseHunkHeader(line string) (diffview.Hunk, error) {
+	rest, section, ok := strings.Cut(strings.TrimPrefix(line, "@@ "), " @@")
+	fields := strings.Fields(rest)
+	if !ok || len(fields) != 2 {
+		return diffview.Hunk{}, fmt.Errorf("invalid hunk header: %q", line)
+	}
+	var h diffview.Hunk
+	var err error
+	if h.OldStart, h.OldCount, err = gitdiff.ParseRange(fields[0], "-"); err != nil {
+		return diffview.Hunk{}, fmt.Errorf("invalid hunk header: %q: %w", line, err)
+	}
+	if h.NewStart, h.NewCount, err = gitdiff.ParseRange(fields[1], "+"); err != nil {
+		return diffview.Hunk{}, fmt.Errorf("invalid hunk header: %q: %w", line, err)
+	}
+	h.Section = strings.TrimPrefix(section, " ")
+	return h, nil
+}
@@ -0,0 +1,143 @@
+package unidiff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/unidiff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParser_Parse_DiffU(t *testing.T) {
+	t.Parallel()
+
+	input := `--- project.orig/main.c	2026-10-18 10:00:00.000000000 +0000
++++ project/main.c	2026-10-18 10:05:00.000000000 +0000
+@@ -1,3 +1,3 @@ int main(void)
+ #include <stdio.h>
+-int x = 1;
++int x = 2;
+
+`
+
+	diff, err := unidiff.NewParser().Parse(strings.NewReader(input))
+
+	require.NoError(t, err)
+	require.Len(t, diff.Files, 1)
+	file := diff.Files[0]
+	assert.Equal(t, "main.c", file.OldPath)
+	assert.Equal(t, "main.c", file.NewPath)
+	assert.Equal(t, diffview.FileModified, file.Operation)
+	require.Len(t, file.Hunks, 1)
+	hunk := file.Hunks[0]
+	assert.Equal(t, "int main(void)", hunk.Section)
+	require.Len(t, hunk.Lines, 4)
+	assert.Equal(t, diffview.Line{Type: diffview.LineAdded, Content: "int x = 2;\n", NewLineNum: 2}, hunk.Lines[2])
+	assert.Equal(t, diffview.Line{Type: diffview.LineContext, Content: "\n", OldLineNum: 3, NewLineNum: 3}, hunk.Lines[3], "an empty context line without its space")
+}
+
+func TestParser_Parse_Hg(t *testing.T) {
+	t.Parallel()
+
+	input := `diff -r 1a2b3c4d5e6f src/app.py
+--- a/src/app.py	Sun Oct 18 10:00:00 2026 +0000
++++ b/src/app.py	Sun Oct 18 10:05:00 2026 +0000
+@@ -1,1 +1,1 @@
+-print("hi")
++print("hello")
+diff -r 1a2b3c4d5e6f src/new.py
+--- /dev/null	Thu Jan 01 00:00:00 1970 +0000
++++ b/src/new.py	Sun Oct 18 10:05:00 2026 +0000
+@@ -0,0 +1,1 @@
++pass
+`
+
+	diff, err := unidiff.NewParser().Parse(strings.NewReader(input))
+
+	require.NoError(t, err)
+	require.Len(t, diff.Files, 2)
+	assert.Equal(t, "src/app.py", diff.Files[0].NewPath)
+	assert.Equal(t, []string{"diff -r 1a2b3c4d5e6f src/app.py"}, diff.Files[0].Extended)
+	assert.Equal(t, diffview.FileAdded, diff.Files[1].Operation)
+	assert.Empty(t, diff.Files[1].OldPath)
+	assert.Equal(t, "src/new.py", diff.Files[1].NewPath)
+}
+
+func TestParser_Parse_Svn(t *testing.T) {
+	t.Parallel()
+
+	input := `Index: trunk/old.txt
+===================================================================
+--- trunk/old.txt	(revision 41)
++++ trunk/old.txt	(working copy)
+@@ -1,2 +0,0 @@
+-first
+-second
+Index: trunk/notes.txt
+===================================================================
+--- trunk/notes.txt	(revision 41)
++++ trunk/notes.txt	(working copy)
+@@ -1,2 +1,2 @@
+--- not a header
++-- still not a header
+ end
+`
+
+	diff, err := unidiff.NewParser().Parse(strings.NewReader(input))
+
+	require.NoError(t, err)
+	require.Len(t, diff.Files, 2)
+	assert.Equal(t, diffview.FileDeleted, diff.Files[0].Operation, "a hunk to nothing deletes the file")
+	assert.Equal(t, []string{"Index: trunk/old.txt"}, diff.Files[0].Extended)
+
+	file := diff.Files[1]
+	assert.Equal(t, "trunk/notes.txt", file.Path())
+	require.Len(t, file.Hunks, 1)
+	require.Len(t, file.Hunks[0].Lines, 3, "a deleted line starting with -- is not a file header")
+	assert.Equal(t, "-- not a header\n", file.Hunks[0].Lines[0].Content)
+}
+
+func TestParser_Parse_Rename(t *testing.T) {
+	t.Parallel()
+
+	input := `--- before.txt
++++ after.txt
+@@ -1 +1 @@
+-a
+\ No newline at end of file
++b
+\ No newline at end of file
+`
+
+	diff, err := unidiff.NewParser().Parse(strings.NewReader(input))
+
+	require.NoError(t, err)
+	require.Len(t, diff.Files, 1)
+	file := diff.Files[0]
+	assert.Equal(t, diffview.FileRenamed, file.Operation)
+	assert.Equal(t, "before.txt", file.OldPath)
+	assert.Equal(t, "after.txt", file.NewPath)
+	assert.True(t, file.Hunks[0].Lines[0].NoNewline)
+	assert.True(t, file.Hunks[0].Lines[1].NoNewline)
+}
+
+func TestParser_Parse_Binary(t *testing.T) {
+	t.Parallel()
+
+	diff, err := unidiff.NewParser().Parse(strings.NewReader("Binary files a/logo.png and b/logo.png differ\n"))
+
+	require.NoError(t, err)
+	require.Len(t, diff.Files, 1)
+	assert.True(t, diff.Files[0].IsBinary)
+	assert.Equal(t, "logo.png", diff.Files[0].Path())
+}
+
+func TestParser_Parse_InvalidHunkHeader(t *testing.T) {
+	t.Parallel()
+
+	_, err := unidiff.NewParser().Parse(strings.NewReader("--- a/x\n+++ b/x\n@@ -1,x +1 @@\n"))
+
+	require.Error(t, err)
+}
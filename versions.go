@@ -0,0 +1,92 @@
+package diffview
+
+import (
+	"context"
+	"strings"
+)
+
+// FileVersions holds the full content of a file on both sides of a diff, so
+// viewers can tokenize whole files instead of isolated hunks.
+type FileVersions struct {
+	Old string // Content at the old revision; empty for added files
+	New string // Content at the new revision; empty for deleted files
+}
+
+// FileVersionSource provides the full content of the files in a diff, for
+// viewers that tokenize whole files or expand context around hunks.
+type FileVersionSource interface {
+	// FileVersions returns the content of the diff's files keyed by
+	// FileDiff.Path(). Files that can't be read are left out.
+	FileVersions(ctx context.Context, diff *Diff) (map[string]FileVersions, error)
+}
+
+// LoadFileVersions fetches the old and new content of every text file in
+// diff from git, keyed by FileDiff.Path(). Renamed files are read from their
+// old path on the old side. Files that can't be read are left out, so callers
+// fall back to per-hunk highlighting for them; only a cancelled context is
+// returned as an error.
+func LoadFileVersions(ctx context.Context, runner GitRunner, repoPath, oldRev, newRev string, diff *Diff) (map[string]FileVersions, error) {
+	return loadFileVersions(ctx, runner, repoPath, oldRev, newRev, true, diff)
+}
+
+// LoadOldFileVersions is like LoadFileVersions but only reads the old side,
+// for callers that take the new content from elsewhere, such as the working
+// tree.
+func LoadOldFileVersions(ctx context.Context, runner GitRunner, repoPath, oldRev string, diff *Diff) (map[string]FileVersions, error) {
+	return loadFileVersions(ctx, runner, repoPath, oldRev, "", false, diff)
+}
+
+// loadFileVersions reads the old side of every file and, when readNew is
+// set, the new side.
+func loadFileVersions(ctx context.Context, runner GitRunner, repoPath, oldRev, newRev string, readNew bool, diff *Diff) (map[string]FileVersions, error) {
+	if diff == nil {
+		return nil, nil
+	}
+	versions := make(map[string]FileVersions)
+	for _, file := range diff.Files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if file.IsBinary || len(file.Hunks) == 0 {
+			continue
+		}
+
+		var v FileVersions
+		var err error
+		if file.Operation != FileAdded {
+			v.Old, err = runner.ShowFile(ctx, repoPath, oldRev, file.OldPath)
+			if err != nil {
+				continue
+			}
+		}
+		if readNew && file.Operation != FileDeleted {
+			v.New, err = runner.ShowFile(ctx, repoPath, newRev, file.NewPath)
+			if err != nil {
+				continue
+			}
+		}
+		versions[file.Path()] = v
+	}
+	return versions, nil
+}
+
+// FileLines is the content of a file on both sides of a diff, one entry per
+// line without line endings. A side that isn't known is nil.
+type FileLines struct {
+	Old []string
+	New []string
+}
+
+// Lines returns the content split into lines.
+func (v FileVersions) Lines() FileLines {
+	return FileLines{Old: contentLines(v.Old), New: contentLines(v.New)}
+}
+
+// contentLines splits content into lines without line endings, or returns
+// nil for no content.
+func contentLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
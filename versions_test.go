@@ -0,0 +1,90 @@
+package diffview_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFileVersions(t *testing.T) {
+	t.Parallel()
+
+	hunk := diffview.Hunk{Lines: []diffview.Line{addLine("x", 1)}}
+	diff := &diffview.Diff{Files: []diffview.FileDiff{
+		{OldPath: "old.go", NewPath: "new.go", Operation: diffview.FileRenamed, Hunks: []diffview.Hunk{hunk}},
+		{NewPath: "added.go", Operation: diffview.FileAdded, Hunks: []diffview.Hunk{hunk}},
+		{OldPath: "deleted.go", Operation: diffview.FileDeleted, Hunks: []diffview.Hunk{hunk}},
+		{OldPath: "image.png", NewPath: "image.png", IsBinary: true},
+		{OldPath: "gone.go", NewPath: "gone.go", Hunks: []diffview.Hunk{hunk}},
+		{OldPath: "a/x.go", NewPath: "a/x.go", Hunks: []diffview.Hunk{hunk}},
+	}}
+
+	var shown []string
+	runner := &mock.GitRunner{
+		ShowFileFn: func(_ context.Context, repoPath, rev, path string) (string, error) {
+			assert.Equal(t, "/repo", repoPath)
+			shown = append(shown, rev+":"+path)
+			if path == "gone.go" {
+				return "", errors.New("git show failed")
+			}
+			return rev + " " + path, nil
+		},
+	}
+
+	versions, err := diffview.LoadFileVersions(context.Background(), runner, "/repo", "base", "head", diff)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]diffview.FileVersions{
+		"new.go":     {Old: "base old.go", New: "head new.go"},
+		"added.go":   {New: "head added.go"},
+		"deleted.go": {Old: "base deleted.go"},
+		"a/x.go":     {Old: "base a/x.go", New: "head a/x.go"},
+	}, versions)
+	assert.NotContains(t, shown, "base:image.png", "binary files are not read")
+}
+
+func TestLoadOldFileVersions(t *testing.T) {
+	t.Parallel()
+
+	hunk := diffview.Hunk{Lines: []diffview.Line{addLine("x", 1)}}
+	diff := &diffview.Diff{Files: []diffview.FileDiff{
+		{OldPath: "main.go", NewPath: "main.go", Hunks: []diffview.Hunk{hunk}},
+		{NewPath: "added.go", Operation: diffview.FileAdded, Hunks: []diffview.Hunk{hunk}},
+	}}
+
+	var shown []string
+	runner := &mock.GitRunner{
+		ShowFileFn: func(_ context.Context, _, rev, path string) (string, error) {
+			shown = append(shown, rev+":"+path)
+			return rev + " " + path, nil
+		},
+	}
+
+	versions, err := diffview.LoadOldFileVersions(context.Background(), runner, "/repo", "HEAD", diff)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]diffview.FileVersions{
+		"main.go":  {Old: "HEAD main.go"},
+		"added.go": {},
+	}, versions)
+	assert.Equal(t, []string{"HEAD:main.go"}, shown)
+}
+
+func TestLoadFileVersions_Cancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	diff := &diffview.Diff{Files: []diffview.FileDiff{
+		{OldPath: "a.go", NewPath: "a.go", Hunks: []diffview.Hunk{{Lines: []diffview.Line{addLine("x", 1)}}}},
+	}}
+
+	_, err := diffview.LoadFileVersions(ctx, &mock.GitRunner{}, "/repo", "base", "head", diff)
+
+	require.ErrorIs(t, err, context.Canceled)
+}
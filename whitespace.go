@@ -0,0 +1,304 @@
+package diffview
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// IsWhitespaceOnly reports whether the hunk changes lines but every change
+// disappears when whitespace and blank lines are ignored, like
+// git diff -w --ignore-blank-lines: re-indentation, trailing spaces, and
+// added or removed blank lines.
+func (h Hunk) IsWhitespaceOnly() bool {
+	if !h.hasChanges() {
+		return false
+	}
+	for i := 0; i < len(h.Lines); {
+		if h.Lines[i].Type == LineContext {
+			i++
+			continue
+		}
+
+		// Within each change run, the non-blank deleted and added lines
+		// must match one for one once whitespace is stripped.
+		var deleted, added []string
+		for ; i < len(h.Lines) && h.Lines[i].Type != LineContext; i++ {
+			key := stripWhitespace(h.Lines[i].Content)
+			switch {
+			case key == "":
+			case h.Lines[i].Type == LineDeleted:
+				deleted = append(deleted, key)
+			default:
+				added = append(added, key)
+			}
+		}
+		if !slices.Equal(deleted, added) {
+			return false
+		}
+	}
+	return true
+}
+
+// IgnoringWhitespace returns a copy of the hunk without changes that only
+// differ in whitespace. A deleted and an added line that match with all
+// whitespace removed become one context line showing the new content, and
+// added or deleted blank lines are dropped. Line numbers and the hunk range
+// are kept, so comments and hunk references still apply.
+func (h Hunk) IgnoringWhitespace() Hunk {
+	lines := make([]Line, 0, len(h.Lines))
+	for i := 0; i < len(h.Lines); {
+		if h.Lines[i].Type == LineContext {
+			lines = append(lines, h.Lines[i])
+			i++
+			continue
+		}
+
+		// Split the run of changed lines into its deleted and added lines
+		var deleted, added []Line
+		for ; i < len(h.Lines) && h.Lines[i].Type != LineContext; i++ {
+			if h.Lines[i].Type == LineDeleted {
+				deleted = append(deleted, h.Lines[i])
+			} else {
+				added = append(added, h.Lines[i])
+			}
+		}
+		lines = append(lines, ignoreWhitespaceRun(deleted, added)...)
+	}
+
+	h.Lines = lines
+	return h
+}
+
+// IgnoringWhitespace returns a copy of the diff with every hunk passed
+// through Hunk.IgnoringWhitespace. Files and hunks keep their positions.
+func (d *Diff) IgnoringWhitespace() *Diff {
+	if d == nil {
+		return nil
+	}
+	files := make([]FileDiff, len(d.Files))
+	for i, file := range d.Files {
+		hunks := make([]Hunk, len(file.Hunks))
+		for j, hunk := range file.Hunks {
+			hunks[j] = hunk.IgnoringWhitespace()
+		}
+		file.Hunks = hunks
+		files[i] = file
+	}
+	return &Diff{Files: files}
+}
+
+// hasChanges reports whether the hunk has added or deleted lines.
+func (h Hunk) hasChanges() bool {
+	for _, line := range h.Lines {
+		if line.Type != LineContext {
+			return true
+		}
+	}
+	return false
+}
+
+// maxWhitespaceCells caps the size of the LCS table built for one change
+// run. Larger runs are matched positionally.
+const maxWhitespaceCells = 512 * 512
+
+// ignoreWhitespaceRun matches the deleted and added lines of one change run
+// by their content without whitespace (longest common subsequence, or by
+// position for runs beyond maxWhitespaceCells). Matched pairs become context
+// lines; unmatched blank lines are dropped and the remaining lines stay
+// changed, deletions before additions.
+func ignoreWhitespaceRun(deleted, added []Line) []Line {
+	m, n := len(deleted), len(added)
+	oldKeys := make([]string, m)
+	for i, line := range deleted {
+		oldKeys[i] = stripWhitespace(line.Content)
+	}
+	newKeys := make([]string, n)
+	for j, line := range added {
+		newKeys[j] = stripWhitespace(line.Content)
+	}
+
+	// table[i*stride+j] is the LCS length of oldKeys[i:] and newKeys[j:]
+	var table []int
+	stride := n + 1
+	aligned := m*n <= maxWhitespaceCells
+	if aligned {
+		table = make([]int, (m+1)*stride)
+		for i := m - 1; i >= 0; i-- {
+			for j := n - 1; j >= 0; j-- {
+				switch {
+				case oldKeys[i] == newKeys[j]:
+					table[i*stride+j] = table[(i+1)*stride+j+1] + 1
+				case table[(i+1)*stride+j] >= table[i*stride+j+1]:
+					table[i*stride+j] = table[(i+1)*stride+j]
+				default:
+					table[i*stride+j] = table[i*stride+j+1]
+				}
+			}
+		}
+	}
+
+	lines := make([]Line, 0, m+n)
+	var pendingDeleted, pendingAdded []Line
+	flush := func() {
+		lines = append(lines, pendingDeleted...)
+		lines = append(lines, pendingAdded...)
+		pendingDeleted, pendingAdded = nil, nil
+	}
+	keep := func(pending []Line, line Line, key string) []Line {
+		if key == "" {
+			return pending
+		}
+		return append(pending, line)
+	}
+
+	i, j := 0, 0
+	for i < m && j < n {
+		switch {
+		case oldKeys[i] == newKeys[j]:
+			flush()
+			line := added[j]
+			line.Type = LineContext
+			line.OldLineNum = deleted[i].OldLineNum
+			lines = append(lines, line)
+			i++
+			j++
+		case !aligned:
+			pendingDeleted = keep(pendingDeleted, deleted[i], oldKeys[i])
+			pendingAdded = keep(pendingAdded, added[j], newKeys[j])
+			i++
+			j++
+		case table[(i+1)*stride+j] >= table[i*stride+j+1]:
+			pendingDeleted = keep(pendingDeleted, deleted[i], oldKeys[i])
+			i++
+		default:
+			pendingAdded = keep(pendingAdded, added[j], newKeys[j])
+			j++
+		}
+	}
+	for ; i < m; i++ {
+		pendingDeleted = keep(pendingDeleted, deleted[i], oldKeys[i])
+	}
+	for ; j < n; j++ {
+		pendingAdded = keep(pendingAdded, added[j], newKeys[j])
+	}
+	flush()
+	return lines
+}
+
+// stripWhitespace removes all whitespace from s.
+func stripWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
+
+// WhitespaceClassifier classifies whitespace-only hunks itself, as collapsed
+// noise, and passes the rest of the diff to an inner StoryClassifier. The
+// inner classifier never sees whitespace-only hunks; its hunk references are
+// mapped back to the full diff.
+type WhitespaceClassifier struct {
+	inner StoryClassifier
+}
+
+// NewWhitespaceClassifier wraps inner with whitespace-only hunk detection.
+func NewWhitespaceClassifier(inner StoryClassifier) *WhitespaceClassifier {
+	return &WhitespaceClassifier{inner: inner}
+}
+
+// Whitespace-only hunks are grouped into a final section.
+const (
+	whitespaceSectionTitle = "Whitespace-only changes"
+	whitespaceCollapseText = "Whitespace-only change"
+)
+
+// Classify implements StoryClassifier.
+func (c *WhitespaceClassifier) Classify(ctx context.Context, input ClassificationInput) (*StoryClassification, error) {
+	filtered, indices, noise := splitWhitespaceHunks(input.Diff)
+	if len(noise) == 0 {
+		return c.inner.Classify(ctx, input)
+	}
+
+	section := Section{
+		Role:        "cleanup",
+		Title:       whitespaceSectionTitle,
+		Hunks:       noise,
+		Explanation: "These hunks only change whitespace; they were detected without the classifier.",
+	}
+
+	// Nothing left for the inner classifier
+	if !hasHunks(filtered) {
+		return &StoryClassification{
+			ChangeType: "chore",
+			Narrative:  "core-periphery",
+			Summary:    "Whitespace-only changes.",
+			Sections:   []Section{section},
+		}, nil
+	}
+
+	input.Diff = filtered
+	story, err := c.inner.Classify(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Map hunk references back to the full diff without touching the
+	// inner classifier's result.
+	result := *story
+	result.Sections = make([]Section, 0, len(story.Sections)+1)
+	for _, s := range story.Sections {
+		hunks := make([]HunkRef, len(s.Hunks))
+		for i, ref := range s.Hunks {
+			if orig, ok := indices[ref.File]; ok && ref.HunkIndex >= 0 && ref.HunkIndex < len(orig) {
+				ref.HunkIndex = orig[ref.HunkIndex]
+			}
+			hunks[i] = ref
+		}
+		s.Hunks = hunks
+		result.Sections = append(result.Sections, s)
+	}
+	result.Sections = append(result.Sections, section)
+	return &result, nil
+}
+
+// splitWhitespaceHunks removes whitespace-only hunks from diff. It returns
+// the filtered diff, the original index of every kept hunk by file path, and
+// noise references for the removed hunks. Files left without hunks are
+// dropped; files that had no hunks to begin with are kept.
+func splitWhitespaceHunks(diff Diff) (Diff, map[string][]int, []HunkRef) {
+	var filtered Diff
+	indices := make(map[string][]int)
+	var noise []HunkRef
+	for _, file := range diff.Files {
+		path := file.Path()
+		var hunks []Hunk
+		for i, hunk := range file.Hunks {
+			if hunk.IsWhitespaceOnly() {
+				noise = append(noise, HunkRef{
+					File:         path,
+					HunkIndex:    i,
+					Category:     "noise",
+					Collapsed:    true,
+					CollapseText: whitespaceCollapseText,
+				})
+				continue
+			}
+			hunks = append(hunks, hunk)
+			indices[path] = append(indices[path], i)
+		}
+		if len(file.Hunks) > 0 && len(hunks) == 0 {
+			continue
+		}
+		file.Hunks = hunks
+		filtered.Files = append(filtered.Files, file)
+	}
+	return filtered, indices, noise
+}
+
+// hasHunks reports whether any file in diff has hunks.
+func hasHunks(diff Diff) bool {
+	for _, file := range diff.Files {
+		if len(file.Hunks) > 0 {
+			return true
+		}
+	}
+	return false
+}
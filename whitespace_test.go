@@ -0,0 +1,251 @@
+package diffview_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/fwojciec/diffstory"
+	"github.com/fwojciec/diffstory/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func delLine(content string, oldNum int) diffview.Line {
+	return diffview.Line{Type: diffview.LineDeleted, Content: content, OldLineNum: oldNum}
+}
+
+func addLine(content string, newNum int) diffview.Line {
+	return diffview.Line{Type: diffview.LineAdded, Content: content, NewLineNum: newNum}
+}
+
+func ctxLine(content string, oldNum, newNum int) diffview.Line {
+	return diffview.Line{Type: diffview.LineContext, Content: content, OldLineNum: oldNum, NewLineNum: newNum}
+}
+
+func TestHunk_IsWhitespaceOnly(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		lines []diffview.Line
+		want  bool
+	}{
+		{
+			name:  "re-indented line",
+			lines: []diffview.Line{delLine("  return x\n", 1), addLine("\treturn x\n", 1)},
+			want:  true,
+		},
+		{
+			name:  "trailing whitespace removed",
+			lines: []diffview.Line{delLine("x := 1   \n", 1), addLine("x := 1\n", 1)},
+			want:  true,
+		},
+		{
+			name:  "spacing inside line",
+			lines: []diffview.Line{delLine("a:=b+c\n", 1), addLine("a := b + c\n", 1)},
+			want:  true,
+		},
+		{
+			name:  "blank lines added",
+			lines: []diffview.Line{ctxLine("a\n", 1, 1), addLine("\n", 2), addLine("  \n", 3), ctxLine("b\n", 2, 4)},
+			want:  true,
+		},
+		{
+			name:  "content change",
+			lines: []diffview.Line{delLine("return x\n", 1), addLine("return y\n", 1)},
+			want:  false,
+		},
+		{
+			name:  "re-indent plus new line",
+			lines: []diffview.Line{delLine("  a\n", 1), addLine("\ta\n", 1), addLine("\tb\n", 2)},
+			want:  false,
+		},
+		{
+			name:  "context only",
+			lines: []diffview.Line{ctxLine("a\n", 1, 1)},
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, diffview.Hunk{Lines: tt.lines}.IsWhitespaceOnly())
+		})
+	}
+}
+
+func TestHunk_IgnoringWhitespace(t *testing.T) {
+	t.Parallel()
+
+	t.Run("turns whitespace changes into context", func(t *testing.T) {
+		t.Parallel()
+
+		hunk := diffview.Hunk{
+			OldStart: 10, OldCount: 3, NewStart: 10, NewCount: 4,
+			Lines: []diffview.Line{
+				delLine("if ok {\n", 10),
+				delLine("  run()\n", 11),
+				delLine("}\n", 12),
+				addLine("if ok {\n", 10),
+				addLine("\trun()\n", 11),
+				addLine("\tlog()\n", 12),
+				addLine("}\n", 13),
+			},
+		}
+
+		got := hunk.IgnoringWhitespace()
+
+		assert.Equal(t, []diffview.Line{
+			ctxLine("if ok {\n", 10, 10),
+			ctxLine("\trun()\n", 11, 11),
+			addLine("\tlog()\n", 12),
+			ctxLine("}\n", 12, 13),
+		}, got.Lines)
+		assert.Equal(t, 4, got.NewCount, "hunk range is kept")
+		assert.Len(t, hunk.Lines, 7, "original hunk is unchanged")
+	})
+
+	t.Run("keeps real changes and drops blank lines", func(t *testing.T) {
+		t.Parallel()
+
+		hunk := diffview.Hunk{Lines: []diffview.Line{
+			delLine("old()\n", 1),
+			addLine("new()\n", 1),
+			addLine("\n", 2),
+		}}
+
+		assert.Equal(t, []diffview.Line{delLine("old()\n", 1), addLine("new()\n", 1)}, hunk.IgnoringWhitespace().Lines)
+	})
+}
+
+func TestHunk_LargeWhitespaceRewrite(t *testing.T) {
+	t.Parallel()
+
+	// A rewrite far beyond the LCS cap must still be detected and collapsed
+	// without building a table over every deleted×added pair.
+	const n = 8000
+	var lines []diffview.Line
+	for i := range n {
+		lines = append(lines, delLine(fmt.Sprintf("  line%d()\n", i), i+1))
+	}
+	for i := range n {
+		lines = append(lines, addLine(fmt.Sprintf("\tline%d()\n", i), i+1))
+	}
+	hunk := diffview.Hunk{Lines: lines}
+
+	assert.True(t, hunk.IsWhitespaceOnly())
+
+	got := hunk.IgnoringWhitespace()
+	require.Len(t, got.Lines, n)
+	assert.Equal(t, ctxLine("\tline0()\n", 1, 1), got.Lines[0])
+	assert.Equal(t, ctxLine(fmt.Sprintf("\tline%d()\n", n-1), n, n), got.Lines[n-1])
+
+	lines[len(lines)-1] = addLine("\tchanged()\n", n)
+	assert.False(t, diffview.Hunk{Lines: lines}.IsWhitespaceOnly())
+}
+
+func TestWhitespaceClassifier(t *testing.T) {
+	t.Parallel()
+
+	reindent := diffview.Hunk{Lines: []diffview.Line{delLine("  a\n", 1), addLine("\ta\n", 1)}}
+	change := diffview.Hunk{Lines: []diffview.Line{delLine("a\n", 5), addLine("b\n", 5)}}
+	noise := diffview.HunkRef{File: "a.go", Category: "noise", Collapsed: true, CollapseText: "Whitespace-only change"}
+
+	t.Run("classifies remaining hunks and maps indices back", func(t *testing.T) {
+		t.Parallel()
+
+		input := diffview.ClassificationInput{Repo: "r", Diff: diffview.Diff{Files: []diffview.FileDiff{
+			{NewPath: "a.go", Hunks: []diffview.Hunk{reindent, change}},
+			{NewPath: "b.go", Hunks: []diffview.Hunk{reindent}},
+		}}}
+		inner := &mock.StoryClassifier{
+			ClassifyFn: func(_ context.Context, got diffview.ClassificationInput) (*diffview.StoryClassification, error) {
+				// Only the real change reaches the inner classifier
+				require.Len(t, got.Diff.Files, 1)
+				assert.Equal(t, []diffview.Hunk{change}, got.Diff.Files[0].Hunks)
+				assert.Equal(t, "r", got.Repo)
+				return &diffview.StoryClassification{
+					ChangeType: "bugfix",
+					Sections: []diffview.Section{
+						{Role: "fix", Hunks: []diffview.HunkRef{{File: "a.go", HunkIndex: 0, Category: "core"}}},
+					},
+				}, nil
+			},
+		}
+
+		story, err := diffview.NewWhitespaceClassifier(inner).Classify(context.Background(), input)
+
+		require.NoError(t, err)
+		assert.Equal(t, "bugfix", story.ChangeType)
+		require.Len(t, story.Sections, 2)
+		assert.Equal(t, []diffview.HunkRef{{File: "a.go", HunkIndex: 1, Category: "core"}}, story.Sections[0].Hunks)
+
+		second := noise
+		second.File = "b.go"
+		assert.Equal(t, []diffview.HunkRef{noise, second}, story.Sections[1].Hunks)
+		assert.Equal(t, "Whitespace-only changes", story.Sections[1].Title)
+		assert.Empty(t, diffview.ValidateClassification(&input.Diff, story))
+	})
+
+	t.Run("skips inner classifier when all hunks are whitespace-only", func(t *testing.T) {
+		t.Parallel()
+
+		input := diffview.ClassificationInput{Diff: diffview.Diff{Files: []diffview.FileDiff{
+			{NewPath: "a.go", Hunks: []diffview.Hunk{reindent}},
+		}}}
+		inner := &mock.StoryClassifier{
+			ClassifyFn: func(context.Context, diffview.ClassificationInput) (*diffview.StoryClassification, error) {
+				t.Fatal("inner classifier should not be called")
+				return nil, nil
+			},
+		}
+
+		story, err := diffview.NewWhitespaceClassifier(inner).Classify(context.Background(), input)
+
+		require.NoError(t, err)
+		assert.Equal(t, "chore", story.ChangeType)
+		require.Len(t, story.Sections, 1)
+		assert.Equal(t, []diffview.HunkRef{noise}, story.Sections[0].Hunks)
+	})
+
+	t.Run("passes input through without whitespace-only hunks", func(t *testing.T) {
+		t.Parallel()
+
+		input := diffview.ClassificationInput{Diff: diffview.Diff{Files: []diffview.FileDiff{
+			{NewPath: "a.go", Hunks: []diffview.Hunk{change}},
+		}}}
+		want := &diffview.StoryClassification{ChangeType: "feature"}
+		inner := &mock.StoryClassifier{
+			ClassifyFn: func(_ context.Context, got diffview.ClassificationInput) (*diffview.StoryClassification, error) {
+				assert.Equal(t, input, got)
+				return want, nil
+			},
+		}
+
+		story, err := diffview.NewWhitespaceClassifier(inner).Classify(context.Background(), input)
+
+		require.NoError(t, err)
+		assert.Same(t, want, story)
+	})
+
+	t.Run("returns inner error", func(t *testing.T) {
+		t.Parallel()
+
+		input := diffview.ClassificationInput{Diff: diffview.Diff{Files: []diffview.FileDiff{
+			{NewPath: "a.go", Hunks: []diffview.Hunk{reindent, change}},
+		}}}
+		errInner := errors.New("boom")
+		inner := &mock.StoryClassifier{
+			ClassifyFn: func(context.Context, diffview.ClassificationInput) (*diffview.StoryClassification, error) {
+				return nil, errInner
+			},
+		}
+
+		_, err := diffview.NewWhitespaceClassifier(inner).Classify(context.Background(), input)
+
+		require.ErrorIs(t, err, errInner)
+	})
+}
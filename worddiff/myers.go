@@ -0,0 +1,143 @@
+package worddiff
+
+// match pairs an old token with the new token it is unchanged as.
+type match struct{ oldIdx, newIdx int }
+
+// myersMatches returns the positions of a longest common subsequence of a
+// and b in increasing order, using Myers' O(ND) algorithm with the
+// linear-space divide and conquer refinement: each step finds where the
+// forward and reverse searches for the shortest edit script overlap and
+// splits the problem there. Time is O((N+M)·D) and space O(N+M), where D is
+// the number of differing tokens.
+func myersMatches(a, b []string) []match {
+	size := len(a) + len(b) + 3
+	s := &myers{
+		a:        a,
+		b:        b,
+		forward:  make([]int, size),
+		backward: make([]int, size),
+	}
+	s.compare(0, len(a), 0, len(b))
+	return s.matches
+}
+
+// myers holds the inputs, the reusable search vectors and the matches found
+// so far. Subproblems are solved in order, so matches stay sorted.
+type myers struct {
+	a, b              []string
+	forward, backward []int
+	matches           []match
+}
+
+// compare appends the matches between a[aLo:aHi] and b[bLo:bHi].
+func (s *myers) compare(aLo, aHi, bLo, bHi int) {
+	// Common prefix and suffix match directly
+	for aLo < aHi && bLo < bHi && s.a[aLo] == s.b[bLo] {
+		s.matches = append(s.matches, match{aLo, bLo})
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && s.a[aHi-suffix-1] == s.b[bHi-suffix-1] {
+		suffix++
+	}
+	aHi -= suffix
+	bHi -= suffix
+
+	if aLo < aHi && bLo < bHi {
+		if x, y, ok := s.bisect(aLo, aHi, bLo, bHi); ok {
+			s.compare(aLo, x, bLo, y)
+			s.compare(x, aHi, y, bHi)
+		}
+	}
+
+	for i := suffix; i > 0; i-- {
+		s.matches = append(s.matches, match{aHi + suffix - i, bHi + suffix - i})
+	}
+}
+
+// bisect finds a point (x, y) on a shortest edit script between a[aLo:aHi]
+// and b[bLo:bHi], where the forward and reverse searches meet. It reports
+// false when the ranges have nothing in common.
+func (s *myers) bisect(aLo, aHi, bLo, bHi int) (x, y int, ok bool) {
+	n, m := aHi-aLo, bHi-bLo
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	length := 2 * maxD
+	v1 := s.forward[:length+2]
+	v2 := s.backward[:length+2]
+	for i := range v1 {
+		v1[i] = -1
+		v2[i] = -1
+	}
+	v1[offset+1] = 0
+	v2[offset+1] = 0
+
+	// With an odd delta the paths can only meet on a forward step, with an
+	// even one on a reverse step.
+	delta := n - m
+	front := delta%2 != 0
+
+	// Diagonals that ran off the edge of the grid are trimmed from the search
+	k1start, k1end, k2start, k2end := 0, 0, 0, 0
+	for d := 0; d < maxD; d++ {
+		for k1 := -d + k1start; k1 <= d-k1end; k1 += 2 {
+			k1Offset := offset + k1
+			var x1 int
+			if k1 == -d || (k1 != d && v1[k1Offset-1] < v1[k1Offset+1]) {
+				x1 = v1[k1Offset+1]
+			} else {
+				x1 = v1[k1Offset-1] + 1
+			}
+			y1 := x1 - k1
+			for x1 < n && y1 < m && s.a[aLo+x1] == s.b[bLo+y1] {
+				x1++
+				y1++
+			}
+			v1[k1Offset] = x1
+			switch {
+			case x1 > n:
+				k1end += 2
+			case y1 > m:
+				k1start += 2
+			case front:
+				k2Offset := offset + delta - k1
+				if k2Offset >= 0 && k2Offset < length && v2[k2Offset] != -1 && x1 >= n-v2[k2Offset] {
+					return aLo + x1, bLo + y1, true
+				}
+			}
+		}
+
+		for k2 := -d + k2start; k2 <= d-k2end; k2 += 2 {
+			k2Offset := offset + k2
+			var x2 int
+			if k2 == -d || (k2 != d && v2[k2Offset-1] < v2[k2Offset+1]) {
+				x2 = v2[k2Offset+1]
+			} else {
+				x2 = v2[k2Offset-1] + 1
+			}
+			y2 := x2 - k2
+			for x2 < n && y2 < m && s.a[aHi-x2-1] == s.b[bHi-y2-1] {
+				x2++
+				y2++
+			}
+			v2[k2Offset] = x2
+			switch {
+			case x2 > n:
+				k2end += 2
+			case y2 > m:
+				k2start += 2
+			case !front:
+				k1Offset := offset + delta - k2
+				if k1Offset >= 0 && k1Offset < length && v1[k1Offset] != -1 {
+					x1 := v1[k1Offset]
+					y1 := offset + x1 - k1Offset
+					if x1 >= n-x2 {
+						return aLo + x1, bLo + y1, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
@@ -151,12 +151,21 @@ func isWhitespace(c byte) bool {
 }
 
 // Compile-time interface verification.
-var _ diffview.WordDiffer = (*Differ)(nil)
+var (
+	_ diffview.WordDiffer           = (*Differ)(nil)
+	_ diffview.WhitespaceWordDiffer = (*Differ)(nil)
+	_ diffview.SimilarityScorer     = (*Differ)(nil)
+)
 
 // similarityThreshold is the minimum ratio for word-level diffing.
 // Below this threshold, lines are treated as complete replacements.
 const similarityThreshold = 0.4
 
+// maxDiffTokens caps the combined token count of a line pair. Longer pairs,
+// like minified JavaScript or generated SQL, are shown as whole-line
+// replacements rather than stalling rendering.
+const maxDiffTokens = 5000
+
 // Diff returns segments for both the old and new strings,
 // marking which portions changed between them.
 func (d *Differ) Diff(old, new string) (oldSegs, newSegs []diffview.Segment) {
@@ -181,21 +190,97 @@ func (d *Differ) Diff(old, new string) (oldSegs, newSegs []diffview.Segment) {
 	newTokens := d.Tokenize(new)
 
 	// Quick similarity check: count common tokens
-	if !hasSufficientSimilarity(oldTokens, newTokens) {
+	if len(oldTokens)+len(newTokens) > maxDiffTokens || !hasSufficientSimilarity(oldTokens, newTokens) {
+		return []diffview.Segment{{Text: old, Changed: true}},
+			[]diffview.Segment{{Text: new, Changed: true}}
+	}
+
+	// Match tokens and build pre-merged segments
+	return diffSegments(oldTokens, newTokens, oldTokens, newTokens)
+}
+
+// DiffIgnoringWhitespace is like Diff but treats whitespace changes as
+// unchanged: lines that only differ in whitespace are entirely unchanged, and
+// changed segments made only of whitespace are merged into their unchanged
+// neighbors.
+func (d *Differ) DiffIgnoringWhitespace(old, new string) (oldSegs, newSegs []diffview.Segment) {
+	if old != "" && new != "" && strings.Join(strings.Fields(old), "") == strings.Join(strings.Fields(new), "") {
+		return []diffview.Segment{{Text: old, Changed: false}},
+			[]diffview.Segment{{Text: new, Changed: false}}
+	}
+	if old == "" || new == "" {
+		return d.Diff(old, new)
+	}
+
+	// Match on keys where every whitespace run is the same, so resized
+	// whitespace lines up with its counterpart
+	oldTokens := d.Tokenize(old)
+	newTokens := d.Tokenize(new)
+	oldKeys := whitespaceKeys(oldTokens)
+	newKeys := whitespaceKeys(newTokens)
+	if len(oldKeys)+len(newKeys) > maxDiffTokens || !hasSufficientSimilarity(oldKeys, newKeys) {
 		return []diffview.Segment{{Text: old, Changed: true}},
 			[]diffview.Segment{{Text: new, Changed: true}}
 	}
 
-	// Compute LCS and build pre-merged segments
-	return lcsSegments(oldTokens, newTokens)
+	oldSegs, newSegs = diffSegments(oldTokens, newTokens, oldKeys, newKeys)
+	return ignoreWhitespaceSegments(oldSegs), ignoreWhitespaceSegments(newSegs)
+}
+
+// whitespaceKeys returns comparison keys for tokens with every whitespace
+// run replaced by a single space.
+func whitespaceKeys(tokens []string) []string {
+	keys := make([]string, len(tokens))
+	for i, t := range tokens {
+		if isWhitespace(t[0]) {
+			keys[i] = " "
+		} else {
+			keys[i] = t
+		}
+	}
+	return keys
+}
+
+// ignoreWhitespaceSegments marks whitespace-only changed segments as
+// unchanged and merges adjacent segments with the same status.
+func ignoreWhitespaceSegments(segs []diffview.Segment) []diffview.Segment {
+	merged := make([]diffview.Segment, 0, len(segs))
+	for _, seg := range segs {
+		if seg.Changed && strings.TrimSpace(seg.Text) == "" {
+			seg.Changed = false
+		}
+		if last := len(merged) - 1; last >= 0 && merged[last].Changed == seg.Changed {
+			merged[last].Text += seg.Text
+			continue
+		}
+		merged = append(merged, seg)
+	}
+	return merged
+}
+
+// Similarity scores how alike two lines are by token overlap, from 0
+// (no tokens in common) to 1 (same tokens). It is the measure Diff uses to
+// decide between word-level highlighting and a whole-line replacement, and
+// is much cheaper than Diff itself.
+func (d *Differ) Similarity(old, new string) float64 {
+	if old == new {
+		return 1
+	}
+	return tokenSimilarity(d.Tokenize(old), d.Tokenize(new))
 }
 
 // hasSufficientSimilarity checks if tokens have enough overlap to warrant word-level diff.
-// Uses a simple count of common tokens as an upper bound estimate.
 func hasSufficientSimilarity(oldTokens, newTokens []string) bool {
+	return tokenSimilarity(oldTokens, newTokens) >= similarityThreshold
+}
+
+// tokenSimilarity returns 2 * common / (len(old) + len(new)), where common
+// counts tokens present in both sequences regardless of order. This is an
+// upper bound on the LCS-based similarity.
+func tokenSimilarity(oldTokens, newTokens []string) float64 {
 	oldLen, newLen := len(oldTokens), len(newTokens)
 	if oldLen == 0 || newLen == 0 {
-		return false
+		return 0
 	}
 
 	// Count tokens in old sequence
@@ -214,64 +299,26 @@ func hasSufficientSimilarity(oldTokens, newTokens []string) bool {
 	}
 
 	// Ratio = 2.0 * common / (len(old) + len(new))
-	// Check if ratio >= threshold
 	total := oldLen + newLen
-	return float64(2*common)/float64(total) >= similarityThreshold
+	return float64(2*common) / float64(total)
 }
 
-// lcsSegments computes the LCS of two token sequences and returns merged diff segments.
-// Uses O(n×m) dynamic programming with a flat array to minimize allocations.
+// diffSegments matches two token sequences and returns merged diff segments.
+// Tokens are matched by their keys (usually the tokens themselves) and segments
+// are built from the token text. Matching uses Myers' algorithm (see
+// myersMatches), so time grows with the number of differences rather than the
+// product of the lengths.
 // Returns pre-merged segments to avoid an extra allocation pass.
-func lcsSegments(oldTokens, newTokens []string) (oldSegs, newSegs []diffview.Segment) {
+func diffSegments(oldTokens, newTokens, oldKeys, newKeys []string) (oldSegs, newSegs []diffview.Segment) {
 	m, n := len(oldTokens), len(newTokens)
 
-	// Allocate DP table as a flat slice (single allocation)
-	// table[i*(n+1)+j] corresponds to table[i][j]
-	table := make([]int, (m+1)*(n+1))
-	stride := n + 1
-
-	// Fill DP table
-	for i := 1; i <= m; i++ {
-		for j := 1; j <= n; j++ {
-			if oldTokens[i-1] == newTokens[j-1] {
-				table[i*stride+j] = table[(i-1)*stride+j-1] + 1
-			} else if table[(i-1)*stride+j] > table[i*stride+j-1] {
-				table[i*stride+j] = table[(i-1)*stride+j]
-			} else {
-				table[i*stride+j] = table[i*stride+j-1]
-			}
-		}
-	}
-
-	lcsLen := table[m*stride+n]
-	if lcsLen == 0 {
+	matches := myersMatches(oldKeys, newKeys)
+	if len(matches) == 0 {
 		// No common subsequence
 		return []diffview.Segment{{Text: joinTokens(oldTokens), Changed: true}},
 			[]diffview.Segment{{Text: joinTokens(newTokens), Changed: true}}
 	}
 
-	// Backtrack to find matching positions
-	type match struct{ oldIdx, newIdx int }
-	matches := make([]match, 0, lcsLen)
-
-	i, j := m, n
-	for i > 0 && j > 0 {
-		if oldTokens[i-1] == newTokens[j-1] {
-			matches = append(matches, match{i - 1, j - 1})
-			i--
-			j--
-		} else if table[(i-1)*stride+j] > table[i*stride+j-1] {
-			i--
-		} else {
-			j--
-		}
-	}
-
-	// Reverse matches (backtracking gives them in reverse order)
-	for left, right := 0, len(matches)-1; left < right; left, right = left+1, right-1 {
-		matches[left], matches[right] = matches[right], matches[left]
-	}
-
 	// Estimate total text length for pre-sizing builders
 	oldTotalLen, newTotalLen := 0, 0
 	for _, t := range oldTokens {
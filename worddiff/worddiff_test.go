@@ -1,6 +1,8 @@
 package worddiff_test
 
 import (
+	"math/rand/v2"
+	"strings"
 	"testing"
 
 	"github.com/fwojciec/diffstory"
@@ -386,3 +388,185 @@ func BenchmarkDiffer_Diff(b *testing.B) {
 		}
 	})
 }
+
+func TestDiffIgnoringWhitespace(t *testing.T) {
+	t.Parallel()
+
+	d := worddiff.NewDiffer()
+
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected struct {
+			old []diffview.Segment
+			new []diffview.Segment
+		}
+	}{
+		{
+			name: "whitespace-only difference is unchanged",
+			old:  "x := a+b",
+			new:  "x := a + b",
+			expected: struct {
+				old []diffview.Segment
+				new []diffview.Segment
+			}{
+				old: []diffview.Segment{{Text: "x := a+b", Changed: false}},
+				new: []diffview.Segment{{Text: "x := a + b", Changed: false}},
+			},
+		},
+		{
+			name: "whitespace changes merge into unchanged text",
+			old:  "return  foo(a)",
+			new:  "return bar(a)",
+			expected: struct {
+				old []diffview.Segment
+				new []diffview.Segment
+			}{
+				old: []diffview.Segment{
+					{Text: "return  ", Changed: false},
+					{Text: "foo", Changed: true},
+					{Text: "(a)", Changed: false},
+				},
+				new: []diffview.Segment{
+					{Text: "return ", Changed: false},
+					{Text: "bar", Changed: true},
+					{Text: "(a)", Changed: false},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			oldSegs, newSegs := d.DiffIgnoringWhitespace(tt.old, tt.new)
+
+			assert.Equal(t, tt.expected.old, oldSegs)
+			assert.Equal(t, tt.expected.new, newSegs)
+		})
+	}
+}
+
+func TestDiff_LongLines(t *testing.T) {
+	t.Parallel()
+
+	d := worddiff.NewDiffer()
+
+	t.Run("random edits keep both sides intact", func(t *testing.T) {
+		t.Parallel()
+
+		rng := rand.New(rand.NewPCG(1, 2))
+		words := []string{"a", "b", "c", "foo", "bar", "(", ")", ",", " ", "42"}
+		for range 200 {
+			old := randomLine(rng, words, 200)
+			new := mutateLine(rng, old, words)
+
+			oldSegs, newSegs := d.Diff(old, new)
+
+			assert.Equal(t, old, joinSegments(oldSegs, false))
+			assert.Equal(t, new, joinSegments(newSegs, false))
+			assert.Equal(t, joinSegments(oldSegs, true), joinSegments(newSegs, true),
+				"unchanged text must be common to both sides")
+		}
+	})
+
+	t.Run("pair over the token cutoff is a whole-line replacement", func(t *testing.T) {
+		t.Parallel()
+
+		old := strings.Repeat("x=1;", 1000)
+		new := strings.Repeat("x=1;", 999) + "x=2;"
+
+		oldSegs, newSegs := d.Diff(old, new)
+
+		assert.Equal(t, []diffview.Segment{{Text: old, Changed: true}}, oldSegs)
+		assert.Equal(t, []diffview.Segment{{Text: new, Changed: true}}, newSegs)
+	})
+
+	t.Run("long line under the cutoff gets word-level segments", func(t *testing.T) {
+		t.Parallel()
+
+		old := strings.Repeat("x=1;", 300)
+		new := strings.Repeat("x=1;", 150) + "yy " + strings.Repeat("x=1;", 150)
+
+		oldSegs, newSegs := d.Diff(old, new)
+
+		assert.Equal(t, []diffview.Segment{{Text: old, Changed: false}}, oldSegs)
+		assert.Equal(t, []diffview.Segment{
+			{Text: strings.Repeat("x=1;", 150), Changed: false},
+			{Text: "yy ", Changed: true},
+			{Text: strings.Repeat("x=1;", 150), Changed: false},
+		}, newSegs)
+	})
+}
+
+// randomLine joins n random words.
+func randomLine(rng *rand.Rand, words []string, n int) string {
+	var sb strings.Builder
+	for range n {
+		sb.WriteString(words[rng.IntN(len(words))])
+	}
+	return sb.String()
+}
+
+// mutateLine inserts, deletes or replaces a few words of line.
+func mutateLine(rng *rand.Rand, line string, words []string) string {
+	for range 1 + rng.IntN(10) {
+		pos := rng.IntN(len(line) + 1)
+		switch rng.IntN(3) {
+		case 0:
+			line = line[:pos] + words[rng.IntN(len(words))] + line[pos:]
+		case 1:
+			if pos < len(line) {
+				line = line[:pos] + line[pos+1:]
+			}
+		default:
+			line = line[:pos] + words[rng.IntN(len(words))] + line[min(pos+1, len(line)):]
+		}
+	}
+	return line
+}
+
+// joinSegments concatenates the text of segments, or only the unchanged
+// segments when unchangedOnly is set.
+func joinSegments(segs []diffview.Segment, unchangedOnly bool) string {
+	var sb strings.Builder
+	for _, seg := range segs {
+		if !unchangedOnly || !seg.Changed {
+			sb.WriteString(seg.Text)
+		}
+	}
+	return sb.String()
+}
+
+func BenchmarkDiffer_Diff_LongLines(b *testing.B) {
+	d := worddiff.NewDiffer()
+
+	b.Run("minified_js", func(b *testing.B) {
+		// Long line with a handful of scattered edits
+		oldLine := strings.Repeat("function(a,b){return a+b};var x=[1,2,3];", 100)
+		newLine := strings.Replace(oldLine, "return a+b", "return a-b", 5)
+		for b.Loop() {
+			d.Diff(oldLine, newLine)
+		}
+	})
+
+	b.Run("long_json", func(b *testing.B) {
+		// Generated JSON line with one value changed in the middle
+		oldLine := "{" + strings.Repeat(`"key":"value","n":123,`, 100) + `"end":true}`
+		newLine := strings.Replace(oldLine, `"n":123`, `"n":124`, 1)
+		for b.Loop() {
+			d.Diff(oldLine, newLine)
+		}
+	})
+
+	b.Run("over_cutoff", func(b *testing.B) {
+		// Past the token cutoff: whole-line replacement
+		oldLine := strings.Repeat("x=1;", 5000)
+		newLine := strings.Repeat("x=2;", 5000)
+		for b.Loop() {
+			d.Diff(oldLine, newLine)
+		}
+	})
+}